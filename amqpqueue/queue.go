@@ -0,0 +1,222 @@
+// Package amqpqueue 以 RabbitMQ（AMQP 0-9-1）队列作为 workerpool 的 Job 队列：
+// prefetch 限制同时在途的消息数，通常取 pool 的容量；执行成功时 ack，
+// 失败或 panic 时首次投递的消息 nack 并重新入队，已重投过的消息 nack 且不入队，
+// 由队列配置的 dead-letter exchange 接收
+//
+// 包本身不依赖具体的 AMQP 客户端，使用 rabbitmq/amqp091-go 时包装 *amqp.Channel 与 amqp.Delivery：
+//
+//	func (d delivery) Ack() error               { return d.d.Ack(false) }
+//	func (d delivery) Nack(requeue bool) error  { return d.d.Nack(false, requeue) }
+//	...
+package amqpqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	workerpool "workerpool/pool"
+)
+
+// Delivery 是一条 AMQP 消息
+type Delivery interface {
+	Body() []byte
+	Redelivered() bool
+	Ack() error
+	Nack(requeue bool) error
+}
+
+// Channel 是 Queue 用到的 AMQP channel 操作
+type Channel interface {
+	// Qos 对应 basic.qos，设置未 ack 消息数的上限
+	Qos(prefetch int) error
+	// Consume 对应 basic.consume，ctx 取消时停止消费并关闭返回的 channel
+	Consume(ctx context.Context) (<-chan Delivery, error)
+	Publish(ctx context.Context, body []byte) error
+}
+
+type Config struct {
+	Prefetch int                              // 同时在途的消息数上限，默认 100
+	Logf     func(format string, args ...any) // 日志输出，默认 fmt.Printf
+}
+
+type Queue struct {
+	ch  Channel
+	cfg Config
+
+	once       sync.Once
+	deliveries <-chan Delivery
+	err        error // Consume 失败的错误
+	cancel     context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[*inflight]struct{} // 已取出但尚未 ack/nack 的消息
+	closed  bool
+	idle    chan struct{} // Close 等待 pending 清空，清空时关闭
+}
+
+// Delivery 的实现不一定可比较（如包含 []byte 的结构体），以指针作为 Token
+type inflight struct {
+	d Delivery
+}
+
+var _ workerpool.Queue = (*Queue)(nil)
+
+type message struct {
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	Key     string `json:"key,omitempty"`
+}
+
+var ErrClosed = errors.New("amqpqueue: closed")
+
+func New(ch Channel, cfg Config) *Queue {
+	if cfg.Prefetch <= 0 {
+		cfg.Prefetch = 100
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(format string, args ...any) { fmt.Printf(format, args...) }
+	}
+	return &Queue{ch: ch, cfg: cfg, pending: make(map[*inflight]struct{})}
+}
+
+func (q *Queue) Push(ctx context.Context, j workerpool.Job) error {
+	b, err := json.Marshal(message{Type: j.Type, Payload: j.Payload, Key: j.Key})
+	if err != nil {
+		return err
+	}
+	return q.ch.Publish(ctx, b)
+}
+
+// Len 返回已取出但尚未 ack/nack 的消息数，队列中的积压请用 RabbitMQ 管理工具查看
+func (q *Queue) Len(context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending), nil
+}
+
+func (q *Queue) start() {
+	q.once.Do(func() {
+		if q.err = q.ch.Qos(q.cfg.Prefetch); q.err != nil {
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		q.cancel = cancel
+		q.deliveries, q.err = q.ch.Consume(ctx)
+	})
+}
+
+// Pop 返回下一条消息，首次调用时设置 prefetch 并开始消费；无法解析的消息 nack 且不入队
+func (q *Queue) Pop(ctx context.Context) (workerpool.Delivery, error) {
+	q.start()
+	if q.err != nil {
+		return workerpool.Delivery{}, q.err
+	}
+	for {
+		var d Delivery
+		var ok bool
+		select {
+		case d, ok = <-q.deliveries:
+			if !ok {
+				return workerpool.Delivery{}, ErrClosed
+			}
+		case <-ctx.Done():
+			return workerpool.Delivery{}, ctx.Err()
+		}
+		var m message
+		if err := json.Unmarshal(d.Body(), &m); err != nil {
+			q.cfg.Logf("amqpqueue: bad message: %s\n", err)
+			if err := d.Nack(false); err != nil {
+				q.cfg.Logf("amqpqueue: nack: %s\n", err)
+			}
+			continue
+		}
+		t := &inflight{d}
+		q.mu.Lock()
+		closed := q.closed
+		if !closed {
+			q.pending[t] = struct{}{}
+		}
+		q.mu.Unlock()
+		if closed { // 与 Close 并发取出的消息不再分发
+			if err := d.Nack(true); err != nil {
+				q.cfg.Logf("amqpqueue: nack: %s\n", err)
+			}
+			return workerpool.Delivery{}, ErrClosed
+		}
+		return workerpool.Delivery{Job: workerpool.Job{Type: m.Type, Payload: m.Payload, Key: m.Key}, Token: t}, nil
+	}
+}
+
+// Ack 在执行成功时 ack；失败时首次投递的消息重新入队，重投过的进入 dead-letter
+func (q *Queue) Ack(_ context.Context, wd workerpool.Delivery, err error) error {
+	t, ok := wd.Token.(*inflight)
+	if !ok {
+		return errors.New("amqpqueue: delivery not from this queue")
+	}
+	q.mu.Lock()
+	_, ok = q.pending[t]
+	delete(q.pending, t)
+	if q.idle != nil && len(q.pending) == 0 {
+		close(q.idle)
+		q.idle = nil
+	}
+	q.mu.Unlock()
+	if !ok {
+		return nil // 重复的 Ack
+	}
+	d := t.d
+	if err != nil {
+		return d.Nack(!d.Redelivered())
+	}
+	return d.Ack()
+}
+
+// Close 停止消费，将已收到但尚未由 Pop 取出的消息重新入队，并等待已取出的消息 ack/nack 后返回；
+// ctx 结束时不再等待并返回其错误，仍在执行的消息不会被重新入队，由服务端在 channel 关闭后重投，
+// 因而可以与 pool 的 Free 并发调用；prefetch 中尚未送达的消息同样由服务端重投
+func (q *Queue) Close(ctx context.Context) error {
+	q.once.Do(func() { q.err = ErrClosed }) // 尚未开始消费时不再开始
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	idle := make(chan struct{})
+	if len(q.pending) == 0 {
+		close(idle)
+	} else {
+		q.idle = idle
+	}
+	q.mu.Unlock()
+	if q.cancel != nil {
+		q.cancel()
+	}
+	var errs []error
+	for deliveries := q.deliveries; deliveries != nil; {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				deliveries = nil
+				break
+			}
+			if err := d.Nack(true); err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		q.mu.Lock()
+		n := len(q.pending)
+		q.mu.Unlock()
+		errs = append(errs, fmt.Errorf("amqpqueue: %d deliveries still running: %w", n, ctx.Err()))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,211 @@
+package amqpqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// fakeChannel 模拟一个队列：Nack(true) 的消息以 Redelivered 重新投递，消费停止后记入 requeued
+type fakeChannel struct {
+	mu       sync.Mutex
+	prefetch int
+	out      chan Delivery
+	stopped  bool
+	acked    []string
+	dead     []string // Nack(false) 的消息
+	requeued []string // 消费停止后 Nack(true) 的消息
+}
+
+type fakeDelivery struct {
+	ch          *fakeChannel
+	body        []byte
+	redelivered bool
+}
+
+func newFakeChannel() *fakeChannel {
+	return &fakeChannel{out: make(chan Delivery, 16)}
+}
+
+func (c *fakeChannel) Qos(prefetch int) error {
+	c.prefetch = prefetch
+	return nil
+}
+
+func (c *fakeChannel) Consume(ctx context.Context) (<-chan Delivery, error) {
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		c.stopped = true
+		close(c.out)
+		c.mu.Unlock()
+	}()
+	return c.out, nil
+}
+
+func (c *fakeChannel) Publish(_ context.Context, body []byte) error {
+	return c.deliver(&fakeDelivery{ch: c, body: body})
+}
+
+func (c *fakeChannel) deliver(d *fakeDelivery) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		c.requeued = append(c.requeued, string(d.body))
+		return nil
+	}
+	c.out <- d
+	return nil
+}
+
+func (c *fakeChannel) record(list *[]string, d *fakeDelivery) {
+	c.mu.Lock()
+	*list = append(*list, string(d.body))
+	c.mu.Unlock()
+}
+
+func (c *fakeChannel) counts() (acked, dead, requeued int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.acked), len(c.dead), len(c.requeued)
+}
+
+func (d *fakeDelivery) Body() []byte      { return d.body }
+func (d *fakeDelivery) Redelivered() bool { return d.redelivered }
+func (d *fakeDelivery) Ack() error {
+	d.ch.record(&d.ch.acked, d)
+	return nil
+}
+func (d *fakeDelivery) Nack(requeue bool) error {
+	if !requeue {
+		d.ch.record(&d.ch.dead, d)
+		return nil
+	}
+	return d.ch.deliver(&fakeDelivery{ch: d.ch, body: d.body, redelivered: true})
+}
+
+func pop(t *testing.T, q *Queue) workerpool.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestQueueAck(t *testing.T) {
+	ch := newFakeChannel()
+	q := New(ch, Config{Prefetch: 4})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t", Payload: []byte("a"), Key: "k"})
+	d := pop(t, q)
+	if ch.prefetch != 4 || d.Job.Type != "t" || string(d.Job.Payload) != "a" || d.Job.Key != "k" {
+		t.Fatalf("prefetch %d, job %+v", ch.prefetch, d.Job)
+	}
+	if n, _ := q.Len(ctx); n != 1 {
+		t.Fatalf("Len = %d", n)
+	}
+	if err := q.Ack(ctx, d, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("Len after Ack = %d", n)
+	}
+	if acked, _, _ := ch.counts(); acked != 1 {
+		t.Fatalf("acked = %d", acked)
+	}
+}
+
+func TestQueueNack(t *testing.T) {
+	ch := newFakeChannel()
+	var logged []string
+	q := New(ch, Config{Logf: func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t"})
+	q.Ack(ctx, pop(t, q), errors.New("boom")) // 首次失败重新入队
+	q.Ack(ctx, pop(t, q), errors.New("boom")) // 重投后再失败进入 dead-letter
+	if acked, dead, _ := ch.counts(); acked != 0 || dead != 1 {
+		t.Fatalf("acked = %d, dead = %d", acked, dead)
+	}
+
+	ch.out <- &fakeDelivery{ch: ch, body: []byte("not json")}
+	q.Push(ctx, workerpool.Job{Type: "good"})
+	if d := pop(t, q); d.Job.Type != "good" {
+		t.Fatalf("Pop after a bad message = %+v", d.Job)
+	}
+	if _, dead, _ := ch.counts(); dead != 2 {
+		t.Fatalf("bad message not dead-lettered, dead = %d", dead)
+	}
+	if len(logged) != 1 || !strings.HasPrefix(logged[0], "amqpqueue: bad message") {
+		t.Fatalf("logged %q", logged)
+	}
+}
+
+func TestQueueCloseWaitsForInflight(t *testing.T) {
+	ch := newFakeChannel()
+	q := New(ch, Config{})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "running"})
+	q.Push(ctx, workerpool.Job{Type: "buffered"})
+	d := pop(t, q)
+	closed := make(chan error, 1)
+	go func() { closed <- q.Close(ctx) }()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if _, _, requeued := ch.counts(); requeued == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("undispatched delivery not requeued")
+		}
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("Close returned before the running delivery was acked: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	q.Ack(ctx, d, nil)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if acked, _, requeued := ch.counts(); acked != 1 || requeued != 1 {
+		t.Fatalf("acked = %d, requeued = %d", acked, requeued)
+	}
+	if _, err := q.Pop(ctx); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Pop after Close = %v", err)
+	}
+}
+
+func TestQueueCloseTimeout(t *testing.T) {
+	ch := newFakeChannel()
+	q := New(ch, Config{})
+	q.Push(context.Background(), workerpool.Job{Type: "running"})
+	pop(t, q)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v", err)
+	}
+	if acked, dead, requeued := ch.counts(); acked+dead+requeued != 0 {
+		t.Fatalf("running delivery settled by Close: acked %d, dead %d, requeued %d", acked, dead, requeued)
+	}
+}
+
+func TestQueueCloseBeforePop(t *testing.T) {
+	q := New(newFakeChannel(), Config{})
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Pop(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Pop after Close = %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package ants 为从 github.com/panjf2000/ants 迁移的项目提供兼容层：
+// 只需将 import 改为 workerpool/ants，NewPool、Submit、Running、Free、Cap、Tune、Release 等调用无需修改，
+// 语义与 ants 一致（容量可用 Tune 在运行时调整，满时阻塞或在非阻塞模式下返回 ErrPoolOverload）
+//
+// 任务实际在 workerpool 上执行，worker 按需创建，容量的限制由本包负责；底层 workerpool 的日志同样输出到 Options.Logger
+package ants
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+var (
+	ErrPoolClosed   = errors.New("this pool has been closed")
+	ErrPoolOverload = errors.New("too many goroutines blocked on submit or Nonblocking is set")
+	ErrTimeout      = errors.New("operation timed out")
+)
+
+// 底层 workerpool 的容量，即 Tune 可以调整到的上限；worker 按需创建，不会预先占用
+const maxSize = 10000
+
+type Options struct {
+	Nonblocking      bool      // 为 true 时容量已满的 Submit 立即返回 ErrPoolOverload
+	MaxBlockingTasks int       // 阻塞在 Submit 上的调用数上限，0 表示不限
+	PanicHandler     func(any) // 任务 panic 时调用，nil 时打印日志
+	PreAlloc         bool      // 仅为兼容保留，worker 总是按需创建
+	Logger           Logger    // 任务 panic 与底层 workerpool 的日志输出，nil 时与 ants 一样输出到 stderr
+}
+
+// Logger 与 ants 的 Logger 相同
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+var defaultLogger Logger = log.New(os.Stderr, "[ants]: ", log.LstdFlags)
+
+type Option func(*Options)
+
+func WithOptions(o Options) Option { // 一次设置全部选项
+	return func(opts *Options) {
+		*opts = o
+	}
+}
+
+func WithNonblocking(nonblocking bool) Option { // 容量已满时 Submit 不阻塞
+	return func(opts *Options) {
+		opts.Nonblocking = nonblocking
+	}
+}
+
+func WithMaxBlockingTasks(n int) Option { // 阻塞在 Submit 上的调用数上限
+	return func(opts *Options) {
+		opts.MaxBlockingTasks = n
+	}
+}
+
+func WithPanicHandler(h func(any)) Option { // 任务 panic 时的处理函数
+	return func(opts *Options) {
+		opts.PanicHandler = h
+	}
+}
+
+func WithLogger(logger Logger) Option { // 日志输出
+	return func(opts *Options) {
+		opts.Logger = logger
+	}
+}
+
+func WithPreAlloc(preAlloc bool) Option { // 仅为兼容保留
+	return func(opts *Options) {
+		opts.PreAlloc = preAlloc
+	}
+}
+
+type Pool struct {
+	p    *workerpool.Pool
+	opts Options
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	cap     int
+	running int
+	waiting int
+	closed  bool
+}
+
+// NewPool 创建容量为 size 的 pool，size <= 0 或超过 10000 时为 10000
+func NewPool(size int, options ...Option) (*Pool, error) {
+	var opts Options
+	for _, o := range options {
+		o(&opts)
+	}
+	if size <= 0 || size > maxSize {
+		size = maxSize
+	}
+	if opts.Logger == nil {
+		opts.Logger = defaultLogger
+	}
+	a := &Pool{p: workerpool.New(maxSize, workerpool.WithBlock(true), workerpool.WithLogger(opts.Logger.Printf)), opts: opts, cap: size}
+	a.cond = sync.NewCond(&a.mu)
+	return a, nil
+}
+
+// Submit 提交任务，容量已满时阻塞（或按 Options 返回 ErrPoolOverload）
+func (a *Pool) Submit(task func()) error {
+	a.mu.Lock()
+	for !a.closed && a.running >= a.cap {
+		if a.opts.Nonblocking || (a.opts.MaxBlockingTasks > 0 && a.waiting >= a.opts.MaxBlockingTasks) {
+			a.mu.Unlock()
+			return ErrPoolOverload
+		}
+		a.waiting++
+		a.cond.Wait()
+		a.waiting--
+	}
+	if a.closed {
+		a.mu.Unlock()
+		return ErrPoolClosed
+	}
+	a.running++
+	a.mu.Unlock()
+	// 被接受后未执行即被丢弃（如 Release）的任务同样通过回调归还计数
+	err := a.p.ScheduleWithCallback(func() {
+		defer a.recover()
+		task()
+	}, func(error, time.Duration) { a.done() })
+	if err != nil {
+		a.done()
+		return ErrPoolClosed
+	}
+	return nil
+}
+
+func (a *Pool) recover() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if a.opts.PanicHandler != nil {
+		a.opts.PanicHandler(v)
+		return
+	}
+	a.opts.Logger.Printf("task panic: %v\n", v)
+}
+
+func (a *Pool) done() {
+	a.mu.Lock()
+	a.running--
+	a.mu.Unlock()
+	a.cond.Broadcast()
+}
+
+// Running 返回正在执行的任务数
+func (a *Pool) Running() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.running
+}
+
+// Free 返回可以立即执行新任务的空位数
+func (a *Pool) Free() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return max(a.cap-a.running, 0)
+}
+
+// Waiting 返回阻塞在 Submit 上的调用数
+func (a *Pool) Waiting() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.waiting
+}
+
+func (a *Pool) Cap() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cap
+}
+
+// Tune 调整容量，size <= 0 时忽略，超过 10000 时为 10000；缩小时正在执行的任务不受影响
+func (a *Pool) Tune(size int) {
+	if size <= 0 {
+		return
+	}
+	a.mu.Lock()
+	a.cap = min(size, maxSize)
+	a.mu.Unlock()
+	a.cond.Broadcast()
+}
+
+func (a *Pool) IsClosed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.closed
+}
+
+// Release 关闭 pool，之后的 Submit 返回 ErrPoolClosed，阻塞中的 Submit 被唤醒并返回该错误；
+// 与 ants 一致，不等待正在执行的任务
+func (a *Pool) Release() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	a.mu.Unlock()
+	a.cond.Broadcast()
+	go func() { // 已提交的任务执行完后再销毁底层 pool，避免其中尚未分发的任务被丢弃
+		a.mu.Lock()
+		for a.running > 0 {
+			a.cond.Wait()
+		}
+		a.mu.Unlock()
+		a.p.Free()
+	}()
+}
+
+// ReleaseTimeout 关闭 pool 并等待正在执行的任务结束，超过 timeout 时返回 ErrTimeout
+func (a *Pool) ReleaseTimeout(timeout time.Duration) error {
+	a.Release()
+	deadline := time.Now().Add(timeout)
+	for a.Running() > 0 {
+		if time.Now().After(deadline) {
+			return ErrTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package ants
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// bufLogger 收集日志输出
+type bufLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *bufLogger) Printf(format string, args ...any) {
+	l.mu.Lock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+}
+
+func (l *bufLogger) has(line string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Contains(l.lines, line)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	a, _ := NewPool(2, WithLogger(&bufLogger{}))
+	defer a.Release()
+	release := make(chan struct{})
+	var done atomic.Int32
+	for range 2 {
+		if err := a.Submit(func() { <-release; done.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.Running() != 2 || a.Free() != 0 {
+		t.Fatalf("Running = %d, Free = %d", a.Running(), a.Free())
+	}
+	submitted := make(chan error, 1)
+	go func() { submitted <- a.Submit(func() { done.Add(1) }) }()
+	waitFor(t, "blocked submit", func() bool { return a.Waiting() == 1 })
+	close(release)
+	if err := <-submitted; err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "tasks", func() bool { return done.Load() == 3 && a.Running() == 0 })
+}
+
+func TestSubmitNonblocking(t *testing.T) {
+	a, _ := NewPool(1, WithNonblocking(true), WithLogger(&bufLogger{}))
+	defer a.Release()
+	release := make(chan struct{})
+	defer close(release)
+	a.Submit(func() { <-release })
+	if err := a.Submit(func() {}); !errors.Is(err, ErrPoolOverload) {
+		t.Fatalf("Submit on full nonblocking pool = %v", err)
+	}
+}
+
+func TestPanicLogged(t *testing.T) {
+	l := &bufLogger{}
+	a, _ := NewPool(1, WithLogger(l))
+	defer a.Release()
+	a.Submit(func() { panic("boom") })
+	waitFor(t, "panic log", func() bool { return l.has("task panic: boom\n") })
+
+	var got atomic.Value
+	h, _ := NewPool(1, WithPanicHandler(func(v any) { got.Store(v) }), WithLogger(l))
+	defer h.Release()
+	h.Submit(func() { panic("handled") })
+	waitFor(t, "panic handler", func() bool { return got.Load() == "handled" })
+}
+
+func TestTune(t *testing.T) {
+	a, _ := NewPool(1, WithLogger(&bufLogger{}))
+	defer a.Release()
+	release := make(chan struct{})
+	defer close(release)
+	a.Submit(func() { <-release })
+	submitted := make(chan error, 1)
+	go func() { submitted <- a.Submit(func() { <-release }) }()
+	waitFor(t, "blocked submit", func() bool { return a.Waiting() == 1 })
+	a.Tune(2)
+	if err := <-submitted; err != nil {
+		t.Fatal(err)
+	}
+	if a.Cap() != 2 || a.Running() != 2 {
+		t.Fatalf("Cap = %d, Running = %d", a.Cap(), a.Running())
+	}
+	a.Tune(0)
+	if a.Cap() != 2 {
+		t.Fatalf("Tune(0) changed Cap to %d", a.Cap())
+	}
+	a.Tune(maxSize + 1)
+	if a.Cap() != maxSize {
+		t.Fatalf("Cap = %d, want %d", a.Cap(), maxSize)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	a, _ := NewPool(1, WithLogger(&bufLogger{}))
+	release := make(chan struct{})
+	var ran atomic.Bool
+	a.Submit(func() { <-release; ran.Store(true) })
+	submitted := make(chan error, 1)
+	go func() { submitted <- a.Submit(func() {}) }()
+	waitFor(t, "blocked submit", func() bool { return a.Waiting() == 1 })
+	a.Release()
+	if err := <-submitted; !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("blocked Submit after Release = %v", err)
+	}
+	if err := a.Submit(func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Submit after Release = %v", err)
+	}
+	if !a.IsClosed() {
+		t.Fatal("IsClosed = false")
+	}
+	close(release)
+	waitFor(t, "running task", ran.Load)
+}
+
+func TestReleaseTimeout(t *testing.T) {
+	a, _ := NewPool(1, WithLogger(&bufLogger{}))
+	release := make(chan struct{})
+	a.Submit(func() { <-release })
+	if err := a.ReleaseTimeout(20 * time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("ReleaseTimeout with a running task = %v", err)
+	}
+	close(release)
+	if err := a.ReleaseTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if a.Running() != 0 {
+		t.Fatalf("Running = %d", a.Running())
+	}
+}
@@ -0,0 +1,213 @@
+// workerpoolctl 通过 pooladmin 暴露的 HTTP 接口查看与操作运行中服务里的具名 pool
+//
+//	workerpoolctl [-addr URL] list
+//	workerpoolctl [-addr URL] stats <pool>
+//	workerpoolctl [-addr URL] pause|resume <pool>
+//	workerpoolctl [-addr URL] resize <pool> <capacity>
+//	workerpoolctl [-addr URL] drain <pool> [timeout]
+//	workerpoolctl [-addr URL] purge <pool>
+//	workerpoolctl [-addr URL] stacks <pool>
+//	workerpoolctl [-addr URL] jobs <pool>
+//
+// URL 为 pooladmin.Handler 挂载的位置，默认取环境变量 WORKERPOOLCTL_ADDR
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	workerpool "workerpool/pool"
+	"workerpool/pooladmin"
+)
+
+const defaultAddr = "http://localhost:6060/debug/workerpool"
+
+func main() {
+	addr := os.Getenv("WORKERPOOLCTL_ADDR")
+	if addr == "" {
+		addr = defaultAddr
+	}
+	flag.StringVar(&addr, "addr", addr, "pooladmin endpoint")
+	timeout := flag.Duration("timeout", time.Minute, "request timeout")
+	flag.Usage = usage
+	flag.Parse()
+	c := &client{base: strings.TrimSuffix(addr, "/"), http: &http.Client{Timeout: *timeout}}
+	if err := run(c, flag.Args(), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "workerpoolctl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `usage: workerpoolctl [flags] <command> [args]
+
+commands:
+  list                      show all pools
+  stats <pool>              show one pool
+  pause <pool>              stop dispatching new tasks
+  resume <pool>             resume dispatching
+  resize <pool> <capacity>  change the pool capacity
+  drain <pool> [timeout]    finish accepted tasks, then free the pool
+  purge <pool>              discard tasks that have not started
+  stacks <pool>             dump worker stacks
+  jobs <pool>               show per-type job statistics
+
+flags:
+`)
+	flag.PrintDefaults()
+}
+
+func run(c *client, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		usage()
+		return errors.New("missing command")
+	}
+	cmd, args := args[0], args[1:]
+	need := func(n int) error {
+		if len(args) < n {
+			return fmt.Errorf("%s: missing arguments", cmd)
+		}
+		return nil
+	}
+	switch cmd {
+	case "list":
+		var list []pooladmin.Status
+		if err := c.do("GET", "/pools", nil, &list); err != nil {
+			return err
+		}
+		printStatus(out, list...)
+		return nil
+	case "stats", "pause", "resume":
+		if err := need(1); err != nil {
+			return err
+		}
+		method, path := "POST", "/pools/"+url.PathEscape(args[0])+"/"+cmd
+		if cmd == "stats" {
+			method, path = "GET", "/pools/"+url.PathEscape(args[0])
+		}
+		var st pooladmin.Status
+		if err := c.do(method, path, nil, &st); err != nil {
+			return err
+		}
+		printStatus(out, st)
+		return nil
+	case "resize":
+		if err := need(2); err != nil {
+			return err
+		}
+		var st pooladmin.Status
+		q := url.Values{"capacity": {args[1]}}
+		if err := c.do("POST", "/pools/"+url.PathEscape(args[0])+"/resize", q, &st); err != nil {
+			return err
+		}
+		printStatus(out, st)
+		return nil
+	case "drain":
+		if err := need(1); err != nil {
+			return err
+		}
+		q := url.Values{}
+		if len(args) > 1 {
+			q.Set("timeout", args[1])
+		}
+		var st pooladmin.Status
+		if err := c.do("POST", "/pools/"+url.PathEscape(args[0])+"/drain", q, &st); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "pool %s drained\n", args[0])
+		return nil
+	case "purge":
+		if err := need(1); err != nil {
+			return err
+		}
+		var r struct{ Purged int }
+		if err := c.do("POST", "/pools/"+url.PathEscape(args[0])+"/purge", nil, &r); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "pool %s: %d tasks purged\n", args[0], r.Purged)
+		return nil
+	case "stacks":
+		if err := need(1); err != nil {
+			return err
+		}
+		return c.do("GET", "/pools/"+url.PathEscape(args[0])+"/stacks", nil, out)
+	case "jobs":
+		if err := need(1); err != nil {
+			return err
+		}
+		var list []workerpool.JobTypeStats
+		if err := c.do("GET", "/pools/"+url.PathEscape(args[0])+"/jobs", nil, &list); err != nil {
+			return err
+		}
+		printJobs(out, list)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func printStatus(out io.Writer, list ...pooladmin.Status) {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCAPACITY\tWORKERS\tBUSY\tQUEUED\tINFLIGHT\tP95 WAIT\tPAUSED\tHEALTH")
+	for _, s := range list {
+		health := s.Health
+		if health == "" {
+			health = "ok"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\t%t\t%s\n", s.Name, s.Stats.Capacity, s.Stats.Workers,
+			s.Stats.Busy, s.Stats.Queued, s.Stats.Inflight, s.Stats.QueueWait.P95, s.Paused, health)
+	}
+	tw.Flush()
+}
+
+func printJobs(out io.Writer, list []workerpool.JobTypeStats) {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tENQUEUED\tRUNNING\tSUCCEEDED\tFAILED\tP95 EXEC\tLAST ERROR")
+	for _, s := range list {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", s.Type, s.Enqueued, s.Running, s.Succeeded, s.Failed, s.ExecTime.P95, s.LastError)
+	}
+	tw.Flush()
+}
+
+type client struct {
+	base string
+	http *http.Client
+}
+
+// do 发送请求，v 为 io.Writer 时原样写出响应，否则按 JSON 解码
+func (c *client) do(method, path string, q url.Values, v any) error {
+	u := c.base + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct{ Error string }
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return fmt.Errorf("%s %s: %s", method, path, e.Error)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if w, ok := v.(io.Writer); ok {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
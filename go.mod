@@ -1,3 +1,3 @@
 module workerpool
 
-go 1.20
+go 1.23
@@ -0,0 +1,85 @@
+// Package grpcpool 让 gRPC 服务的 handler 在 pool 的 worker 上执行，pool 没有空闲 worker 时立即拒绝，
+// 以 RESOURCE_EXHAUSTED 返回给客户端，提供与 httppool 相同的过载保护
+//
+// 包本身不依赖 google.golang.org/grpc，以 Limiter 实现拦截器只需几行：
+//
+//	l := grpcpool.New(p, grpcpool.Config{
+//		Reject: func(err error) error { return status.Error(codes.ResourceExhausted, err.Error()) },
+//	})
+//	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+//		return l.Unary(ctx, func(ctx context.Context) (any, error) { return h(ctx, req) })
+//	}
+//	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+//		return l.Stream(func() error { return h(srv, ss) })
+//	}
+//	grpc.NewServer(grpc.UnaryInterceptor(unary), grpc.StreamInterceptor(stream))
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	workerpool "workerpool/pool"
+)
+
+// ErrResourceExhausted 是未设置 Config.Reject 时拒绝请求返回的错误
+var ErrResourceExhausted = errors.New("grpcpool: resource exhausted")
+
+type Config struct {
+	// Reject 将拒绝的原因（pool 已满或已销毁）转换为返回给客户端的错误，
+	// 通常为 status.Error(codes.ResourceExhausted, ...)；默认返回包装了 ErrResourceExhausted 的错误
+	Reject func(err error) error
+}
+
+type Limiter struct {
+	p   *workerpool.Pool
+	cfg Config
+}
+
+func New(p *workerpool.Pool, cfg Config) *Limiter {
+	if cfg.Reject == nil {
+		cfg.Reject = func(err error) error { return fmt.Errorf("%w: %w", ErrResourceExhausted, err) }
+	}
+	return &Limiter{p: p, cfg: cfg}
+}
+
+// Unary 在 worker 上执行一元 RPC 的 handler，调用方 goroutine 等待其返回；handler 中的 panic 在调用方重新抛出
+func (l *Limiter) Unary(ctx context.Context, handler func(ctx context.Context) (any, error)) (any, error) {
+	var resp any
+	err := l.run(func() error {
+		var err error
+		resp, err = handler(ctx)
+		return err
+	})
+	return resp, err
+}
+
+// Stream 在 worker 上执行流式 RPC 的 handler，整个流的生命周期内占用该 worker
+func (l *Limiter) Stream(handler func() error) error {
+	return l.run(handler)
+}
+
+// run 以 Future 等待 fn：已接受的 RPC 在执行前被丢弃（Purge、Free）时同样以 Reject 返回，而不是永远等待
+func (l *Limiter) run(fn func() error) error {
+	type result struct {
+		err   error
+		panic any
+	}
+	f, err := workerpool.TrySubmit(l.p, func(context.Context) (r result, _ error) {
+		defer func() { r.panic = recover() }()
+		r.err = fn()
+		return r, nil
+	})
+	var r result
+	if err == nil {
+		r, err = f.Wait()
+	}
+	if err != nil {
+		return l.cfg.Reject(err)
+	}
+	if r.panic != nil {
+		panic(r.panic)
+	}
+	return r.err
+}
@@ -0,0 +1,61 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+func TestUnaryRejectsDiscardedCall(t *testing.T) {
+	p := workerpool.New(1, workerpool.WithQueueSize(1), workerpool.WithLogger(nil))
+	l := New(p, Config{})
+	started, release := make(chan struct{}), make(chan struct{})
+	go l.Stream(func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	done := make(chan error)
+	go func() {
+		_, err := l.Unary(context.Background(), func(context.Context) (any, error) { return "ok", nil })
+		done <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Purge() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("call was never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrResourceExhausted) || !errors.Is(err, workerpool.ErrTaskDiscarded) {
+			t.Fatalf("err = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("purged call is still waiting")
+	}
+	close(release)
+	p.Free()
+}
+
+func TestUnaryResultAndPanic(t *testing.T) {
+	p := workerpool.New(1, workerpool.WithLogger(nil))
+	defer p.Free()
+	l := New(p, Config{})
+	boom := errors.New("boom")
+	if _, err := l.Unary(context.Background(), func(context.Context) (any, error) { return nil, boom }); err != boom {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	defer func() {
+		if v := recover(); v != "panic" {
+			t.Fatalf("recovered %v", v)
+		}
+	}()
+	l.Unary(context.Background(), func(context.Context) (any, error) { panic("panic") })
+}
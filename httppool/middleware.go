@@ -0,0 +1,62 @@
+// Package httppool 提供 net/http 中间件：每个请求的 handler 在 pool 的 worker 上执行，
+// pool 没有空闲 worker 时立即返回 503 并带上 Retry-After，为 HTTP 服务提供准入控制与并发上限
+//
+//	p := workerpool.New(64)
+//	http.ListenAndServe(":8080", httppool.Middleware(p, httppool.Config{})(mux))
+package httppool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+type Config struct {
+	RetryAfter time.Duration // 503 响应中 Retry-After 的值，向上取整到秒，默认 1s
+	// OnReject 在请求因 pool 已满或已销毁被拒绝，或排队中被丢弃时调用，可用于计数或记录日志
+	OnReject func(r *http.Request, err error)
+}
+
+// Middleware 返回在 p 上执行 handler 的中间件；发起请求的 goroutine 等待 handler 在 worker 上执行完毕后返回，
+// handler 中的 panic 会在该 goroutine 中重新抛出，交给 net/http 处理（包括 http.ErrAbortHandler）
+func Middleware(p *workerpool.Pool, cfg Config) func(http.Handler) http.Handler {
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = time.Second
+	}
+	retryAfter := strconv.Itoa(int((cfg.RetryAfter + time.Second - 1) / time.Second))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 以 Future 等待：已接受的请求在执行前被丢弃（Purge、Free）时同样得到 503，而不是永远等待
+			f, err := workerpool.TrySubmit(p, func(context.Context) (v any, _ error) {
+				defer func() { v = recover() }()
+				next.ServeHTTP(w, r)
+				return nil, nil
+			})
+			var v any
+			if err == nil {
+				v, err = f.Wait()
+			}
+			if err != nil {
+				if cfg.OnReject != nil {
+					cfg.OnReject(r, err)
+				}
+				reject(w, err, retryAfter)
+				return
+			}
+			if v != nil {
+				panic(v)
+			}
+		})
+	}
+}
+
+func reject(w http.ResponseWriter, err error, retryAfter string) {
+	if !errors.Is(err, workerpool.ErrPoolClosed) {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
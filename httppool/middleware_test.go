@@ -0,0 +1,61 @@
+package httppool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+func TestMiddlewareRejectsPurgedRequest(t *testing.T) {
+	p := workerpool.New(1, workerpool.WithQueueSize(1), workerpool.WithLogger(nil))
+	defer p.Free()
+	started, release := make(chan struct{}), make(chan struct{})
+	h := Middleware(p, Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+	}))
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	<-started
+	defer close(release)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/queued", nil))
+		done <- rec
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Purge() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("request was never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case rec := <-done:
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503", rec.Code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("purged request is still waiting")
+	}
+}
+
+func TestMiddlewareRepanics(t *testing.T) {
+	p := workerpool.New(1, workerpool.WithLogger(nil))
+	defer p.Free()
+	h := Middleware(p, Config{})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -0,0 +1,271 @@
+// Package kafkaqueue 以 Kafka topic 作为 workerpool 的 Job 队列：
+// 不同分区的记录在 pool 上并发执行，同一分区的记录严格按 offset 串行执行，
+// 一条记录执行成功（或重试耗尽）后才提交其 offset，进程崩溃时未提交的记录会被重新消费
+//
+// 包本身不依赖具体的 Kafka 客户端，Client 可以用 franz-go、segmentio/kafka-go 等实现，
+// 消费组与分区分配由客户端负责，Commit 须关闭客户端的自动提交
+package kafkaqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// Record 是一条 Kafka 记录
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+// Client 是 Queue 用到的 Kafka 操作
+type Client interface {
+	// Fetch 拉取一批记录，没有新记录时阻塞直到有记录或 ctx 取消
+	Fetch(ctx context.Context) ([]Record, error)
+	// Commit 提交分区的消费位置，offset 为下一条待消费记录的 offset
+	Commit(ctx context.Context, topic string, partition int32, offset int64) error
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+type Config struct {
+	Topic       string // Push 写入的 topic
+	MaxBuffered int    // 已拉取但尚未执行完的记录数上限，达到后暂停拉取，默认 1000
+	MaxAttempts int    // 每条记录最多执行的次数，默认 3
+	// OnFailure 在记录重试耗尽时调用（如写入死信 topic），之后照常提交 offset 继续消费
+	OnFailure func(r Record, err error)
+	Logf      func(format string, args ...any) // 日志输出，默认 fmt.Printf
+}
+
+// fetchRetryDelay 是 Fetch 出错后重试前的等待时间
+const fetchRetryDelay = time.Second
+
+type Queue struct {
+	c   Client
+	cfg Config
+
+	mu       sync.Mutex
+	parts    map[partitionKey]*partition
+	ready    []*partition  // 有待执行记录且没有记录在执行中的分区
+	buffered int           // 所有分区中尚未执行完的记录数
+	notify   chan struct{} // ready 变化时非空，唤醒 Pop
+	space    chan struct{} // buffered 减少时非空，唤醒暂停的 fetch
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{} // fetch 退出时关闭
+}
+
+var _ workerpool.Queue = (*Queue)(nil)
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+type partition struct {
+	records  []Record
+	busy     bool // 队首记录已交给 pool、尚未 Ack
+	attempts int  // 队首记录已执行的次数
+}
+
+type message struct {
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	Key     string `json:"key,omitempty"`
+}
+
+func New(c Client, cfg Config) *Queue {
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 1000
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(format string, args ...any) { fmt.Printf(format, args...) }
+	}
+	return &Queue{
+		c:      c,
+		cfg:    cfg,
+		parts:  make(map[partitionKey]*partition),
+		notify: make(chan struct{}, 1),
+		space:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+func (q *Queue) Push(ctx context.Context, j workerpool.Job) error {
+	b, err := json.Marshal(message{Type: j.Type, Payload: j.Payload, Key: j.Key})
+	if err != nil {
+		return err
+	}
+	return q.c.Produce(ctx, q.cfg.Topic, nil, b)
+}
+
+// Len 返回已拉取但尚未执行完的记录数，不包括 broker 上尚未拉取的积压（即消费延迟）
+func (q *Queue) Len(context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.buffered, nil
+}
+
+// Pop 返回某个空闲分区的下一条记录，首次调用时开始在后台拉取
+func (q *Queue) Pop(ctx context.Context) (workerpool.Delivery, error) {
+	q.once.Do(func() {
+		fctx, cancel := context.WithCancel(context.Background())
+		q.cancel = cancel
+		go q.fetch(fctx)
+	})
+	for {
+		q.mu.Lock()
+		if len(q.ready) > 0 {
+			p := q.ready[0]
+			q.ready = q.ready[1:]
+			p.busy = true
+			r := p.records[0]
+			attempt := p.attempts + 1
+			q.mu.Unlock()
+			var m message
+			if err := json.Unmarshal(r.Value, &m); err != nil {
+				// 无法解析的记录不会因重试而成功，直接视为重试耗尽
+				q.finish(ctx, p, r, fmt.Errorf("bad message: %w", err), true)
+				continue
+			}
+			d := &delivery{p: p, r: r}
+			return workerpool.Delivery{Job: workerpool.Job{Type: m.Type, Payload: m.Payload, Key: m.Key, Attempt: attempt}, Token: d}, nil
+		}
+		q.mu.Unlock()
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return workerpool.Delivery{}, ctx.Err()
+		}
+	}
+}
+
+type delivery struct {
+	p *partition
+	r Record
+}
+
+// Ack 在执行成功时提交 offset 并放行该分区的下一条记录；失败时重新投递同一条记录，
+// 达到 MaxAttempts 后调用 OnFailure 并提交
+func (q *Queue) Ack(ctx context.Context, d workerpool.Delivery, err error) error {
+	kd, ok := d.Token.(*delivery)
+	if !ok {
+		return errors.New("kafkaqueue: delivery not from this queue")
+	}
+	q.mu.Lock()
+	kd.p.attempts++
+	exhausted := kd.p.attempts >= q.cfg.MaxAttempts
+	q.mu.Unlock()
+	return q.finish(ctx, kd.p, kd.r, err, exhausted)
+}
+
+// finish 结束分区队首记录 r 的一次执行：成功或重试耗尽时先提交 offset 再放行下一条，
+// 保证同一分区的提交按 offset 递增
+func (q *Queue) finish(ctx context.Context, p *partition, r Record, err error, exhausted bool) error {
+	var cerr error
+	done := err == nil || exhausted
+	if done {
+		if err != nil && q.cfg.OnFailure != nil {
+			q.cfg.OnFailure(r, err)
+		}
+		cerr = q.c.Commit(ctx, r.Topic, r.Partition, r.Offset+1)
+	}
+	q.mu.Lock()
+	if done {
+		p.records[0] = Record{}
+		p.records = p.records[1:]
+		p.attempts = 0
+		q.buffered--
+	}
+	p.busy = false
+	if len(p.records) > 0 {
+		q.ready = append(q.ready, p)
+	}
+	q.mu.Unlock()
+	signal(q.notify)
+	if done {
+		signal(q.space)
+	}
+	return cerr
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// fetch 在后台持续拉取记录，已缓冲的记录达到 MaxBuffered 时暂停
+func (q *Queue) fetch(ctx context.Context) {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		full := q.buffered >= q.cfg.MaxBuffered
+		q.mu.Unlock()
+		if full {
+			select {
+			case <-q.space:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		recs, err := q.c.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			q.cfg.Logf("kafkaqueue: fetch: %s\n", err)
+			select {
+			case <-time.After(fetchRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		q.add(recs)
+	}
+}
+
+func (q *Queue) add(recs []Record) {
+	if len(recs) == 0 {
+		return
+	}
+	q.mu.Lock()
+	for _, r := range recs {
+		k := partitionKey{r.Topic, r.Partition}
+		p, ok := q.parts[k]
+		if !ok {
+			p = &partition{}
+			q.parts[k] = p
+		}
+		if len(p.records) == 0 && !p.busy {
+			q.ready = append(q.ready, p)
+		}
+		p.records = append(p.records, r)
+		q.buffered++
+	}
+	q.mu.Unlock()
+	signal(q.notify)
+}
+
+// Close 停止后台拉取，应在 pool 的 Free 返回后调用；未提交的记录在下次消费时重新投递
+func (q *Queue) Close() {
+	q.once.Do(func() { close(q.done) })
+	if q.cancel != nil {
+		q.cancel()
+	}
+	<-q.done
+}
@@ -0,0 +1,177 @@
+package kafkaqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// fakeKafka 将 Produce 的记录按 key 分配到分区，Fetch 每次返回所有新记录
+type fakeKafka struct {
+	mu        sync.Mutex
+	offsets   map[int32]int64
+	records   chan Record
+	committed map[int32]int64
+}
+
+func newFakeKafka() *fakeKafka {
+	return &fakeKafka{offsets: make(map[int32]int64), records: make(chan Record, 100), committed: make(map[int32]int64)}
+}
+
+func (k *fakeKafka) Fetch(ctx context.Context) ([]Record, error) {
+	select {
+	case r := <-k.records:
+		recs := []Record{r}
+		for {
+			select {
+			case r := <-k.records:
+				recs = append(recs, r)
+			default:
+				return recs, nil
+			}
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (k *fakeKafka) Commit(_ context.Context, _ string, partition int32, offset int64) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if offset <= k.committed[partition] {
+		return errors.New("offset committed out of order")
+	}
+	k.committed[partition] = offset
+	return nil
+}
+
+func (k *fakeKafka) Produce(_ context.Context, topic string, key, value []byte) error {
+	return k.produce(topic, int32(len(key)), value)
+}
+
+func (k *fakeKafka) produce(topic string, partition int32, value []byte) error {
+	k.mu.Lock()
+	off := k.offsets[partition]
+	k.offsets[partition]++
+	k.mu.Unlock()
+	k.records <- Record{Topic: topic, Partition: partition, Offset: off, Value: value}
+	return nil
+}
+
+func (k *fakeKafka) commit(partition int32) int64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.committed[partition]
+}
+
+func pop(t *testing.T, q *Queue) workerpool.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func noPop(t *testing.T, q *Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if d, err := q.Pop(ctx); err == nil {
+		t.Fatalf("unexpected delivery %+v", d.Job)
+	}
+}
+
+func TestQueuePartitionOrder(t *testing.T) {
+	k := newFakeKafka()
+	q := New(k, Config{Topic: "jobs"})
+	defer q.Close()
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "a1", Key: "k"})
+	q.Push(ctx, workerpool.Job{Type: "a2"})
+	k.produce("jobs", 1, []byte(`{"type":"b1"}`))
+
+	first, second := pop(t, q), pop(t, q) // 两个分区的队首记录并发投递
+	if first.Job.Type != "a1" || first.Job.Key != "k" || second.Job.Type != "b1" {
+		t.Fatalf("Pop = %+v, %+v", first.Job, second.Job)
+	}
+	noPop(t, q) // a2 须等 a1 结束
+	if n, _ := q.Len(ctx); n != 3 {
+		t.Fatalf("Len = %d", n)
+	}
+	if err := q.Ack(ctx, first, nil); err != nil {
+		t.Fatal(err)
+	}
+	if k.commit(0) != 1 {
+		t.Fatalf("committed offset = %d", k.commit(0))
+	}
+	if d := pop(t, q); d.Job.Type != "a2" {
+		t.Fatalf("Pop after Ack = %+v", d.Job)
+	}
+}
+
+func TestQueueRetry(t *testing.T) {
+	k := newFakeKafka()
+	var failed []Record
+	q := New(k, Config{Topic: "jobs", MaxAttempts: 2, OnFailure: func(r Record, err error) { failed = append(failed, r) }})
+	defer q.Close()
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t"})
+	for attempt := 1; attempt <= 2; attempt++ {
+		d := pop(t, q)
+		if d.Job.Attempt != attempt {
+			t.Fatalf("Attempt = %d, want %d", d.Job.Attempt, attempt)
+		}
+		if attempt == 1 && k.commit(0) != 0 {
+			t.Fatal("failed record committed before retries are exhausted")
+		}
+		q.Ack(ctx, d, errors.New("boom"))
+	}
+	if len(failed) != 1 || k.commit(0) != 1 {
+		t.Fatalf("OnFailure calls %d, committed %d", len(failed), k.commit(0))
+	}
+
+	k.produce("jobs", 0, []byte("not json"))
+	q.Push(ctx, workerpool.Job{Type: "good"})
+	if d := pop(t, q); d.Job.Type != "good" {
+		t.Fatalf("Pop after a bad message = %+v", d.Job)
+	}
+	if len(failed) != 2 || k.commit(0) != 2 {
+		t.Fatalf("bad message: OnFailure calls %d, committed %d", len(failed), k.commit(0))
+	}
+}
+
+func TestQueueMaxBuffered(t *testing.T) {
+	k := newFakeKafka()
+	q := New(k, Config{Topic: "jobs", MaxBuffered: 1})
+	defer q.Close()
+	ctx := context.Background()
+	k.produce("jobs", 0, []byte(`{"type":"a"}`))
+	d := pop(t, q)
+	k.produce("jobs", 1, []byte(`{"type":"b"}`))
+	noPop(t, q) // 缓冲已满，暂停拉取
+	q.Ack(ctx, d, nil)
+	if d := pop(t, q); d.Job.Type != "b" {
+		t.Fatalf("Pop after Ack = %+v", d.Job)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	q := New(newFakeKafka(), Config{})
+	q.Close() // 尚未开始拉取
+	q = New(newFakeKafka(), Config{})
+	noPop(t, q)
+	closed := make(chan struct{})
+	go func() { q.Close(); close(closed) }()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop the fetch loop")
+	}
+}
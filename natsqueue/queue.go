@@ -0,0 +1,189 @@
+// Package natsqueue 以 NATS JetStream 的 pull consumer 作为 workerpool 的 Job 队列：
+// 执行成功时 Ack，失败时 Nak 交给 JetStream 按 consumer 的 MaxDeliver/BackOff 重投，
+// 同时在途（已拉取、尚未 Ack/Nak）的消息数不超过 MaxInFlight，通常取 pool 的容量
+//
+// jetstream.Msg 已经实现了 Msg，Consumer 只需包装 jetstream.Consumer.Fetch：
+//
+//	func (c consumer) Fetch(ctx context.Context, batch int) ([]natsqueue.Msg, error) {
+//		b, err := c.c.Fetch(batch, jetstream.FetchMaxWait(5*time.Second))
+//		...
+//	}
+package natsqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	workerpool "workerpool/pool"
+)
+
+// Msg 是一条 JetStream 消息
+type Msg interface {
+	Data() []byte
+	Ack() error
+	Nak() error
+	// Term 表示该消息永远无法处理成功，不再重投
+	Term() error
+}
+
+// Consumer 拉取最多 batch 条消息，没有消息时阻塞直到有消息、超时（返回空）或 ctx 取消
+type Consumer interface {
+	Fetch(ctx context.Context, batch int) ([]Msg, error)
+}
+
+// Publisher 用于 Push，只消费时可以为 nil
+type Publisher interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+type Config struct {
+	Subject     string                           // Push 发布的 subject
+	MaxInFlight int                              // 同时在途的消息数上限，默认 100
+	Logf        func(format string, args ...any) // 日志输出，默认 fmt.Printf
+}
+
+type Queue struct {
+	c   Consumer
+	pub Publisher
+	cfg Config
+
+	mu       sync.Mutex
+	buffered []Msg
+	inflight int           // 已拉取但尚未 Ack/Nak 的消息数，包括 buffered
+	acked    chan struct{} // inflight 减少时非空
+}
+
+var _ workerpool.Queue = (*Queue)(nil)
+
+type message struct {
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	Key     string `json:"key,omitempty"`
+}
+
+func New(c Consumer, pub Publisher, cfg Config) *Queue {
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 100
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(format string, args ...any) { fmt.Printf(format, args...) }
+	}
+	return &Queue{c: c, pub: pub, cfg: cfg, acked: make(chan struct{}, 1)}
+}
+
+func (q *Queue) Push(ctx context.Context, j workerpool.Job) error {
+	if q.pub == nil {
+		return errors.New("natsqueue: no publisher")
+	}
+	b, err := json.Marshal(message{Type: j.Type, Payload: j.Payload, Key: j.Key})
+	if err != nil {
+		return err
+	}
+	return q.pub.Publish(ctx, q.cfg.Subject, b)
+}
+
+// Len 返回在途的消息数，stream 中的积压请用 consumer info 的 NumPending 查看
+func (q *Queue) Len(context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.inflight, nil
+}
+
+// Pop 返回一条消息；本地没有已拉取的消息时，在在途数不超过 MaxInFlight 的前提下向服务端拉取
+// 无法解析的消息以 Term 丢弃
+func (q *Queue) Pop(ctx context.Context) (workerpool.Delivery, error) {
+	for {
+		m, err := q.next(ctx)
+		if err != nil {
+			return workerpool.Delivery{}, err
+		}
+		var msg message
+		if err := json.Unmarshal(m.Data(), &msg); err != nil {
+			q.cfg.Logf("natsqueue: bad message: %s\n", err)
+			if err := m.Term(); err != nil {
+				q.cfg.Logf("natsqueue: term: %s\n", err)
+			}
+			q.release()
+			continue
+		}
+		return workerpool.Delivery{Job: workerpool.Job{Type: msg.Type, Payload: msg.Payload, Key: msg.Key}, Token: m}, nil
+	}
+}
+
+func (q *Queue) next(ctx context.Context) (Msg, error) {
+	for {
+		q.mu.Lock()
+		if len(q.buffered) > 0 {
+			m := q.buffered[0]
+			q.buffered = q.buffered[1:]
+			q.mu.Unlock()
+			return m, nil
+		}
+		room := q.cfg.MaxInFlight - q.inflight
+		q.mu.Unlock()
+		if room <= 0 {
+			select {
+			case <-q.acked:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		msgs, err := q.c.Fetch(ctx, room)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return nil, err
+		}
+		q.mu.Lock()
+		q.buffered = append(q.buffered, msgs...)
+		q.inflight += len(msgs)
+		q.mu.Unlock()
+		if len(msgs) == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Ack 在执行成功时 Ack，失败时 Nak
+func (q *Queue) Ack(_ context.Context, d workerpool.Delivery, err error) error {
+	m, ok := d.Token.(Msg)
+	if !ok {
+		return errors.New("natsqueue: delivery not from this queue")
+	}
+	defer q.release()
+	if err != nil {
+		return m.Nak()
+	}
+	return m.Ack()
+}
+
+func (q *Queue) release() {
+	q.mu.Lock()
+	q.inflight--
+	q.mu.Unlock()
+	select {
+	case q.acked <- struct{}{}:
+	default:
+	}
+}
+
+// Close 将已拉取但尚未交给 pool 的消息 Nak，使其立即重投给其它消费者；应在 pool 的 Free 返回后调用
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	msgs := q.buffered
+	q.buffered = nil
+	q.mu.Unlock()
+	var errs []error
+	for _, m := range msgs {
+		if err := m.Nak(); err != nil {
+			errs = append(errs, err)
+		}
+		q.release()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,178 @@
+package natsqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// fakeStream 模拟一个 stream 与 pull consumer：Nak 的消息重新排到队尾
+type fakeStream struct {
+	mu      sync.Mutex
+	pending [][]byte
+	fetches []int // 每次 Fetch 的 batch
+	acked   int
+	naked   int
+	termed  int
+}
+
+type fakeMsg struct {
+	s    *fakeStream
+	data []byte
+}
+
+func (s *fakeStream) Publish(_ context.Context, _ string, data []byte) error {
+	s.mu.Lock()
+	s.pending = append(s.pending, data)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *fakeStream) Fetch(ctx context.Context, batch int) ([]Msg, error) {
+	s.mu.Lock()
+	s.fetches = append(s.fetches, batch)
+	n := min(batch, len(s.pending))
+	var msgs []Msg
+	for _, data := range s.pending[:n] {
+		msgs = append(msgs, &fakeMsg{s: s, data: data})
+	}
+	s.pending = s.pending[n:]
+	s.mu.Unlock()
+	if len(msgs) == 0 {
+		select { // 模拟 FetchMaxWait
+		case <-ctx.Done():
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return msgs, nil
+}
+
+func (s *fakeStream) count(n *int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *n
+}
+
+func (m *fakeMsg) Data() []byte { return m.data }
+func (m *fakeMsg) Ack() error {
+	m.s.mu.Lock()
+	m.s.acked++
+	m.s.mu.Unlock()
+	return nil
+}
+func (m *fakeMsg) Nak() error {
+	m.s.mu.Lock()
+	m.s.naked++
+	m.s.pending = append(m.s.pending, m.data)
+	m.s.mu.Unlock()
+	return nil
+}
+func (m *fakeMsg) Term() error {
+	m.s.mu.Lock()
+	m.s.termed++
+	m.s.mu.Unlock()
+	return nil
+}
+
+func pop(t *testing.T, q *Queue) workerpool.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestQueueAck(t *testing.T) {
+	s := &fakeStream{}
+	q := New(s, s, Config{Subject: "jobs"})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t", Payload: []byte("a"), Key: "k"})
+	d := pop(t, q)
+	if d.Job.Type != "t" || string(d.Job.Payload) != "a" || d.Job.Key != "k" {
+		t.Fatalf("Pop = %+v", d.Job)
+	}
+	q.Ack(ctx, d, errors.New("boom")) // Nak 后重投
+	d = pop(t, q)
+	q.Ack(ctx, d, nil)
+	if s.count(&s.acked) != 1 || s.count(&s.naked) != 1 {
+		t.Fatalf("acked %d, naked %d", s.acked, s.naked)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("Len = %d", n)
+	}
+	if err := New(s, nil, Config{}).Push(ctx, workerpool.Job{}); err == nil {
+		t.Fatal("Push without a publisher succeeded")
+	}
+}
+
+func TestQueueMaxInFlight(t *testing.T) {
+	s := &fakeStream{}
+	q := New(s, s, Config{MaxInFlight: 2})
+	ctx := context.Background()
+	for range 3 {
+		q.Push(ctx, workerpool.Job{Type: "t"})
+	}
+	first, _ := pop(t, q), pop(t, q)
+	pctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(pctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Pop beyond MaxInFlight = %v", err)
+	}
+	q.Ack(ctx, first, nil)
+	pop(t, q)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, batch := range s.fetches {
+		if batch > 2 {
+			t.Fatalf("fetched %d messages with MaxInFlight 2", batch)
+		}
+	}
+}
+
+func TestQueueBadMessage(t *testing.T) {
+	s := &fakeStream{}
+	var logged []string
+	q := New(s, s, Config{Logf: func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}})
+	ctx := context.Background()
+	s.Publish(ctx, "", []byte("not json"))
+	q.Push(ctx, workerpool.Job{Type: "good"})
+	if d := pop(t, q); d.Job.Type != "good" {
+		t.Fatalf("Pop after a bad message = %+v", d.Job)
+	}
+	if s.count(&s.termed) != 1 || len(logged) != 1 || !strings.HasPrefix(logged[0], "natsqueue: bad message") {
+		t.Fatalf("termed %d, logged %q", s.termed, logged)
+	}
+	if n, _ := q.Len(ctx); n != 1 {
+		t.Fatalf("Len = %d", n)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	s := &fakeStream{}
+	q := New(s, s, Config{})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "a"})
+	q.Push(ctx, workerpool.Job{Type: "b"})
+	d := pop(t, q) // 两条一起拉取，另一条留在本地缓冲中
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s.count(&s.naked) != 1 {
+		t.Fatalf("buffered message not naked, naked = %d", s.naked)
+	}
+	q.Ack(ctx, d, nil)
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("Len after Close = %d", n)
+	}
+}
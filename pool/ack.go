@@ -0,0 +1,111 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAckTimeout 表示 Job 超过可见性超时仍未完成或确认，交给 Queue.Ack 时后端应重新投递
+var ErrAckTimeout = errors.New("job not acknowledged in time")
+
+type ackKey struct{}
+
+type ackSet struct {
+	mu      sync.Mutex
+	pending map[*ackState]struct{}
+	closed  bool
+}
+
+// ackState 是受可见性超时约束、已从队列取出的一个 Job
+type ackState struct {
+	p       *Pool
+	d       Delivery
+	mu      sync.Mutex
+	settled bool
+	timer   Timer
+}
+
+// Ack 在 WithExplicitAck 模式下确认当前 Job 已处理完毕，ctx 为处理函数收到的 ctx（或由其派生）；
+// 可以在处理函数返回后、由其它 goroutine 异步调用。已超时重投或已确认时返回 false
+func Ack(ctx context.Context) bool {
+	st, ok := ctx.Value(ackKey{}).(*ackState)
+	return ok && st.settle(nil)
+}
+
+// Nack 在 WithExplicitAck 模式下报告当前 Job 处理失败，由队列后端决定是否重投
+func Nack(ctx context.Context, err error) bool {
+	if err == nil {
+		err = errors.New("job nacked")
+	}
+	st, ok := ctx.Value(ackKey{}).(*ackState)
+	return ok && st.settle(err)
+}
+
+// trackAck 登记等待确认的 d，确认超时从 start 开始计时
+func (p *Pool) trackAck(d Delivery) *ackState {
+	st := &ackState{p: p, d: d}
+	s := &p.acks
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		s.pending = make(map[*ackState]struct{})
+	}
+	s.pending[st] = struct{}{}
+	return st
+}
+
+// start 在处理函数开始执行时调用，超时后以 ErrAckTimeout 交给队列重投
+func (st *ackState) start() {
+	s := &st.p.acks
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if s.closed || st.settled {
+		return
+	}
+	st.timer = st.p.clock.AfterFunc(st.p.visibility, func() {
+		if st.settle(ErrAckTimeout) {
+			st.p.logf("job[%s]: %s, redelivering\n", st.d.Job.Type, ErrAckTimeout)
+		}
+	})
+}
+
+// settle 以 err 结束 st，只有第一次调用生效
+func (st *ackState) settle(err error) bool {
+	st.mu.Lock()
+	if st.settled {
+		st.mu.Unlock()
+		return false
+	}
+	st.settled = true
+	if st.timer != nil {
+		st.timer.Stop()
+	}
+	st.mu.Unlock()
+	s := &st.p.acks
+	s.mu.Lock()
+	delete(s.pending, st)
+	s.mu.Unlock()
+	if err == nil && st.p.explicitAck {
+		st.p.markDone(context.Background(), st.d.Job)
+	}
+	st.p.ack(st.d, err)
+	return true
+}
+
+// stopAcks 停止所有确认超时的计时：pool 销毁后不再重投，未确认的 Job 保留在 WAL 或后端中
+func (p *Pool) stopAcks() {
+	s := &p.acks
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for st := range s.pending {
+		st.mu.Lock()
+		if st.timer != nil {
+			st.timer.Stop()
+		}
+		st.mu.Unlock()
+	}
+}
@@ -0,0 +1,93 @@
+package workerpool
+
+import (
+	"fmt"
+	"time"
+)
+
+// alertInterval 是检查告警条件的周期
+const alertInterval = time.Second
+
+// AlertCondition 是 WithAlert 的告警条件：Check 持续返回 true 达到 For 时告警
+// 传给 Check 的 PoolStats 中，QueueWait 与 ExecTime 只统计最近一个检查周期（1s）内开始或结束的任务，
+// 以便“p95 等待时间超过 Z”这类条件反映当前状况，而不是被 pool 启动以来的历史稀释
+type AlertCondition struct {
+	Name  string               // 用于日志，如 "queue depth > 100 for 30s"
+	Check func(PoolStats) bool // 条件是否满足
+	For   time.Duration        // 条件需要持续满足的时间，0 表示第一次满足即告警
+}
+
+// QueueDepthAbove 在排队的任务数持续 d 超过 n 时告警
+func QueueDepthAbove(n int, d time.Duration) AlertCondition {
+	return AlertCondition{
+		Name:  fmt.Sprintf("queue depth > %d for %s", n, d),
+		Check: func(s PoolStats) bool { return s.Queued > n },
+		For:   d,
+	}
+}
+
+// QueueWaitP95Above 在任务排队等待时间的 p95 持续 d 超过 wait 时告警，检查周期内没有任务开始执行时视为不满足
+func QueueWaitP95Above(wait, d time.Duration) AlertCondition {
+	return AlertCondition{
+		Name:  fmt.Sprintf("p95 queue wait > %s for %s", wait, d),
+		Check: func(s PoolStats) bool { return s.QueueWait.Count > 0 && s.QueueWait.P95 > wait },
+		For:   d,
+	}
+}
+
+func WithAlert(cond AlertCondition, fn func(PoolStats)) Option { // 条件持续满足时调用 fn，条件解除后再次满足时重新告警，可直接从 pool 发出告警而不必等待下一次指标采集
+	return func(p *Pool) {
+		if cond.Check == nil || fn == nil {
+			p.invalid("alert %q needs a condition and a callback", cond.Name)
+			return
+		}
+		p.alerts = append(p.alerts, &alert{AlertCondition: cond, fn: fn})
+	}
+}
+
+type alert struct {
+	AlertCondition
+	fn func(PoolStats)
+
+	since time.Time // 条件开始持续满足的时刻，不满足时为零值
+	fired bool      // 本次持续满足期间已告警
+}
+
+// runAlerts 周期检查 WithAlert 的条件，直到 pool 销毁
+func (p *Pool) runAlerts() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	ticker := p.clock.NewTicker(alertInterval)
+	defer ticker.Stop()
+	wait, exec := p.queueWait.snapshot(), p.execTime.snapshot()
+	for {
+		select {
+		case <-ticker.C():
+		case <-p.quit:
+			return
+		}
+		s := p.stats()
+		s.QueueWait, wait = p.queueWait.interval(wait)
+		s.ExecTime, exec = p.execTime.interval(exec)
+		now := p.clock.Now()
+		for _, a := range p.alerts {
+			a.check(p, s, now)
+		}
+	}
+}
+
+func (a *alert) check(p *Pool, s PoolStats, now time.Time) {
+	if !a.Check(s) {
+		a.since, a.fired = time.Time{}, false
+		return
+	}
+	if a.since.IsZero() {
+		a.since = now
+	}
+	if a.fired || now.Sub(a.since) < a.For {
+		return
+	}
+	a.fired = true
+	p.logf("workerpool: alert %s %s\n", a.Name, s.summary())
+	a.fn(s)
+}
@@ -0,0 +1,68 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertFiresOncePerEpisode(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	var fired int
+	a := &alert{AlertCondition: QueueDepthAbove(2, 3*time.Second), fn: func(PoolStats) { fired++ }}
+	t0 := time.Unix(0, 0)
+	deep, shallow := PoolStats{Queued: 3}, PoolStats{Queued: 1}
+	for i := 0; i <= 5; i++ {
+		a.check(p, deep, t0.Add(time.Duration(i)*time.Second))
+		if want := min(max(i-2, 0), 1); fired != want {
+			t.Fatalf("after %ds fired %d times, want %d", i, fired, want)
+		}
+	}
+	a.check(p, shallow, t0.Add(6*time.Second))
+	for i := 7; i <= 10; i++ {
+		a.check(p, deep, t0.Add(time.Duration(i)*time.Second))
+	}
+	if fired != 2 {
+		t.Fatalf("fired %d times after the condition cleared and held again, want 2", fired)
+	}
+}
+
+func TestWithAlert(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	alerts := make(chan PoolStats, 1)
+	p, release := newBusyPool(t, WithClock(clk), WithQueueSize(4),
+		WithAlert(QueueDepthAbove(1, time.Second), func(s PoolStats) { alerts <- s }))
+	defer release()
+	for range 3 {
+		if err := p.Schedule(func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; ; i++ {
+		select {
+		case s := <-alerts:
+			if s.Queued != 3 {
+				t.Fatalf("alert stats queued = %d, want 3", s.Queued)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if i == 500 {
+			t.Fatal("alert never fired")
+		}
+		clk.Advance(alertInterval)
+	}
+}
+
+func TestLatencyInterval(t *testing.T) {
+	var r latencyRecorder
+	r.record(time.Second)
+	prev := r.snapshot()
+	for range 10 {
+		r.record(time.Millisecond)
+	}
+	s, _ := r.interval(prev)
+	if s.Count != 10 || s.P95 > 2*time.Millisecond || s.Max > 2*time.Millisecond {
+		t.Fatalf("interval stats = %+v, want 10 records around 1ms", s)
+	}
+}
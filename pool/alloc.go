@@ -0,0 +1,93 @@
+package workerpool
+
+import (
+	"runtime/metrics"
+	"sync"
+)
+
+// allocMetric 是进程累计分配的堆内存字节数，读取时不会暂停程序
+const allocMetric = "/gc/heap/allocs:bytes"
+
+// AllocStats 是一类任务（按 TaskInfo.Name）被采样的分配统计
+// 计数来自进程级的累计分配量，同时执行的其它 goroutine 的分配也会计入，只能作为定位 GC 压力来源的近似参考
+type AllocStats struct {
+	Sampled uint64 // 被采样的任务数
+	Bytes   uint64 // 被采样的任务执行期间的分配字节数之和
+}
+
+// PerTask 返回平均每个任务的分配字节数
+func (s AllocStats) PerTask() uint64 {
+	if s.Sampled == 0 {
+		return 0
+	}
+	return s.Bytes / s.Sampled
+}
+
+type allocSampler struct {
+	every uint64 // 每 every 个任务采样一个
+
+	mu    sync.Mutex
+	n     uint64
+	stats map[string]AllocStats
+}
+
+func WithAllocSampling(every int) Option { // 每 every 个任务采样一次执行期间的堆分配量，按任务名称（WithTaskName，未命名为 ""）统计，见 AllocStats；0 表示关闭
+	return func(p *Pool) {
+		if every < 0 {
+			p.invalid("WithAllocSampling(%d): negative interval", every)
+			return
+		}
+		p.allocs = nil
+		if every > 0 {
+			p.allocs = &allocSampler{every: uint64(every), stats: make(map[string]AllocStats)}
+		}
+	}
+}
+
+// AllocStats 返回按任务名称统计的分配量，未设置 WithAllocSampling 时返回 nil
+func (p *Pool) AllocStats() map[string]AllocStats {
+	p = p.applied()
+	a := p.allocs
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	m := make(map[string]AllocStats, len(a.stats))
+	for k, v := range a.stats {
+		m[k] = v
+	}
+	return m
+}
+
+// sample 判断是否采样下一个任务
+func (a *allocSampler) sample() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.n++
+	return a.n%a.every == 0
+}
+
+func readAllocs() uint64 {
+	s := []metrics.Sample{{Name: allocMetric}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s[0].Value.Uint64()
+}
+
+// record 在被采样的任务结束后调用，将开始时的累计分配量 before 之后的分配记入 info 的名称
+func (a *allocSampler) record(info *TaskInfo, before uint64) {
+	d := readAllocs() - before
+	name := ""
+	if info != nil {
+		name = info.Name
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.stats[name]
+	s.Sampled++
+	s.Bytes += d
+	a.stats[name] = s
+}
@@ -0,0 +1,28 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+)
+
+var allocSink []byte
+
+func TestAllocSampling(t *testing.T) {
+	p := New(1, WithLogger(nil), WithAllocSampling(3))
+	defer p.Free()
+	for i := 0; i < 10; i++ {
+		p.ScheduleWith(func(context.Context) { allocSink = make([]byte, 1<<20) }, WithTaskName("big"))
+		p.ScheduleWith(func(context.Context) {}, WithTaskName("small"))
+	}
+	p.Wait()
+	s := p.AllocStats()
+	if s["big"].Sampled+s["small"].Sampled != 6 {
+		t.Fatalf("sampled %+v, want 6 of 20 tasks", s)
+	}
+	if s["big"].PerTask() < 1<<20 {
+		t.Fatalf("big allocates %d bytes per task, want at least 1MiB", s["big"].PerTask())
+	}
+	if New(1, WithLogger(nil)).AllocStats() != nil {
+		t.Fatal("AllocStats without sampling should be nil")
+	}
+}
@@ -0,0 +1,91 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ApplyOptions 在运行中以 opts 修改 p 的选项，包括无法原地修改的结构性选项（WithQueueSize、WithPriorityScheduling 等）：
+// 以创建 p 时的容量与选项加上 opts 新建一个 pool，经由 HandoffTo 将流量切换到新的 pool 并排空旧的，
+// 此后 p 的所有方法都作用于新的 pool，调用方继续使用 p 即可；选项不合理时返回 NewE 的错误，p 不受影响
+// 已设置的延迟/周期任务与 Debounce 中的任务随旧的 pool 停止，不会迁移；启用 WithWAL 时返回错误
+func (p *Pool) ApplyOptions(opts ...Option) error {
+	return p.replace(0, opts)
+}
+
+// Resize 以同样的方式把 p 的容量改为 capacity，限制与 ApplyOptions 相同
+func (p *Pool) Resize(capacity int) error {
+	if capacity <= 0 {
+		return fmt.Errorf("%w: capacity %d must be positive", ErrInvalidOption, capacity)
+	}
+	return p.replace(capacity, nil)
+}
+
+// replace 以 capacity（0 表示沿用当前容量）与追加的 opts 新建 pool 并替换 p
+func (p *Pool) replace(capacity int, opts []Option) error {
+	p.applyMu.Lock()
+	defer p.applyMu.Unlock()
+	cur := p.applied()
+	if cur.wal != nil {
+		return errors.New("workerpool: ApplyOptions does not support pools with WithWAL")
+	}
+	if capacity == 0 {
+		capacity = cur.capacity
+	}
+	q, err := NewE(capacity, append(slices.Clip(cur.opts), opts...)...)
+	if err != nil {
+		return err
+	}
+	q.jobs.copyFrom(&cur.jobs)
+	if cur.pause.paused() { // 运行中的设置随之保留
+		q.pause.pause()
+	}
+	cur.rate.mu.Lock()
+	q.rate.set(cur.rate.interval)
+	cur.rate.mu.Unlock()
+	cur.forward.Store(true)
+	if err := cur.HandoffTo(q); err != nil {
+		cur.forward.Store(false)
+		q.Free()
+		return err
+	}
+	return nil
+}
+
+// applied 返回 p 经 ApplyOptions 替换后当前生效的 pool，未替换时为 p 自身
+func (p *Pool) applied() *Pool {
+	for p.forward.Load() {
+		q := p.successor.Load()
+		if q == nil {
+			break
+		}
+		p = q
+	}
+	return p
+}
+
+func (r *jobRegistry) copyFrom(from *jobRegistry) {
+	from.mu.RLock()
+	defer from.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, h := range from.handlers {
+		if r.handlers == nil {
+			r.handlers = make(map[string]JobHandler)
+		}
+		r.handlers[k] = h
+	}
+	for k, c := range from.counters {
+		if r.counters == nil {
+			r.counters = make(map[string]*jobCounter)
+		}
+		r.counters[k] = c
+	}
+	for k, c := range from.codecs {
+		if r.codecs == nil {
+			r.codecs = make(map[string]Codec)
+		}
+		r.codecs[k] = c
+	}
+}
@@ -0,0 +1,61 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestApplyOptions(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Schedule(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	applied := make(chan error)
+	go func() { applied <- p.ApplyOptions(WithQueueSize(8)) }()
+	var ran atomic.Int32
+	for i := 0; i < 20; i++ { // 切换期间与之后的提交都不会被拒绝
+		if err := p.Schedule(func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+	if err := <-applied; err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	if n := ran.Load(); n != 20 {
+		t.Fatalf("%d of 20 tasks ran", n)
+	}
+	if cur := p.applied(); cur == p || cur.queueSize != 8 || cur.Cap() != 2 {
+		t.Fatalf("current pool: queue %d cap %d", cur.queueSize, cur.Cap())
+	}
+	if err := p.ApplyOptions(WithQueueSize(-1)); err == nil {
+		t.Fatal("invalid option should be rejected")
+	}
+}
+
+func TestResize(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	p.Pause()
+	p.SetRateLimit(10)
+	if err := p.Resize(4); err != nil {
+		t.Fatal(err)
+	}
+	if c := p.Cap(); c != 4 {
+		t.Fatalf("Cap = %d after Resize(4)", c)
+	}
+	if !p.Paused() || p.RateLimit() != 10 {
+		t.Fatalf("paused = %t, rate = %g after Resize, want both kept", p.Paused(), p.RateLimit())
+	}
+	p.Resume()
+	if err := p.Resize(0); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("Resize(0) = %v, want ErrInvalidOption", err)
+	}
+}
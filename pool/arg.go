@@ -0,0 +1,15 @@
+package workerpool
+
+// ScheduleArg 与 Schedule 相同，任务为 fn(arg)：fn 与 arg 直接保存在任务中，不必为每次提交创建闭包，
+// 适合大量提交小任务的热路径；arg 为指针、map、channel 等单指针大小的类型时不需要装箱，其它类型的 arg 装箱一次
+func ScheduleArg[T any](p *Pool, fn func(T), arg T) error {
+	return p.schedule(task{argFn: fn, arg: arg, argRun: argRunner[T]{}})
+}
+
+// argRunner 还原 ScheduleArg 保存的函数与参数的类型并调用；空结构体存入接口时不分配，
+// 取泛型函数的值则需要为其字典分配闭包
+type argRunner[T any] struct{}
+
+func (argRunner[T]) run(fn, arg any) {
+	fn.(func(T))(arg.(T))
+}
@@ -0,0 +1,33 @@
+package workerpool
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestScheduleArg(t *testing.T) {
+	p := New(4, WithLogger(nil), WithPreAllocWorkers(true), WithQueueSize(64))
+	defer p.Free()
+	var sum atomic.Int64
+	for i := 1; i <= 10; i++ {
+		if err := ScheduleArg(p, func(n int) { sum.Add(int64(n)) }, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+	if n := sum.Load(); n != 55 {
+		t.Fatalf("sum %d, want 55", n)
+	}
+
+	if raceEnabled {
+		return
+	}
+	add := func(n *atomic.Int64) { n.Add(1) }
+	withArg := testing.AllocsPerRun(1000, func() { ScheduleArg(p, add, &sum) })
+	p.Wait()
+	withClosure := testing.AllocsPerRun(1000, func() { p.Schedule(func() { add(&sum) }) })
+	p.Wait()
+	if withArg >= withClosure {
+		t.Fatalf("ScheduleArg allocates %v per task, closure %v", withArg, withClosure)
+	}
+}
@@ -0,0 +1,41 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// auditRecord 是审计日志中的一行
+type auditRecord struct {
+	Time     time.Time         `json:"time"`
+	Event    EventKind         `json:"event"`
+	TaskID   uint64            `json:"task_id"`
+	Name     string            `json:"name,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Attempt  int               `json:"attempt,omitempty"`
+	Worker   int               `json:"worker,omitempty"`
+	Duration time.Duration     `json:"duration_ns,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func WithAuditWriter(w io.Writer) Option { // 把任务的生命周期事件（见 EventKind）逐行以 JSON 写入 w，写入在事件发生的 goroutine 中同步进行
+	return func(p *Pool) {
+		var mu sync.Mutex
+		enc := json.NewEncoder(w)
+		p.observers = append(p.observers, func(e Event) {
+			r := auditRecord{Time: e.Time, Event: e.Kind, TaskID: e.TaskID, Worker: e.Worker, Duration: e.Duration}
+			if e.Info != nil {
+				r.Name, r.Labels, r.Tags, r.Attempt = e.Info.Name, e.Info.Labels, e.Info.Tags, e.Info.Attempt
+			}
+			if e.Err != nil {
+				r.Error = e.Err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			_ = enc.Encode(r) // 写入失败不影响任务
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func readAudit(t *testing.T, buf *bytes.Buffer) []auditRecord {
+	t.Helper()
+	var records []auditRecord
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		var r auditRecord
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("bad audit line %q: %v", s.Text(), err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestAuditWriter(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(1, WithLogger(nil), WithAuditWriter(&buf))
+	if err := p.ScheduleWith(func(ctx context.Context) {}, WithTaskName("ok"), WithTaskLabel("k", "v")); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	if err := p.ScheduleWith(func(ctx context.Context) { panic("boom") }, WithTaskName("bad")); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	p.Free()
+	if err := p.Schedule(func() {}); err == nil {
+		t.Fatal("Schedule after Free succeeded")
+	}
+
+	var got []EventKind
+	records := readAudit(t, &buf)
+	for _, r := range records {
+		got = append(got, r.Event)
+	}
+	want := []EventKind{EventSubmitted, EventStarted, EventFinished, EventSubmitted, EventStarted, EventFailed, EventRejected}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+	ok, bad := records[2], records[5]
+	if ok.Name != "ok" || ok.Labels["k"] != "v" || ok.TaskID != records[0].TaskID || ok.Worker == 0 || ok.Time.IsZero() {
+		t.Fatalf("finished record = %+v", ok)
+	}
+	if bad.Name != "bad" || bad.Error == "" {
+		t.Fatalf("failed record = %+v", bad)
+	}
+	if records[6].Error == "" || records[6].TaskID == 0 {
+		t.Fatalf("rejected record = %+v", records[6])
+	}
+}
+
+func TestAuditWriterDiscarded(t *testing.T) {
+	var buf bytes.Buffer
+	p, release := newBusyPool(t, WithQueueSize(1), WithAuditWriter(&buf))
+	if err := p.ScheduleWith(func(ctx context.Context) {}, WithTaskName("queued")); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Purge(); n != 1 {
+		t.Fatalf("Purge = %d, want 1", n)
+	}
+	release()
+	p.Free()
+
+	var discarded []auditRecord
+	for _, r := range readAudit(t, &buf) {
+		if r.Event == EventDiscarded {
+			discarded = append(discarded, r)
+		}
+	}
+	if len(discarded) != 1 || discarded[0].Name != "queued" || discarded[0].Error != ErrTaskDiscarded.Error() {
+		t.Fatalf("discarded records = %+v", discarded)
+	}
+}
@@ -0,0 +1,77 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPoolInactive 是 WithAutoFreeAfter 销毁 pool 时的原因，之后的提交返回包装了它的 ErrPoolClosed
+var ErrPoolInactive = errors.New("pool inactive")
+
+type autoFree struct {
+	after  time.Duration
+	onFree func()
+	last   atomic.Int64 // 最近一次提交的时刻（UnixNano）
+}
+
+func WithAutoFreeAfter(d time.Duration, onFree func()) Option { // 连续 d 没有新的提交、且没有未执行完的任务与延迟任务时自动销毁 pool（原因为 ErrPoolInactive），销毁前调用 onFree（可为 nil）；用于按会话或租户创建、结束后容易忘记 Free 的 pool，WithLazyStart 下从第一次提交起计时
+	return func(p *Pool) {
+		if d <= 0 {
+			p.invalid("WithAutoFreeAfter(%s): duration must be positive", d)
+			return
+		}
+		p.autoFree = &autoFree{after: d, onFree: onFree}
+	}
+}
+
+// touchAutoFree 在每次提交时记录活动
+func (p *Pool) touchAutoFree() {
+	if p.autoFree != nil {
+		p.autoFree.last.Store(p.clock.Now().UnixNano())
+	}
+}
+
+// inactive 表示没有未执行完的任务与待触发的延迟任务
+func (p *Pool) inactive() bool {
+	if p.inflight.count() > 0 {
+		return false
+	}
+	p.timers.mu.Lock()
+	defer p.timers.mu.Unlock()
+	return p.timers.count == 0
+}
+
+// runAutoFree 在 pool 连续 after 没有活动时销毁它；仍有任务时每 after/4 重新检查
+func (p *Pool) runAutoFree() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	a := p.autoFree
+	if a.last.Load() == 0 {
+		a.last.Store(p.clock.Now().UnixNano()) // 从启动起计时
+	}
+	timer := p.clock.NewTimer(a.after)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C():
+		case <-p.quit:
+			return
+		}
+		idle := p.clock.Since(time.Unix(0, a.last.Load()))
+		switch {
+		case idle < a.after:
+			timer.Reset(a.after - idle)
+			continue
+		case !p.inactive():
+			timer.Reset(max(a.after/4, time.Millisecond))
+			continue
+		}
+		p.logf("workerpool: no submissions for %s, free\n", idle.Round(time.Millisecond))
+		if a.onFree != nil {
+			a.onFree()
+		}
+		go p.free(ErrPoolInactive) // free 等待 p.wg，不能在辅助 goroutine 中调用
+		return
+	}
+}
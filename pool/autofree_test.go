@@ -0,0 +1,64 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutoFreeAfter(t *testing.T) {
+	freed := make(chan struct{})
+	p := New(2, WithLogger(nil), WithAutoFreeAfter(50*time.Millisecond, func() { close(freed) }))
+	defer p.Free()
+	var ran atomic.Int32
+	gate := make(chan struct{})
+	if err := p.Schedule(func() { <-gate; ran.Add(1) }); err != nil {
+		t.Fatal(err)
+	}
+	// 任务未执行完时不销毁
+	time.Sleep(120 * time.Millisecond)
+	select {
+	case <-freed:
+		t.Fatal("freed while a task was running")
+	default:
+	}
+	close(gate)
+	select {
+	case <-freed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool not freed after inactivity")
+	}
+	if ran.Load() != 1 {
+		t.Fatal("task did not finish")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := p.Schedule(func() {})
+		if errors.Is(err, ErrPoolClosed) {
+			if !errors.Is(err, ErrPoolInactive) {
+				t.Fatalf("err = %v, want cause ErrPoolInactive", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Schedule after auto free: err = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAutoFreeAfterActivity(t *testing.T) {
+	var freed atomic.Bool
+	p := New(1, WithLogger(nil), WithAutoFreeAfter(80*time.Millisecond, func() { freed.Store(true) }))
+	defer p.Free()
+	for i := 0; i < 10; i++ { // 持续的提交推迟销毁
+		if err := p.Schedule(func() {}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if freed.Load() {
+		t.Fatal("freed while receiving submissions")
+	}
+}
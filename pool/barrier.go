@@ -0,0 +1,87 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// Barrier 是供 pool 中的任务分阶段同步的屏障：n 个任务都调用 Wait 后一起返回，之后可以用于下一阶段
+// 任务阻塞在 Wait 上时把所占的容量转交给一个新的 worker，因此即使参与的任务数超过容量，
+// 其余参与者也能开始执行并到达屏障，不会因 worker 全部阻塞而死锁；阻塞过的任务返回后其 goroutine 直接退出，
+// 期间 pool 的 goroutine 数可能暂时超过容量
+type Barrier struct {
+	p *Pool
+	n int
+
+	mu      sync.Mutex
+	arrived int
+	trip    chan struct{} // 本阶段全部到达时关闭，并替换为下一阶段的
+}
+
+// NewBarrier 返回 n 个参与者的屏障，n 必须为正数
+func (p *Pool) NewBarrier(n int) *Barrier {
+	if n <= 0 {
+		panic("workerpool: barrier size must be positive")
+	}
+	return &Barrier{p: p.applied(), n: n, trip: make(chan struct{})}
+}
+
+// Wait 阻塞直到本阶段的 n 个参与者都已到达、ctx 取消或 pool 销毁；ctx 取消时不再计入本阶段的到达数
+// ctx 应为 TaskFunc 收到的 ctx（或由其派生），否则无法识别所在的 worker，阻塞期间不转交容量
+func (b *Barrier) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.arrived++
+	if b.arrived == b.n {
+		close(b.trip)
+		b.trip, b.arrived = make(chan struct{}), 0
+		b.mu.Unlock()
+		return nil
+	}
+	trip := b.trip
+	b.mu.Unlock()
+	b.p.lend(ctx)
+	select {
+	case <-trip:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		select {
+		case <-trip: // 与最后一个参与者同时发生，本阶段已完成
+			return nil
+		default:
+		}
+		b.arrived--
+		return ctx.Err()
+	case <-b.p.quit:
+		return b.p.freedErr()
+	}
+}
+
+// Waiting 返回本阶段已到达、正在等待的参与者数
+func (b *Barrier) Waiting() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.arrived
+}
+
+// lend 在 ctx 所在的 worker 属于 p 时，把它占用的容量转交给一个新的 worker，该 worker 的任务返回后直接退出，
+// 每个 worker 只转交一次；WithOverflow 的 worker 与正以预算执行任务的 worker 不转交
+func (p *Pool) lend(ctx context.Context) {
+	w, ok := ctx.Value(workerKey{}).(*worker)
+	if !ok || !w.replaceable || w.lent || w.budgeted || w.abandoned {
+		return
+	}
+	if v, ok := p.live.Load(w.id); !ok || v != w {
+		return // 其它 pool 的 worker
+	}
+	select {
+	case <-p.quit:
+		return
+	default:
+	}
+	w.lent = true
+	p.logf("worker[%03d]: wait on barrier, lend capacity\n", w.id)
+	p.releaseSlot(w) // 位置随容量转交
+	p.newWorker(nil)
+}
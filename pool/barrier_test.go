@@ -0,0 +1,74 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 参与者多于容量时，阻塞在屏障上的任务转交容量，其余参与者仍能开始执行
+func TestBarrierMoreParticipantsThanCapacity(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	const n = 5
+	b := p.NewBarrier(n)
+	var phase1, phase2 atomic.Int32
+	for range n {
+		err := p.ScheduleFunc(func(ctx context.Context) {
+			if b.Wait(ctx) != nil {
+				return
+			}
+			phase1.Add(1)
+			if b.Wait(ctx) != nil {
+				return
+			}
+			phase2.Add(1)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := make(chan struct{})
+	go func() { p.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("barrier deadlocked the pool: %d waiting", b.Waiting())
+	}
+	if phase1.Load() != n || phase2.Load() != n {
+		t.Fatalf("phase1 = %d, phase2 = %d, want %d", phase1.Load(), phase2.Load(), n)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Workers > 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("workers = %d after barrier, want at most capacity", p.Stats().Workers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBarrierWaitCanceled(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	b := p.NewBarrier(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n := b.Waiting(); n != 0 {
+		t.Fatalf("waiting = %d after cancel, want 0", n)
+	}
+	// 取消的参与者不计入到达数，两个新的参与者完成本阶段
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() { errs <- b.Wait(context.Background()) }()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package workerpool
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrBatcherClosed = errors.New("batcher closed")
+
+// Batcher 将逐个提交的元素攒成批次交给 pool 处理：
+// 攒够 size 个，或第一个元素到达后经过 interval，就以当前批次调用一次 handler
+type Batcher[T any] struct {
+	p        *Pool
+	size     int
+	interval time.Duration
+	handler  func(batch []T)
+
+	mu     sync.Mutex
+	items  []T
+	gen    uint64      // 每开始一个新批次加一，用于识别过期的定时器
+	timer  TimerHandle // 当前批次的定时器
+	closed bool
+	wg     sync.WaitGroup // 已投递但尚未结束的 handler
+}
+
+func NewBatcher[T any](p *Pool, size int, interval time.Duration, handler func(batch []T)) *Batcher[T] {
+	if size <= 0 {
+		size = 1
+	}
+	return &Batcher[T]{p: p, size: size, interval: interval, handler: handler}
+}
+
+// Add 加入一个元素；批次攒满时投递到 pool，pool 满且阻塞时 Add 随之阻塞
+func (b *Batcher[T]) Add(item T) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatcherClosed
+	}
+	b.items = append(b.items, item)
+	if len(b.items) == 1 && b.interval > 0 && len(b.items) < b.size {
+		gen := b.gen
+		b.timer, _ = b.p.ScheduleAfter(b.interval, func() { b.expire(gen) })
+	}
+	if len(b.items) < b.size {
+		b.mu.Unlock()
+		return nil
+	}
+	batch := b.take()
+	b.mu.Unlock()
+	return b.dispatch(batch)
+}
+
+// Flush 立即投递当前批次（如果非空）
+func (b *Batcher[T]) Flush() error {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+	return b.dispatch(batch)
+}
+
+// Close 停止接收新元素，投递剩余的元素并等待所有 handler 结束
+func (b *Batcher[T]) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	batch := b.take()
+	b.mu.Unlock()
+	err := b.dispatch(batch)
+	b.wg.Wait()
+	return err
+}
+
+// 取走当前批次并使其定时器失效；需持有 b.mu
+// 非空批次在持锁时计入 b.wg，保证 Close 能等到它
+func (b *Batcher[T]) take() []T {
+	if len(b.items) == 0 {
+		return nil
+	}
+	batch := b.items
+	b.items = nil
+	b.gen++
+	b.timer.Stop()
+	b.wg.Add(1)
+	return batch
+}
+
+func (b *Batcher[T]) dispatch(batch []T) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	err := b.p.scheduleOr(task{fn: func() {
+		defer b.wg.Done()
+		b.handler(batch)
+	}}, func(error) { b.wg.Done() })
+	if err != nil {
+		b.wg.Done()
+	}
+	return err
+}
+
+// 定时器到期时已经运行在 worker 上，直接在当前 worker 中处理批次，
+// 避免在 pool 满时由 worker 再向 pool 提交任务而互相等待
+func (b *Batcher[T]) expire(gen uint64) {
+	b.mu.Lock()
+	if gen != b.gen {
+		b.mu.Unlock()
+		return
+	}
+	batch := b.take()
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		defer b.wg.Done()
+		b.handler(batch)
+	}
+}
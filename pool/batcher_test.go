@@ -0,0 +1,100 @@
+package workerpool
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batches 收集 handler 收到的批次
+type batches[T any] struct {
+	mu  sync.Mutex
+	got [][]T
+	ch  chan []T
+}
+
+func newBatches[T any]() *batches[T] { return &batches[T]{ch: make(chan []T, 16)} }
+
+func (b *batches[T]) handle(batch []T) {
+	b.mu.Lock()
+	b.got = append(b.got, batch)
+	b.mu.Unlock()
+	b.ch <- batch
+}
+
+func TestBatcherSize(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	got := newBatches[int]()
+	b := NewBatcher(p, 3, 0, got.handle)
+	for i := range 7 {
+		if err := b.Add(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(got.got, func(a, b []int) int { return a[0] - b[0] })
+	if len(got.got) != 3 || !slices.Equal(got.got[0], []int{0, 1, 2}) || !slices.Equal(got.got[1], []int{3, 4, 5}) || !slices.Equal(got.got[2], []int{6}) {
+		t.Fatalf("batches = %v", got.got)
+	}
+	if err := b.Add(7); !errors.Is(err, ErrBatcherClosed) {
+		t.Fatalf("Add after Close = %v", err)
+	}
+}
+
+func TestBatcherInterval(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := New(1, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	got := newBatches[string]()
+	b := NewBatcher(p, 10, time.Second, got.handle)
+	b.Add("a")
+	clock.BlockUntil(1)
+	clock.Advance(500 * time.Millisecond)
+	b.Add("b") // 不会重新计时，从第一个元素起算
+	clock.BlockUntil(1)
+	clock.Advance(500 * time.Millisecond)
+	if batch := <-got.ch; !slices.Equal(batch, []string{"a", "b"}) {
+		t.Fatalf("batch = %v", batch)
+	}
+
+	b.Add("c")
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if batch := <-got.ch; !slices.Equal(batch, []string{"c"}) {
+		t.Fatalf("flushed batch = %v", batch)
+	}
+	clock.Advance(time.Hour) // 已经 Flush 的批次的定时器失效
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.ch) != 0 {
+		t.Fatalf("stale timer delivered %v", <-got.ch)
+	}
+}
+
+func TestBatcherCloseWaits(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	release := make(chan struct{})
+	var done bool
+	b := NewBatcher(p, 1, 0, func([]int) { <-release; done = true })
+	b.Add(1)
+	closed := make(chan struct{})
+	go func() { b.Close(); close(closed) }()
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the handler finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-closed
+	if !done {
+		t.Fatal("handler did not finish before Close returned")
+	}
+}
@@ -0,0 +1,113 @@
+package workerpool
+
+import (
+	"slices"
+	"time"
+)
+
+// BlackoutWindow 是禁止执行普通任务的时段，见 WithBlackout：
+// From/Until 非零时为一段确定的时间（如一次维护），否则为每天（或 Weekdays 中的每一天）重复的 Start–End 时段
+type BlackoutWindow struct {
+	Start    time.Duration  // 距当天 0 点的偏移，如 9 * time.Hour
+	End      time.Duration  // End 小于 Start 表示跨越午夜，如 21:00–06:00
+	Weekdays []time.Weekday // 时段开始的那一天须为其中之一，空表示每天
+
+	From, Until time.Time // 确定的一段时间 [From, Until)
+}
+
+func (w BlackoutWindow) explicit() bool {
+	return !w.From.IsZero() || !w.Until.IsZero()
+}
+
+// until 返回 now 落在 w 中时 w 结束的时刻
+func (w BlackoutWindow) until(now time.Time, loc *time.Location) (time.Time, bool) {
+	if w.explicit() {
+		return w.Until, !now.Before(w.From) && now.Before(w.Until)
+	}
+	t := now.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	off := t.Sub(day)
+	var start time.Time // 所在时段开始的那一天
+	switch {
+	case w.Start <= w.End && off >= w.Start && off < w.End:
+		start = day
+	case w.Start > w.End && off >= w.Start:
+		start = day
+	case w.Start > w.End && off < w.End:
+		start = day.AddDate(0, 0, -1)
+	default:
+		return time.Time{}, false
+	}
+	if len(w.Weekdays) > 0 && !slices.Contains(w.Weekdays, start.Weekday()) {
+		return time.Time{}, false
+	}
+	end := start.Add(w.End)
+	if w.Start > w.End {
+		end = start.AddDate(0, 0, 1).Add(w.End)
+	}
+	return end, true
+}
+
+func WithBlackout(loc *time.Location, windows ...BlackoutWindow) Option { // 在任一时段内暂停分发普通任务，任务留在 pool 中排队，时段结束后继续；ScheduleUrgent 的任务不受影响；loc 为 nil 时使用 time.Local
+	return func(p *Pool) {
+		for _, w := range windows {
+			bad := w.Start < 0 || w.Start >= 24*time.Hour || w.End < 0 || w.End >= 24*time.Hour
+			if w.explicit() {
+				bad = !w.From.Before(w.Until)
+			}
+			if bad {
+				p.invalid("WithBlackout: bad window %+v", w)
+				return
+			}
+		}
+		if loc == nil {
+			loc = time.Local
+		}
+		p.blackoutLoc, p.blackouts = loc, windows
+	}
+}
+
+// InBlackout 返回 p 当前是否处于 WithBlackout 的时段内，以及分发恢复的时刻
+func (p *Pool) InBlackout() (until time.Time, ok bool) {
+	p = p.applied()
+	return p.blackoutUntil(p.clock.Now())
+}
+
+// blackoutUntil 返回 now 所处时段的结束时刻，相邻或重叠的时段合并计算
+func (p *Pool) blackoutUntil(now time.Time) (until time.Time, ok bool) {
+	for at := now; ; {
+		found := false
+		for _, w := range p.blackouts {
+			if end, in := w.until(at, p.blackoutLoc); in && end.After(at) {
+				at, found, ok = end, true, true
+			}
+		}
+		if !found && !ok {
+			return time.Time{}, false
+		}
+		if !found {
+			return at, true
+		}
+	}
+}
+
+// waitBlackout 在时段内阻塞执行普通任务的 worker，直到时段结束或 p 销毁
+func (p *Pool) waitBlackout(w *worker, t task) {
+	if len(p.blackouts) == 0 || t.urgent {
+		return
+	}
+	for {
+		until, ok := p.blackoutUntil(p.clock.Now())
+		if !ok {
+			return
+		}
+		p.logf("worker[%03d]: blackout until %s, hold task\n", w.id, until.Format(time.RFC3339))
+		timer := p.clock.NewTimer(until.Sub(p.clock.Now()))
+		select {
+		case <-timer.C():
+		case <-p.quit:
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlackout(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(monday)
+	p := New(1, WithLogger(nil), WithClock(clock), WithReservedWorkers(1),
+		WithBlackout(time.UTC, BlackoutWindow{Start: 9 * time.Hour, End: 17 * time.Hour}))
+	defer p.Free()
+	if until, ok := p.InBlackout(); !ok || !until.Equal(monday.Add(7*time.Hour)) {
+		t.Fatalf("InBlackout = %s, %t", until, ok)
+	}
+	ran := make(chan string, 2)
+	p.Schedule(func() { ran <- "normal" })
+	clock.BlockUntil(1) // worker 持有任务等待时段结束
+	p.ScheduleUrgent(func() { ran <- "urgent" })
+	if got := <-ran; got != "urgent" {
+		t.Fatalf("%s task ran during blackout", got)
+	}
+	clock.Advance(7 * time.Hour)
+	if got := <-ran; got != "normal" {
+		t.Fatalf("got %s", got)
+	}
+	if _, ok := p.InBlackout(); ok {
+		t.Fatal("blackout should be over")
+	}
+}
+
+func TestBlackoutWindows(t *testing.T) {
+	loc := time.UTC
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, loc) } // 10 月 12 日是周一
+	p := &Pool{blackoutLoc: loc, blackouts: []BlackoutWindow{
+		{Start: 22 * time.Hour, End: 2 * time.Hour, Weekdays: []time.Weekday{time.Friday}},
+		{From: at(20, 1), Until: at(20, 4)},
+		{From: at(20, 4), Until: at(20, 6)}, // 与上一个相接，合并计算
+	}}
+	for _, c := range []struct {
+		now   time.Time
+		until time.Time
+		ok    bool
+	}{
+		{at(16, 23), at(17, 2), true}, // 周五晚上，跨越午夜
+		{at(17, 1), at(17, 2), true},  // 时段开始于周五
+		{at(17, 23), time.Time{}, false},
+		{at(20, 2), at(20, 6), true},
+		{at(20, 6), time.Time{}, false},
+	} {
+		until, ok := p.blackoutUntil(c.now)
+		if ok != c.ok || ok && !until.Equal(c.until) {
+			t.Errorf("blackoutUntil(%s) = %s, %t, want %s, %t", c.now, until, ok, c.until, c.ok)
+		}
+	}
+}
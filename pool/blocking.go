@@ -0,0 +1,40 @@
+package workerpool
+
+import (
+	"runtime/debug"
+	"runtime/pprof"
+)
+
+// 阻塞在系统调用或 cgo 中的 goroutine 会让出 P，但仍独占一个 OS 线程；
+// Go 进程线程数超过 debug.SetMaxThreads 的上限（默认 10000）时会直接崩溃，
+// 因此 blockingMode 下 pool 在创建时检查线程预算，并单独统计被阻塞占用的线程数
+
+// 为 runtime 自身及 pool 之外的 goroutine 预留的线程数
+const threadHeadroom = 64
+
+// BlockingThreads 返回 blockingMode 下当前被任务阻塞占用的 OS 线程数，非 blockingMode 时恒为 0
+func (p *Pool) BlockingThreads() int {
+	p = p.applied()
+	return int(p.blocking.Load())
+}
+
+func (p *Pool) checkThreadBudget() {
+	limit := currentMaxThreads()
+	if p.maxThreads > limit {
+		debug.SetMaxThreads(p.maxThreads)
+		p.logf("workerpool: raise max threads %d -> %d\n", limit, p.maxThreads)
+		limit = p.maxThreads
+	}
+	created := pprof.Lookup("threadcreate").Count()
+	if need := created + p.capacity + threadHeadroom; need > limit {
+		p.logf("workerpool: %d blocking workers may exceed the thread limit %d (already created %d), "+
+			"use WithBlockingTasks(%d) or debug.SetMaxThreads to raise it\n", p.capacity, limit, created, need)
+	}
+}
+
+// debug 包没有只读接口，设置后立即恢复原值
+func currentMaxThreads() int {
+	n := debug.SetMaxThreads(1 << 20)
+	debug.SetMaxThreads(n)
+	return n
+}
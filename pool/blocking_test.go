@@ -0,0 +1,59 @@
+package workerpool
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBlockingThreads(t *testing.T) {
+	p := New(3, WithBlockingTasks(0), WithLogger(nil))
+	defer p.Free()
+	var started sync.WaitGroup
+	release := make(chan struct{})
+	started.Add(3)
+	for range 3 {
+		p.Schedule(func() { started.Done(); <-release })
+	}
+	started.Wait()
+	if n := p.BlockingThreads(); n != 3 {
+		t.Fatalf("BlockingThreads = %d with 3 running tasks", n)
+	}
+	close(release)
+	p.Wait()
+	if n := p.BlockingThreads(); n != 0 {
+		t.Fatalf("BlockingThreads = %d after Wait", n)
+	}
+
+	plain := New(1, WithLogger(nil))
+	defer plain.Free()
+	plain.Schedule(func() {
+		if n := plain.BlockingThreads(); n != 0 {
+			t.Errorf("BlockingThreads = %d without WithBlockingTasks", n)
+		}
+	})
+	plain.Wait()
+}
+
+func TestBlockingTasksThreadBudget(t *testing.T) {
+	limit := currentMaxThreads()
+	defer debug.SetMaxThreads(limit)
+	var logs []string
+	logf := func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }
+
+	p := New(1, WithBlockingTasks(limit+100), WithLogger(logf))
+	p.Free()
+	if got := currentMaxThreads(); got != limit+100 {
+		t.Fatalf("max threads = %d, want %d", got, limit+100)
+	}
+
+	logs = nil
+	capacity := currentMaxThreads() + 1
+	p = New(capacity, WithMaxCapacity(capacity), WithBlockingTasks(0), WithLogger(logf))
+	p.Free()
+	if !strings.Contains(strings.Join(logs, ""), "may exceed the thread limit") {
+		t.Fatalf("no thread budget warning for capacity %d, logs %q", capacity, logs)
+	}
+}
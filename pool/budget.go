@@ -0,0 +1,78 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBudgetExceeded 是超出预算的任务 ctx 的取消原因（context.Cause）
+var ErrBudgetExceeded = errors.New("task exceeded its budget")
+
+// 软预算：Go 无法终止 goroutine，任务执行超出预算时只能放弃它——取消其 ctx，不再计入 Wait，
+// 并启动一个新的 worker 接替它占用的容量，pool 的有效容量不因失控的任务而缩小。
+// 被放弃的任务仍在原来的 goroutine 上运行直到返回，期间计入 AbandonedTasks，返回后该 goroutine 直接退出
+
+func WithBudget(d time.Duration) TaskOption { // 任务的执行预算，覆盖 WithTaskBudget，0 表示不限
+	return func(t *task) {
+		t.budget = d
+		t.hasBudget = true
+	}
+}
+
+// AbandonedTasks 返回超出预算被放弃、仍未返回的任务数，这些 goroutine 不计入 worker 数量
+func (p *Pool) AbandonedTasks() int {
+	p = p.applied()
+	return int(p.abandoned.Load())
+}
+
+func (p *Pool) budgetOf(t task) time.Duration {
+	if t.hasBudget {
+		return t.budget
+	}
+	return p.budget
+}
+
+// runBudget 在 w 上以预算 budget 执行 run；超出预算时 w 被标记为 abandoned，返回后应立即退出且不归还容量
+func (p *Pool) runBudget(w *worker, t task, ctx context.Context, run TaskFunc, budget time.Duration) {
+	var state atomic.Int32 // 0 执行中，1 已返回，2 已放弃
+	w.budgeted = true
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := p.clock.AfterFunc(budget, func() {
+		if state.CompareAndSwap(0, 2) {
+			cancel(ErrBudgetExceeded)
+			p.abandon(w, t, budget)
+		}
+	})
+	defer func() { // panic 时同样执行，worker 随后因 panic 退出
+		timer.Stop()
+		w.budgeted = false
+		cancel(nil)
+		if !state.CompareAndSwap(0, 1) {
+			w.abandoned = true
+			p.abandoned.Add(-1)
+		}
+	}()
+	run(ctx)
+}
+
+// abandon 放弃 w 上超出预算的 t，由新的 worker 接替 w 占用的容量
+// w 尚未退出（计入 p.wg），此时 Add p.wg 是安全的
+func (p *Pool) abandon(w *worker, t task, budget time.Duration) {
+	p.abandoned.Add(1)
+	if t.counted {
+		p.inflight.add(-1)
+	}
+	name := "a task"
+	if t.info != nil {
+		name = t.info.String()
+	}
+	p.logf("worker[%03d]: abandon %s after budget %s\n", w.id, name, budget)
+	select {
+	case <-p.quit:
+	default:
+		p.releaseSlot(w) // 位置随容量转交
+		p.newWorker(nil)
+	}
+}
@@ -0,0 +1,62 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBudgetAbandonsOverrunningTask(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	stuck := make(chan struct{})
+	causes := make(chan error, 1)
+	// 忽略 ctx 取消的任务占住唯一的 worker，超出预算后容量转交给新的 worker
+	if err := p.ScheduleWith(func(ctx context.Context) {
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		<-stuck
+	}, WithBudget(10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-causes; err != ErrBudgetExceeded {
+		t.Fatalf("cause = %v, want ErrBudgetExceeded", err)
+	}
+	var ran atomic.Int32
+	for i := 0; i < 10; i++ {
+		if err := p.Schedule(func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait() // 被放弃的任务不再计入 Wait
+	if n := ran.Load(); n != 10 {
+		t.Fatalf("%d of 10 tasks ran", n)
+	}
+	if n := p.AbandonedTasks(); n != 1 {
+		t.Fatalf("AbandonedTasks() = %d, want 1", n)
+	}
+	close(stuck)
+	deadline := time.Now().Add(5 * time.Second)
+	for p.AbandonedTasks() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned task not accounted after it returned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(p.active); n != 1 {
+		t.Fatalf("%d slots in use, want 1", n)
+	}
+}
+
+func TestBudgetNotExceeded(t *testing.T) {
+	p := New(1, WithLogger(nil), WithTaskBudget(time.Hour))
+	defer p.Free()
+	for i := 0; i < 100; i++ {
+		p.Schedule(func() {})
+	}
+	p.Wait()
+	if n := p.AbandonedTasks(); n != 0 {
+		t.Fatalf("AbandonedTasks() = %d", n)
+	}
+}
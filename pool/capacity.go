@@ -0,0 +1,111 @@
+package workerpool
+
+import (
+	"time"
+)
+
+// CapacityWindow 是一天中的一个时段及其间的容量，见 WithCapacitySchedule
+type CapacityWindow struct {
+	Start    time.Duration // 距当天 0 点的偏移，如 9 * time.Hour
+	End      time.Duration // End 小于 Start 表示跨越午夜，如 21:00–09:00
+	Capacity int           // 时段内的容量，超出 New 的 capacity 时按 capacity 计
+}
+
+func (w CapacityWindow) contains(off time.Duration) bool {
+	if w.Start <= w.End {
+		return off >= w.Start && off < w.End
+	}
+	return off >= w.Start || off < w.End
+}
+
+func WithCapacitySchedule(loc *time.Location, windows ...CapacityWindow) Option { // 按 loc 中的时刻自动调整容量：落在某个时段内时使用其 Capacity（先列出的优先），其余时间使用 New 的 capacity；loc 为 nil 时使用 time.Local
+	return func(p *Pool) {
+		for _, w := range windows {
+			if w.Start < 0 || w.Start >= 24*time.Hour || w.End < 0 || w.End >= 24*time.Hour || w.Capacity < 1 {
+				p.invalid("WithCapacitySchedule: bad window %+v", w)
+				return
+			}
+		}
+		if loc == nil {
+			loc = time.Local
+		}
+		p.capLoc, p.capWindows = loc, windows
+		p.shrink = make(chan struct{})
+	}
+}
+
+// scheduledCapacity 返回 now 所在时段的容量，以及到下一个时段边界的时间
+func (p *Pool) scheduledCapacity(now time.Time) (int, time.Duration) {
+	t := now.In(p.capLoc)
+	off := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, p.capLoc))
+	capacity := p.capacity
+	for _, w := range p.capWindows {
+		if w.contains(off) {
+			capacity = min(w.Capacity, p.capacity)
+			break
+		}
+	}
+	next := 24 * time.Hour
+	for _, w := range p.capWindows {
+		for _, b := range []time.Duration{w.Start, w.End} {
+			d := (b - off + 24*time.Hour) % (24 * time.Hour)
+			if d > 0 {
+				next = min(next, d)
+			}
+		}
+	}
+	return capacity, next
+}
+
+// runCapacitySchedule 是 WithCapacitySchedule 的辅助 goroutine：通过占住 p.active 中的位置降低容量，
+// 或请一个空闲的 worker 经由 p.shrink 退出并转交它的位置；到达时段边界时重新计算
+func (p *Pool) runCapacitySchedule() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	parked := 0
+	defer func() {
+		for ; parked > 0; parked-- {
+			<-p.active
+		}
+		p.parked.Store(0)
+	}()
+	current := p.capacity
+	for {
+		capacity, next := p.scheduledCapacity(p.clock.Now())
+		if capacity != current {
+			p.logf("workerpool: capacity %d -> %d by schedule\n", current, capacity)
+			current = capacity
+		}
+		timer := p.clock.NewTimer(next)
+		for parked > p.capacity-capacity {
+			<-p.active
+			parked--
+			p.parked.Store(int32(parked))
+			p.replenish(p.preAlloc)
+		}
+		fired := false
+		for !fired && parked < p.capacity-capacity {
+			select {
+			case p.active <- struct{}{}:
+				parked++
+				p.parked.Store(int32(parked))
+			case p.shrink <- struct{}{}: // 一个空闲的 worker 退出，把它的位置转交过来
+				parked++
+				p.parked.Store(int32(parked))
+			case <-timer.C():
+				fired = true
+			case <-p.quit:
+				timer.Stop()
+				return
+			}
+		}
+		if !fired {
+			select {
+			case <-timer.C():
+			case <-p.quit:
+				timer.Stop()
+				return
+			}
+		}
+	}
+}
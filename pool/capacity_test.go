@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduledCapacity(t *testing.T) {
+	p := New(10, WithLogger(nil), WithCapacitySchedule(time.UTC,
+		CapacityWindow{Start: 9 * time.Hour, End: 21 * time.Hour, Capacity: 50},
+		CapacityWindow{Start: 21 * time.Hour, End: 6 * time.Hour, Capacity: 2},
+	))
+	defer p.Free()
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		at       time.Duration
+		capacity int
+		next     time.Duration
+	}{
+		{10 * time.Hour, 10, 11 * time.Hour}, // 超出 capacity 时按 capacity 计
+		{23 * time.Hour, 2, 7 * time.Hour},
+		{3 * time.Hour, 2, 3 * time.Hour},
+		{7 * time.Hour, 10, 2 * time.Hour},
+		{21 * time.Hour, 2, 9 * time.Hour},
+	} {
+		capacity, next := p.scheduledCapacity(day.Add(c.at))
+		if capacity != c.capacity || next != c.next {
+			t.Errorf("at %s: capacity %d next %s, want %d and %s", c.at, capacity, next, c.capacity, c.next)
+		}
+	}
+}
+
+func TestCapacityScheduleLimitsConcurrency(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC))
+	p := New(4, WithLogger(nil), WithClock(clock), WithPreAllocWorkers(true),
+		WithCapacitySchedule(time.UTC, CapacityWindow{Start: 0, End: 9 * time.Hour, Capacity: 1}))
+	defer p.Free()
+	waitCapacity := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for p.Stats().Capacity != want || p.Stats().Workers != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("stats = %+v, want capacity and workers %d", p.Stats(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitCapacity(1)
+
+	var running, peak atomic.Int32
+	task := func() {
+		n := running.Add(1)
+		for {
+			m := peak.Load()
+			if n <= m || peak.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+	}
+	for i := 0; i < 5; i++ {
+		if err := p.Schedule(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+	if peak.Load() != 1 {
+		t.Fatalf("peak concurrency = %d, want 1", peak.Load())
+	}
+
+	clock.Advance(time.Hour) // 09:00 起恢复为 capacity
+	waitCapacity(4)
+}
+
+func TestCapacityScheduleValidation(t *testing.T) {
+	for _, w := range []CapacityWindow{
+		{Start: -time.Hour, End: time.Hour, Capacity: 1},
+		{Start: 0, End: 24 * time.Hour, Capacity: 1},
+		{Start: 0, End: time.Hour, Capacity: 0},
+	} {
+		if _, err := NewE(4, WithCapacitySchedule(nil, w)); !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("window %+v: err = %v, want ErrInvalidOption", w, err)
+		}
+	}
+}
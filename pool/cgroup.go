@@ -0,0 +1,101 @@
+package workerpool
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot 是 cgroup 文件系统的挂载点，测试中替换
+var cgroupRoot = "/sys/fs/cgroup"
+
+// CPUQuota 返回容器（cgroup v2 的 cpu.max，或 v1 的 cpu.cfs_quota_us/cpu.cfs_period_us）限制的 CPU 数，
+// 如 2 个 CPU 的配额返回 2，1.5 返回 1.5；未设置配额或无法读取时 ok 为 false
+func CPUQuota() (cpus float64, ok bool) {
+	return cpuQuota(cgroupRoot, "/proc/self/cgroup")
+}
+
+func cpuQuota(root, self string) (float64, bool) {
+	// cgroup v2：cpu.max 为 "$MAX $PERIOD"，不限制时 MAX 为 "max"
+	for _, dir := range cgroupDirs(self, "") {
+		if b, err := os.ReadFile(filepath.Join(root, dir, "cpu.max")); err == nil {
+			f := strings.Fields(string(b))
+			if len(f) != 2 || f[0] == "max" {
+				return 0, false
+			}
+			return quotaRatio(f[0], f[1])
+		}
+	}
+	// cgroup v1：cpu.cfs_quota_us 为 -1 时不限制
+	for _, dir := range cgroupDirs(self, "cpu") {
+		base := filepath.Join(root, "cpu", dir)
+		quota, err1 := os.ReadFile(filepath.Join(base, "cpu.cfs_quota_us"))
+		period, err2 := os.ReadFile(filepath.Join(base, "cpu.cfs_period_us"))
+		if err1 == nil && err2 == nil {
+			return quotaRatio(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+		}
+	}
+	return 0, false
+}
+
+// cgroupDirs 返回依次尝试的 cgroup 目录：/proc/self/cgroup 中当前进程所在的路径（v2 的控制器为空），
+// 以及容器内常见的、被挂载为根的 "/"
+func cgroupDirs(self, controller string) []string {
+	dirs := []string{}
+	if b, err := os.ReadFile(self); err == nil {
+		s := bufio.NewScanner(bytes.NewReader(b))
+		for s.Scan() {
+			// 每行为 "hierarchy-ID:controller-list:path"
+			parts := strings.SplitN(s.Text(), ":", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			for _, c := range strings.Split(parts[1], ",") {
+				if c == controller {
+					dirs = append(dirs, parts[2])
+				}
+			}
+		}
+	}
+	return append(dirs, "/")
+}
+
+func quotaRatio(quota, period string) (float64, bool) {
+	q, err1 := strconv.ParseFloat(quota, 64)
+	p, err2 := strconv.ParseFloat(period, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
+
+// AvailableCPUs 返回进程实际可用的 CPU 数：CPU 配额向上取整，不超过 runtime.NumCPU()，至少为 1
+func AvailableCPUs() int {
+	n := runtime.NumCPU()
+	if cpus, ok := CPUQuota(); ok {
+		n = min(n, int(math.Ceil(cpus)))
+	}
+	return max(n, 1)
+}
+
+// AdjustMaxProcs 在 GOMAXPROCS 超出 AvailableCPUs 时将其调低（类似 automaxprocs），返回调整前后的值；
+// 在 2 个 CPU 配额的容器中运行于 64 核的节点上时，避免 runtime 与按 CPU 数确定的 pool 容量过度订阅
+// 较新的 Go 版本会自动按配额设置 GOMAXPROCS，此时不会有任何改变
+func AdjustMaxProcs() (prev, cur int) {
+	prev = runtime.GOMAXPROCS(0)
+	if n := AvailableCPUs(); n < prev {
+		runtime.GOMAXPROCS(n)
+		return prev, n
+	}
+	return prev, prev
+}
+
+// CPUCapacity 返回每个可用 CPU perCPU 个 worker 的容量，用于按容器的 CPU 配额确定 New 的 capacity
+func CPUCapacity(perCPU int) int {
+	return max(perCPU, 1) * AvailableCPUs()
+}
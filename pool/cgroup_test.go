@@ -0,0 +1,58 @@
+package workerpool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCPUQuota(t *testing.T) {
+	dir := t.TempDir()
+	self := filepath.Join(dir, "self")
+	root := filepath.Join(dir, "cgroup")
+
+	writeFile(t, self, "0::/kubepods/pod1\n")
+	writeFile(t, filepath.Join(root, "kubepods/pod1/cpu.max"), "150000 100000\n")
+	if cpus, ok := cpuQuota(root, self); !ok || cpus != 1.5 {
+		t.Fatalf("v2 quota = %v %v, want 1.5", cpus, ok)
+	}
+	writeFile(t, filepath.Join(root, "kubepods/pod1/cpu.max"), "max 100000\n")
+	if _, ok := cpuQuota(root, self); ok {
+		t.Fatal("unlimited v2 quota reported as limited")
+	}
+
+	root = filepath.Join(dir, "v1")
+	writeFile(t, self, "4:cpu,cpuacct:/docker/abc\n")
+	writeFile(t, filepath.Join(root, "cpu/docker/abc/cpu.cfs_quota_us"), "200000\n")
+	writeFile(t, filepath.Join(root, "cpu/docker/abc/cpu.cfs_period_us"), "100000\n")
+	if cpus, ok := cpuQuota(root, self); !ok || cpus != 2 {
+		t.Fatalf("v1 quota = %v %v, want 2", cpus, ok)
+	}
+	writeFile(t, filepath.Join(root, "cpu/docker/abc/cpu.cfs_quota_us"), "-1\n")
+	if _, ok := cpuQuota(root, self); ok {
+		t.Fatal("unlimited v1 quota reported as limited")
+	}
+}
+
+func TestCPUCapacity(t *testing.T) {
+	prev := cgroupRoot
+	defer func() { cgroupRoot = prev }()
+	cgroupRoot = t.TempDir()
+	writeFile(t, filepath.Join(cgroupRoot, "cpu.max"), "100000 100000\n")
+	if quota, ok := CPUQuota(); !ok || quota != 1 {
+		t.Fatalf("quota = %v %v, want 1", quota, ok)
+	}
+	if n := CPUCapacity(8); n != 8 {
+		t.Fatalf("CPUCapacity(8) = %d with one CPU, want 8", n)
+	}
+}
@@ -0,0 +1,265 @@
+package workerpool
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 抽象 pool 内部所有的时间操作（空闲超时、延迟任务、看门狗、限流等），
+// 测试中可以注入 FakeClock 虚拟推进时间，而不必真的 sleep
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer 对应 *time.Timer，AfterFunc 创建的 Timer 其 C() 返回 nil
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker 对应 *time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// 默认使用系统时钟
+var systemClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) Sleep(d time.Duration)           { time.Sleep(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time   { return t.t.C }
+func (t realTicker) Stop()                 { t.t.Stop() }
+func (t realTicker) Reset(d time.Duration) { t.t.Reset(d) }
+
+// FakeClock 是一个手动推进的时钟，只有调用 Advance/Set 时时间才会流逝，
+// 到期的 timer/ticker 在 Advance 的调用方 goroutine 中按到期顺序触发
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // waiters 变化时关闭并重建，供 BlockUntil 等待
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration // ticker 的周期，timer 为 0
+	ch       chan time.Time
+	fn       func()
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.NewTimer(d).C()
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.add(w, d)
+	return (*fakeTimer)(w)
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	w := &fakeWaiter{clock: c, fn: f}
+	c.add(w, d)
+	return (*fakeTimer)(w)
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("workerpool: non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{clock: c, period: d, ch: make(chan time.Time, 1)}
+	c.add(w, d)
+	return (*fakeTicker)(w)
+}
+
+// Advance 将时间推进 d，并触发期间所有到期的 timer/ticker
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set 将时间设置为 t（不允许回拨），并触发期间所有到期的 timer/ticker
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(t) {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		if w.deadline.After(c.now) {
+			c.now = w.deadline
+		}
+		now := c.now
+		if w.period > 0 {
+			w.deadline = now.Add(w.period)
+			c.insert(w)
+		}
+		c.notify()
+		c.mu.Unlock()
+
+		if w.fn != nil {
+			w.fn()
+		} else {
+			select {
+			case w.ch <- now:
+			default: // 与 time 包一致，接收方来不及消费则丢弃
+			}
+		}
+	}
+}
+
+// BlockUntil 阻塞直到至少有 n 个未触发的 timer/ticker，
+// 用于测试中确认被测 goroutine 已经开始等待，再推进时间
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= n {
+			c.mu.Unlock()
+			return
+		}
+		ch := c.changed
+		c.mu.Unlock()
+		<-ch
+	}
+}
+
+func (c *FakeClock) add(w *fakeWaiter, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.deadline = c.now.Add(d)
+	if d <= 0 && w.period == 0 { // 与 time 包一致，立即到期
+		if w.fn != nil {
+			go w.fn()
+		} else {
+			w.ch <- c.now
+		}
+		return
+	}
+	c.insert(w)
+	c.notify()
+}
+
+// 按 deadline 有序插入，deadline 相同时按创建顺序
+func (c *FakeClock) insert(w *fakeWaiter) {
+	i := sort.Search(len(c.waiters), func(i int) bool {
+		return c.waiters[i].deadline.After(w.deadline)
+	})
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+}
+
+func (c *FakeClock) remove(w *fakeWaiter) bool {
+	for i, x := range c.waiters {
+		if x == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+type fakeTimer fakeWaiter
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remove((*fakeWaiter)(t))
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := c.remove((*fakeWaiter)(t))
+	t.deadline = c.now.Add(d)
+	c.insert((*fakeWaiter)(t))
+	c.notify()
+	return active
+}
+
+type fakeTicker fakeWaiter
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove((*fakeWaiter)(t))
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("workerpool: non-positive interval for FakeClock.Ticker.Reset")
+	}
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove((*fakeWaiter)(t))
+	t.period = d
+	t.deadline = c.now.Add(d)
+	c.insert((*fakeWaiter)(t))
+	c.notify()
+}
@@ -0,0 +1,112 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFakeClock(start)
+	var order []string
+	c.AfterFunc(2*time.Second, func() { order = append(order, "func@2s") })
+	t1 := c.NewTimer(time.Second)
+	stopped := c.NewTimer(time.Second)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatal("Stop should report true only for an active timer")
+	}
+
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-t1.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	c.Advance(time.Millisecond)
+	if at := <-t1.C(); !at.Equal(start.Add(time.Second)) {
+		t.Fatalf("timer fired at %s", at.Sub(start))
+	}
+	c.Advance(5 * time.Second)
+	if len(order) != 1 || c.Since(start) != 6*time.Second {
+		t.Fatalf("order %v, now %s", order, c.Since(start))
+	}
+
+	if t1.Reset(time.Second) {
+		t.Fatal("Reset of a fired timer reported it active")
+	}
+	c.Advance(time.Second)
+	if at := <-t1.C(); !at.Equal(start.Add(7 * time.Second)) {
+		t.Fatalf("reset timer fired at %s", at.Sub(start))
+	}
+	select {
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFakeClock(start)
+	tk := c.NewTicker(time.Second)
+	var ticks []time.Duration
+	for range 3 {
+		c.Advance(time.Second)
+		ticks = append(ticks, (<-tk.C()).Sub(start))
+	}
+	if ticks[0] != time.Second || ticks[2] != 3*time.Second {
+		t.Fatalf("ticks %v", ticks)
+	}
+	c.Advance(5 * time.Second) // 来不及消费的 tick 与 time.Ticker 一样被丢弃
+	if len(tk.C()) != 1 {
+		t.Fatalf("%d ticks buffered", len(tk.C()))
+	}
+	<-tk.C()
+	tk.Reset(10 * time.Second)
+	c.Advance(9 * time.Second)
+	if len(tk.C()) != 0 {
+		t.Fatal("ticker fired before the reset period")
+	}
+	tk.Stop()
+	c.Advance(time.Minute)
+	if len(tk.C()) != 0 {
+		t.Fatal("stopped ticker fired")
+	}
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	woke := make(chan struct{})
+	go func() {
+		c.Sleep(time.Minute)
+		close(woke)
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	select {
+	case <-woke:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep did not return after Advance")
+	}
+	c.Set(time.Unix(0, 0)) // 不允许回拨
+	if c.Now() != time.Unix(60, 0) {
+		t.Fatalf("Set moved the clock back to %s", c.Now())
+	}
+}
+
+func TestFakeClockDrivesPool(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	p := New(1, WithClock(c), WithLogger(nil))
+	defer p.Free()
+	ran := make(chan struct{})
+	if _, err := p.ScheduleAfter(time.Hour, func() { close(ran) }); err != nil {
+		t.Fatal(err)
+	}
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("delayed task did not run after advancing the fake clock")
+	}
+}
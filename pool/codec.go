@@ -0,0 +1,87 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec 负责 Job 参数的序列化，持久化与远程队列后端只保存序列化后的字节
+// 需要 protobuf 时可以用 proto.Marshal/proto.Unmarshal 实现该接口
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	JSONCodec Codec = jsonCodec{}
+	GobCodec  Codec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// RegisterJob 以 codec 注册 jobType 类型的 Job，处理函数直接接收解码后的 T；
+// 之后可以用 EnqueueValue 提交 T，由 codec 编码为 Job.Payload
+func RegisterJob[T any](p *Pool, jobType string, codec Codec, h func(ctx context.Context, v T) error) {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	p.Register(jobType, func(ctx context.Context, payload []byte) error {
+		var v T
+		if err := codec.Unmarshal(payload, &v); err != nil {
+			return fmt.Errorf("decode %s: %w", jobType, err)
+		}
+		return h(ctx, v)
+	})
+	r := &p.jobs
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.codecs == nil {
+		r.codecs = make(map[string]Codec)
+	}
+	r.codecs[jobType] = codec
+}
+
+// EncodeJob 用 jobType 注册的 codec 将 v 编码为 Job，供需要自行投递 Job 的后端使用
+func EncodeJob(p *Pool, jobType string, v any) (Job, error) {
+	r := &p.jobs
+	r.mu.RLock()
+	codec, ok := r.codecs[jobType]
+	r.mu.RUnlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %s (no codec registered)", ErrUnknownJobType, jobType)
+	}
+	payload, err := codec.Marshal(v)
+	if err != nil {
+		return Job{}, fmt.Errorf("encode %s: %w", jobType, err)
+	}
+	return Job{Type: jobType, Payload: payload}, nil
+}
+
+// EnqueueValue 编码 v 并以 Enqueue 提交
+func EnqueueValue(p *Pool, jobType string, v any) error {
+	j, err := EncodeJob(p, jobType, v)
+	if err != nil {
+		return err
+	}
+	return p.Enqueue(j)
+}
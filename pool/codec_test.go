@@ -0,0 +1,84 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type codecJob struct {
+	Name  string
+	Count int
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for name, c := range map[string]Codec{"json": JSONCodec, "gob": GobCodec} {
+		b, err := c.Marshal(codecJob{Name: "a", Count: 3})
+		if err != nil {
+			t.Fatalf("%s: Marshal = %v", name, err)
+		}
+		var v codecJob
+		if err := c.Unmarshal(b, &v); err != nil || v != (codecJob{Name: "a", Count: 3}) {
+			t.Fatalf("%s: Unmarshal = %+v, %v", name, v, err)
+		}
+	}
+}
+
+func TestRegisterJob(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	got := make(chan codecJob, 1)
+	RegisterJob(p, "gob", GobCodec, func(ctx context.Context, v codecJob) error {
+		got <- v
+		return nil
+	})
+	if err := EnqueueValue(p, "gob", codecJob{Name: "b", Count: 7}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-got:
+		if v != (codecJob{Name: "b", Count: 7}) {
+			t.Fatalf("handler got %+v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called")
+	}
+
+	// nil 表示 JSONCodec，EncodeJob 得到的 Payload 即 JSON
+	RegisterJob(p, "json", nil, func(ctx context.Context, v codecJob) error { return nil })
+	if j, err := EncodeJob(p, "json", codecJob{Name: "c"}); err != nil || j.Type != "json" || string(j.Payload) != `{"Name":"c","Count":0}` {
+		t.Fatalf("EncodeJob = %+v, %v", j, err)
+	}
+
+	// 没有以 RegisterJob 注册的类型没有 codec
+	p.Register("raw", func(context.Context, []byte) error { return nil })
+	if _, err := EncodeJob(p, "raw", 1); !errors.Is(err, ErrUnknownJobType) {
+		t.Fatalf("EncodeJob of a raw type = %v", err)
+	}
+	if err := EnqueueValue(p, "json", func() {}); err == nil || !strings.Contains(err.Error(), "encode json") {
+		t.Fatalf("EnqueueValue of an unencodable value = %v", err)
+	}
+}
+
+func TestRegisterJobDecodeError(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	called := make(chan struct{}, 1)
+	RegisterJob(p, "typed", JSONCodec, func(ctx context.Context, v codecJob) error {
+		called <- struct{}{}
+		return nil
+	})
+	if err := p.Enqueue(Job{Type: "typed", Payload: []byte("not json")}); err != nil {
+		t.Fatal(err)
+	}
+	var s JobTypeStats
+	eventually(t, "the job to fail", func() bool {
+		s = p.JobTypes()[0]
+		return s.Failed == 1
+	})
+	if !strings.HasPrefix(s.LastError, "decode typed:") || len(called) != 0 {
+		t.Fatalf("stats %+v, handler called %v", s, len(called) != 0)
+	}
+}
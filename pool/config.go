@@ -0,0 +1,316 @@
+package workerpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config 以声明的方式描述一个 pool，便于从应用自己的配置（文件、配置中心等）构建，
+// 零值字段使用与 New 相同的默认值；无法用数据描述的设置（Clock、Queue、钩子等）通过 NewFromConfig 的 opts 追加
+// 可以嵌入服务的配置文件：JSON 中的时长写作 "30s" 这样的字符串（也接受纳秒数），
+// YAML 使用字段上的 yaml 标签（gopkg.in/yaml.v3 会将 "30s" 解析为 time.Duration），解析后调用 Validate
+type Config struct {
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`                   // 见 WithName
+	Capacity    int    `json:"capacity,omitempty" yaml:"capacity,omitempty"`           // worker 数量上限，0 表示默认的 100
+	MaxCapacity int    `json:"max_capacity,omitempty" yaml:"max_capacity,omitempty"`   // 见 WithMaxCapacity，0 表示默认的 10000，负数表示不限
+	QueueSize   int    `json:"queue_size,omitempty" yaml:"queue_size,omitempty"`       // 见 WithQueueSize
+	NonBlocking bool   `json:"non_blocking,omitempty" yaml:"non_blocking,omitempty"`   // 已满时 Schedule 立即返回 ErrPoolSaturated 而不是阻塞，即 WithBlock(false)
+	PreAlloc    bool   `json:"prealloc,omitempty" yaml:"prealloc,omitempty"`           // 见 WithPreAllocWorkers
+	LazyStart   bool   `json:"lazy_start,omitempty" yaml:"lazy_start,omitempty"`       // 见 WithLazyStart
+	DrainOnFree bool   `json:"drain_on_free,omitempty" yaml:"drain_on_free,omitempty"` // 见 WithDrainOnFree
+	FIFO        bool   `json:"fifo,omitempty" yaml:"fifo,omitempty"`                   // 见 WithFIFO
+	Priority    bool   `json:"priority,omitempty" yaml:"priority,omitempty"`           // 见 WithPriorityScheduling
+	FairSubmit  bool   `json:"fair_submit,omitempty" yaml:"fair_submit,omitempty"`     // 见 WithFairSubmit
+
+	CapacityPerCPU    int           `json:"capacity_per_cpu,omitempty" yaml:"capacity_per_cpu,omitempty"`         // Capacity 为 0 时按可用的 CPU 数（考虑容器的 CPU 配额）确定容量，见 CPUCapacity
+	MaxTasksPerWorker int           `json:"max_tasks_per_worker,omitempty" yaml:"max_tasks_per_worker,omitempty"` // 见 WithMaxTasksPerWorker
+	ReservedWorkers   int           `json:"reserved_workers,omitempty" yaml:"reserved_workers,omitempty"`         // 只供紧急任务使用的 worker 数量，见 WithReservedWorkers
+	MaxWorkerAge      time.Duration `json:"max_worker_age,omitempty" yaml:"max_worker_age,omitempty"`             // 见 WithMaxWorkerAge
+	IdleTimeout       time.Duration `json:"idle_timeout,omitempty" yaml:"idle_timeout,omitempty"`                 // 见 WithIdleTimeout
+	QueueTTL          time.Duration `json:"queue_ttl,omitempty" yaml:"queue_ttl,omitempty"`                       // 见 WithQueueTTL
+	AutoFreeAfter     time.Duration `json:"auto_free_after,omitempty" yaml:"auto_free_after,omitempty"`           // 见 WithAutoFreeAfter，不调用回调
+	VisibilityTimeout time.Duration `json:"visibility_timeout,omitempty" yaml:"visibility_timeout,omitempty"`     // 见 WithVisibilityTimeout
+	AckTimeout        time.Duration `json:"ack_timeout,omitempty" yaml:"ack_timeout,omitempty"`                   // 非 0 时 Job 须显式 Ack，见 WithExplicitAck
+	TimerTick         time.Duration `json:"timer_tick,omitempty" yaml:"timer_tick,omitempty"`                     // 见 WithTimerTick，0 表示默认的 1ms
+	WAL               string        `json:"wal,omitempty" yaml:"wal,omitempty"`                                   // 预写日志路径，见 WithWAL，空表示不启用
+	PriorityAging     time.Duration `json:"priority_aging,omitempty" yaml:"priority_aging,omitempty"`             // Priority 为 true 时的 aging，见 WithPriorityScheduling
+	PriorityWeights   map[int]int   `json:"priority_weights,omitempty" yaml:"priority_weights,omitempty"`         // Priority 为 true 时各优先级的出队权重，见 WithPriorityWeights
+
+	Quiet  bool                             `json:"quiet,omitempty" yaml:"quiet,omitempty"` // 不打印日志
+	Logger func(format string, args ...any) `json:"-" yaml:"-"`                             // 日志输出，nil 表示 fmt.Printf
+}
+
+// Options 返回与 c 等价的 Option 列表
+func (c Config) Options() []Option {
+	opts := []Option{WithBlock(!c.NonBlocking), WithPreAllocWorkers(c.PreAlloc)}
+	if c.Name != "" {
+		opts = append(opts, WithName(c.Name))
+	}
+	if c.LazyStart {
+		opts = append(opts, WithLazyStart())
+	}
+	if c.DrainOnFree {
+		opts = append(opts, WithDrainOnFree())
+	}
+	if c.FIFO {
+		opts = append(opts, WithFIFO())
+	}
+	if c.FairSubmit {
+		opts = append(opts, WithFairSubmit())
+	}
+	if c.Priority {
+		opts = append(opts, WithPriorityScheduling(c.PriorityAging))
+		if c.PriorityWeights != nil {
+			opts = append(opts, WithPriorityWeights(c.PriorityWeights))
+		}
+	}
+	if c.MaxCapacity != 0 {
+		opts = append(opts, WithMaxCapacity(c.MaxCapacity))
+	}
+	if c.QueueSize != 0 {
+		opts = append(opts, WithQueueSize(c.QueueSize))
+	}
+	if c.MaxTasksPerWorker != 0 {
+		opts = append(opts, WithMaxTasksPerWorker(c.MaxTasksPerWorker))
+	}
+	if c.ReservedWorkers != 0 {
+		opts = append(opts, WithReservedWorkers(c.ReservedWorkers))
+	}
+	if c.MaxWorkerAge != 0 {
+		opts = append(opts, WithMaxWorkerAge(c.MaxWorkerAge))
+	}
+	if c.IdleTimeout != 0 {
+		opts = append(opts, WithIdleTimeout(c.IdleTimeout))
+	}
+	if c.QueueTTL != 0 {
+		opts = append(opts, WithQueueTTL(c.QueueTTL))
+	}
+	if c.AutoFreeAfter != 0 {
+		opts = append(opts, WithAutoFreeAfter(c.AutoFreeAfter, nil))
+	}
+	if c.VisibilityTimeout != 0 {
+		opts = append(opts, WithVisibilityTimeout(c.VisibilityTimeout))
+	}
+	if c.AckTimeout != 0 {
+		opts = append(opts, WithExplicitAck(c.AckTimeout))
+	}
+	if c.TimerTick != 0 {
+		opts = append(opts, WithTimerTick(c.TimerTick))
+	}
+	if c.WAL != "" {
+		opts = append(opts, WithWAL(c.WAL))
+	}
+	switch {
+	case c.Quiet:
+		opts = append(opts, WithLogger(nil))
+	case c.Logger != nil:
+		opts = append(opts, WithLogger(c.Logger))
+	}
+	return opts
+}
+
+// NewFromConfig 按 cfg 创建 pool，opts 在 cfg 之后应用；与 NewE 一样，不合理的配置返回错误
+func NewFromConfig(cfg Config, opts ...Option) (*Pool, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	capacity := cfg.Capacity
+	switch {
+	case capacity == 0 && cfg.CapacityPerCPU > 0:
+		capacity = CPUCapacity(cfg.CapacityPerCPU)
+	case capacity == 0:
+		capacity = defaultCapacity
+	}
+	return NewE(capacity, append(cfg.Options(), opts...)...)
+}
+
+// ConfigSnapshot 返回 p 实际生效的配置（纠正与默认值之后），可以序列化后记录，或交给 NewFromConfig 在别处重建同样的 pool；
+// ApplyOptions 与 Resize 之后反映替换后的 pool，无法用数据描述的设置（Clock、Queue、钩子等）不包含在内
+func (p *Pool) ConfigSnapshot() Config {
+	p = p.applied()
+	c := Config{
+		Name:              p.name,
+		Capacity:          p.capacity,
+		MaxCapacity:       p.maxCapacity,
+		QueueSize:         p.queueSize,
+		NonBlocking:       !p.block,
+		PreAlloc:          p.preAlloc,
+		LazyStart:         p.lazy,
+		DrainOnFree:       p.drainFree,
+		FIFO:              p.fifo,
+		Priority:          p.priority,
+		FairSubmit:        p.fair != nil,
+		MaxTasksPerWorker: p.maxWorkerTasks,
+		ReservedWorkers:   cap(p.reserved),
+		MaxWorkerAge:      p.maxWorkerAge,
+		IdleTimeout:       p.idleTimeout,
+		QueueTTL:          p.queueTTL,
+		TimerTick:         p.timers.tick,
+		Quiet:             p.logger == nil && p.slog == nil,
+		Logger:            p.logger,
+	}
+	if c.MaxCapacity == 0 {
+		c.MaxCapacity = -1 // Config 中 0 表示默认值
+	}
+	if p.autoFree != nil {
+		c.AutoFreeAfter = p.autoFree.after
+	}
+	if p.explicitAck {
+		c.AckTimeout = p.visibility
+	} else {
+		c.VisibilityTimeout = p.visibility
+	}
+	if p.wal != nil {
+		c.WAL = p.wal.path
+	}
+	if p.priority {
+		c.PriorityAging = p.aging
+		if p.weights != nil {
+			c.PriorityWeights = make(map[int]int, len(p.weights))
+			for prio, w := range p.weights {
+				c.PriorityWeights[prio] = w
+			}
+		}
+	}
+	return c
+}
+
+// Validate 检查 c 中的取值，错误（包装 ErrInvalidOption）以 JSON 字段名指明出错的字段
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s: "+format, append([]any{ErrInvalidOption, field}, args...)...))
+	}
+	limit := defaultMaxCapacity
+	if c.MaxCapacity != 0 {
+		limit = max(c.MaxCapacity, 0)
+	}
+	switch {
+	case c.Capacity < 0:
+		fail("capacity", "%d is negative", c.Capacity)
+	case limit > 0 && c.Capacity > limit:
+		fail("capacity", "%d exceeds max_capacity %d", c.Capacity, limit)
+	}
+	if c.CapacityPerCPU < 0 {
+		fail("capacity_per_cpu", "%d is negative", c.CapacityPerCPU)
+	}
+	if c.QueueSize < 0 {
+		fail("queue_size", "%d is negative", c.QueueSize)
+	}
+	if c.MaxTasksPerWorker < 0 {
+		fail("max_tasks_per_worker", "%d is negative", c.MaxTasksPerWorker)
+	}
+	if c.ReservedWorkers < 0 {
+		fail("reserved_workers", "%d is negative", c.ReservedWorkers)
+	}
+	for prio, w := range c.PriorityWeights {
+		if w <= 0 {
+			fail("priority_weights", "weight %d for priority %d is not positive", w, prio)
+		}
+	}
+	for _, d := range c.durations() {
+		if *d.v < 0 {
+			fail(d.field, "%s is negative", *d.v)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type configDuration struct {
+	field string
+	v     *time.Duration
+}
+
+func (c *Config) durations() []configDuration {
+	return []configDuration{
+		{"max_worker_age", &c.MaxWorkerAge},
+		{"idle_timeout", &c.IdleTimeout},
+		{"queue_ttl", &c.QueueTTL},
+		{"auto_free_after", &c.AutoFreeAfter},
+		{"visibility_timeout", &c.VisibilityTimeout},
+		{"ack_timeout", &c.AckTimeout},
+		{"timer_tick", &c.TimerTick},
+		{"priority_aging", &c.PriorityAging},
+	}
+}
+
+func (d configDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.v.String())
+}
+
+// UnmarshalJSON 接受 "1m30s" 形式的字符串或纳秒数
+func (d configDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("%w: %s: want a duration like \"30s\", got %s", ErrInvalidOption, d.field, b)
+		}
+		*d.v = time.Duration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidOption, d.field, err)
+	}
+	*d.v = v
+	return nil
+}
+
+// configJSON 是 Config 的 JSON 形式，时长字段覆盖 Config 中同名的字段
+type configJSON struct {
+	*configFields
+	MaxWorkerAge      *configDuration `json:"max_worker_age,omitempty"`
+	IdleTimeout       *configDuration `json:"idle_timeout,omitempty"`
+	QueueTTL          *configDuration `json:"queue_ttl,omitempty"`
+	AutoFreeAfter     *configDuration `json:"auto_free_after,omitempty"`
+	VisibilityTimeout *configDuration `json:"visibility_timeout,omitempty"`
+	AckTimeout        *configDuration `json:"ack_timeout,omitempty"`
+	TimerTick         *configDuration `json:"timer_tick,omitempty"`
+	PriorityAging     *configDuration `json:"priority_aging,omitempty"`
+}
+
+type configFields Config // 没有 MarshalJSON/UnmarshalJSON 方法，避免递归
+
+// toJSON 返回 c 的 JSON 形式，omitZero 为 true 时省略值为 0 的时长
+func (c *Config) toJSON(omitZero bool) configJSON {
+	j := configJSON{configFields: (*configFields)(c)}
+	ptrs := []**configDuration{&j.MaxWorkerAge, &j.IdleTimeout, &j.QueueTTL, &j.AutoFreeAfter, &j.VisibilityTimeout, &j.AckTimeout, &j.TimerTick, &j.PriorityAging}
+	for i, d := range c.durations() {
+		if !omitZero || *d.v != 0 {
+			*ptrs[i] = &d
+		}
+	}
+	return j
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toJSON(true))
+}
+
+// UnmarshalJSON 只覆盖 b 中出现的字段，其它字段保持原值
+func (c *Config) UnmarshalJSON(b []byte) error {
+	j := c.toJSON(false)
+	return json.Unmarshal(b, &j)
+}
+
+// ParseConfig 解析 JSON 形式的 Config 并调用 Validate，未知的字段视为错误（通常是拼写错误）
+func ParseConfig(b []byte) (Config, error) {
+	var c Config
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	j := c.toJSON(false)
+	if err := dec.Decode(&j); err != nil {
+		if !errors.Is(err, ErrInvalidOption) {
+			err = fmt.Errorf("%w: %w", ErrInvalidOption, err)
+		}
+		return Config{}, err
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
@@ -0,0 +1,97 @@
+package workerpool
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigSnapshot(t *testing.T) {
+	p := New(20000, WithLogger(nil), WithName("snap"), WithQueueSize(4), WithBlock(false),
+		WithPriorityScheduling(time.Second), WithPriorityWeights(map[int]int{1: 3}),
+		WithIdleTimeout(time.Minute), WithReservedWorkers(2), WithExplicitAck(5*time.Second))
+	defer p.Free()
+	c := p.ConfigSnapshot()
+	if c.Capacity != defaultMaxCapacity || c.MaxCapacity != defaultMaxCapacity {
+		t.Fatalf("capacity %d max %d, want clamped to %d", c.Capacity, c.MaxCapacity, defaultMaxCapacity)
+	}
+	if !c.Quiet || !c.NonBlocking || c.TimerTick != defaultTimerTick || c.AckTimeout != 5*time.Second || c.ReservedWorkers != 2 {
+		t.Fatalf("unexpected snapshot %+v", c)
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseConfig(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewFromConfig(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Free()
+	got := q.ConfigSnapshot()
+	got.Logger, c.Logger = nil, nil
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("round trip:\n got %+v\nwant %+v", got, c)
+	}
+
+	if err := p.Resize(8); err != nil {
+		t.Fatal(err)
+	}
+	if c := p.ConfigSnapshot(); c.Capacity != 8 || c.QueueSize != 4 {
+		t.Fatalf("after Resize: capacity %d queue %d", c.Capacity, c.QueueSize)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte(`{"name":"api","capacity":8,"idle_timeout":"1m30s","queue_ttl":2000000000,"priority_weights":{"1":3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "api" || c.Capacity != 8 || c.IdleTimeout != 90*time.Second || c.QueueTTL != 2*time.Second || c.PriorityWeights[1] != 3 {
+		t.Fatalf("ParseConfig = %+v", c)
+	}
+
+	for _, tc := range []struct {
+		in, field string
+	}{
+		{`{"capacity":8,"idel_timeout":"1s"}`, "idel_timeout"}, // 未知的字段
+		{`{"idle_timeout":"soon"}`, "idle_timeout"},
+		{`{"idle_timeout":true}`, "idle_timeout"},
+		{`{"capacity":-1}`, "capacity"},
+		{`{"capacity":20,"max_capacity":10}`, "capacity"},
+		{`{"queue_ttl":"-1s"}`, "queue_ttl"},
+		{`{"priority_weights":{"1":0}}`, "priority_weights"},
+	} {
+		_, err := ParseConfig([]byte(tc.in))
+		if !errors.Is(err, ErrInvalidOption) || !strings.Contains(err.Error(), tc.field) {
+			t.Errorf("ParseConfig(%s) = %v, want an error naming %s", tc.in, err, tc.field)
+		}
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	b, err := json.Marshal(Config{Capacity: 4, IdleTimeout: 30 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 时长写作字符串，值为 0 的时长省略
+	if got := string(b); got != `{"capacity":4,"idle_timeout":"30s"}` {
+		t.Fatalf("Marshal = %s", got)
+	}
+
+	// UnmarshalJSON 只覆盖出现的字段
+	c := Config{Name: "keep", Capacity: 4, QueueTTL: time.Second}
+	if err := json.Unmarshal([]byte(`{"capacity":6,"idle_timeout":"5s"}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "keep" || c.Capacity != 6 || c.IdleTimeout != 5*time.Second || c.QueueTTL != time.Second {
+		t.Fatalf("Unmarshal = %+v", c)
+	}
+}
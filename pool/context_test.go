@@ -0,0 +1,110 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+func TestWithContext(t *testing.T) {
+	base, cancel := context.WithCancelCause(context.WithValue(context.Background(), ctxKey("env"), "prod"))
+	p := New(2, WithLogger(nil), WithContext(base))
+	defer p.Free()
+	got := make(chan any, 1)
+	p.ScheduleFunc(func(ctx context.Context) { got <- ctx.Value(ctxKey("env")) })
+	if v := <-got; v != "prod" {
+		t.Fatalf("task saw %v, want the value of the base ctx", v)
+	}
+
+	// 基础 ctx 取消时所有任务的 ctx 随之取消
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	p.ScheduleFunc(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+	})
+	<-started
+	stop := errors.New("shutdown")
+	cancel(stop)
+	select {
+	case err := <-causes:
+		if !errors.Is(err, stop) {
+			t.Fatalf("task ctx canceled with %v, want %v", err, stop)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("task ctx not canceled with the base ctx")
+	}
+	if !errors.Is(context.Cause(p.Context()), stop) {
+		t.Fatalf("Context() cause = %v", context.Cause(p.Context()))
+	}
+}
+
+func TestWithContextFree(t *testing.T) {
+	p := New(1, WithLogger(nil), WithContext(context.Background()))
+	ctx := p.Context()
+	p.Free() // Free 取消基础 ctx，父 ctx 不受影响
+	if !errors.Is(context.Cause(ctx), ErrPoolClosed) {
+		t.Fatalf("Context() cause after Free = %v", context.Cause(ctx))
+	}
+	if _, err := NewE(1, WithContext(nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("WithContext(nil) = %v, want ErrInvalidOption", err)
+	}
+}
+
+func TestContextValues(t *testing.T) {
+	p := New(1, WithLogger(nil), WithContextValues(ctxKey("request"), ctxKey("tenant")))
+	defer p.Free()
+	ctx := context.WithValue(context.Background(), ctxKey("request"), "r-1")
+	ctx = context.WithValue(ctx, ctxKey("secret"), "s")
+	got := make(chan [3]any, 1)
+	p.ScheduleCtx(ctx, func(ctx context.Context) {
+		got <- [3]any{ctx.Value(ctxKey("request")), ctx.Value(ctxKey("tenant")), ctx.Value(ctxKey("secret"))}
+	})
+	// 只传递列出的 key，提交方 ctx 中没有的 key 不设置
+	if v := <-got; v != [3]any{"r-1", nil, nil} {
+		t.Fatalf("task saw %v", v)
+	}
+	if _, ok := WorkerID(ctx); ok {
+		t.Fatal("submitter ctx carries worker info")
+	}
+}
+
+func TestContextPropagator(t *testing.T) {
+	var order []string
+	p := New(1, WithLogger(nil),
+		WithContextPropagator(func(from, to context.Context) context.Context {
+			order = append(order, "first")
+			return context.WithValue(to, ctxKey("trace"), from.Value(ctxKey("trace")))
+		}),
+		WithContextPropagator(func(from, to context.Context) context.Context {
+			order = append(order, "second")
+			return context.WithValue(to, ctxKey("seen"), to.Value(ctxKey("trace"))) // 按顺序执行，能看到前一个的结果
+		}))
+	defer p.Free()
+	got := make(chan [2]any, 1)
+	p.ScheduleCtx(context.WithValue(context.Background(), ctxKey("trace"), "t-1"), func(ctx context.Context) {
+		if _, ok := WorkerID(ctx); !ok {
+			t.Error("propagated ctx lost the worker info")
+		}
+		got <- [2]any{ctx.Value(ctxKey("trace")), ctx.Value(ctxKey("seen"))}
+	})
+	if v := <-got; v != [2]any{"t-1", "t-1"} {
+		t.Fatalf("task saw %v", v)
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Fatalf("propagators ran in order %v", order)
+	}
+
+	// 不经 ScheduleCtx 提交的任务没有提交方 ctx，不调用传递函数
+	order = nil
+	done := make(chan struct{})
+	p.ScheduleFunc(func(context.Context) { close(done) })
+	<-done
+	if len(order) != 0 {
+		t.Fatalf("propagators ran for ScheduleFunc: %v", order)
+	}
+}
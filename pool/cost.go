@@ -0,0 +1,59 @@
+package workerpool
+
+import (
+	"context"
+	"time"
+)
+
+// costUnit 是 WithShortestJobFirst 按成本排序时一个成本单位对应的预计时长
+const costUnit = time.Millisecond
+
+func WithCostEstimator(estimate func(TaskInfo) int) Option { // ScheduleWith 提交任务时由 estimate 估计其成本（如按负载大小），记入 TaskInfo.Cost：成本 n 大于 1 的任务与 ScheduleWeighted 一样占用 n 个 worker（不超过容量）；WithShortestJobFirst 未指定估计函数时，没有 WithExpectedDuration 提示的任务按成本排序（一个单位计为 1ms）；WithCost 给出的成本优先
+	return func(p *Pool) {
+		p.costEstimate = estimate
+	}
+}
+
+func WithCost(n int) TaskOption { // 任务的成本，取代 WithCostEstimator 的估计，见 TaskInfo.Cost
+	return func(t *task) {
+		t.info.Cost = n
+	}
+}
+
+// estimateCost 在选项应用之后、提交之前确定 info.Cost
+func (p *Pool) estimateCost(info *TaskInfo) {
+	if info.Cost == 0 && p.costEstimate != nil {
+		info.Cost = p.costEstimate(*info)
+	}
+	info.Cost = max(info.Cost, 0)
+}
+
+// scheduleCosted 提交成本大于 1 的 tk：先占住另外 Cost-1 个 worker（不超过容量），tk 执行完或被丢弃时释放
+func (p *Pool) scheduleCosted(tk task) error {
+	p = p.applied()
+	weight := min(tk.info.Cost, p.capacity)
+	if weight <= 1 {
+		return p.schedule(tk)
+	}
+	release, err := p.acquireWeight(weight)
+	if err != nil {
+		return err
+	}
+	fn := tk.fnc
+	tk.fnc = func(ctx context.Context) {
+		defer release()
+		fn(ctx)
+	}
+	prev := tk.claim.onDiscard
+	tk.claim.onDiscard = func(reason error) {
+		release()
+		if prev != nil {
+			prev(reason)
+		}
+	}
+	if err := p.schedule(tk); err != nil {
+		release()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCostEstimator(t *testing.T) {
+	estimate := func(info TaskInfo) int { return int(info.Size / 100) }
+	p := New(4, WithLogger(nil), WithBlock(false), WithCostEstimator(estimate))
+	defer p.Free()
+	started, gate := make(chan TaskInfo), make(chan struct{})
+	defer close(gate)
+	if err := p.ScheduleWith(func(ctx context.Context) {
+		info, _ := TaskInfoFrom(ctx)
+		started <- info
+		<-gate
+	}, WithPayloadSize(300)); err != nil {
+		t.Fatal(err)
+	}
+	if info := <-started; info.Cost != 3 {
+		t.Fatalf("cost = %d, want 3", info.Cost)
+	}
+	// 成本为 3 的任务占住 3 个 worker，只剩 1 个
+	if err := p.ScheduleWith(func(context.Context) {}, WithCost(2)); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("cost 2 with one free worker: %v", err)
+	}
+
+	q := New(2, WithLogger(nil), WithCostEstimator(estimate))
+	defer q.Free()
+	ran := make(chan struct{})
+	if err := q.ScheduleWith(func(context.Context) { close(ran) }, WithCost(9)); err != nil { // 超过容量时按容量占用
+		t.Fatal(err)
+	}
+	<-ran
+}
+
+func TestCostShortestJobFirst(t *testing.T) {
+	p, release := newBusyPool(t, WithShortestJobFirst(nil), WithQueueSize(10),
+		WithCostEstimator(func(info TaskInfo) int { return int(info.Size) }))
+	defer p.Free()
+	var r recorder
+	for _, size := range []int{30, 10, 20} {
+		run := r.task(size)
+		if err := p.ScheduleWith(func(context.Context) { run() }, WithPayloadSize(int64(size))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release()
+	p.Wait()
+	want := []int{10, 20, 30}
+	for i := range want {
+		if r.got[i] != want[i] {
+			t.Fatalf("ran %v, want %v", r.got, want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy 决定周期任务的上一次执行尚未结束（排队中或执行中）时如何处理新的一次
+type OverlapPolicy int
+
+const (
+	OverlapSkip    OverlapPolicy = iota // 跳过本次
+	OverlapQueue                        // 等上一次结束后再执行本次
+	OverlapReplace                      // 通过 ctx 取消上一次，并执行本次；上一次尚未开始则直接丢弃
+)
+
+// Cron 按 cron 表达式周期性地向 pool 投递 t，返回的句柄可用于停止后续触发
+//
+// 表达式为标准 5 段格式：分 时 日 月 周，每段支持 *、数字、a-b、列表 a,b 及步长 /n，
+// 月份与星期可使用英文缩写（JAN、MON）；也支持 @yearly、@monthly、@weekly、@daily、@hourly，
+// 以及 "CRON_TZ=Asia/Shanghai " 前缀指定时区，默认使用本地时区
+func (p *Pool) Cron(spec string, t TaskFunc, policy OverlapPolicy) (TimerHandle, error) {
+	p = p.applied()
+	sched, err := parseCron(spec)
+	if err != nil {
+		return TimerHandle{}, err
+	}
+	now := p.clock.Now()
+	first, ok := sched.next(now)
+	if !ok {
+		return TimerHandle{}, fmt.Errorf("workerpool: cron spec %q never fires", spec)
+	}
+	job := &recurringJob{p: p, fn: t, policy: policy}
+	return p.addTimer(&timerEntry{p: p, kind: PendingCron, nextAt: sched.next, occur: job.occur}, first.Sub(now))
+}
+
+// 周期任务的单次执行管理，按 OverlapPolicy 处理重叠，Cron 与 ScheduleEvery 共用
+type recurringJob struct {
+	p      *Pool
+	fn     TaskFunc
+	policy OverlapPolicy
+
+	mu      sync.Mutex
+	active  int                // 已投递但尚未结束的次数
+	pending int                // OverlapQueue 下等待上一次结束的次数
+	gen     uint64             // 每投递一次加一，OverlapReplace 下用于识别被替换的执行
+	cancel  context.CancelFunc // 正在执行的那一次的取消函数
+}
+
+func (j *recurringJob) occur() (task, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.active > 0 {
+		switch j.policy {
+		case OverlapSkip:
+			return task{}, false
+		case OverlapQueue:
+			j.pending++
+			return task{}, false
+		case OverlapReplace:
+			if j.cancel != nil {
+				j.cancel()
+			}
+		}
+	}
+	return j.newRun(), true
+}
+
+// 需持有 j.mu
+func (j *recurringJob) newRun() task {
+	j.active++
+	j.gen++
+	gen := j.gen
+	return task{fnc: func(ctx context.Context) { j.exec(ctx, gen) }}
+}
+
+func (j *recurringJob) exec(ctx context.Context, gen uint64) {
+	j.mu.Lock()
+	if j.policy == OverlapReplace && gen != j.gen { // 尚未开始就已被替换
+		j.active--
+		j.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	j.cancel = cancel
+	j.mu.Unlock()
+
+	defer func() {
+		cancel()
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.active--
+		if gen == j.gen {
+			j.cancel = nil
+		}
+		if j.pending > 0 && j.active == 0 {
+			j.pending--
+			// 在 worker 中投递，p.wg 计数大于 0，dispatchAsync 中的 Add 是安全的
+			j.p.dispatchAsync(j.newRun())
+		}
+	}()
+	j.fn(ctx)
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64 // 各字段允许取值的位图
+	domStar, dowStar              bool   // 日、周字段是否为 *，用于判断两者是“与”还是“或”
+	loc                           *time.Location
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{0, 59, nil}
+	cronHour   = cronField{0, 23, nil}
+	cronDom    = cronField{1, 31, nil}
+	cronMonth  = cronField{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	s := &cronSchedule{loc: time.Local}
+	expr := strings.TrimSpace(spec)
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		i := strings.IndexByte(expr, ' ')
+		if i < 0 {
+			return nil, fmt.Errorf("workerpool: invalid cron spec %q: missing fields", spec)
+		}
+		loc, err := time.LoadLocation(expr[strings.IndexByte(expr, '=')+1 : i])
+		if err != nil {
+			return nil, fmt.Errorf("workerpool: invalid cron spec %q: %w", spec, err)
+		}
+		s.loc = loc
+		expr = strings.TrimSpace(expr[i:])
+	}
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("workerpool: invalid cron spec %q: expected 5 fields, got %d", spec, len(fields))
+	}
+	var err error
+	parse := func(i int, f cronField) uint64 {
+		if err != nil {
+			return 0
+		}
+		var bits uint64
+		bits, err = f.parse(fields[i])
+		if err != nil {
+			err = fmt.Errorf("workerpool: invalid cron spec %q: field %d: %w", spec, i+1, err)
+		}
+		return bits
+	}
+	s.minute = parse(0, cronMinute)
+	s.hour = parse(1, cronHour)
+	s.dom = parse(2, cronDom)
+	s.month = parse(3, cronMonth)
+	s.dow = parse(4, cronDow)
+	if err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 { // 7 与 0 都表示周日
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rng := part
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step, rng = n, part[:i]
+		}
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 { // 单个值；带步长时 "a/n" 表示从 a 开始到最大值
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %q out of range [%d, %d]", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// next 返回 now 之后（不含）的第一个触发时间，5 年内找不到时返回 false
+func (s *cronSchedule) next(now time.Time) (time.Time, bool) {
+	t := now.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,139 @@
+package workerpool
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	utc := func(s string) time.Time {
+		at, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+	cases := []struct {
+		spec, from string
+		want       []string
+	}{
+		{"CRON_TZ=UTC */15 * * * *", "2024-03-01 10:07", []string{"2024-03-01 10:15", "2024-03-01 10:30", "2024-03-01 10:45", "2024-03-01 11:00"}},
+		{"CRON_TZ=UTC 30 9 * * MON-FRI", "2024-03-01 10:00", []string{"2024-03-04 09:30", "2024-03-05 09:30"}}, // 03-01 是周五
+		{"CRON_TZ=UTC 0 0 1,15 * *", "2024-02-10 00:00", []string{"2024-02-15 00:00", "2024-03-01 00:00"}},
+		{"CRON_TZ=UTC 0 12 13 * 5", "2024-09-01 00:00", []string{"2024-09-06 12:00", "2024-09-13 12:00"}}, // 日与周都受限时取“或”
+		{"CRON_TZ=UTC 0 0 29 feb *", "2024-03-01 00:00", []string{"2028-02-29 00:00"}},
+		{"CRON_TZ=UTC @hourly", "2024-03-01 10:00", []string{"2024-03-01 11:00"}},
+		{"CRON_TZ=UTC 0 0 * * 7", "2024-03-01 00:00", []string{"2024-03-03 00:00"}}, // 7 也表示周日
+	}
+	for _, c := range cases {
+		s, err := parseCron(c.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		at := utc(c.from)
+		for _, want := range c.want {
+			var ok bool
+			if at, ok = s.next(at); !ok || !at.Equal(utc(want)) {
+				t.Fatalf("%s: next = %s, %v, want %s", c.spec, at, ok, want)
+			}
+		}
+	}
+}
+
+func TestCronInvalidSpec(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	for spec, want := range map[string]string{
+		"* * * *":             "expected 5 fields",
+		"60 * * * *":          "out of range",
+		"* * * * MONDAY":      "out of range",
+		"5-1 * * * *":         "invalid range",
+		"*/0 * * * *":         "invalid step",
+		"CRON_TZ=Nowhere/X *": "invalid cron spec",
+		"0 0 30 2 *":          "never fires",
+	} {
+		if _, err := p.Cron(spec, func(context.Context) {}, OverlapSkip); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Cron(%q) = %v, want %q", spec, err, want)
+		}
+	}
+}
+
+func TestCronFires(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 3, 1, 10, 0, 30, 0, time.UTC))
+	p := New(1, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	fired := make(chan time.Time, 4)
+	h, err := p.Cron("CRON_TZ=UTC * * * * *", func(context.Context) { fired <- clock.Now() }, OverlapSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		if at := <-fired; at.Minute() != i {
+			t.Fatalf("fired at %s", at)
+		}
+	}
+	p.ScheduleAfter(24*time.Hour, func() {}) // 使时间轮在 Stop 之后仍有待触发的任务
+	h.Stop()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	clock.BlockUntil(1) // 时间轮已处理到期的槽并重新等待
+	if len(fired) != 0 {
+		t.Fatal("cron fired after Stop")
+	}
+}
+
+func TestCronOverlap(t *testing.T) {
+	for _, policy := range []OverlapPolicy{OverlapSkip, OverlapQueue, OverlapReplace} {
+		clock := NewFakeClock(time.Date(2024, 3, 1, 10, 0, 30, 0, time.UTC))
+		p := New(2, WithClock(clock), WithLogger(nil))
+		runs := make(chan int, 4)
+		canceled := make(chan int, 4)
+		release := make(chan struct{})
+		n := 0
+		p.Cron("CRON_TZ=UTC * * * * *", func(ctx context.Context) {
+			n++
+			run := n
+			runs <- run
+			select {
+			case <-release:
+			case <-ctx.Done():
+				canceled <- run
+			}
+		}, policy)
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		<-runs
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute) // 第一次仍在执行
+		clock.BlockUntil(1)        // 时间轮已处理第二次触发
+		switch policy {
+		case OverlapSkip:
+			if len(runs) != 0 {
+				t.Fatal("OverlapSkip ran an overlapping occurrence")
+			}
+		case OverlapReplace:
+			if run := <-canceled; run != 1 {
+				t.Fatalf("OverlapReplace canceled run %d", run)
+			}
+			if run := <-runs; run != 2 {
+				t.Fatalf("OverlapReplace started run %d", run)
+			}
+		case OverlapQueue:
+			if len(runs) != 0 {
+				t.Fatal("OverlapQueue started the next run before the previous one ended")
+			}
+			close(release)
+			if run := <-runs; run != 2 {
+				t.Fatalf("OverlapQueue started run %d", run)
+			}
+		}
+		if policy != OverlapQueue {
+			close(release)
+		}
+		p.Free()
+	}
+}
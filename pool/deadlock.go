@@ -0,0 +1,152 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var ErrDeadlock = errors.New("pool wait cycle deadlock")
+
+// DeadlockError 描述一个等待环：环中每个 pool 的 worker 都已用满，且全部阻塞在向环中 pool 的提交上
+type DeadlockError struct {
+	Pools  []uint64 // 环中 pool 的 ID
+	Stacks []string // 环中所有阻塞 worker 的调用栈
+}
+
+func (e *DeadlockError) Error() string {
+	ids := make([]string, len(e.Pools))
+	for i, id := range e.Pools {
+		ids[i] = fmt.Sprint(id)
+	}
+	return fmt.Sprintf("%s: pools [%s]", ErrDeadlock, strings.Join(ids, " "))
+}
+
+func (e *DeadlockError) Is(target error) bool {
+	return target == ErrDeadlock
+}
+
+// waitGraphState 是全局的等待关系：开启检测的 pool 的 worker 阻塞在某个 pool 的提交上
+type waitGraphState struct {
+	mu    sync.Mutex
+	pools map[*Pool]struct{}  // 开启检测的 pool
+	waits map[int64]*poolWait // 按 goroutine id
+}
+
+var waitGraph waitGraphState
+
+type poolWait struct {
+	from, to *Pool
+	stack    string
+}
+
+func (p *Pool) enableDeadlockDetection() {
+	g := &waitGraph
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pools == nil {
+		g.pools = make(map[*Pool]struct{})
+		g.waits = make(map[int64]*poolWait)
+	}
+	g.pools[p] = struct{}{}
+}
+
+func (p *Pool) disableDeadlockDetection() {
+	g := &waitGraph
+	g.mu.Lock()
+	delete(g.pools, p)
+	g.mu.Unlock()
+}
+
+// enterWait 在当前 goroutine 即将阻塞在向 p 的提交上时调用；当前 goroutine 是开启检测的 pool 的 worker 时
+// 登记等待关系，若由此形成等待环则返回 *DeadlockError，否则返回撤销登记的函数
+func (p *Pool) enterWait() (func(), error) {
+	g := &waitGraph
+	g.mu.Lock()
+	if len(g.pools) == 0 {
+		g.mu.Unlock()
+		return func() {}, nil
+	}
+	gid := goid()
+	var from *Pool
+	for q := range g.pools {
+		if q.workers.get(gid) != nil {
+			from = q
+			break
+		}
+	}
+	if from == nil {
+		g.mu.Unlock()
+		return func() {}, nil
+	}
+	buf := make([]byte, 8<<10)
+	g.waits[gid] = &poolWait{from: from, to: p, stack: string(buf[:runtime.Stack(buf, false)])}
+	cycle := g.deadlocked(from)
+	if cycle != nil {
+		delete(g.waits, gid)
+	}
+	g.mu.Unlock()
+	if cycle != nil {
+		from.reportDeadlock(cycle)
+		return nil, cycle
+	}
+	return func() {
+		g.mu.Lock()
+		delete(g.waits, gid)
+		g.mu.Unlock()
+	}, nil
+}
+
+// deadlocked 判断 from 是否处于等待环中：先取所有 worker 都已用满且全部阻塞的 pool，
+// 再反复剔除有 worker 在等待集合外 pool 的成员（集合外的 pool 仍能推进），剩下的集合中的 pool 互相等待、无法推进
+// 需持有 g.mu
+func (g *waitGraphState) deadlocked(from *Pool) *DeadlockError {
+	blocked := make(map[*Pool][]*poolWait)
+	for _, w := range g.waits {
+		blocked[w.from] = append(blocked[w.from], w)
+	}
+	stuck := make(map[*Pool]bool)
+	for q, ws := range blocked {
+		q.workers.mu.Lock()
+		n := len(q.workers.m)
+		q.workers.mu.Unlock()
+		if n == q.capacity && len(ws) == n {
+			stuck[q] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for q := range stuck {
+			for _, w := range blocked[q] {
+				if !stuck[w.to] {
+					delete(stuck, q)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	if !stuck[from] {
+		return nil
+	}
+	e := &DeadlockError{}
+	for q := range stuck {
+		e.Pools = append(e.Pools, q.id)
+		for _, w := range blocked[q] {
+			e.Stacks = append(e.Stacks, w.stack)
+		}
+	}
+	slices.Sort(e.Pools)
+	return e
+}
+
+func (p *Pool) reportDeadlock(e *DeadlockError) {
+	if p.onDeadlock != nil {
+		p.onDeadlock(e)
+		return
+	}
+	p.logf("workerpool: %s\n%s\n", e, strings.Join(e.Stacks, "\n"))
+}
@@ -0,0 +1,91 @@
+package workerpool
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeadlockSelf(t *testing.T) {
+	reports := make(chan *DeadlockError, 1)
+	p := New(1, WithLogger(nil), WithDeadlockDetection(func(e *DeadlockError) { reports <- e }))
+	defer p.Free()
+	errc := make(chan error, 1)
+	// 唯一的 worker 阻塞在向自身的提交上，永远等不到空闲的 worker
+	p.Schedule(func() { errc <- p.Schedule(func() {}) })
+	var err error
+	select {
+	case err = <-errc:
+	case <-time.After(5 * time.Second):
+		t.Fatal("self wait was not detected")
+	}
+	var de *DeadlockError
+	if !errors.Is(err, ErrDeadlock) || !errors.As(err, &de) || !slices.Equal(de.Pools, []uint64{p.ID()}) || len(de.Stacks) != 1 {
+		t.Fatalf("Schedule = %v", err)
+	}
+	if e := <-reports; e != de {
+		t.Fatalf("reported %v, returned %v", e, de)
+	}
+}
+
+func TestDeadlockCycle(t *testing.T) {
+	a := New(1, WithLogger(nil), WithDeadlockDetection(func(*DeadlockError) {}))
+	defer a.Free()
+	b := New(1, WithLogger(nil), WithDeadlockDetection(func(*DeadlockError) {}))
+	defer b.Free()
+	errc := make(chan error, 1)
+	back := make(chan error, 1)
+	a.Schedule(func() {
+		// b 的 worker 等待 a，此时 a 的 worker 仍在推进，不构成环
+		b.Schedule(func() { back <- a.Schedule(func() {}) })
+		for a.Stats().Blocked == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		errc <- b.Schedule(func() {}) // a 等待 b、b 等待 a
+	})
+	var de *DeadlockError
+	select {
+	case err := <-errc:
+		if !errors.As(err, &de) || !slices.Equal(de.Pools, sorted(a.ID(), b.ID())) || len(de.Stacks) != 2 {
+			t.Fatalf("Schedule = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cycle was not detected")
+	}
+	// 返回错误的一方结束后，另一方的提交得以完成
+	select {
+	case err := <-back:
+		if err != nil {
+			t.Fatalf("b's submission to a = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("b's submission to a never finished")
+	}
+}
+
+func TestDeadlockNoCycle(t *testing.T) {
+	a := New(1, WithLogger(nil), WithDeadlockDetection(nil))
+	defer a.Free()
+	b := New(1, WithLogger(nil), WithDeadlockDetection(nil))
+	defer b.Free()
+	release := make(chan struct{})
+	b.Schedule(func() { <-release }) // b 的 worker 忙碌但没有等待任何 pool
+	errc := make(chan error, 1)
+	a.Schedule(func() { errc <- b.Schedule(func() {}) })
+	eventually(t, "a's worker to block on b", func() bool { return b.Stats().Blocked == 1 })
+	close(release)
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Schedule = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("submission to b never finished")
+	}
+}
+
+func sorted(ids ...uint64) []uint64 {
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"sync"
+	"time"
+)
+
+type debounceSet struct {
+	mu      sync.Mutex
+	pending map[string]*debounceEntry
+}
+
+type debounceEntry struct {
+	h     TimerHandle
+	first time.Time // 本轮合并中第一次提交的时间
+}
+
+// Debounce 按 key 合并快速重复提交的任务：每次提交都会把执行推迟到 window 之后，
+// 窗口内只有最后一次提交的 t 会被执行，之前尚未执行的会被丢弃
+// maxWait > 0 时，一轮合并从第一次提交起最多推迟 maxWait，避免持续提交导致任务永远不执行；
+// 已经到期投递的任务不受之后提交的影响，之后的提交开始新一轮合并
+func (p *Pool) Debounce(key string, window, maxWait time.Duration, t Task) error {
+	p = p.applied()
+	d := &p.debounced
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := p.clock.Now()
+	first := now
+	if e, ok := d.pending[key]; ok && e.h.Stop() {
+		first = e.first
+	}
+	delay := window
+	if maxWait > 0 {
+		if left := first.Add(maxWait).Sub(now); left < delay {
+			delay = left
+		}
+	}
+	e := &debounceEntry{first: first}
+	var err error
+	e.h, err = p.ScheduleAfter(delay, func() {
+		d.mu.Lock()
+		if d.pending[key] == e {
+			delete(d.pending, key)
+		}
+		d.mu.Unlock()
+		t()
+	})
+	if err != nil {
+		delete(d.pending, key)
+		return err
+	}
+	if d.pending == nil {
+		d.pending = make(map[string]*debounceEntry)
+	}
+	d.pending[key] = e
+	return nil
+}
@@ -0,0 +1,62 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := New(2, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	start := clock.Now()
+	type run struct {
+		value string
+		at    time.Duration
+	}
+	ran := make(chan run, 8)
+	submit := func(key, value string, maxWait time.Duration) {
+		if err := p.Debounce(key, 100*time.Millisecond, maxWait, func() { ran <- run{value, clock.Since(start)} }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	step := func(d time.Duration) {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+		clock.BlockUntil(1) // 时间轮已处理到期的槽
+	}
+	p.ScheduleAfter(time.Hour, func() {}) // 使时间轮始终有待触发的任务
+
+	submit("a", "a1", 0)
+	submit("b", "b1", 0)
+	step(50 * time.Millisecond)
+	submit("a", "a2", 0)
+	step(50 * time.Millisecond)
+	submit("a", "a3", 0)
+	if r := <-ran; r != (run{"b1", 100 * time.Millisecond}) {
+		t.Fatalf("got %+v", r)
+	}
+	step(99 * time.Millisecond)
+	if len(ran) != 0 {
+		t.Fatal("debounced task ran before the window passed")
+	}
+	step(time.Millisecond)
+	if r := <-ran; r != (run{"a3", 200 * time.Millisecond}) {
+		t.Fatalf("got %+v, want only the last submission", r)
+	}
+
+	// 持续提交时最多推迟 maxWait
+	base := clock.Since(start)
+	for _, v := range []string{"c0", "c1", "c2", "c3", "c4"} {
+		submit("c", v, 250*time.Millisecond)
+		step(50 * time.Millisecond)
+	}
+	if r := <-ran; r.value != "c4" || r.at-base != 250*time.Millisecond { // 不限制时 c4 会在 300ms 执行
+		t.Fatalf("got %+v, want c4 after 250ms", r)
+	}
+	submit("c", "next", 250*time.Millisecond) // 新一轮合并
+	step(100 * time.Millisecond)
+	if r := <-ran; r.value != "next" {
+		t.Fatalf("got %+v", r)
+	}
+}
@@ -0,0 +1,49 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduleDetached(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	started := make(chan struct{})
+	var stopped atomic.Bool
+	if err := p.ScheduleDetached(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done() // 常驻的后台循环，直到 Free
+		time.Sleep(10 * time.Millisecond)
+		stopped.Store(true)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	var ran atomic.Int32
+	p.Schedule(func() { ran.Add(1) })
+	waited := make(chan struct{})
+	go func() {
+		p.Wait() // 不等待后台任务
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait blocked on a detached task")
+	}
+	if ran.Load() != 1 {
+		t.Fatal("Wait returned before the counted task finished")
+	}
+	if s := p.Stats(); s.Busy != 1 { // 后台任务同样占用 worker
+		t.Fatalf("Busy = %d, want 1", s.Busy)
+	}
+	p.Free() // Free 仍等待后台任务返回
+	if !stopped.Load() {
+		t.Fatal("Free returned before the detached task")
+	}
+	if err := p.ScheduleDetached(func(context.Context) {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("ScheduleDetached after Free = %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package workerpool
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DispatchStrategy 决定任务如何交给空闲的 worker，见 WithDispatchStrategy；
+// 容量未满时总是直接创建 worker 执行任务，缓冲（WithQueueSize）中的任务总是经由共享的 channel 按提交顺序取走
+type DispatchStrategy int
+
+const (
+	// DispatchSharedChannel 是默认的策略：空闲的 worker 都等待同一个 channel，任务交给其中任意一个。
+	// 实现最简单、单个任务的开销最低；但任务在空闲的 worker 之间大致轮流分配，负载低时所有 worker 都保持活跃，
+	// WithIdleTimeout 难以回收多余的 worker，并发提交较多时 channel 的锁也会成为争用点
+	DispatchSharedChannel DispatchStrategy = iota
+	// DispatchHandoffStack 把空闲的 worker 压入栈中，任务交给最近空闲的 worker（LIFO）：
+	// 刚执行过任务的 worker 缓存与 worker 本地资源（WithWorkerInit）更热，栈底的 worker 持续空闲，
+	// 可以被 WithIdleTimeout 回收，使 worker 数量贴近实际负载；代价是每次交出多一次加锁
+	DispatchHandoffStack
+	// DispatchShardedQueues 与 DispatchHandoffStack 相同，但按 GOMAXPROCS 把空闲的 worker 分到多个栈中，
+	// 提交方轮流从不同的栈取 worker，适合多个 goroutine 高并发提交短任务；各栈内为 LIFO，整体不再严格 LIFO
+	DispatchShardedQueues
+)
+
+func (s DispatchStrategy) String() string {
+	switch s {
+	case DispatchSharedChannel:
+		return "shared-channel"
+	case DispatchHandoffStack:
+		return "handoff-stack"
+	case DispatchShardedQueues:
+		return "sharded-queues"
+	}
+	return fmt.Sprintf("DispatchStrategy(%d)", int(s))
+}
+
+func WithDispatchStrategy(s DispatchStrategy) Option { // 选择交给空闲 worker 的方式，各策略的取舍见 DispatchStrategy；WithFIFO 下任务总是经由共享的 channel 按顺序交出
+	return func(p *Pool) {
+		switch s {
+		case DispatchSharedChannel:
+			p.idle = nil
+		case DispatchHandoffStack:
+			p.idle = newIdleStacks(s, 1)
+		case DispatchShardedQueues:
+			p.idle = newIdleStacks(s, runtime.GOMAXPROCS(0))
+		default:
+			p.invalid("WithDispatchStrategy: unknown strategy %d", int(s))
+		}
+	}
+}
+
+// idleStacks 是 DispatchHandoffStack 与 DispatchShardedQueues 下空闲的 worker，每个 worker 只在一个栈中
+type idleStacks struct {
+	kind   DispatchStrategy
+	shards []idleShard
+	next   atomic.Uint32 // 提交方下一次先尝试的栈
+}
+
+type idleShard struct {
+	mu      sync.Mutex
+	workers []*worker
+}
+
+func newIdleStacks(kind DispatchStrategy, n int) *idleStacks {
+	return &idleStacks{kind: kind, shards: make([]idleShard, max(n, 1))}
+}
+
+func (s *idleStacks) strategy() DispatchStrategy {
+	if s == nil {
+		return DispatchSharedChannel
+	}
+	return s.kind
+}
+
+// park 在 w 开始等待任务前把它压入所在的栈
+func (s *idleStacks) park(w *worker) {
+	if w.inbox == nil {
+		w.inbox = make(chan task, 1)
+	}
+	sh := &s.shards[w.id%len(s.shards)]
+	sh.mu.Lock()
+	sh.workers = append(sh.workers, w)
+	w.parked = true
+	sh.mu.Unlock()
+}
+
+// unpark 在 w 不经由 inbox 得到任务或退出时把它移出栈，返回 false 表示它已被 pop 取走，inbox 中随后会有一个任务
+func (s *idleStacks) unpark(w *worker) bool {
+	sh := &s.shards[w.id%len(s.shards)]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if !w.parked {
+		return false
+	}
+	w.parked = false
+	for i, x := range sh.workers {
+		if x == w {
+			sh.workers = append(sh.workers[:i], sh.workers[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// pop 取出最近空闲的 worker，调用方随即把任务放入它的 inbox（容量为 1，不会阻塞）；没有空闲的 worker 时返回 nil
+func (s *idleStacks) pop() *worker {
+	start := int(s.next.Add(1))
+	for i := range s.shards {
+		sh := &s.shards[(start+i)%len(s.shards)]
+		sh.mu.Lock()
+		if n := len(sh.workers); n > 0 {
+			w := sh.workers[n-1]
+			sh.workers[n-1] = nil
+			sh.workers = sh.workers[:n-1]
+			w.parked = false
+			sh.mu.Unlock()
+			return w
+		}
+		sh.mu.Unlock()
+	}
+	return nil
+}
+
+// handToIdle 把 t 交给栈中空闲的 worker，返回是否交出
+func (p *Pool) handToIdle(t task) bool {
+	if p.idle == nil {
+		return false
+	}
+	w := p.idle.pop()
+	if w == nil {
+		return false
+	}
+	w.inbox <- t
+	return true
+}
+
+// DispatchStrategy 返回 WithDispatchStrategy 选择的策略
+func (p *Pool) DispatchStrategy() DispatchStrategy {
+	return p.applied().idle.strategy()
+}
+
+// leaveIdle 在 w 不经由 inbox 得到任务或将要退出时把它移出空闲栈；
+// 此前它已被 pop 取走时，随后放入 inbox 的任务交给其它 worker
+func (p *Pool) leaveIdle(w *worker) {
+	if p.idle == nil || p.idle.unpark(w) {
+		return
+	}
+	p.dispatchAsync(<-w.inbox)
+}
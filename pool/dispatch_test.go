@@ -0,0 +1,102 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitParked 等待 p 的空闲栈中共有 n 个 worker
+func waitParked(t *testing.T, p *Pool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		parked := 0
+		for i := range p.idle.shards {
+			sh := &p.idle.shards[i]
+			sh.mu.Lock()
+			parked += len(sh.workers)
+			sh.mu.Unlock()
+		}
+		if parked == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d workers parked, want %d", parked, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatchHandoffStack(t *testing.T) {
+	p := New(3, WithLogger(nil), WithDispatchStrategy(DispatchHandoffStack))
+	defer p.Free()
+	if s := p.DispatchStrategy(); s != DispatchHandoffStack {
+		t.Fatalf("strategy = %s", s)
+	}
+	gates := make([]chan struct{}, 3)
+	ids := make([]int, 3)
+	var started sync.WaitGroup
+	for i := range gates {
+		gates[i] = make(chan struct{})
+		started.Add(1)
+		p.ScheduleFunc(func(ctx context.Context) {
+			ids[i], _ = WorkerID(ctx)
+			started.Done()
+			<-gates[i]
+		})
+	}
+	started.Wait()
+	for i, g := range gates { // 依次空闲，最后空闲的在栈顶
+		close(g)
+		waitParked(t, p, i+1)
+	}
+	for range 10 { // 负载低时总是交给同一个 worker，其余的保持空闲
+		ran := make(chan int)
+		p.ScheduleFunc(func(ctx context.Context) {
+			id, _ := WorkerID(ctx)
+			ran <- id
+		})
+		if id := <-ran; id != ids[2] {
+			t.Fatalf("task ran on worker %d, want the most recently idle %d (workers %v)", id, ids[2], ids)
+		}
+		waitParked(t, p, 3)
+	}
+}
+
+func TestDispatchShardedQueues(t *testing.T) {
+	p := New(8, WithLogger(nil), WithQueueSize(16), WithDispatchStrategy(DispatchShardedQueues))
+	var ran atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				if err := p.Schedule(func() { ran.Add(1) }); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	p.Wait()
+	p.Free()
+	if n := ran.Load(); n != 1600 {
+		t.Fatalf("%d of 1600 tasks ran", n)
+	}
+	if s := p.DispatchStrategy().String(); s != "sharded-queues" {
+		t.Fatalf("strategy = %s", s)
+	}
+
+	if _, err := NewE(1, WithFIFO(), WithDispatchStrategy(DispatchHandoffStack)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("handoff stack with FIFO: %v", err)
+	}
+	if _, err := NewE(1, WithDispatchStrategy(DispatchStrategy(7))); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("unknown strategy: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrainOnFree(t *testing.T) {
+	p, release := newBusyPool(t, WithDrainOnFree(), WithQueueSize(4))
+	var ran atomic.Int32
+	for range 3 {
+		if err := p.Schedule(func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitQueued(t, p, 3)
+	freed := make(chan struct{})
+	go func() {
+		p.Free()
+		close(freed)
+	}()
+	// 排空期间不再接受新的任务
+	eventually(t, "Free to start", func() bool { return errors.Is(p.Schedule(func() {}), ErrPoolClosed) })
+	select {
+	case <-freed:
+		t.Fatal("Free returned before the accepted tasks ran")
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-freed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Free did not return after draining")
+	}
+	if n := ran.Load(); n != 3 {
+		t.Fatalf("%d of 3 queued tasks ran", n)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(4))
+	var ran atomic.Int32
+	p.Schedule(func() { ran.Add(1) })
+	waitQueued(t, p, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain = %v, want %v", err, context.DeadlineExceeded)
+	}
+	release() // 排空在后台继续
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("second Drain = %v", err)
+	}
+	if n := ran.Load(); n != 1 {
+		t.Fatalf("queued task ran %d times", n)
+	}
+}
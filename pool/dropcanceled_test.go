@@ -0,0 +1,51 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDropCanceled(t *testing.T) {
+	for _, drop := range []bool{false, true} {
+		reasons := make(chan error, 1)
+		opts := []Option{WithQueueSize(1), WithOnDiscard(func(reason error) { reasons <- reason })}
+		if drop {
+			opts = append(opts, WithDropCanceled())
+		}
+		p, release := newBusyPool(t, opts...)
+		gone := errors.New("client gone")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		var ran atomic.Bool
+		if err := p.ScheduleCtx(ctx, func(context.Context) { ran.Store(true) }); err != nil {
+			t.Fatal(err)
+		}
+		cancel(gone)
+		release()
+		p.Wait()
+		if ran.Load() == drop {
+			t.Fatalf("drop=%t: task ran = %t", drop, ran.Load())
+		}
+		if drop {
+			if reason := <-reasons; reason != gone {
+				t.Fatalf("discard reason = %v, want %v", reason, gone)
+			}
+		}
+		p.Free()
+	}
+}
+
+// 提交方的 ctx 未取消的任务照常执行
+func TestDropCanceledKeepsLiveTasks(t *testing.T) {
+	p := New(1, WithLogger(nil), WithDropCanceled())
+	defer p.Free()
+	var ran atomic.Bool
+	if err := p.ScheduleCtx(context.Background(), func(context.Context) { ran.Store(true) }); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	if !ran.Load() {
+		t.Fatal("task did not run")
+	}
+}
@@ -0,0 +1,64 @@
+package workerpool
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"strconv"
+)
+
+// DumpStacks 将 p 的所有 worker goroutine 的调用栈写入 w，每个栈前注明 worker 编号与正在执行的任务
+// （经由 ScheduleWith 提交的任务显示其 TaskInfo），用于在生产环境中排查卡住的任务而无需接入调试器
+// worker 依据 pprof 标签（LabelPool、LabelWorker）识别，调用栈取自 goroutine profile
+func (p *Pool) DumpStacks(w io.Writer) error {
+	p = p.applied()
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return err
+	}
+	pool := []byte(strconv.Quote(LabelPool) + ":" + strconv.Quote(strconv.FormatUint(p.id, 10)))
+	role := []byte(strconv.Quote(LabelRole) + ":" + strconv.Quote(roleWorker))
+	for _, rec := range bytes.Split(buf.Bytes(), []byte("\n\n")) {
+		var labels []byte
+		var frames [][]byte
+		for _, line := range bytes.Split(rec, []byte("\n")) {
+			switch {
+			case bytes.HasPrefix(line, []byte("# labels: ")):
+				labels = line
+			case bytes.HasPrefix(line, []byte("#\t")):
+				frames = append(frames, line[2:])
+			}
+		}
+		if !bytes.Contains(labels, pool) || !bytes.Contains(labels, role) {
+			continue
+		}
+		id := labelValue(labels, LabelWorker)
+		task := "idle"
+		if n, err := strconv.Atoi(id); err == nil {
+			if v, ok := p.live.Load(n); ok {
+				if info := v.(*worker).task.Load(); info != nil {
+					task = info.String()
+				}
+			}
+		}
+		if _, err := fmt.Fprintf(w, "worker[%s] %s\n\t%s\n\n", id, task, bytes.Join(frames, []byte("\n\t"))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelValue 从 goroutine profile 的 "# labels: {...}" 行中取出 key 的值
+func labelValue(labels []byte, key string) string {
+	prefix := []byte(strconv.Quote(key) + ":\"")
+	i := bytes.Index(labels, prefix)
+	if i < 0 {
+		return ""
+	}
+	v := labels[i+len(prefix):]
+	if j := bytes.IndexByte(v, '"'); j >= 0 {
+		v = v[:j]
+	}
+	return string(v)
+}
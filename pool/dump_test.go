@@ -0,0 +1,32 @@
+package workerpool
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func stuckInDumpTest(release <-chan struct{}) { <-release }
+
+func TestDumpStacks(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	p.ScheduleWith(func(context.Context) {
+		close(started)
+		stuckInDumpTest(release)
+	}, WithTaskName("hang"))
+	<-started
+	var b strings.Builder
+	if err := p.DumpStacks(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "worker[001] task[1 hang]") || !strings.Contains(out, "stuckInDumpTest") {
+		t.Fatalf("dump missing the stuck worker:\n%s", out)
+	}
+	if strings.Contains(out, "tRunner") {
+		t.Fatalf("dump contains non-worker goroutines:\n%s", out)
+	}
+}
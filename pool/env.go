@@ -0,0 +1,92 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFromEnv 从以 prefix 为前缀的环境变量读取 Config，便于运维按部署调整 pool 而不修改代码，
+// 如 prefix 为 "WORKERPOOL" 时读取 WORKERPOOL_CAPACITY；未设置的变量保持零值（即默认值）：
+//
+//	_NAME, _CAPACITY, _MAX_CAPACITY, _QUEUE_SIZE       字符串 / 整数
+//	_BLOCK, _PREALLOC, _DRAIN_ON_FREE, _FIFO, _QUIET  布尔值，如 true、false、1、0
+//	_IDLE_TIMEOUT, _MAX_WORKER_AGE                     时长，如 30s、5m
+//	_MAX_TASKS_PER_WORKER                              整数
+//
+// 无法解析的变量返回错误，错误中包含变量名；其余变量仍会读取
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	e := envReader{prefix: prefix}
+	var c Config
+	c.Name = e.str("NAME")
+	c.Capacity = e.int("CAPACITY")
+	c.MaxCapacity = e.int("MAX_CAPACITY")
+	c.QueueSize = e.int("QUEUE_SIZE")
+	if block, ok := e.bool("BLOCK"); ok {
+		c.NonBlocking = !block
+	}
+	c.PreAlloc, _ = e.bool("PREALLOC")
+	c.DrainOnFree, _ = e.bool("DRAIN_ON_FREE")
+	c.FIFO, _ = e.bool("FIFO")
+	c.Quiet, _ = e.bool("QUIET")
+	c.IdleTimeout = e.duration("IDLE_TIMEOUT")
+	c.MaxWorkerAge = e.duration("MAX_WORKER_AGE")
+	c.MaxTasksPerWorker = e.int("MAX_TASKS_PER_WORKER")
+	return c, errors.Join(e.errs...)
+}
+
+type envReader struct {
+	prefix string
+	errs   []error
+}
+
+func (e *envReader) str(name string) string {
+	return os.Getenv(e.prefix + name)
+}
+
+func (e *envReader) fail(name, v string, err error) {
+	e.errs = append(e.errs, fmt.Errorf("%w: %s%s=%q: %w", ErrInvalidOption, e.prefix, name, v, err))
+}
+
+func (e *envReader) int(name string) int {
+	v := e.str(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.fail(name, v, err)
+	}
+	return n
+}
+
+func (e *envReader) bool(name string) (bool, bool) {
+	v := e.str(name)
+	if v == "" {
+		return false, false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.fail(name, v, err)
+		return false, false
+	}
+	return b, true
+}
+
+func (e *envReader) duration(name string) time.Duration {
+	v := e.str(name)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.fail(name, v, err)
+	}
+	return d
+}
@@ -0,0 +1,74 @@
+package workerpool
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	for k, v := range map[string]string{
+		"WP_NAME":                 "env",
+		"WP_CAPACITY":             "8",
+		"WP_MAX_CAPACITY":         "16",
+		"WP_QUEUE_SIZE":           "32",
+		"WP_BLOCK":                "false",
+		"WP_PREALLOC":             "1",
+		"WP_DRAIN_ON_FREE":        "true",
+		"WP_FIFO":                 "true",
+		"WP_QUIET":                "t",
+		"WP_IDLE_TIMEOUT":         "30s",
+		"WP_MAX_WORKER_AGE":       "5m",
+		"WP_MAX_TASKS_PER_WORKER": "100",
+	} {
+		t.Setenv(k, v)
+	}
+	c, err := ConfigFromEnv("WP") // 前缀补上 "_"
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Name: "env", Capacity: 8, MaxCapacity: 16, QueueSize: 32, NonBlocking: true, PreAlloc: true,
+		DrainOnFree: true, FIFO: true, Quiet: true, IdleTimeout: 30 * time.Second, MaxWorkerAge: 5 * time.Minute, MaxTasksPerWorker: 100,
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("ConfigFromEnv =\n%+v\nwant\n%+v", c, want)
+	}
+	p, err := NewFromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Free()
+	if p.Cap() != 8 {
+		t.Fatalf("Cap = %d", p.Cap())
+	}
+}
+
+func TestConfigFromEnvUnset(t *testing.T) {
+	t.Setenv("WPUNSET_BLOCK", "true")
+	c, err := ConfigFromEnv("WPUNSET_")
+	if err != nil || !reflect.DeepEqual(c, Config{}) { // BLOCK=true 即默认值
+		t.Fatalf("ConfigFromEnv = %+v, %v", c, err)
+	}
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	t.Setenv("WPBAD_CAPACITY", "many")
+	t.Setenv("WPBAD_FIFO", "maybe")
+	t.Setenv("WPBAD_IDLE_TIMEOUT", "soon")
+	t.Setenv("WPBAD_QUEUE_SIZE", "4")
+	c, err := ConfigFromEnv("WPBAD")
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption", err)
+	}
+	for _, name := range []string{"WPBAD_CAPACITY", "WPBAD_FIFO", "WPBAD_IDLE_TIMEOUT"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not name %s", err, name)
+		}
+	}
+	if c.QueueSize != 4 { // 其余变量照常读取
+		t.Fatalf("QueueSize = %d", c.QueueSize)
+	}
+}
@@ -0,0 +1,148 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 提交失败的原因分为以下几类，返回的错误是对应的 *XxxError，携带失败时的上下文，
+// 可以用 errors.Is 匹配下面的哨兵错误，或用 errors.As 取出具体的类型
+var (
+	ErrPoolClosed      = errors.New("pool closed")        // *PoolClosedError：Free 已开始
+	ErrPoolSaturated   = errors.New("pool saturated")     // *SaturatedError：不阻塞的提交没有空闲的 worker
+	ErrQueueFull       = errors.New("queue full")         // *QueueFullError：不阻塞的提交遇到已满的 WithQueueSize 缓冲，同时匹配 ErrPoolSaturated
+	ErrScheduleTimeout = errors.New("schedule timed out") // *ScheduleTimeoutError：阻塞的提交在分发前到达了时限
+)
+
+// 旧的哨兵错误，与新的分类等价
+var (
+	// Deprecated: 使用 ErrPoolClosed
+	ErrWorkerPoolFreed = ErrPoolClosed
+	// Deprecated: 使用 ErrPoolSaturated
+	ErrNoIdleWorkerInPool = ErrPoolSaturated
+)
+
+// PoolClosedError 是 Free 开始后提交返回的错误
+type PoolClosedError struct {
+	Pool  string // WithName
+	Cause error  // FreeWithCause 的 cause，Free 时为 nil
+}
+
+func (e *PoolClosedError) Error() string {
+	msg := "pool closed"
+	if e.Pool != "" {
+		msg = "pool " + e.Pool + " closed"
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+func (e *PoolClosedError) Is(target error) bool { return target == ErrPoolClosed }
+func (e *PoolClosedError) Unwrap() error        { return e.Cause }
+
+// SaturatedError 是不阻塞的提交没有空闲 worker 时返回的错误
+type SaturatedError struct {
+	Pool     string
+	Capacity int
+	stats    PoolStats
+}
+
+func (e *SaturatedError) Error() string {
+	if e.Pool != "" {
+		return "pool " + e.Pool + " saturated " + e.stats.summary()
+	}
+	return "pool saturated " + e.stats.summary()
+}
+
+func (e *SaturatedError) Is(target error) bool { return target == ErrPoolSaturated }
+
+// Snapshot 返回提交失败时 pool 的状态
+func (e *SaturatedError) Snapshot() PoolStats { return e.stats }
+
+// QueueFullError 是不阻塞的提交遇到已满的缓冲时返回的错误
+type QueueFullError struct {
+	Pool      string
+	Capacity  int
+	QueueSize int // WithQueueSize
+	stats     PoolStats
+
+	QueueBytes int64 // 因 WithQueueBytes 的字节数上限被拒绝时为该上限，否则为 0
+}
+
+func (e *QueueFullError) Error() string {
+	msg := "queue full "
+	if e.QueueBytes > 0 {
+		msg = fmt.Sprintf("queue full (%d of %d bytes) ", e.stats.QueuedBytes, e.QueueBytes)
+	}
+	if e.Pool != "" {
+		return "pool " + e.Pool + ": " + msg + e.stats.summary()
+	}
+	return msg + e.stats.summary()
+}
+
+// Snapshot 返回提交失败时 pool 的状态
+func (e *QueueFullError) Snapshot() PoolStats { return e.stats }
+
+func (e *QueueFullError) Is(target error) bool {
+	return target == ErrQueueFull || target == ErrPoolSaturated
+}
+
+// ScheduleTimeoutError 是阻塞的提交在分发前到达 ctx 的截止时间（或 ScheduleTimeout 的时限）时返回的错误，
+// 包装 context.DeadlineExceeded
+type ScheduleTimeoutError struct {
+	Pool     string
+	Deadline time.Time
+	Waited   time.Duration // 放弃前等待 worker 的时间，与任务本身的耗时无关
+	Err      error         // ctx.Err()
+}
+
+func (e *ScheduleTimeoutError) Error() string {
+	msg := fmt.Sprintf("schedule timed out after waiting %s: %s", e.Waited, e.Err)
+	if e.Pool != "" {
+		return "pool " + e.Pool + ": " + msg
+	}
+	return msg
+}
+
+func (e *ScheduleTimeoutError) Is(target error) bool { return target == ErrScheduleTimeout }
+func (e *ScheduleTimeoutError) Unwrap() error        { return e.Err }
+
+// SnapshotOf 返回 err 中携带的 pool 状态（*SaturatedError、*QueueFullError 及包装了它们的错误），
+// 便于在过载日志中直接看到失败时 pool 的状态
+func SnapshotOf(err error) (PoolStats, bool) {
+	var s interface{ Snapshot() PoolStats }
+	if errors.As(err, &s) {
+		return s.Snapshot(), true
+	}
+	return PoolStats{}, false
+}
+
+func (s PoolStats) summary() string {
+	return fmt.Sprintf("(capacity %d, busy %d, queued %d, blocked %d)", s.Capacity, s.Busy, s.Queued, s.Blocked)
+}
+
+func (p *Pool) freedErr() error {
+	return &PoolClosedError{Pool: p.name, Cause: p.freeCause}
+}
+
+// saturatedErr 是不阻塞的提交没能交出任务时的错误
+func (p *Pool) saturatedErr() error {
+	if p.queueSize > 0 {
+		return &QueueFullError{Pool: p.name, Capacity: p.capacity, QueueSize: p.queueSize, stats: p.stats()}
+	}
+	return &SaturatedError{Pool: p.name, Capacity: p.capacity, stats: p.stats()}
+}
+
+// waitErr 是阻塞的提交因 ctx 结束而放弃时的错误，截止时间到达时为 *ScheduleTimeoutError
+func (p *Pool) waitErr(ctx context.Context) error {
+	err := ctx.Err()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	return &ScheduleTimeoutError{Pool: p.name, Deadline: deadline, Err: err}
+}
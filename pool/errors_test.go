@@ -0,0 +1,104 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSaturatedErrors(t *testing.T) {
+	p, release := newBusyPool(t, WithName("busy"))
+	defer p.Free()
+	defer release()
+	err := p.TryScheduleFunc(func(context.Context) {})
+	var se *SaturatedError
+	if !errors.As(err, &se) || se.Pool != "busy" || se.Capacity != 1 {
+		t.Fatalf("err = %#v, want *SaturatedError", err)
+	}
+	if !errors.Is(err, ErrPoolSaturated) || !errors.Is(err, ErrNoIdleWorkerInPool) || errors.Is(err, ErrQueueFull) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+
+	q, releaseQ := newBusyPool(t, WithQueueSize(1))
+	defer q.Free()
+	defer releaseQ()
+	if err := q.TryScheduleFunc(func(context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	err = q.TryScheduleFunc(func(context.Context) {})
+	var qe *QueueFullError
+	if !errors.As(err, &qe) || qe.QueueSize != 1 {
+		t.Fatalf("err = %#v, want *QueueFullError", err)
+	}
+	if !errors.Is(err, ErrQueueFull) || !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+}
+
+func TestScheduleTimeoutError(t *testing.T) {
+	p, release := newBusyPool(t)
+	defer p.Free()
+	defer release()
+	err := p.ScheduleTimeout(func() {}, 10*time.Millisecond)
+	var te *ScheduleTimeoutError
+	if !errors.As(err, &te) || te.Deadline.IsZero() {
+		t.Fatalf("err = %#v, want *ScheduleTimeoutError", err)
+	}
+	if !errors.Is(err, ErrScheduleTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.submit(ctx, task{fn: func() {}}, true); err != context.Canceled {
+		t.Fatalf("canceled submit: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestPoolClosedError(t *testing.T) {
+	p := New(1, WithLogger(nil), WithName("svc"))
+	cause := errors.New("shutdown")
+	p.FreeWithCause(cause)
+	err := p.Schedule(func() {})
+	var ce *PoolClosedError
+	if !errors.As(err, &ce) || ce.Pool != "svc" || ce.Cause != cause {
+		t.Fatalf("err = %#v, want *PoolClosedError", err)
+	}
+	if !errors.Is(err, ErrPoolClosed) || !errors.Is(err, ErrWorkerPoolFreed) || !errors.Is(err, cause) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+	if got, want := err.Error(), "pool svc closed: shutdown"; got != want {
+		t.Fatalf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestSaturatedErrorSnapshot(t *testing.T) {
+	p, release := newBusyPool(t)
+	done := make(chan error, 1)
+	go func() { done <- p.Schedule(func() {}) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Blocked != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("submitter did not block")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	err := p.TryScheduleFunc(func(context.Context) {})
+	s, ok := SnapshotOf(fmt.Errorf("wrapped: %w", err))
+	if !ok || s.Capacity != 1 || s.Busy != 1 || s.Blocked != 1 {
+		t.Fatalf("SnapshotOf(%v) = %+v, %v", err, s, ok)
+	}
+	if got, want := err.Error(), "pool saturated (capacity 1, busy 1, queued 1, blocked 1)"; got != want {
+		t.Fatalf("Error() = %q, want %q", got, want)
+	}
+	if _, ok := SnapshotOf(ErrPoolClosed); ok {
+		t.Fatal("SnapshotOf(ErrPoolClosed) reported a snapshot")
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	p.Free()
+}
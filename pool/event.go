@@ -0,0 +1,75 @@
+package workerpool
+
+import "time"
+
+// EventKind 是任务生命周期事件的类型
+type EventKind string
+
+const (
+	EventSubmitted EventKind = "submitted" // 提交被接受
+	EventRejected  EventKind = "rejected"  // 提交失败，见 Event.Err
+	EventStarted   EventKind = "started"   // 开始在 worker 上执行
+	EventFinished  EventKind = "finished"  // 执行完毕
+	EventFailed    EventKind = "failed"    // 执行中 panic，Err 为 *PanicError
+	EventDiscarded EventKind = "discarded" // 被接受后未执行即被丢弃，Err 为原因
+)
+
+// pool 自身的事件，只经由 Events 发出，不写入 WithAuditWriter 与 WithEventRecorder
+const (
+	EventWorkerStarted      EventKind = "worker_started"       // worker 初始化完成，Worker 为其编号
+	EventWorkerExited       EventKind = "worker_exited"        // worker 退出
+	EventQueueHighWatermark EventKind = "queue_high_watermark" // 排队的任务数达到 WithEvents 的 highWatermark，Queued 为当时的数量
+	EventShutdownBegan      EventKind = "shutdown_began"       // Free 开始，HandoffTo 替换 pool 时 Err 为 ErrPoolReplaced
+	EventShutdownFinished   EventKind = "shutdown_finished"    // Free 完成，之后不再有 worker 事件
+)
+
+// Event 是一个任务生命周期事件
+type Event struct {
+	Kind     EventKind
+	Time     time.Time
+	TaskID   uint64        // 同一任务的各个事件相同；经由 ScheduleWith 提交的任务即 TaskInfo.ID
+	Info     *TaskInfo     // ScheduleWith 提交的任务的元数据，其它任务为 nil
+	Worker   int           // 执行任务的 worker 编号，started/finished/failed 才有
+	Duration time.Duration // finished/failed 的执行时长
+	Err      error         // rejected/failed/discarded 的原因
+	Queued   int           // queue_high_watermark 时排队的任务数
+}
+
+// observed 表示有事件的接收方，没有时不构造事件
+func (p *Pool) observed() bool {
+	return len(p.observers) > 0
+}
+
+func (p *Pool) emit(e Event) {
+	e.Time = p.clock.Now()
+	for _, o := range p.observers {
+		o(e)
+	}
+}
+
+// taskID 为 t 分配事件中的编号，已有 TaskInfo 时沿用它的 ID
+func (p *Pool) taskID(t *task) {
+	switch {
+	case t.id != 0:
+	case t.info != nil:
+		t.id = t.info.ID
+	default:
+		t.id = p.taskSeq.Add(1)
+	}
+}
+
+// runObserved 执行 run 并发出 started 与 finished/failed 事件
+func (p *Pool) runObserved(w *worker, t task, run func()) {
+	start := p.clock.Now()
+	p.emit(Event{Kind: EventStarted, TaskID: t.id, Info: t.info, Worker: w.id})
+	defer func() {
+		e := Event{Kind: EventFinished, TaskID: t.id, Info: t.info, Worker: w.id, Duration: p.clock.Since(start)}
+		if r := recover(); r != nil {
+			e.Kind, e.Err = EventFailed, &PanicError{Value: r}
+			p.emit(e)
+			panic(r) // 交给 worker 照常处理
+		}
+		p.emit(e)
+	}()
+	run()
+}
@@ -0,0 +1,82 @@
+package workerpool
+
+import "sync/atomic"
+
+// eventChannel 是 Events 返回的 channel：发送不阻塞，已满时丢弃最早的事件
+type eventChannel struct {
+	ch      chan Event
+	dropped atomic.Uint64
+	high    int         // 发出 queue_high_watermark 的排队任务数，0 表示不发出
+	above   atomic.Bool // 已发出 queue_high_watermark，排队数降到 high 的一半以下后重新计
+}
+
+func WithEvents(buffer, highWatermark int) Option { // 经由 Events 返回的 channel 发出任务与 pool 自身的事件（见 EventKind），channel 长度为 buffer，已满时丢弃最早的事件；排队的任务数达到 highWatermark 时发出 queue_high_watermark，0 表示不发出
+	return func(p *Pool) {
+		if buffer <= 0 {
+			p.invalid("event buffer %d must be positive", buffer)
+			return
+		}
+		c := &eventChannel{ch: make(chan Event, buffer), high: highWatermark}
+		p.events = c
+		p.observers = append(p.observers, c.send)
+	}
+}
+
+// Events 返回 WithEvents 的事件 channel，未设置时返回 nil；channel 不会被关闭，pool 销毁后以 shutdown_finished 结束
+// 消费不及时时最早的事件被丢弃（见 DroppedEvents），事件的发出方永远不会因此阻塞
+func (p *Pool) Events() <-chan Event {
+	p = p.applied()
+	if p.events == nil {
+		return nil
+	}
+	return p.events.ch
+}
+
+// DroppedEvents 返回因 Events 的 channel 已满而丢弃的事件数
+func (p *Pool) DroppedEvents() uint64 {
+	p = p.applied()
+	if p.events == nil {
+		return 0
+	}
+	return p.events.dropped.Load()
+}
+
+func (c *eventChannel) send(e Event) {
+	for {
+		select {
+		case c.ch <- e:
+			return
+		default:
+		}
+		select {
+		case <-c.ch:
+			c.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// emitPool 发出 pool 自身的事件，没有设置 WithEvents 时不构造事件
+func (p *Pool) emitPool(kind EventKind, worker int, err error) {
+	if p.events == nil {
+		return
+	}
+	p.events.send(Event{Kind: kind, Time: p.clock.Now(), Worker: worker, Err: err})
+}
+
+// watermark 在提交被接受后检查排队的任务数
+func (p *Pool) watermark() {
+	c := p.events
+	if c == nil || c.high <= 0 {
+		return
+	}
+	p.queued.mu.Lock()
+	n := len(p.queued.m)
+	p.queued.mu.Unlock()
+	switch {
+	case n >= c.high && c.above.CompareAndSwap(false, true):
+		c.send(Event{Kind: EventQueueHighWatermark, Time: p.clock.Now(), Queued: n})
+	case n < c.high/2:
+		c.above.Store(false)
+	}
+}
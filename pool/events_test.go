@@ -0,0 +1,56 @@
+package workerpool
+
+import (
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(4), WithEvents(64, 2))
+	for range 3 {
+		if err := p.Schedule(func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release()
+	p.Wait()
+	p.Free()
+	kinds := map[EventKind]int{}
+	var last Event
+	for len(p.Events()) > 0 {
+		last = <-p.Events()
+		kinds[last.Kind]++
+		if last.Kind == EventQueueHighWatermark && last.Queued < 2 {
+			t.Fatalf("watermark event with queued %d, want at least 2", last.Queued)
+		}
+	}
+	if kinds[EventQueueHighWatermark] != 1 {
+		t.Fatalf("%d watermark events, want 1", kinds[EventQueueHighWatermark])
+	}
+	for _, k := range []EventKind{EventWorkerStarted, EventWorkerExited, EventSubmitted, EventFinished, EventShutdownBegan} {
+		if kinds[k] == 0 {
+			t.Fatalf("no %s event in %v", k, kinds)
+		}
+	}
+	if last.Kind != EventShutdownFinished {
+		t.Fatalf("last event = %s, want %s", last.Kind, EventShutdownFinished)
+	}
+}
+
+// channel 已满时丢弃最早的事件，发出方不阻塞
+func TestEventsDropOldest(t *testing.T) {
+	p := New(1, WithLogger(nil), WithEvents(2, 0))
+	defer p.Free()
+	for range 5 {
+		p.Schedule(func() {})
+	}
+	p.Wait()
+	if n := len(p.Events()); n != 2 {
+		t.Fatalf("%d events buffered, want 2", n)
+	}
+	if p.DroppedEvents() == 0 {
+		t.Fatal("no events dropped")
+	}
+	if e := <-p.Events(); e.Kind == EventWorkerStarted {
+		t.Fatal("oldest event kept")
+	}
+}
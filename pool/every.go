@@ -0,0 +1,84 @@
+package workerpool
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+type everyConfig struct {
+	jitter       time.Duration
+	initialDelay time.Duration
+	hasDelay     bool
+	policy       OverlapPolicy
+}
+
+type EveryOption func(*everyConfig)
+
+func WithJitter(d time.Duration) EveryOption { // 每次触发随机推迟 [0, d)，避免多个实例同时触发
+	return func(c *everyConfig) {
+		c.jitter = d
+	}
+}
+
+func WithInitialDelay(d time.Duration) EveryOption { // 首次触发的延迟，默认为一个周期
+	return func(c *everyConfig) {
+		c.initialDelay = d
+		c.hasDelay = true
+	}
+}
+
+func WithOverlap(policy OverlapPolicy) EveryOption { // 上一次未结束时的处理方式，默认 OverlapSkip
+	return func(c *everyConfig) {
+		c.policy = policy
+	}
+}
+
+// ScheduleEvery 以固定间隔周期性地向 pool 投递 t，返回的句柄可用于停止后续触发
+// 触发时间按固定频率计算，抖动不会累积；落后超过一个周期时跳过错过的触发
+func (p *Pool) ScheduleEvery(interval time.Duration, t TaskFunc, opts ...EveryOption) (TimerHandle, error) {
+	p = p.applied()
+	if interval <= 0 {
+		return TimerHandle{}, errors.New("workerpool: non-positive interval for ScheduleEvery")
+	}
+	cfg := everyConfig{policy: OverlapSkip}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	delay := interval
+	if cfg.hasDelay {
+		delay = cfg.initialDelay
+	}
+	s := &everySchedule{interval: interval, jitter: cfg.jitter, base: p.clock.Now().Add(delay)}
+	job := &recurringJob{p: p, fn: t, policy: cfg.policy}
+	e := &timerEntry{p: p, kind: PendingEvery, nextAt: s.next, occur: job.occur, reset: s.reset}
+	return p.addTimer(e, delay+jitter(cfg.jitter))
+}
+
+// 由 timerWheel.mu 保护
+type everySchedule struct {
+	interval time.Duration
+	jitter   time.Duration
+	base     time.Time // 不含抖动的理论触发时间
+}
+
+func (s *everySchedule) next(now time.Time) (time.Time, bool) {
+	for !s.base.After(now) {
+		s.base = s.base.Add(s.interval)
+	}
+	return s.base.Add(jitter(s.jitter)), true
+}
+
+func (s *everySchedule) reset(now time.Time, d time.Duration) {
+	if d > 0 {
+		s.interval = d
+	}
+	s.base = now.Add(d)
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
@@ -0,0 +1,70 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScheduleEvery(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := New(1, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	start := clock.Now()
+	fired := make(chan time.Duration, 4)
+	h, err := p.ScheduleEvery(time.Second, func(context.Context) { fired <- clock.Since(start) }, WithInitialDelay(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := func(d time.Duration) {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+		clock.BlockUntil(1) // 时间轮已处理到期的槽
+	}
+	step(4 * time.Second)
+	if len(fired) != 0 {
+		t.Fatal("fired before the initial delay")
+	}
+	step(time.Second)
+	if at := <-fired; at != 5*time.Second {
+		t.Fatalf("first run at %s", at)
+	}
+	step(time.Second)
+	if at := <-fired; at != 6*time.Second {
+		t.Fatalf("second run at %s", at)
+	}
+	step(3500 * time.Millisecond) // 落后超过一个周期，错过的触发只补一次
+	if at := <-fired; at != 9500*time.Millisecond {
+		t.Fatalf("catch-up run at %s", at)
+	}
+	step(500 * time.Millisecond) // 仍按原来的节拍触发
+	if at := <-fired; at != 10*time.Second {
+		t.Fatalf("run after catch-up at %s", at)
+	}
+	if len(fired) != 0 {
+		t.Fatalf("%d extra runs", len(fired))
+	}
+	h.Stop()
+}
+
+func TestEveryJitterDoesNotAccumulate(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := &everySchedule{interval: time.Second, jitter: 100 * time.Millisecond, base: start.Add(time.Second)}
+	now := start
+	for i := 1; i <= 50; i++ {
+		at, _ := s.next(now)
+		base := start.Add(time.Duration(i) * time.Second)
+		if at.Before(base) || !at.Before(base.Add(100*time.Millisecond)) {
+			t.Fatalf("run %d at %s, want within 100ms after %s", i, at.Sub(start), base.Sub(start))
+		}
+		now = at
+	}
+}
+
+func TestScheduleEveryInvalidInterval(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	if _, err := p.ScheduleEvery(0, func(context.Context) {}); err == nil {
+		t.Fatal("ScheduleEvery(0) succeeded")
+	}
+}
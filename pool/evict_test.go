@@ -0,0 +1,49 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPriorityEviction(t *testing.T) {
+	var evicted atomic.Int32
+	p, release := newBusyPool(t, WithBlock(false), WithPriorityScheduling(0), WithQueueSize(3), WithPriorityEviction(),
+		WithOnDiscard(func(reason error) {
+			if errors.Is(reason, ErrTaskEvicted) {
+				evicted.Add(1)
+			}
+		}))
+	defer p.Free()
+	var r recorder
+	for _, prio := range []int{1, 2, 3} {
+		if err := p.SchedulePriority(prio, r.task(prio)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 队列已满：更高优先级的任务挤掉排队中最低的一个，不高于任何排队任务的则照常被拒绝
+	if err := p.SchedulePriority(9, r.task(9)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SchedulePriority(0, r.task(0)); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated", err)
+	}
+	release()
+	p.Wait()
+	if evicted.Load() != 1 || len(r.got) != 3 || r.got[0] != 9 {
+		t.Fatalf("ran %v with %d evicted, want 9 first and one evicted", r.got, evicted.Load())
+	}
+	for _, prio := range r.got {
+		if prio == 3 {
+			return
+		}
+	}
+	// feeder 持有、等待 worker 的条目不会被挤掉，因此被挤掉的是 1 或 2，但不会是 3
+	t.Fatalf("ran %v, a higher-priority task was evicted", r.got)
+}
+
+func TestPriorityEvictionRequiresQueue(t *testing.T) {
+	if _, err := NewE(1, WithPriorityEviction()); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption without priority scheduling", err)
+	}
+}
@@ -0,0 +1,104 @@
+package workerpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Explanation 说明一个已提交的任务为什么还没有开始执行，见 Future.Explain
+type Explanation struct {
+	Pending   bool         // 任务仍在等待；为 false 时已开始、已结束或已被丢弃，其余字段为零值
+	Queue     QueueInfo    // 在队列中的位置与估计的等待时间
+	Capacity  int          // pool 当前的容量
+	Busy      []BusyWorker // 正在执行任务的 worker，按编号排序
+	Paused    bool         // 分发已由 Pause 暂停
+	RateLimit float64      // SetRateLimit 的每秒任务数，0 表示不限速
+	Blackout  time.Time    // 处于 WithBlackout 的时段内时为分发恢复的时刻
+	Closing   bool         // pool 正在销毁
+	Reasons   []string     // 以上情况的文字说明，按影响从大到小排列
+}
+
+// BusyWorker 是正在执行任务的 worker
+type BusyWorker struct {
+	Worker  int
+	Task    string        // 任务的 TaskInfo，不是经由 ScheduleWith 提交的任务为 "a task"
+	Running time.Duration // 已执行的时长
+}
+
+func (e Explanation) String() string {
+	if !e.Pending {
+		return "task is not pending"
+	}
+	return strings.Join(e.Reasons, "; ")
+}
+
+// explain 返回 c 对应的任务尚未执行的原因
+func (p *Pool) explain(c *taskClaim) Explanation {
+	if c == nil || c.state.Load() != claimPending {
+		return Explanation{}
+	}
+	p = p.applied()
+	now := p.clock.Now()
+	e := Explanation{Pending: true, Queue: p.queueInfo(c), Capacity: p.Stats().Capacity, Paused: p.pause.paused()}
+	p.rate.mu.Lock()
+	if p.rate.interval > 0 {
+		e.RateLimit = float64(time.Second) / float64(p.rate.interval)
+	}
+	p.rate.mu.Unlock()
+	if until, ok := p.blackoutUntil(now); ok {
+		e.Blackout = until
+	}
+	select {
+	case <-p.closing:
+		e.Closing = true
+	default:
+	}
+	p.live.Range(func(_, v any) bool {
+		w := v.(*worker)
+		since := w.busySince.Load()
+		if since == 0 {
+			return true
+		}
+		b := BusyWorker{Worker: w.id, Task: "a task", Running: now.Sub(time.Unix(0, since))}
+		if info := w.task.Load(); info != nil {
+			b.Task = info.String()
+		}
+		e.Busy = append(e.Busy, b)
+		return true
+	})
+	sort.Slice(e.Busy, func(i, j int) bool { return e.Busy[i].Worker < e.Busy[j].Worker })
+
+	add := func(format string, args ...any) { e.Reasons = append(e.Reasons, fmt.Sprintf(format, args...)) }
+	if e.Closing {
+		add("pool is shutting down")
+	}
+	if e.Paused {
+		add("dispatch is paused")
+	}
+	if !e.Blackout.IsZero() {
+		add("in a blackout window until %s", e.Blackout.Format(time.RFC3339))
+	}
+	if e.RateLimit > 0 {
+		add("rate limited to %g tasks/s", e.RateLimit)
+	}
+	if e.Queue.Queued {
+		if e.Queue.EstimatedWait > 0 {
+			add("position %d in queue, estimated wait %s", e.Queue.Position, e.Queue.EstimatedWait.Round(time.Millisecond))
+		} else {
+			add("position %d in queue", e.Queue.Position)
+		}
+	}
+	if len(e.Busy) >= e.Capacity {
+		busy := make([]string, 0, len(e.Busy))
+		for _, b := range e.Busy {
+			busy = append(busy, fmt.Sprintf("worker[%03d] running %s for %s", b.Worker, b.Task, b.Running.Round(time.Millisecond)))
+		}
+		add("all %d workers are busy: %s", e.Capacity, strings.Join(busy, ", "))
+	}
+	if len(e.Reasons) == 0 {
+		add("waiting to be picked up by a worker")
+	}
+	return e
+}
@@ -0,0 +1,53 @@
+package workerpool
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFutureExplain(t *testing.T) {
+	p := New(1, WithLogger(nil), WithQueueSize(4))
+	defer p.Free()
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	p.ScheduleWith(func(context.Context) {
+		close(started)
+		<-gate
+	}, WithTaskName("resize"))
+	<-started
+	fn := func(context.Context) (int, error) { return 1, nil }
+	if _, err := Submit(p, fn); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Submit(p, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Pause()
+	p.SetRateLimit(5)
+
+	e := f.Explain()
+	if !e.Pending || !e.Queue.Queued || e.Queue.Position != 2 || !e.Paused || e.RateLimit != 5 {
+		t.Fatalf("Explain = %+v", e)
+	}
+	if len(e.Busy) != 1 || !strings.Contains(e.Busy[0].Task, "resize") {
+		t.Fatalf("busy = %+v", e.Busy)
+	}
+	s := e.String()
+	for _, want := range []string{"paused", "rate limited", "position 2", "all 1 workers are busy", "resize"} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("String() = %q, missing %q", s, want)
+		}
+	}
+
+	p.ClearRateLimit()
+	p.Resume()
+	close(gate)
+	if _, err := f.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if e := f.Explain(); e.Pending || e.String() != "task is not pending" {
+		t.Fatalf("Explain after run = %+v", e)
+	}
+}
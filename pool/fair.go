@@ -0,0 +1,25 @@
+package workerpool
+
+// fairTurn 在 WithFairSubmit 下等待轮到 t 的提交方：同一时刻只有一个阻塞的提交方等待交出，其余按到达顺序排队；
+// 排队期间 p.fairWaiting 非 0，新的提交不尝试直接交出，不会越过排队的提交方
+// ok 为 false 表示等待期间 pool 开始销毁、done 关闭或 t 被丢弃
+func (p *Pool) fairTurn(t task, done <-chan struct{}) (release func(), ok bool) {
+	p.fairWaiting.Add(1)
+	select {
+	case p.fair <- struct{}{}: // 阻塞的发送方按到达顺序被接收
+		return func() {
+			<-p.fair
+			p.fairWaiting.Add(-1)
+		}, true
+	case <-p.closing:
+	case <-done:
+	case <-t.claim.discarded:
+	}
+	p.fairWaiting.Add(-1)
+	return nil, false
+}
+
+// queueFair 表示 t 的提交须排在阻塞中的提交方之后
+func (p *Pool) queueFair() bool {
+	return p.fair != nil && p.fairWaiting.Load() > 0
+}
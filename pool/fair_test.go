@@ -0,0 +1,41 @@
+package workerpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFairSubmitOrder(t *testing.T) {
+	p, release := newBusyPool(t, WithFairSubmit())
+	defer p.Free()
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Schedule(func() {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+			})
+		}()
+		// 等第 i 个提交方开始排队后再启动下一个，使到达顺序确定
+		for deadline := time.Now().Add(5 * time.Second); p.fairWaiting.Load() != int32(i+1); {
+			if time.Now().After(deadline) {
+				t.Fatalf("submitter %d did not block", i)
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}
+	release()
+	wg.Wait()
+	p.Wait()
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("tasks ran in order %v, want submission order", order)
+		}
+	}
+}
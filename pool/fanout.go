@@ -0,0 +1,211 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// FanOut 从 in 读取元素，在 p 上同时最多执行 workers 个 fn，结果按完成顺序写入返回的 channel；
+// in 关闭或 ctx 取消后不再读取新元素，执行中的任务结束后输出关闭。workers <= 0 时取 p 的容量
+// fn panic 的元素没有输出；提交失败（如 pool 已销毁）时停止读取，输出提前关闭
+func FanOut[In, Out any](ctx context.Context, p *Pool, workers int, in <-chan In, fn func(ctx context.Context, v In) Out) <-chan Out {
+	return fanOut(ctx, p, in, wrapFanOut(fn), fanOutConfig{workers: workers})
+}
+
+// FanOutOrdered 与 FanOut 相同，但输出与输入顺序一致；
+// 已完成但前面仍有元素未完成的结果会被暂存，暂存与执行中的元素合计不超过 workers 个
+func FanOutOrdered[In, Out any](ctx context.Context, p *Pool, workers int, in <-chan In, fn func(ctx context.Context, v In) Out) <-chan Out {
+	return fanOut(ctx, p, in, wrapFanOut(fn), fanOutConfig{workers: workers, ordered: true})
+}
+
+// FanIn 将多个 channel 合并到一个 channel，全部关闭或 ctx 取消后输出关闭
+func FanIn[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, ch := range chans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func wrapFanOut[In, Out any](fn func(ctx context.Context, v In) Out) func(ctx context.Context, v In) (Out, bool) {
+	return func(ctx context.Context, v In) (Out, bool) {
+		return fn(ctx, v), true
+	}
+}
+
+type fanOutConfig struct {
+	workers int
+	ordered bool
+	onErr   func(error)     // 提交失败或任务被丢弃时调用，nil 表示忽略
+	wg      *sync.WaitGroup // 非 nil 时计入 fanOut 内部的所有 goroutine 与任务
+}
+
+// fanOut 是 FanOut/FanOutOrdered/Pipe 的共同实现：fn 返回 false 表示该元素没有输出，
+// 所有 goroutine 与任务结束后输出关闭
+func fanOut[In, Out any](ctx context.Context, p *Pool, in <-chan In, fn func(ctx context.Context, v In) (Out, bool), cfg fanOutConfig) <-chan Out {
+	workers, ordered := cfg.workers, cfg.ordered
+	if workers <= 0 {
+		workers = p.Cap()
+	}
+	if cfg.wg != nil {
+		cfg.wg.Add(1)
+	}
+	type result struct {
+		v  Out
+		ok bool
+	}
+	out := make(chan Out)
+	send := func(v Out) {
+		select {
+		case out <- v:
+		case <-ctx.Done():
+		}
+	}
+	// 有序模式下每个元素占一个槽位，槽位按输入顺序排队，由 emit 依次取出结果发送
+	var slots chan chan result
+	var emitted chan struct{}
+	if ordered {
+		slots = make(chan chan result, workers-1) // emit 另持有一个槽位
+		emitted = make(chan struct{})
+		go func() {
+			defer close(emitted)
+			for slot := range slots {
+				select {
+				case r := <-slot:
+					if r.ok {
+						send(r.v)
+					}
+				case <-ctx.Done():
+					// 取消后不再等待结果，任务照常结束并写入带缓冲的槽位
+				}
+			}
+		}()
+	}
+	sem := make(chan struct{}, workers)
+	var running sync.WaitGroup
+	go func() {
+		if cfg.wg != nil {
+			defer cfg.wg.Done()
+		}
+		defer close(out)
+		if ordered {
+			defer func() { <-emitted }()
+			defer close(slots)
+		}
+		defer running.Wait()
+		for {
+			var v In
+			var ok bool
+			select {
+			case v, ok = <-in:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			var slot chan result
+			if ordered {
+				slot = make(chan result, 1)
+				select {
+				case slots <- slot:
+				case <-ctx.Done():
+					return
+				}
+			} else {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			running.Add(1)
+			err := p.scheduleOr(task{fn: func() {
+				var r result
+				defer running.Done()
+				defer func() {
+					if ordered {
+						slot <- r
+					} else {
+						<-sem
+					}
+				}()
+				r.v, r.ok = fn(ctx, v)
+				if r.ok && !ordered {
+					send(r.v)
+				}
+			}}, func(reason error) {
+				if ordered {
+					slot <- result{}
+				} else {
+					<-sem
+				}
+				running.Done()
+				if cfg.onErr != nil {
+					cfg.onErr(reason)
+				}
+			})
+			if err != nil {
+				running.Done()
+				if ordered {
+					slot <- result{}
+				}
+				if cfg.onErr != nil {
+					cfg.onErr(err)
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Consume 持续从 ch 读取元素并在 p 上执行 fn，直到 ch 关闭或 ctx 取消，
+// 返回前等待所有已提交的 fn 结束；pool 满时读取随 Schedule 阻塞，不会无限积压
+// ch 关闭时返回 nil，ctx 取消时返回 ctx 的错误，提交失败时返回该错误
+func Consume[T any](ctx context.Context, p *Pool, ch <-chan T, fn func(v T)) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			wg.Add(1)
+			err := p.scheduleOr(task{fn: func() {
+				defer wg.Done()
+				fn(v)
+			}}, func(error) { wg.Done() })
+			if err != nil {
+				wg.Done()
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 依次发送 vs 后关闭的 channel
+func feed[T any](vs ...T) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for _, v := range vs {
+			ch <- v
+		}
+	}()
+	return ch
+}
+
+func TestFanOut(t *testing.T) {
+	p := New(8, WithLogger(nil))
+	defer p.Free()
+	var running, peak atomic.Int32
+	out := FanOut(context.Background(), p, 3, feed(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), func(ctx context.Context, v int) int {
+		n := running.Add(1)
+		for {
+			if old := peak.Load(); n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return v * v
+	})
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 4, 9, 16, 25, 36, 49, 64, 81, 100}) {
+		t.Fatalf("FanOut = %v", got)
+	}
+	if peak.Load() > 3 {
+		t.Fatalf("FanOut ran %d at once, want at most 3", peak.Load())
+	}
+}
+
+func TestFanOutOrdered(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	// 越靠前的元素越晚完成，输出仍须与输入顺序一致
+	out := FanOutOrdered(context.Background(), p, 4, feed(4, 3, 2, 1, 0), func(ctx context.Context, v int) int {
+		time.Sleep(time.Duration(v) * 5 * time.Millisecond)
+		return v
+	})
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{4, 3, 2, 1, 0}) {
+		t.Fatalf("FanOutOrdered = %v", got)
+	}
+}
+
+func TestFanOutPanic(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	out := FanOut(context.Background(), p, 2, feed(1, 2, 3), func(ctx context.Context, v int) int {
+		if v == 2 {
+			panic("fan out")
+		}
+		return v
+	})
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 3}) {
+		t.Fatalf("FanOut with a panic = %v", got)
+	}
+}
+
+func TestFanOutCancel(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int) // 永不关闭
+	out := FanOut(ctx, p, 2, in, func(ctx context.Context, v int) int { return v })
+	in <- 1
+	<-out
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("FanOut produced a value after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("FanOut output not closed after cancel")
+	}
+}
+
+func TestFanOutFreed(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	p.Free()
+	in := make(chan int, 2)
+	in <- 1
+	in <- 2
+	close(in)
+	out := FanOut(context.Background(), p, 1, in, func(ctx context.Context, v int) int { return v })
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("FanOut on a freed pool produced a value")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("FanOut output not closed after the submission failed")
+	}
+}
+
+func TestFanIn(t *testing.T) {
+	var got []int
+	for v := range FanIn(context.Background(), feed(1, 2), feed(3), feed[int]()) {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("FanIn = %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := FanIn(ctx, make(chan int))
+	cancel()
+	select {
+	case <-out:
+	case <-time.After(5 * time.Second):
+		t.Fatal("FanIn output not closed after cancel")
+	}
+}
+
+func TestConsume(t *testing.T) {
+	p := New(3, WithLogger(nil))
+	defer p.Free()
+	var sum atomic.Int64
+	// ch 关闭时返回 nil，此时所有已提交的 fn 都已结束
+	if err := Consume(context.Background(), p, feed(1, 2, 3, 4, 5), func(v int) {
+		time.Sleep(time.Millisecond)
+		sum.Add(int64(v))
+	}); err != nil || sum.Load() != 15 {
+		t.Fatalf("Consume = %v, sum %d", err, sum.Load())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	var done atomic.Bool
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Consume(ctx, p, in, func(v int) {
+			time.Sleep(20 * time.Millisecond)
+			done.Store(true)
+		})
+	}()
+	in <- 1
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) || !done.Load() {
+		t.Fatalf("Consume after cancel = %v, running fn finished %v", err, done.Load())
+	}
+
+	p.Free()
+	ch := make(chan int, 1)
+	ch <- 1
+	var pe *PoolClosedError
+	if err := Consume(context.Background(), p, ch, func(int) {}); !errors.As(err, &pe) {
+		t.Fatalf("Consume on a freed pool = %v", err)
+	}
+}
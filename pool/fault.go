@@ -0,0 +1,113 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// FaultConfig 配置混沌测试用的故障注入，各概率取值 [0, 1]，为 0 表示不注入该类故障
+type FaultConfig struct {
+	DelayProb float64       // worker 收到任务后，执行前随机延迟的概率
+	MaxDelay  time.Duration // 延迟时长在 [0, MaxDelay) 内均匀分布
+
+	PanicProb float64 // worker 执行任务前注入 panic 的概率，该任务不会被执行，提交方（Future、Map 等）收到 *PanicError，worker 随之退出
+
+	DropProb     float64       // worker 完成任务后占住容量、暂停接收任务的概率
+	DropDuration time.Duration // 被占住的容量多久后恢复
+
+	Seed int64 // 随机种子，为 0 时使用当前时间
+}
+
+// 注入的 panic 值，便于在 recover/告警中与业务 panic 区分
+type InjectedFault struct {
+	Worker int
+}
+
+func (f InjectedFault) String() string {
+	return fmt.Sprintf("workerpool: injected fault on worker[%03d]", f.Worker)
+}
+
+func (f InjectedFault) Error() string {
+	return f.String()
+}
+
+type faultInjector struct {
+	cfg FaultConfig
+	mu  sync.Mutex // rand.Rand 不是并发安全的
+	rnd *rand.Rand
+}
+
+func newFaultInjector(cfg FaultConfig) *faultInjector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &faultInjector{cfg: cfg, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (f *faultInjector) hit(prob float64) bool {
+	if prob <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rnd.Float64() < prob
+}
+
+func (f *faultInjector) delay() time.Duration {
+	if f.cfg.MaxDelay <= 0 || !f.hit(f.cfg.DelayProb) {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Duration(f.rnd.Int63n(int64(f.cfg.MaxDelay)))
+}
+
+// 任务执行前调用：按配置延迟
+func (p *Pool) injectBeforeTask() {
+	if p.faults == nil {
+		return
+	}
+	if d := p.faults.delay(); d > 0 {
+		p.clock.Sleep(d)
+	}
+}
+
+// faultExec 返回按配置注入 panic 的 t.exec，位于中间件之内，与任务自身的 panic 一样经由观察者与 worker 处理；
+// 注入时 t 不会被执行，先以 *PanicError 通知 t 的提交方，否则 Future、Map 等的完成信号与配额的归还都不会发生
+func (p *Pool) faultExec(worker int, t task) TaskFunc {
+	return func(ctx context.Context) {
+		if !p.faults.hit(p.faults.cfg.PanicProb) {
+			t.exec(ctx)
+			return
+		}
+		fault := InjectedFault{Worker: worker}
+		if c := t.claim; c != nil && c.onDiscard != nil {
+			var reason error = &PanicError{Value: fault, Stack: debug.Stack()}
+			if c.info != nil {
+				reason = &TaskError{Info: *c.info, Err: reason}
+			}
+			c.onDiscard(reason)
+		}
+		panic(fault)
+	}
+}
+
+// 任务执行后调用：按配置让 worker 暂停接活，模拟容量下降；pool 销毁时返回 false
+func (p *Pool) injectAfterTask() bool {
+	if p.faults == nil || p.faults.cfg.DropDuration <= 0 || !p.faults.hit(p.faults.cfg.DropProb) {
+		return true
+	}
+	t := p.clock.NewTimer(p.faults.cfg.DropDuration)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return true
+	case <-p.quit:
+		return false
+	}
+}
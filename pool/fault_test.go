@@ -0,0 +1,104 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFaultPanicResolvesSubmitters(t *testing.T) {
+	p := New(2, WithLogger(nil), WithFaultInjection(FaultConfig{PanicProb: 1, Seed: 1}), WithCallerQuota(1, nil))
+	defer p.Free()
+	isFault := func(err error) bool {
+		var pe *PanicError
+		if !errors.As(err, &pe) {
+			return false
+		}
+		_, ok := pe.Value.(InjectedFault)
+		return ok
+	}
+
+	f, err := Submit(p, func(context.Context) (int, error) { return 1, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := f.Wait()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !isFault(err) {
+			t.Fatalf("Future.Wait: err = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Future.Wait hangs after an injected fault")
+	}
+
+	if _, err := Map(p, []int{1, 2, 3}, func(v int) (int, error) { return v, nil }); !isFault(err) {
+		t.Fatalf("Map: err = %v", err)
+	}
+
+	cb := make(chan error, 1)
+	if err := p.ScheduleWithCallback(func() {}, func(err error, _ time.Duration) { cb <- err }); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-cb; !isFault(err) {
+		t.Fatalf("ScheduleWithCallback: err = %v", err)
+	}
+
+	for i := 0; i < 3; i++ { // 每次注入都归还配额，否则第二次提交即超出
+		if err := p.ScheduleWith(func(context.Context) {}, WithCaller("a")); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+		p.Wait()
+	}
+	if n := p.CallerOutstanding("a"); n != 0 {
+		t.Fatalf("outstanding = %d", n)
+	}
+}
+
+func TestFaultDelay(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	p := New(1, WithLogger(nil), WithClock(clock), WithFaultInjection(FaultConfig{DelayProb: 1, MaxDelay: time.Hour, Seed: 1}))
+	defer p.Free()
+	ran := make(chan struct{})
+	if err := p.Schedule(func() { close(ran) }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+		t.Fatal("task ran without the injected delay")
+	case <-time.After(30 * time.Millisecond):
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("task did not run after the delay")
+	}
+}
+
+func TestFaultDrop(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	p := New(1, WithLogger(nil), WithClock(clock), WithBlock(true), WithFaultInjection(FaultConfig{DropProb: 1, DropDuration: time.Hour, Seed: 1}))
+	defer p.Free()
+	if err := p.Schedule(func() {}); err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan struct{})
+	go p.Schedule(func() { close(ran) })
+	select {
+	case <-ran:
+		t.Fatal("dropped capacity still accepted a task")
+	case <-time.After(30 * time.Millisecond):
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("capacity did not recover after DropDuration")
+	}
+}
@@ -0,0 +1,90 @@
+package workerpool
+
+import "context"
+
+// fifoTurn 使 WithFIFO 下的任务按接受顺序开始执行：每个任务等前一个任务开始后才开始
+type fifoTurn struct {
+	prev    <-chan struct{} // 前一个任务的 started，第一个任务为 nil
+	started chan struct{}   // 本任务开始（或被丢弃）时关闭
+}
+
+// await 在 worker 取走任务后调用，等待轮到该任务
+// worker 按发送顺序取走任务，前一个任务已被其它 worker 取走，因此不会永久等待
+func (t *fifoTurn) await() {
+	if t.prev != nil {
+		<-t.prev
+	}
+	close(t.started)
+}
+
+// lockFIFO 取得 WithFIFO 下的提交顺序，等待者按到达顺序依次取得；持有期间的提交依次交出，
+// block 为 false 时有其它提交在进行即返回 ErrPoolSaturated
+func (p *Pool) lockFIFO(ctx context.Context, block bool) (unlock func(), err error) {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	if !block {
+		select {
+		case p.fifoMu <- struct{}{}:
+		default:
+			return nil, p.saturatedErr()
+		}
+	} else {
+		select {
+		case p.fifoMu <- struct{}{}:
+		case <-p.closing:
+			return nil, p.freedErr()
+		case <-done:
+			return nil, p.waitErr(ctx)
+		}
+	}
+	return func() { <-p.fifoMu }, nil
+}
+
+// nextTurn 返回下一个提交的任务的 fifoTurn，须持有 lockFIFO，交出成功后记入 p.fifoPrev
+func (p *Pool) nextTurn() *fifoTurn {
+	return &fifoTurn{prev: p.fifoPrev, started: make(chan struct{})}
+}
+
+// dispatchFIFO 是 WithFIFO 下的 tryDispatch/waitDispatch：不让新建的 worker 直接执行 t，
+// 任务一律经由 p.tasks 交出，worker 因而按交出顺序取走任务；容量未满时先补充一个 worker 再等待它取走 t
+func (p *Pool) dispatchFIFO(t task, wait bool, stop, done <-chan struct{}) bool {
+	p.enqueue(&t)
+	select {
+	case p.tasks <- t:
+		p.ensureWorker()
+		return true
+	default:
+	}
+	var spawn chan struct{}
+	if wait {
+		spawn = p.active
+	} else {
+		select {
+		case p.active <- struct{}{}:
+			p.newWorker(nil)
+		default:
+			return false
+		}
+	}
+	var discarded <-chan struct{}
+	if t.claim != nil {
+		discarded = t.claim.discarded
+	}
+	for {
+		select {
+		case p.tasks <- t:
+			p.ensureWorker()
+			return true
+		case spawn <- struct{}{}:
+			p.newWorker(nil)
+		case <-stop:
+			return false
+		case <-done:
+			return false
+		case <-discarded:
+			return false
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package workerpool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FlightRecorderConfig 是 WithFlightRecorder 的配置：pool 持续在内存中保留最近一段执行追踪（runtime/trace 的 flight recorder），
+// 出现下列任一异常时把它写入 Dir 下的文件，用 go tool trace 离线分析；Cooldown 内最多写一次
+type FlightRecorderConfig struct {
+	Dir      string        // 快照文件写入的目录（不存在时创建），空表示 os.TempDir()
+	Window   time.Duration // 至少保留的追踪时长，0 表示 10s
+	MaxBytes uint64        // 保留的追踪数据大小上限，0 由 runtime 决定
+
+	OnPanic    bool          // 任务 panic 时快照
+	SlowTask   time.Duration // 任务执行时长达到该值时快照（在任务结束时检查，Window 应不小于它），0 表示不检查
+	QueueSpike int           // 排队的任务数达到该值时快照，0 表示不检查
+	Cooldown   time.Duration // 两次快照的最小间隔，0 表示 1 分钟
+
+	OnSnapshot func(path, reason string, err error) // 可选，每次快照后调用，err 为写入失败的原因
+}
+
+// 快照的原因，出现在文件名与 OnSnapshot 中
+const (
+	FlightReasonPanic      = "panic"
+	FlightReasonSlowTask   = "slow_task"
+	FlightReasonQueueSpike = "queue_spike"
+)
+
+type flightRecorder struct {
+	FlightRecorderConfig
+	trigger chan string // 待写入的快照原因，写入期间的触发被丢弃
+}
+
+// traceRecorder 是 runtime/trace.FlightRecorder 中用到的部分，需要 Go 1.25
+type traceRecorder interface {
+	WriteTo(w io.Writer) (int64, error)
+	Stop()
+}
+
+func WithFlightRecorder(c FlightRecorderConfig) Option { // 任务 panic、执行过慢或排队突增时把最近的执行追踪写入文件，见 FlightRecorderConfig
+	return func(p *Pool) {
+		if c.Window < 0 || c.SlowTask < 0 || c.QueueSpike < 0 || c.Cooldown < 0 {
+			p.invalid("WithFlightRecorder: negative value in %+v", c)
+			return
+		}
+		if !c.OnPanic && c.SlowTask == 0 && c.QueueSpike == 0 {
+			p.invalid("WithFlightRecorder: no trigger configured")
+			return
+		}
+		if c.Dir == "" {
+			c.Dir = os.TempDir()
+		}
+		if c.Window == 0 {
+			c.Window = 10 * time.Second
+		}
+		if c.Cooldown == 0 {
+			c.Cooldown = time.Minute
+		}
+		f := &flightRecorder{FlightRecorderConfig: c, trigger: make(chan string, 1)}
+		p.flight = f
+		p.observers = append(p.observers, func(e Event) {
+			switch {
+			case e.Kind == EventFailed && c.OnPanic:
+				f.fire(FlightReasonPanic)
+			case (e.Kind == EventFinished || e.Kind == EventFailed) && c.SlowTask > 0 && e.Duration >= c.SlowTask:
+				f.fire(FlightReasonSlowTask)
+			case e.Kind == EventSubmitted && c.QueueSpike > 0:
+				p.queued.mu.Lock()
+				n := len(p.queued.m)
+				p.queued.mu.Unlock()
+				if n >= c.QueueSpike {
+					f.fire(FlightReasonQueueSpike)
+				}
+			}
+		})
+	}
+}
+
+// fire 请求一次快照，不阻塞触发它的 worker 或提交方
+func (f *flightRecorder) fire(reason string) {
+	select {
+	case f.trigger <- reason:
+	default:
+	}
+}
+
+// runFlightRecorder 启动录制并写入触发的快照，直到 pool 销毁
+func (p *Pool) runFlightRecorder() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	f := p.flight
+	rec, err := startTraceRecorder(f.Window, f.MaxBytes)
+	if err != nil { // 如同一进程中已有其它 flight recorder
+		p.logf("workerpool: flight recorder: %s\n", err)
+		return
+	}
+	defer rec.Stop()
+	var last time.Time
+	for {
+		var reason string
+		select {
+		case reason = <-f.trigger:
+		case <-p.quit:
+			return
+		}
+		now := p.clock.Now()
+		if !last.IsZero() && now.Sub(last) < f.Cooldown {
+			continue
+		}
+		last = now
+		path, err := f.snapshot(rec, p.name, reason, now)
+		if err != nil {
+			p.logf("workerpool: flight recorder: %s snapshot: %s\n", reason, err)
+		} else {
+			p.logf("workerpool: flight recorder: %s, trace written to %s\n", reason, path)
+		}
+		if f.OnSnapshot != nil {
+			f.OnSnapshot(path, reason, err)
+		}
+	}
+}
+
+func (f *flightRecorder) snapshot(rec traceRecorder, name, reason string, now time.Time) (string, error) {
+	if name == "" {
+		name = "workerpool"
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(f.Dir, fmt.Sprintf("%s-%s-%s.trace", name, reason, now.Format("20060102T150405.000")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	_, err = rec.WriteTo(file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
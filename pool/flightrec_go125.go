@@ -0,0 +1,16 @@
+//go:build go1.25
+
+package workerpool
+
+import (
+	"runtime/trace"
+	"time"
+)
+
+func startTraceRecorder(window time.Duration, maxBytes uint64) (traceRecorder, error) {
+	rec := trace.NewFlightRecorder(trace.FlightRecorderConfig{MinAge: window, MaxBytes: maxBytes})
+	if err := rec.Start(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
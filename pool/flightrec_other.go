@@ -0,0 +1,12 @@
+//go:build !go1.25
+
+package workerpool
+
+import (
+	"errors"
+	"time"
+)
+
+func startTraceRecorder(time.Duration, uint64) (traceRecorder, error) {
+	return nil, errors.New("runtime/trace flight recorder requires Go 1.25")
+}
@@ -0,0 +1,73 @@
+//go:build go1.25
+
+package workerpool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	type snap struct{ path, reason string }
+	snaps := make(chan snap, 4)
+	dir := t.TempDir()
+	p := New(1, WithLogger(nil), WithName("fr"), WithFlightRecorder(FlightRecorderConfig{
+		Dir:      dir,
+		Window:   time.Second,
+		OnPanic:  true,
+		SlowTask: 20 * time.Millisecond,
+		OnSnapshot: func(path, reason string, err error) {
+			if err != nil {
+				t.Errorf("%s snapshot: %v", reason, err)
+			}
+			snaps <- snap{path, reason}
+		},
+	}))
+	p.Schedule(func() { time.Sleep(time.Millisecond) })
+	p.Schedule(func() { panic("boom") })
+	p.Wait()
+	var s snap
+	select {
+	case s = <-snaps:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no snapshot after a task panic")
+	}
+	if s.reason != FlightReasonPanic || filepath.Dir(s.path) != dir || !strings.HasPrefix(filepath.Base(s.path), "fr-panic-") {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if fi, err := os.Stat(s.path); err != nil || fi.Size() == 0 {
+		t.Fatalf("trace file: %v, %v", fi, err)
+	}
+	p.Schedule(func() { time.Sleep(30 * time.Millisecond) }) // 仍在 Cooldown 内
+	p.Wait()
+	p.Free()
+	select {
+	case s := <-snaps:
+		t.Fatalf("snapshot %+v within the cooldown", s)
+	default:
+	}
+
+	// 前一个 pool 销毁时停止录制，同一进程中可以再启动
+	q := New(1, WithLogger(nil), WithFlightRecorder(FlightRecorderConfig{
+		Dir:        dir,
+		SlowTask:   10 * time.Millisecond,
+		OnSnapshot: func(path, reason string, err error) { snaps <- snap{path, reason} },
+	}))
+	defer q.Free()
+	q.Schedule(func() { time.Sleep(20 * time.Millisecond) })
+	select {
+	case s = <-snaps:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no snapshot after a slow task")
+	}
+	if s.reason != FlightReasonSlowTask || !strings.HasPrefix(filepath.Base(s.path), "workerpool-slow_task-") {
+		t.Fatalf("snapshot = %+v", s)
+	}
+
+	if _, err := NewE(1, WithFlightRecorder(FlightRecorderConfig{})); err == nil {
+		t.Fatal("WithFlightRecorder without a trigger accepted")
+	}
+}
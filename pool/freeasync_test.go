@@ -0,0 +1,53 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFreeAsync(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	gate, started := make(chan struct{}), make(chan struct{})
+	if err := p.Schedule(func() { close(started); <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	done := p.FreeAsync()
+	select {
+	case <-done:
+		t.Fatal("FreeAsync completed while a task was still running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if err := p.Schedule(func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Schedule after FreeAsync: err = %v, want ErrPoolClosed", err)
+	}
+	close(gate)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("FreeAsync did not complete")
+	}
+	// 之后的 Free 与 FreeAsync 立即返回
+	p.Free()
+	<-p.FreeAsync()
+}
+
+func TestDrain(t *testing.T) {
+	p := New(1, WithLogger(nil), WithQueueSize(4))
+	var ran atomic.Int32
+	for range 4 {
+		p.Schedule(func() { ran.Add(1) })
+	}
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := ran.Load(); n != 4 {
+		t.Fatalf("ran = %d after Drain, want 4", n)
+	}
+	if err := p.Schedule(func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Schedule after Drain = %v, want ErrPoolClosed", err)
+	}
+}
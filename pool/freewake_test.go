@@ -0,0 +1,50 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 阻塞在 Schedule 中的提交方在 Free 开始后及时返回 ErrPoolClosed，不会永久阻塞
+func TestFreeWakesBlockedSubmitters(t *testing.T) {
+	modes := map[string][]Option{
+		"default":  nil,
+		"queue":    {WithQueueSize(1)},
+		"fifo":     {WithFIFO()},
+		"fair":     {WithFairSubmit()},
+		"priority": {WithPriorityScheduling(0), WithQueueSize(1)},
+		"reserved": {WithReservedWorkers(1)},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			p, _ := newBusyPool(t, opts...)
+			const n = 4
+			errs := make(chan error, n)
+			for i := 0; i < n; i++ {
+				go func() { errs <- p.Schedule(func() {}) }()
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for p.Stats().Blocked == 0 {
+				if time.Now().After(deadline) {
+					t.Fatal("no submitter blocked")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			freed := p.FreeAsync()
+			timeout := time.After(5 * time.Second)
+			for i := 0; i < n; i++ {
+				select {
+				case err := <-errs:
+					// 与 Free 并发时也可能被接受，之后执行或被丢弃
+					if err != nil && !errors.Is(err, ErrPoolClosed) {
+						t.Fatalf("blocked Schedule returned %v", err)
+					}
+				case <-timeout:
+					t.Fatalf("%d submitters still blocked after Free", n-i)
+				}
+			}
+			<-freed
+		})
+	}
+}
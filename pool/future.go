@@ -0,0 +1,232 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Future 表示一个提交到 pool 的、带返回值的任务的结果
+type Future[R any] struct {
+	done chan struct{}
+	val  R
+	err  error
+
+	mu        sync.Mutex
+	p         *Pool
+	claim     *taskClaim         // 任务尚未开始时可以据此将其移除
+	cancel    context.CancelFunc // 任务开始执行后才有
+	canceled  bool
+	callbacks []func() // 任务结束后依次调用，见 onDone
+}
+
+// PanicError 是任务 panic 时 Future 得到的错误
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: task panic: %v", e.Value)
+}
+
+// Submit 将 fn 提交到 pool 执行并返回其 Future，ctx 的用法与 TaskFunc 相同，
+// 另外在调用 Future.Cancel 后被取消；fn panic 时 Future 得到 *PanicError
+func Submit[R any](p *Pool, fn func(ctx context.Context) (R, error)) (*Future[R], error) {
+	f := newFuture[R]()
+	if err := f.submit(p, fn, p.block); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// TrySubmit 与 Submit 相同，但与 TryScheduleFunc 一样，没有空闲 worker 时立即返回 ErrPoolSaturated；
+// 被接受后未执行即被丢弃（Purge、Free）时 Future 得到丢弃的原因，等待方不会永远阻塞
+func TrySubmit[R any](p *Pool, fn func(ctx context.Context) (R, error)) (*Future[R], error) {
+	f := newFuture[R]()
+	if err := f.submit(p, fn, false); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Future[R]) submit(p *Pool, fn func(ctx context.Context) (R, error), block bool) error {
+	return f.submitOr(p, fn, block, nil)
+}
+
+// submitOr 与 submit 相同，任务被丢弃时先调用 discarded（可以为 nil）再解析 Future
+func (f *Future[R]) submitOr(p *Pool, fn func(ctx context.Context) (R, error), block bool, discarded func()) error {
+	c := newTaskClaim()
+	c.onDiscard = func(reason error) {
+		if discarded != nil {
+			discarded()
+		}
+		f.resolve(*new(R), reason)
+	}
+	f.mu.Lock()
+	f.p, f.claim = p, c
+	f.mu.Unlock()
+	return p.submit(nil, task{fnc: func(ctx context.Context) { f.run(ctx, fn) }, claim: c}, block)
+}
+
+func newFuture[R any]() *Future[R] {
+	return &Future[R]{done: make(chan struct{})}
+}
+
+func (f *Future[R]) run(ctx context.Context, fn func(ctx context.Context) (R, error)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	f.mu.Lock()
+	f.cancel = cancel
+	if f.canceled {
+		cancel()
+	}
+	f.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			f.resolve(*new(R), &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+	v, err := fn(ctx)
+	f.resolve(v, err)
+}
+
+// resolve 只有第一次调用生效：任务被丢弃时 Future 可能已经由 onDiscard 解析
+func (f *Future[R]) resolve(v R, err error) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		return
+	default:
+	}
+	f.val, f.err = v, err
+	close(f.done)
+	callbacks := f.callbacks
+	f.callbacks = nil
+	f.mu.Unlock()
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// onDone 在任务结束后调用 fn，已结束时立即在当前 goroutine 调用
+func (f *Future[R]) onDone(fn func()) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		fn()
+	default:
+		f.callbacks = append(f.callbacks, fn)
+		f.mu.Unlock()
+	}
+}
+
+// Then 在 f 成功结束后将 fn(f 的结果) 提交到 p 执行，返回 fn 的 Future；
+// f 失败时跳过 fn，返回的 Future 直接得到 f 的错误
+func Then[R, S any](p *Pool, f *Future[R], fn func(ctx context.Context, v R) (S, error)) *Future[S] {
+	return chain(p, f, func(ctx context.Context, v R, _ error) (S, error) {
+		return fn(ctx, v)
+	}, func(err error) bool { return err == nil })
+}
+
+// Catch 在 f 失败后将 fn(f 的错误) 提交到 p 执行，fn 可以返回一个替代的结果或新的错误；
+// f 成功时跳过 fn，返回的 Future 直接得到 f 的结果
+func Catch[R any](p *Pool, f *Future[R], fn func(ctx context.Context, err error) (R, error)) *Future[R] {
+	return chain(p, f, func(ctx context.Context, _ R, err error) (R, error) {
+		return fn(ctx, err)
+	}, func(err error) bool { return err != nil })
+}
+
+// chain 在 f 结束后，若 run(f 的错误) 为 true 则在 p 上执行 fn，否则直接以 f 的结果解析后继
+// 提交在新的 goroutine 中进行，避免 f 在 worker 上结束时阻塞该 worker
+func chain[R, S any](p *Pool, f *Future[R], fn func(ctx context.Context, v R, err error) (S, error), run func(err error) bool) *Future[S] {
+	next := newFuture[S]()
+	f.onDone(func() {
+		v, err := f.val, f.err
+		if !run(err) {
+			var s S
+			if r, ok := any(v).(S); ok { // Catch 中 R 与 S 相同，原样传递结果
+				s = r
+			}
+			next.resolve(s, err)
+			return
+		}
+		go func() {
+			if serr := next.submit(p, func(ctx context.Context) (S, error) { return fn(ctx, v, err) }, p.block); serr != nil {
+				var zero S
+				next.resolve(zero, serr)
+			}
+		}()
+	})
+	return next
+}
+
+// Done 返回一个在任务结束时关闭的 channel
+func (f *Future[R]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait 阻塞直到任务结束，返回其结果
+func (f *Future[R]) Wait() (R, error) {
+	<-f.done
+	return f.val, f.err
+}
+
+// WaitContext 阻塞直到任务结束或 ctx 被取消，后者返回 ctx.Err()，任务本身不受影响
+func (f *Future[R]) WaitContext(ctx context.Context) (R, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+// QueueInfo 返回任务当前的排队情况：任务被放入缓冲（见 WithQueueSize）尚未被 worker 取走时，
+// 给出它在队列中的位置与按最近吞吐估计的等待时间，调用方可据此决定继续等待、降级或对冲请求
+func (f *Future[R]) QueueInfo() QueueInfo {
+	f.mu.Lock()
+	p, c := f.p, f.claim
+	f.mu.Unlock()
+	if p == nil {
+		return QueueInfo{}
+	}
+	return p.queueInfo(c)
+}
+
+// Explain 说明任务为什么还没有开始执行：在队列中的位置、正忙的 worker 各在执行哪个任务、是否处于暂停、限速或 WithBlackout 的时段，
+// 把“任务为什么卡住”从一次排查变成一次调用；任务已开始、已结束或已被丢弃时 Pending 为 false
+func (f *Future[R]) Explain() Explanation {
+	f.mu.Lock()
+	p, c := f.p, f.claim
+	f.mu.Unlock()
+	if p == nil {
+		return Explanation{}
+	}
+	return p.explain(c)
+}
+
+// Cancel 取消任务：尚未开始的任务直接移除，Future 得到 ErrTaskDiscarded，返回 true；
+// 已开始的任务返回 false，其 ctx 被取消，需自行响应 ctx.Done()
+func (f *Future[R]) Cancel() bool {
+	f.mu.Lock()
+	f.canceled = true
+	if f.cancel != nil {
+		f.cancel()
+	}
+	p, c := f.p, f.claim
+	f.mu.Unlock()
+	return c != nil && p.discard(c, ErrTaskDiscarded)
+}
+
+// Boost 把尚未开始的任务的优先级提升到 priority，返回是否提升，其余与 Pool.BoostFunc 相同
+func (f *Future[R]) Boost(priority int) bool {
+	f.mu.Lock()
+	p, c := f.p, f.claim
+	f.mu.Unlock()
+	return c != nil && p.applied().boost(func(t *task) bool { return t.claim == c }, priority) > 0
+}
@@ -0,0 +1,86 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestThen(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	f, err := Submit(p, func(ctx context.Context) (int, error) { return 21, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := Then(p, f, func(ctx context.Context, v int) (string, error) {
+		if _, ok := WorkerID(ctx); !ok {
+			return "", errors.New("not run on a worker")
+		}
+		return strconv.Itoa(v * 2), nil
+	})
+	if v, err := g.Wait(); v != "42" || err != nil {
+		t.Fatalf("Then = %q, %v", v, err)
+	}
+
+	// f 失败时跳过 fn，错误原样传递
+	boom := errors.New("boom")
+	f, _ = Submit(p, func(ctx context.Context) (int, error) { return 0, boom })
+	called := false
+	g = Then(p, f, func(ctx context.Context, v int) (string, error) { called = true; return "", nil })
+	if _, err := g.Wait(); !errors.Is(err, boom) || called {
+		t.Fatalf("Then after a failure = %v, fn called %v", err, called)
+	}
+
+	// fn 的 panic 以 *PanicError 返回
+	f, _ = Submit(p, func(ctx context.Context) (int, error) { return 1, nil })
+	g = Then(p, f, func(ctx context.Context, v int) (string, error) { panic("then") })
+	var pe *PanicError
+	if _, err := g.Wait(); !errors.As(err, &pe) {
+		t.Fatalf("Then with a panic = %v", err)
+	}
+}
+
+func TestCatch(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	boom := errors.New("boom")
+	f, _ := Submit(p, func(ctx context.Context) (int, error) { return 0, boom })
+	var caught error
+	g := Catch(p, f, func(ctx context.Context, err error) (int, error) {
+		caught = err
+		return -1, nil
+	})
+	if v, err := g.Wait(); v != -1 || err != nil || !errors.Is(caught, boom) {
+		t.Fatalf("Catch = %d, %v, caught %v", v, err, caught)
+	}
+
+	// f 成功时跳过 fn，结果原样传递
+	f, _ = Submit(p, func(ctx context.Context) (int, error) { return 7, nil })
+	called := false
+	g = Catch(p, f, func(ctx context.Context, err error) (int, error) { called = true; return 0, nil })
+	if v, err := g.Wait(); v != 7 || err != nil || called {
+		t.Fatalf("Catch after a success = %d, %v, fn called %v", v, err, called)
+	}
+
+	// 链式调用：Then 的失败由后面的 Catch 处理
+	f, _ = Submit(p, func(ctx context.Context) (int, error) { return 1, nil })
+	h := Catch(p, Then(p, f, func(ctx context.Context, v int) (int, error) { return 0, boom }),
+		func(ctx context.Context, err error) (int, error) { return 0, errors.New("wrapped: " + err.Error()) })
+	if _, err := h.Wait(); err == nil || err.Error() != "wrapped: boom" {
+		t.Fatalf("Then + Catch = %v", err)
+	}
+}
+
+func TestThenFreed(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	f, _ := Submit(p, func(ctx context.Context) (int, error) { return 1, nil })
+	f.Wait()
+	p.Free()
+	// 后继提交失败时，返回的 Future 得到提交的错误而不是永远阻塞
+	var pe *PoolClosedError
+	if _, err := Then(p, f, func(ctx context.Context, v int) (int, error) { return v, nil }).Wait(); !errors.As(err, &pe) {
+		t.Fatalf("Then on a freed pool = %v", err)
+	}
+}
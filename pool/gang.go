@@ -0,0 +1,50 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScheduleGang 提交一组需要同时执行的任务（如以屏障同步的并行算法）：先占住 len(tasks) 个 worker，
+// 全部到手后才让它们同时开始，不会出现一部分已开始、其余还在排队而互相等待的情况；任务数超过容量时返回错误
+// 与 Schedule 一样遵循 WithBlock：不阻塞时凑不齐 worker 即返回 ErrPoolSaturated，没有任务开始执行
+// 返回 nil 后任务计入 Wait；只有 Free 恰好在开始前发生时才可能只开始一部分，此时返回 ErrPoolClosed
+func (p *Pool) ScheduleGang(tasks ...Task) error {
+	p = p.applied()
+	n := len(tasks)
+	switch {
+	case n == 0:
+		return nil
+	case n > p.capacity:
+		return fmt.Errorf("%w: gang of %d exceeds capacity %d", ErrInvalidOption, n, p.capacity)
+	}
+	p.heavyOnce.Do(func() { p.heavy = p.Semaphore() })
+	s := p.heavy
+	if p.block {
+		s.acq <- struct{}{} // 持有者在 pool 销毁时同样会返回
+	} else {
+		select {
+		case s.acq <- struct{}{}:
+		default:
+			return p.saturatedErr()
+		}
+	}
+	got, err := s.take(context.Background(), n, p.block)
+	<-s.acq
+	if err != nil {
+		return err
+	}
+	p.inflight.add(n)
+	for i, t := range tasks {
+		select {
+		case got[i] <- func() {
+			defer p.inflight.add(-1)
+			t()
+		}:
+		case <-p.quit:
+			p.inflight.add(i - n)
+			return p.freedErr()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduleGang(t *testing.T) {
+	p := New(3, WithLogger(nil))
+	defer p.Free()
+	var arrived sync.WaitGroup
+	arrived.Add(3)
+	all := make(chan struct{})
+	go func() { arrived.Wait(); close(all) }()
+	var together atomic.Int32
+	member := func() {
+		arrived.Done()
+		select {
+		case <-all: // 三个任务同时在执行
+			together.Add(1)
+		case <-time.After(5 * time.Second):
+		}
+	}
+	if err := p.ScheduleGang(member, member, member); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	if n := together.Load(); n != 3 {
+		t.Fatalf("%d of 3 gang members ran together", n)
+	}
+}
+
+func TestScheduleGangAllOrNothing(t *testing.T) {
+	p, release := newBusyPool(t, WithBlock(false))
+	defer release()
+	var ran atomic.Int32
+	if err := p.ScheduleGang(func() { ran.Add(1) }); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated", err)
+	}
+	if err := p.ScheduleGang(func() {}, func() {}); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("gang larger than capacity: err = %v, want ErrInvalidOption", err)
+	}
+	if ran.Load() != 0 {
+		t.Fatal("gang member ran without a free worker")
+	}
+
+	q := New(4, WithBlock(false), WithLogger(nil))
+	defer q.Free()
+	gate := make(chan struct{})
+	for range 2 {
+		if err := q.TryScheduleFunc(func(ctx context.Context) { <-gate }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 只有 2 个空闲 worker，3 个任务的组一个也不开始，已占住的 worker 随即释放
+	if err := q.ScheduleGang(func() { ran.Add(1) }, func() { ran.Add(1) }, func() { ran.Add(1) }); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated", err)
+	}
+	if ran.Load() != 0 {
+		t.Fatal("part of the gang started")
+	}
+	close(gate)
+	q.Wait()
+	deadline := time.Now().Add(5 * time.Second)
+	for q.ScheduleGang(func() { ran.Add(1) }, func() { ran.Add(1) }, func() { ran.Add(1) }, func() { ran.Add(1) }) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("gang of capacity size never started on an idle pool")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	q.Wait()
+	if n := ran.Load(); n != 4 {
+		t.Fatalf("ran = %d, want 4", n)
+	}
+}
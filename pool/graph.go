@@ -0,0 +1,142 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDependencyFailed = errors.New("dependency failed")
+	ErrGraphCycle       = errors.New("dependency cycle")
+)
+
+// Graph 描述一组带依赖关系的任务，Run 时在依赖满足的前提下以最大并发在 pool 上执行
+type Graph struct {
+	p     *Pool
+	nodes map[string]*graphNode
+	order []string // 按 Add 的顺序，使调度与错误输出稳定
+}
+
+type graphNode struct {
+	name       string
+	fn         func(ctx context.Context) error
+	deps       []string
+	dependents []*graphNode
+	waiting    int // 尚未成功完成的依赖数
+	err        error
+}
+
+func NewGraph(p *Pool) *Graph {
+	return &Graph{p: p, nodes: make(map[string]*graphNode)}
+}
+
+// Add 添加名为 name 的任务，它在 deps 中的任务全部成功后才会执行；deps 可以在之后再添加
+func (g *Graph) Add(name string, fn func(ctx context.Context) error, deps ...string) error {
+	if _, ok := g.nodes[name]; ok {
+		return fmt.Errorf("graph: duplicate task %q", name)
+	}
+	g.nodes[name] = &graphNode{name: name, fn: fn, deps: deps}
+	g.order = append(g.order, name)
+	return nil
+}
+
+// Run 执行所有任务并等待结束：依赖未知或存在环时不执行任何任务直接返回错误；
+// 任务失败（或 panic）时依赖它的任务不再执行，记为 ErrDependencyFailed，其余分支照常执行；
+// ctx 取消后不再启动新的任务。返回的错误以 "task name: ..." 的形式合并了所有未成功的任务
+func (g *Graph) Run(ctx context.Context) error {
+	if err := g.link(); err != nil {
+		return err
+	}
+	done := make(chan *graphNode, len(g.nodes))
+	finished := 0
+	start := func(n *graphNode) {
+		if err := ctx.Err(); err != nil {
+			n.err = err
+			done <- n
+			return
+		}
+		err := g.p.scheduleOr(task{fnc: func(context.Context) {
+			_, n.err = protect(ctx, func(ctx context.Context) (struct{}, error) { return struct{}{}, n.fn(ctx) })
+			done <- n
+		}}, func(reason error) {
+			n.err = reason
+			done <- n
+		})
+		if err != nil {
+			n.err = err
+			done <- n
+		}
+	}
+	for _, name := range g.order {
+		if n := g.nodes[name]; n.waiting == 0 {
+			start(n)
+		}
+	}
+	for finished < len(g.nodes) {
+		n := <-done
+		finished++
+		for _, d := range n.dependents {
+			if n.err != nil && d.err == nil {
+				d.err = fmt.Errorf("%w: %s", ErrDependencyFailed, n.name)
+			}
+			if d.waiting--; d.waiting > 0 {
+				continue
+			}
+			if d.err != nil {
+				done <- d // 未执行，直接视为结束以继续向下传播
+				continue
+			}
+			start(d)
+		}
+	}
+	var errs []error
+	for _, name := range g.order {
+		if err := g.nodes[name].err; err != nil {
+			errs = append(errs, fmt.Errorf("task %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// link 重置上次 Run 的状态，建立依赖关系并检查未知依赖与环
+func (g *Graph) link() error {
+	for _, n := range g.nodes {
+		n.dependents, n.waiting, n.err = nil, len(n.deps), nil
+	}
+	for _, name := range g.order {
+		n := g.nodes[name]
+		for _, dep := range n.deps {
+			d, ok := g.nodes[dep]
+			if !ok {
+				return fmt.Errorf("graph: task %q depends on unknown task %q", name, dep)
+			}
+			d.dependents = append(d.dependents, n)
+		}
+	}
+	// Kahn 算法：能按拓扑序全部取出则无环
+	waiting := make(map[*graphNode]int, len(g.nodes))
+	var queue []*graphNode
+	for _, name := range g.order {
+		n := g.nodes[name]
+		waiting[n] = n.waiting
+		if n.waiting == 0 {
+			queue = append(queue, n)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, d := range n.dependents {
+			if waiting[d]--; waiting[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	if visited < len(g.nodes) {
+		return ErrGraphCycle
+	}
+	return nil
+}
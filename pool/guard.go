@@ -0,0 +1,199 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ErrorRateGuard 是整个 pool 的错误率熔断：窗口内失败（panic、Job 处理函数返回错误或 MarkFailed）的比例超过阈值时
+// 暂停分发，排队的任务留在 pool 中等待；之后每隔 ProbeInterval 调用一次 Probe，成功后恢复分发
+type ErrorRateGuard struct {
+	Threshold float64       // 失败比例的阈值，如 0.5
+	Window    time.Duration // 统计的时间窗口，0 表示 1 分钟
+	MinTasks  int           // 窗口内至少执行了这么多任务才判断，避免少量任务的偶然失败触发，0 表示 10
+
+	Probe         func(ctx context.Context) error // 检查下游是否已恢复，nil 表示暂停 ProbeInterval 后直接恢复
+	ProbeInterval time.Duration                   // 0 表示 5s
+
+	OnPause  func(rate float64) // 暂停时调用，rate 为触发暂停的失败比例，可用于告警
+	OnResume func()             // 探测成功、恢复分发时调用
+}
+
+// guardSlots 是统计窗口的分段数，窗口按段滚动
+const guardSlots = 10
+
+func WithErrorRateGuard(g ErrorRateGuard) Option { // 失败比例超过阈值时自动暂停分发，探测成功后恢复，见 ErrorRateGuard
+	return func(p *Pool) {
+		if g.Threshold <= 0 || g.Threshold > 1 {
+			p.invalid("error rate threshold %g must be in (0, 1]", g.Threshold)
+			return
+		}
+		if g.Window <= 0 {
+			g.Window = time.Minute
+		}
+		if g.MinTasks <= 0 {
+			g.MinTasks = 10
+		}
+		if g.ProbeInterval <= 0 {
+			g.ProbeInterval = 5 * time.Second
+		}
+		p.guard = &errorGuard{ErrorRateGuard: g}
+	}
+}
+
+// MarkFailed 把 ctx 所在 worker 当前执行的任务计为失败，供不返回错误的 Task 计入 WithErrorRateGuard 的失败比例；
+// ctx 不是 TaskFunc 收到的 ctx 时不做任何事
+func MarkFailed(ctx context.Context) {
+	if w, ok := ctx.Value(workerKey{}).(*worker); ok {
+		w.failed = true
+	}
+}
+
+type errorGuard struct {
+	ErrorRateGuard
+
+	mu      sync.Mutex
+	slots   [guardSlots]guardSlot
+	probing bool // 已暂停，探测 goroutine 正在运行
+}
+
+type guardSlot struct {
+	seq           int64 // 该段对应的时间段编号，不是当前窗口内的段视为空
+	total, failed int
+}
+
+// record 记录一个任务的结果，窗口内的失败比例超过阈值时暂停 p 并开始探测
+func (g *errorGuard) record(p *Pool, failed bool) {
+	now := p.clock.Now()
+	span := max(g.Window/guardSlots, 1)
+	seq := now.UnixNano() / int64(span)
+	g.mu.Lock()
+	s := &g.slots[seq%guardSlots]
+	if s.seq != seq {
+		*s = guardSlot{seq: seq}
+	}
+	s.total++
+	if failed {
+		s.failed++
+	}
+	if g.probing {
+		g.mu.Unlock()
+		return
+	}
+	var total, fails int
+	for _, s := range g.slots {
+		if s.seq > seq-guardSlots {
+			total, fails = total+s.total, fails+s.failed
+		}
+	}
+	rate := float64(fails) / float64(total)
+	if total < g.MinTasks || rate < g.Threshold || !p.enterSubmit() {
+		g.mu.Unlock()
+		return
+	}
+	g.probing = true
+	p.wg.Add(1) // 调用方是执行中的 worker，经由 enterSubmit 确认 pool 尚未销毁
+	p.submitters.Done()
+	g.mu.Unlock()
+	p.pause.pause()
+	p.logf("workerpool: error rate %.2f over %s, pause dispatch\n", rate, g.Window)
+	if g.OnPause != nil {
+		g.OnPause(rate)
+	}
+	go g.probe(p)
+}
+
+// probe 每隔 ProbeInterval 探测一次，成功后清空窗口并恢复分发
+func (g *errorGuard) probe(p *Pool) {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	ticker := p.clock.NewTicker(g.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+		case <-p.quit:
+			return
+		}
+		if g.Probe != nil {
+			if err := g.Probe(p.ctx); err != nil {
+				p.logf("workerpool: error rate probe failed[%s], stay paused\n", err)
+				continue
+			}
+		}
+		g.mu.Lock()
+		g.slots = [guardSlots]guardSlot{}
+		g.probing = false
+		g.mu.Unlock()
+		p.pause.resume()
+		p.logf("workerpool: error rate probe succeeded, resume dispatch\n")
+		if g.OnResume != nil {
+			g.OnResume()
+		}
+		return
+	}
+}
+
+// pauseGate 暂停时 worker 在开始下一个任务前等待，已在执行的任务不受影响
+type pauseGate struct {
+	mu      sync.Mutex
+	resumed chan struct{} // 暂停期间不为 nil，恢复时关闭
+}
+
+func (g *pauseGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		g.resumed = make(chan struct{})
+	}
+}
+
+func (g *pauseGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		close(g.resumed)
+		g.resumed = nil
+	}
+}
+
+func (g *pauseGate) paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resumed != nil
+}
+
+// wait 阻塞到恢复分发或 p 销毁
+func (g *pauseGate) wait(p *Pool) {
+	g.mu.Lock()
+	ch := g.resumed
+	g.mu.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case <-ch:
+	case <-p.quit:
+	}
+}
+
+// Pause 暂停分发：执行中的任务不受影响，worker 在开始下一个任务前等待 Resume，提交照常被接受并排队
+func (p *Pool) Pause() {
+	p = p.applied()
+	p.pause.pause()
+	p.logf("workerpool: pause dispatch\n")
+}
+
+// Resume 恢复分发，同时解除 WithErrorRateGuard 的自动暂停
+func (p *Pool) Resume() {
+	p = p.applied()
+	p.pause.resume()
+	p.logf("workerpool: resume dispatch\n")
+}
+
+// Paused 返回是否已暂停分发（Pause 或 WithErrorRateGuard）
+func (p *Pool) Paused() bool {
+	p = p.applied()
+	return p.pause.paused()
+}
@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestErrorRateGuard(t *testing.T) {
+	var healthy atomic.Bool
+	paused, resumed := make(chan float64, 1), make(chan struct{}, 1)
+	p := New(2, WithLogger(nil), WithErrorRateGuard(ErrorRateGuard{
+		Threshold: 0.5,
+		MinTasks:  4,
+		Probe: func(context.Context) error {
+			if !healthy.Load() {
+				return errors.New("downstream still down")
+			}
+			return nil
+		},
+		ProbeInterval: 5 * time.Millisecond,
+		OnPause:       func(rate float64) { paused <- rate },
+		OnResume:      func() { resumed <- struct{}{} },
+	}))
+	defer p.Free()
+	p.ScheduleFunc(func(ctx context.Context) {})
+	p.Schedule(func() { panic("boom") })
+	p.ScheduleFunc(func(ctx context.Context) { MarkFailed(ctx) })
+	p.Wait()
+	if p.Paused() {
+		t.Fatal("paused before MinTasks tasks ran")
+	}
+	p.ScheduleFunc(func(ctx context.Context) { MarkFailed(ctx) })
+	select {
+	case rate := <-paused:
+		if rate != 0.75 {
+			t.Fatalf("pause rate = %g, want 0.75", rate)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("guard did not pause at 3 failures of 4")
+	}
+	if !p.Paused() {
+		t.Fatal("Paused = false after OnPause")
+	}
+	var ran atomic.Bool
+	p.Schedule(func() { ran.Store(true) })
+	time.Sleep(30 * time.Millisecond) // 期间探测一直失败
+	if ran.Load() {
+		t.Fatal("task dispatched while paused")
+	}
+	healthy.Store(true)
+	select {
+	case <-resumed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("guard did not resume after the probe succeeded")
+	}
+	p.Wait()
+	if !ran.Load() || p.Paused() {
+		t.Fatalf("ran = %t, paused = %t after resume", ran.Load(), p.Paused())
+	}
+}
+
+func TestErrorRateGuardInvalid(t *testing.T) {
+	if _, err := NewE(1, WithErrorRateGuard(ErrorRateGuard{Threshold: 2})); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption", err)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	p.Pause()
+	var ran atomic.Bool
+	if err := p.Schedule(func() { ran.Store(true) }); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ran.Load() || !p.Paused() {
+		t.Fatalf("ran = %t, paused = %t while paused", ran.Load(), p.Paused())
+	}
+	p.Resume()
+	p.Wait()
+	if !ran.Load() {
+		t.Fatal("task did not run after Resume")
+	}
+}
@@ -0,0 +1,101 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPoolReplaced 是 HandoffTo 销毁旧 pool 的原因
+var ErrPoolReplaced = errors.New("pool replaced")
+
+// HandoffTo 以 q 替换 p，用于变更无法原地修改的选项：之后提交到 p 的任务（包括阻塞中的提交）一律转交给 q，
+// p 中已接受、尚未开始的任务按原顺序迁移到 q，执行中的任务在 p 上执行完后 p 被销毁（原因为 ErrPoolReplaced），
+// 整个过程中不拒绝任何任务；返回时迁移已完成
+// 迁移的任务保留 TaskInfo、标签、优先级与丢弃回调，WithFIFO 的开始顺序只在 p 与 q 各自内部保证；
+// Enqueue 提交的 Job 留在 p 的队列中，不会迁移
+func (p *Pool) HandoffTo(q *Pool) error {
+	p = p.applied()
+	if q == p {
+		return errors.New("workerpool: HandoffTo the pool itself")
+	}
+	select {
+	case <-q.closing:
+		return fmt.Errorf("workerpool: HandoffTo a freed pool: %w", q.freedErr())
+	default:
+	}
+	p.closeMu.Lock()
+	if p.closed {
+		p.closeMu.Unlock()
+		return p.freedErr()
+	}
+	p.successor.Store(q)
+	p.closeMu.Unlock()
+	p.free(ErrPoolReplaced)
+	return nil
+}
+
+// migrate 与 drain 类似，在 Free 通知 worker 退出前调用：缓冲中的任务交给接替的 pool 而不是在 p 上执行，
+// 之后等待执行中的任务结束
+func (p *Pool) migrate() {
+	p.stopPump()
+	p.submitters.Wait() // 之后的提交都直接转交给接替的 pool
+	for {
+		select {
+		case t := <-p.tasks:
+			p.drop(t)
+			continue
+		default:
+		}
+		break
+	}
+	if p.sched != nil {
+		p.closeScheduler()
+	}
+	<-p.inflight.wait()
+}
+
+// moveTo 将 p 已接受、尚未开始的 t 提交到 q，q 拒绝时视为丢弃
+func (p *Pool) moveTo(q *Pool, t task) {
+	if t.counted {
+		p.inflight.add(-1)
+	}
+	if !p.start(t) {
+		return // 已被 Purge 丢弃
+	}
+	if t.turn != nil {
+		t.turn.await()
+	}
+	if t.tag != nil {
+		p.tags.remove(t.tag)
+	}
+	err := q.submit(nil, t.migrated(), true)
+	if err == nil || errors.Is(err, ErrTaskDiscarded) { // 在 q 中被丢弃时已由 q 通知
+		return
+	}
+	if t.info != nil {
+		err = &TaskError{Info: *t.info, Err: err}
+	}
+	if t.claim != nil && t.claim.onDiscard != nil {
+		t.claim.onDiscard(err)
+	}
+	if p.onDiscard != nil {
+		p.onDiscard(err)
+	}
+}
+
+// migrated 返回可以提交到另一个 pool 的 t 的副本，不含 p 内部的登记与排队状态
+func (t task) migrated() task {
+	nt := task{
+		fn: t.fn, fnc: t.fnc, ctx: t.ctx, argFn: t.argFn, arg: t.arg, argRun: t.argRun,
+		detached: t.detached, urgent: t.urgent, prio: t.prio, info: t.info,
+		budget: t.budget, hasBudget: t.hasBudget, id: t.id, enqueued: t.enqueued,
+	}
+	if t.claim != nil {
+		nt.claim = newTaskClaim()
+		nt.claim.onDiscard, nt.claim.info = t.claim.onDiscard, t.claim.info
+	}
+	if t.tag != nil {
+		nt.tag = &taskTag{tags: t.tag.tags}
+	}
+	return nt
+}
@@ -0,0 +1,62 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandoffTo(t *testing.T) {
+	old := New(1, WithLogger(nil), WithQueueSize(2), WithWorkerInit(func(int) (any, error) { return "old", nil }))
+	next := New(4, WithLogger(nil), WithWorkerInit(func(int) (any, error) { return "next", nil }))
+	defer next.Free()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	old.Schedule(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	var onOld, onNext atomic.Int32
+	record := func(ctx context.Context) {
+		if WorkerValue(ctx) == "old" {
+			onOld.Add(1)
+		} else {
+			onNext.Add(1)
+		}
+	}
+	for i := 0; i < 2; i++ { // 进入 old 的缓冲
+		if err := old.ScheduleFunc(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocked := make(chan error)
+	go func() { blocked <- old.ScheduleFunc(record) }() // 缓冲已满，阻塞在 old 上
+	waitQueued(t, old, 3)
+
+	handed := make(chan error)
+	go func() { handed <- old.HandoffTo(next) }()
+	if err := <-blocked; err != nil {
+		t.Fatalf("blocked submitter: %v", err)
+	}
+	select {
+	case err := <-handed:
+		t.Fatalf("HandoffTo returned %v before the running task finished", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-handed; err != nil {
+		t.Fatal(err)
+	}
+	if err := old.ScheduleFunc(record); err != nil { // 之后的提交转交给 next
+		t.Fatal(err)
+	}
+	next.Wait()
+	if onOld.Load() != 0 || onNext.Load() != 4 {
+		t.Fatalf("ran %d on old and %d on next, want 0 and 4", onOld.Load(), onNext.Load())
+	}
+	if err := next.HandoffTo(old); err == nil {
+		t.Fatal("handing off to a freed pool should fail")
+	}
+}
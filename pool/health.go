@@ -0,0 +1,98 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrWorkerStuck  = errors.New("worker stuck")
+	ErrProbeTimeout = errors.New("health probe timed out")
+)
+
+// 未设置 WithHealthcheck 时探测任务的等待上限
+const defaultProbeTimeout = time.Second
+
+// Healthcheck 检查 p 是否健康，供 Kubernetes 等的存活/就绪探针使用，健康时返回 nil，否则返回以下错误的组合（errors.Join）：
+//   - pool 已销毁时返回 ErrPoolClosed
+//   - 所有 worker 都在执行任务且排队已满时返回 ErrPoolSaturated
+//   - 设置了 WithHealthcheck 的 stuckAfter 时，每个执行同一任务超过该时长的 worker 各返回一个包装 ErrWorkerStuck 的错误，
+//     WithHeartbeat 下仍在发送心跳的任务除外；心跳已停止的任务同样返回 ErrWorkerStuck
+//   - 未饱和时提交一个空任务，它未在探测超时内执行完时返回 ErrProbeTimeout，说明分发已无响应
+//
+// 探测任务不计入 Wait
+func (p *Pool) Healthcheck() error {
+	p = p.applied()
+	select {
+	case <-p.closing:
+		return p.freedErr()
+	default:
+	}
+	now := p.clock.Now()
+	busy := 0
+	var errs []error
+	p.live.Range(func(_, v any) bool {
+		w := v.(*worker)
+		since := w.busySince.Load()
+		if since == 0 {
+			return true
+		}
+		busy++
+		task := "a task"
+		if info := w.task.Load(); info != nil {
+			task = info.String()
+		}
+		alive := p.heartbeat != nil && w.beats.Load() > 0 // 仍在发送心跳的任务慢而非卡死
+		switch d := now.Sub(time.Unix(0, since)); {
+		case w.stalled.Load():
+			errs = append(errs, fmt.Errorf("%w: worker[%03d] running %s for %s, heartbeat stopped", ErrWorkerStuck, w.id, task, d))
+		case !alive && p.stuckAfter > 0 && d > p.stuckAfter:
+			errs = append(errs, fmt.Errorf("%w: worker[%03d] running %s for %s", ErrWorkerStuck, w.id, task, d))
+		}
+		return true
+	})
+	if busy >= p.capacity-int(p.parked.Load()) && p.queueFull() {
+		errs = append(errs, fmt.Errorf("%w: %d workers busy", ErrPoolSaturated, busy))
+		return errors.Join(errs...)
+	}
+	if err := p.probe(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// queueFull 表示排队的任务已达 WithQueueSize，不缓冲时总是为 true
+func (p *Pool) queueFull() bool {
+	if p.sched != nil {
+		return p.sched.len() >= p.queueSize
+	}
+	return len(p.tasks) >= cap(p.tasks)
+}
+
+// probe 提交一个空任务并等待它执行完
+func (p *Pool) probe() error {
+	timeout := p.probeTimeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	timer := p.clock.AfterFunc(timeout, cancel)
+	defer timer.Stop()
+	done := make(chan struct{})
+	t := task{fn: func() { close(done) }, detached: true}
+	if err := p.submit(ctx, t, p.block); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("%w: no worker accepted the probe within %s", ErrProbeTimeout, timeout)
+		}
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: probe not finished within %s", ErrProbeTimeout, timeout)
+	}
+}
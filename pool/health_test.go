@@ -0,0 +1,52 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealthcheck(t *testing.T) {
+	p := New(1, WithLogger(nil), WithHealthcheck(50*time.Millisecond, 20*time.Millisecond))
+	if err := p.Healthcheck(); err != nil {
+		t.Fatalf("idle pool: %v", err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Schedule(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	time.Sleep(30 * time.Millisecond)
+	err := p.Healthcheck()
+	if !errors.Is(err, ErrPoolSaturated) || !errors.Is(err, ErrWorkerStuck) {
+		t.Fatalf("busy pool: %v, want saturated and stuck", err)
+	}
+	close(release)
+	p.Wait()
+	if err := p.Healthcheck(); err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	p.Free()
+	if err := p.Healthcheck(); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("freed pool: %v", err)
+	}
+}
+
+func TestHealthcheckProbeTimeout(t *testing.T) {
+	// 有排队空间但唯一的 worker 一直被占用：探测任务被接受却无法执行
+	p := New(1, WithLogger(nil), WithQueueSize(4), WithHealthcheck(20*time.Millisecond, 0))
+	defer p.Free()
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	p.Schedule(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	if err := p.Healthcheck(); !errors.Is(err, ErrProbeTimeout) || errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrProbeTimeout only", err)
+	}
+}
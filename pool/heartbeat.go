@@ -0,0 +1,120 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Heartbeat 报告 ctx 所在 worker 当前执行的任务仍在推进，供长时间运行的任务配合 WithHeartbeat 区分“慢但存活”与“卡死”；
+// 调用过 Heartbeat 的任务之后须每隔不超过 WithHeartbeat 的 interval 调用一次。ctx 不是 TaskFunc 收到的 ctx 时不做任何事
+func Heartbeat(ctx context.Context) {
+	if w, ok := ctx.Value(workerKey{}).(*worker); ok {
+		w.beats.Add(1)
+	}
+}
+
+// StalledTask 描述一个心跳停止的任务，见 WithHeartbeat
+type StalledTask struct {
+	Worker  int
+	Info    *TaskInfo     // ScheduleWith 提交的任务的元数据，其它任务为 nil
+	Running time.Duration // 任务已执行的时长
+	Silent  time.Duration // 距上一次心跳的时长
+}
+
+func WithHeartbeat(interval time.Duration, onStall func(StalledTask)) Option { // 调用过 Heartbeat 的任务超过 interval 没有新的心跳时调用 onStall（可为 nil，只记录日志），每次停止只调用一次；此时 Healthcheck 对它返回 ErrWorkerStuck，PoolStats.Stalled 计入，心跳恢复后解除；仍在发送心跳的任务不因 WithHealthcheck 的 stuckAfter 被视为卡死
+	return func(p *Pool) {
+		if interval <= 0 {
+			p.invalid("WithHeartbeat(%s): interval must be positive", interval)
+			return
+		}
+		p.heartbeat = &heartbeatMonitor{interval: interval, onStall: onStall}
+	}
+}
+
+type heartbeatMonitor struct {
+	interval time.Duration
+	onStall  func(StalledTask)
+}
+
+// beatState 是监视 goroutine 对一个 worker 当前任务的记录
+type beatState struct {
+	since   int64     // 任务开始的时刻，即 worker.busySince，变化说明换了任务
+	beats   uint64    // 上一次看到的心跳数
+	seen    time.Time // 心跳数上一次变化的时刻
+	stalled bool
+}
+
+// runHeartbeats 每 interval/4 检查一次各 worker 的心跳，直到 pool 销毁
+func (p *Pool) runHeartbeats() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	m := p.heartbeat
+	ticker := p.clock.NewTicker(max(m.interval/4, time.Millisecond))
+	defer ticker.Stop()
+	states := make(map[*worker]*beatState)
+	for {
+		select {
+		case <-ticker.C():
+		case <-p.quit:
+			return
+		}
+		now := p.clock.Now()
+		seen := make(map[*worker]bool, len(states))
+		p.live.Range(func(_, v any) bool {
+			w := v.(*worker)
+			since := w.busySince.Load()
+			if since == 0 {
+				return true
+			}
+			seen[w] = true
+			beats := w.beats.Load()
+			s := states[w]
+			if s == nil || s.since != since {
+				s = &beatState{since: since, beats: beats, seen: now}
+				states[w] = s
+			}
+			if beats != s.beats {
+				s.beats, s.seen = beats, now
+				if s.stalled {
+					s.stalled = false
+					w.stalled.Store(false)
+					p.logf("worker[%03d]: heartbeat resumed\n", w.id)
+				}
+			}
+			if beats == 0 || s.stalled || now.Sub(s.seen) <= m.interval { // 没有调用过 Heartbeat 的任务不监视
+				return true
+			}
+			s.stalled = true
+			w.stalled.Store(true)
+			st := StalledTask{Worker: w.id, Info: w.task.Load(), Running: now.Sub(time.Unix(0, since)), Silent: now.Sub(s.seen)}
+			p.logf("worker[%03d]: %s\n", w.id, st)
+			if m.onStall != nil {
+				m.onStall(st)
+			}
+			return true
+		})
+		for w := range states {
+			if !seen[w] {
+				delete(states, w)
+			}
+		}
+	}
+}
+
+func (s StalledTask) String() string {
+	task := "a task"
+	if s.Info != nil {
+		task = s.Info.String()
+	}
+	return fmt.Sprintf("no heartbeat from %s for %s (running %s)", task, s.Silent, s.Running)
+}
+
+// HeartbeatStalled 在有任务的心跳持续 d 停止时告警，见 WithHeartbeat
+func HeartbeatStalled(d time.Duration) AlertCondition {
+	return AlertCondition{
+		Name:  fmt.Sprintf("task heartbeat stalled for %s", d),
+		Check: func(s PoolStats) bool { return s.Stalled > 0 },
+		For:   d,
+	}
+}
@@ -0,0 +1,58 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	stalls := make(chan StalledTask, 4)
+	p := New(2, WithLogger(nil), WithHeartbeat(20*time.Millisecond, func(s StalledTask) { stalls <- s }))
+	defer p.Free()
+	beat, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	p.ScheduleWith(func(ctx context.Context) {
+		Heartbeat(ctx)
+		for {
+			select {
+			case <-beat:
+				Heartbeat(ctx)
+			case <-done:
+				return
+			}
+		}
+	}, WithTaskName("beating"))
+	p.ScheduleWith(func(ctx context.Context) { <-done }, WithTaskName("silent")) // 不发送心跳，不被监视
+
+	var s StalledTask
+	select {
+	case s = <-stalls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stall reported")
+	}
+	if s.Info == nil || s.Info.Name != "beating" || s.Silent < 20*time.Millisecond {
+		t.Fatalf("stalled = %+v", s)
+	}
+	if n := p.Stats().Stalled; n != 1 {
+		t.Fatalf("stats stalled = %d", n)
+	}
+	if err := p.Healthcheck(); !errors.Is(err, ErrWorkerStuck) {
+		t.Fatalf("healthcheck = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Stalled != 0 { // 心跳恢复后解除
+		if time.Now().After(deadline) {
+			t.Fatal("stall not cleared after heartbeats resumed")
+		}
+		beat <- struct{}{}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case s := <-stalls:
+		t.Fatalf("unexpected stall %+v", s)
+	default:
+	}
+}
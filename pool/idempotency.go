@@ -0,0 +1,83 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore 记录已成功执行的 Job.Key，多个进程消费同一队列时应使用共享的存储（如 Redis 的 SET NX EX）
+type IdempotencyStore interface {
+	// Seen 报告 key 是否已完成且尚未过期
+	Seen(ctx context.Context, key string) (bool, error)
+	// MarkDone 记录 key 已完成，ttl 后过期，ttl <= 0 表示永不过期
+	MarkDone(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// MemoryIdempotencyStore 是进程内的 IdempotencyStore，过期的 key 在写入时顺带清理
+type MemoryIdempotencyStore struct {
+	mu     sync.Mutex
+	keys   map[string]time.Time // key -> 过期时间，零值表示永不过期
+	writes int
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{keys: make(map[string]time.Time)}
+}
+
+func (s *MemoryIdempotencyStore) Seen(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.keys[key]
+	if ok && !exp.IsZero() && time.Now().After(exp) {
+		delete(s.keys, key)
+		ok = false
+	}
+	return ok, nil
+}
+
+func (s *MemoryIdempotencyStore) MarkDone(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var exp time.Time
+	if ttl > 0 {
+		exp = now.Add(ttl)
+	}
+	s.keys[key] = exp
+	s.writes++
+	if s.writes >= len(s.keys) { // 摊还清理，每写入 len(keys) 次扫描一遍
+		s.writes = 0
+		for k, e := range s.keys {
+			if !e.IsZero() && now.After(e) {
+				delete(s.keys, k)
+			}
+		}
+	}
+	return nil
+}
+
+// duplicate 报告 j 是否已经成功执行过；查询失败时照常执行，宁可重复也不丢失
+func (p *Pool) duplicate(ctx context.Context, j Job) bool {
+	if p.idem == nil || j.Key == "" {
+		return false
+	}
+	seen, err := p.idem.Seen(ctx, j.Key)
+	if err != nil {
+		p.logf("job[%s]: idempotency check %s: %s\n", j.Type, j.Key, err)
+		return false
+	}
+	if seen {
+		p.logf("job[%s]: duplicate key %s, skipped\n", j.Type, j.Key)
+	}
+	return seen
+}
+
+func (p *Pool) markDone(ctx context.Context, j Job) {
+	if p.idem == nil || j.Key == "" {
+		return
+	}
+	if err := p.idem.MarkDone(context.WithoutCancel(ctx), j.Key, p.idemTTL); err != nil {
+		p.logf("job[%s]: record idempotency key %s: %s\n", j.Type, j.Key, err)
+	}
+}
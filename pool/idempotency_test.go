@@ -0,0 +1,94 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryIdempotencyStore()
+	if seen, err := s.Seen(ctx, "a"); seen || err != nil {
+		t.Fatalf("Seen before MarkDone = %v, %v", seen, err)
+	}
+	s.MarkDone(ctx, "a", 0)
+	s.MarkDone(ctx, "b", 10*time.Millisecond)
+	if seen, _ := s.Seen(ctx, "b"); !seen {
+		t.Fatal("b not seen before it expired")
+	}
+	time.Sleep(20 * time.Millisecond)
+	s.MarkDone(ctx, "c", time.Minute) // 顺带清理过期的 b
+	if seen, _ := s.Seen(ctx, "b"); seen {
+		t.Fatal("b seen after it expired")
+	}
+	if seen, _ := s.Seen(ctx, "a"); !seen {
+		t.Fatal("a without a ttl expired")
+	}
+	if _, ok := s.keys["b"]; ok {
+		t.Fatal("expired key not cleaned up")
+	}
+}
+
+// failingStore 的查询总是失败
+type failingStore struct{ marks atomic.Int32 }
+
+func (*failingStore) Seen(context.Context, string) (bool, error) { return false, errors.New("down") }
+func (s *failingStore) MarkDone(context.Context, string, time.Duration) error {
+	s.marks.Add(1)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	p := New(1, WithLogger(nil), WithIdempotency(nil, time.Minute)) // 逐个执行，后面的 Job 开始时前面的已经结束
+	defer p.Free()
+	var calls atomic.Int32
+	boom := errors.New("boom")
+	p.Register("charge", func(ctx context.Context, payload []byte) error {
+		if calls.Add(1) == 1 {
+			return boom // 失败的 Job 不记录 key，重复投递时再次执行
+		}
+		return nil
+	})
+	last := make(chan struct{})
+	p.Register("last", func(context.Context, []byte) error { close(last); return nil })
+	for range 3 {
+		if err := p.Enqueue(Job{Type: "charge", Key: "order-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Enqueue(Job{Type: "charge"}) // 没有 Key 的 Job 不去重
+	p.Enqueue(Job{Type: "last"})
+	select {
+	case <-last:
+	case <-time.After(5 * time.Second):
+		t.Fatal("jobs not run")
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("charge ran %d times, want 3", n)
+	}
+}
+
+func TestIdempotencyStoreDown(t *testing.T) {
+	store := &failingStore{}
+	var logs atomic.Int32
+	p := New(1, WithLogger(func(string, ...any) { logs.Add(1) }), WithIdempotency(store, 0))
+	defer p.Free()
+	var calls atomic.Int32
+	p.Register("job", func(context.Context, []byte) error { calls.Add(1); return nil })
+	p.Enqueue(Job{Type: "job", Key: "k"})
+	p.Enqueue(Job{Type: "job", Key: "k"})
+	// 查询失败时照常执行，宁可重复也不丢失
+	eventually(t, "both jobs to run", func() bool { return calls.Load() == 2 && store.marks.Load() == 2 })
+	if logs.Load() == 0 {
+		t.Fatal("failed idempotency check not logged")
+	}
+}
+
+func TestIdempotencyNegativeTTL(t *testing.T) {
+	if _, err := NewE(1, WithIdempotency(nil, -time.Second)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption", err)
+	}
+}
@@ -0,0 +1,68 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// taskCounter 统计已提交尚未执行完的任务，供 Wait 等待归零
+type taskCounter struct {
+	mu   sync.Mutex
+	n    int
+	zero chan struct{} // n 为 0 时已关闭，n 由 0 变为正数时替换
+}
+
+func (c *taskCounter) add(d int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.zero == nil {
+		c.zero = make(chan struct{})
+		close(c.zero)
+	}
+	if c.n == 0 && d > 0 {
+		c.zero = make(chan struct{})
+	}
+	c.n += d
+	if c.n == 0 {
+		close(c.zero)
+	}
+}
+
+func (c *taskCounter) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func (c *taskCounter) wait() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.zero == nil {
+		c.zero = make(chan struct{})
+		close(c.zero)
+	}
+	return c.zero
+}
+
+// Wait 阻塞直到所有已提交的任务执行完毕（ScheduleDetached 提交的除外）或 pool 被销毁，
+// 等待期间新提交的任务同样需要等待；pool 销毁时未执行的任务不再执行，Wait 随即返回
+func (p *Pool) Wait() {
+	p = p.applied()
+	select {
+	case <-p.inflight.wait():
+	case <-p.quit:
+	}
+}
+
+// WaitCtx 与 Wait 相同，但 ctx 取消时返回 ctx.Err()，任务照常继续执行
+func (p *Pool) WaitCtx(ctx context.Context) error {
+	p = p.applied()
+	select {
+	case <-p.inflight.wait():
+		return nil
+	case <-p.quit:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,48 @@
+package workerpool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInitBackoff(t *testing.T) {
+	p := newPool([]Option{WithInitBackoff(10*time.Millisecond, 50*time.Millisecond)})
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for i, d := range want {
+		if got := p.initBackoff(i + 1); got != d*time.Millisecond {
+			t.Fatalf("failure %d: backoff %s, want %s", i+1, got, d*time.Millisecond)
+		}
+	}
+}
+
+func TestWorkerInitFailureRecovers(t *testing.T) {
+	var attempts atomic.Int32
+	failures := make(chan int, 10)
+	p := New(1, WithLogger(nil), WithPreAllocWorkers(true),
+		WithInitBackoff(time.Millisecond, 4*time.Millisecond),
+		WithWorkerInit(func(int) (any, error) {
+			if attempts.Add(1) <= 3 {
+				return nil, errors.New("dial failed")
+			}
+			return nil, nil
+		}),
+		WithOnWorkerInitFailure(func(_ int, _ error, n int) { failures <- n }))
+	defer p.Free()
+	ran := make(chan struct{})
+	p.Schedule(func() { close(ran) })
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task never ran after init recovered")
+	}
+	for want := 1; want <= 3; want++ {
+		if n := <-failures; n != want {
+			t.Fatalf("consecutive = %d, want %d", n, want)
+		}
+	}
+	if p.initFailures.Load() != 0 {
+		t.Fatal("failure count not reset after a successful init")
+	}
+}
@@ -0,0 +1,177 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrUnknownJobType = errors.New("unknown job type")
+
+// Job 是可以持久化的任务：闭包无法序列化，因此以类型名 + 序列化参数描述任务，
+// 执行时由 Register 注册的同名处理函数解释 Payload
+type Job struct {
+	Type    string
+	Payload []byte
+	// Key 是可选的幂等键，启用 WithIdempotency 时同一 Key 的 Job 成功执行一次后，重复投递的不再执行
+	Key string
+	// Attempt 是该 Job 第几次被投递执行，由 Queue 在 Pop 时填入，0 表示后端无法得知
+	Attempt int
+
+	walID uint64 // 写入 WAL 后分配，执行结束时据此标记完成
+}
+
+// JobHandler 处理一种类型的 Job
+type JobHandler func(ctx context.Context, payload []byte) error
+
+type attemptKey struct{}
+
+// JobAttempt 返回处理函数正在执行的 Job 是第几次投递，ctx 不是 Job 处理函数的 ctx 时返回 0
+func JobAttempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+type jobRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	codecs   map[string]Codec // RegisterJob 注册的类型才有
+	counters map[string]*jobCounter
+}
+
+// Register 注册 jobType 类型 Job 的处理函数，重复注册时覆盖
+func (p *Pool) Register(jobType string, h JobHandler) {
+	p = p.applied()
+	r := &p.jobs
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handlers == nil {
+		r.handlers = make(map[string]JobHandler)
+	}
+	r.handlers[jobType] = h
+	r.ensureCounter(jobType) // 重复注册时保留计数
+	delete(r.codecs, jobType)
+}
+
+func (r *jobRegistry) lookup(jobType string) (JobHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[jobType]
+	return h, ok
+}
+
+// Enqueue 将 Job 放入队列（默认为 MemoryQueue，见 WithQueue）后立即返回，由 pool 按容量取出执行；
+// 启用 WithWAL 时先写入 WAL 并落盘再入队，执行结束后标记完成，进程崩溃不会丢失已被接受的 Job
+// 处理函数返回错误或 panic 时同样视为执行结束
+func (p *Pool) Enqueue(j Job) error {
+	p = p.applied()
+	if _, ok := p.jobs.lookup(j.Type); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJobType, j.Type)
+	}
+	if p.usesWAL() {
+		id, err := p.wal.append(j)
+		if err != nil {
+			return err
+		}
+		j.walID = id
+	}
+	err := p.push(j)
+	if err != nil && p.usesWAL() {
+		p.wal.complete(j.walID) // 未被接受，不应在恢复时执行
+	}
+	if err == nil {
+		p.jobs.counter(j.Type).enqueued.Add(1)
+	}
+	return err
+}
+
+// Dispatch 在 pool 上执行 j 而不写入 WAL，结束后以处理函数的结果调用 done（panic 时为 *PanicError），
+// 供自带持久化的队列后端据此确认或重投消息；提交失败时返回错误且不调用 done
+func (p *Pool) Dispatch(j Job, done func(err error)) error {
+	p = p.applied()
+	t, err := p.jobTask(j, nil, done)
+	if err != nil {
+		return err
+	}
+	return p.schedule(t)
+}
+
+// st 不为 nil 时执行受可见性超时约束，显式确认模式下处理函数通过 ctx 调用 Ack
+func (p *Pool) jobTask(j Job, st *ackState, done func(err error)) (task, error) {
+	h, ok := p.jobs.lookup(j.Type)
+	if !ok {
+		return task{}, fmt.Errorf("%w: %s", ErrUnknownJobType, j.Type)
+	}
+	c := p.jobs.counter(j.Type)
+	return task{fnc: func(ctx context.Context) {
+		ctx = context.WithValue(ctx, attemptKey{}, j.Attempt)
+		if st != nil {
+			st.start()
+			ctx = context.WithValue(ctx, ackKey{}, st)
+		}
+		if p.duplicate(ctx, j) {
+			if done != nil {
+				done(nil)
+			}
+			return
+		}
+		self := &selfHandle{p: p, accept: accepts[[]byte]}
+		ctx = context.WithValue(ctx, requeueKey{}, self)
+		c.running.Add(1)
+		start := p.clock.Now()
+		_, err := protect(ctx, func(ctx context.Context) (struct{}, error) { return struct{}{}, h(ctx, j.Payload) })
+		c.running.Add(-1)
+		if r := self.take(); r != nil && err == nil { // 失败的 Job 交给失败路径（Queue 的重投或失败队列），不再重新排队
+			p.requeueJob(j, r)
+		}
+		p.finishJob(c, j.Type, p.clock.Since(start), err)
+		if err == nil && (st == nil || !p.explicitAck) {
+			p.markDone(ctx, j)
+		}
+		if err != nil {
+			MarkFailed(ctx)
+		}
+		if done != nil {
+			done(err)
+		}
+	}}, nil
+}
+
+// Recover 重新提交 WAL 中上次运行未完成的 Job（按原提交顺序），返回重新提交的数量；
+// 应在 Register 所有处理函数之后调用，只有第一次调用有效
+// 恢复是“至少一次”的：崩溃前已执行但未来得及标记完成的 Job 会再执行一次，
+// seen 不为 nil 时对每个 Job 调用，返回 true 表示已处理过，直接标记完成而不再执行
+// 类型未注册的 Job 保留在 WAL 中，以 ErrUnknownJobType 合并返回
+func (p *Pool) Recover(seen func(j Job) bool) (int, error) {
+	if !p.usesWAL() {
+		return 0, nil
+	}
+	if p.wal.err != nil {
+		return 0, p.wal.err
+	}
+	ids, jobs := p.wal.takeReplay()
+	if len(jobs) > 0 {
+		p.logf("workerpool: recovering %d jobs from wal, jobs may run more than once\n", len(jobs))
+	}
+	var errs []error
+	n := 0
+	for i, j := range jobs {
+		id := ids[i]
+		if _, ok := p.jobs.lookup(j.Type); !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownJobType, j.Type))
+			continue
+		}
+		if seen != nil && seen(j) {
+			p.wal.complete(id)
+			continue
+		}
+		j.walID = id
+		if err := p.push(j); err != nil { // 保留在 WAL 中，下次启动时再恢复
+			errs = append(errs, err)
+			break
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
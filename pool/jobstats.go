@@ -0,0 +1,100 @@
+package workerpool
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// JobTypeStats 是一种 Job 类型自注册以来的累计统计，见 JobTypes
+type JobTypeStats struct {
+	Type      string
+	Enqueued  uint64       // Enqueue 接受的 Job 数
+	Running   int          // 正在执行的 Job 数
+	Succeeded uint64       // 处理函数返回 nil 的次数
+	Failed    uint64       // 处理函数返回错误或 panic 的次数
+	LastError string       // 最近一次失败的错误，没有时为空
+	ExecTime  LatencyStats // 处理函数的执行时间
+}
+
+// JobMetricsCollector 是 MetricsCollector 的可选扩展：WithMetrics 的 collector 同时实现它时，Job 的结果与耗时另按类型上报，
+// name 为 MetricJobsSucceeded 等常量
+type JobMetricsCollector interface {
+	CountJob(jobType, name string, delta int64)
+	ObserveJob(jobType, name string, value float64)
+}
+
+// JobMetricsCollector 按类型上报的指标
+const (
+	MetricJobsSucceeded = "workerpool_jobs_succeeded_total" // 计数器：成功的 Job
+	MetricJobsFailed    = "workerpool_jobs_failed_total"    // 计数器：失败的 Job
+	MetricJobExecTime   = "workerpool_job_exec_seconds"     // 直方图：处理函数的执行时间
+)
+
+// jobCounter 是一种类型的计数，注册时创建，ApplyOptions 与 Resize 时随处理函数转入新的 pool
+type jobCounter struct {
+	enqueued, succeeded, failed atomic.Uint64
+	running                     atomic.Int64
+	lastErr                     atomic.Pointer[string]
+	exec                        latencyRecorder
+}
+
+// 需持有 r.mu
+func (r *jobRegistry) ensureCounter(jobType string) {
+	if r.counters == nil {
+		r.counters = make(map[string]*jobCounter)
+	}
+	if r.counters[jobType] == nil {
+		r.counters[jobType] = &jobCounter{}
+	}
+}
+
+func (r *jobRegistry) counter(jobType string) *jobCounter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.counters[jobType]
+}
+
+// finishJob 记录 jobType 的一次执行
+func (p *Pool) finishJob(c *jobCounter, jobType string, d time.Duration, err error) {
+	c.exec.record(d)
+	name := MetricJobsSucceeded
+	if err != nil {
+		c.failed.Add(1)
+		msg := err.Error()
+		c.lastErr.Store(&msg)
+		name = MetricJobsFailed
+	} else {
+		c.succeeded.Add(1)
+	}
+	if m, ok := p.metrics.(JobMetricsCollector); ok {
+		m.CountJob(jobType, name, 1)
+		m.ObserveJob(jobType, MetricJobExecTime, d.Seconds())
+	}
+}
+
+// JobTypes 返回所有已注册的 Job 类型的统计，按类型名排序，供管理界面与日志查看各类 Job 的运行情况
+func (p *Pool) JobTypes() []JobTypeStats {
+	p = p.applied()
+	r := &p.jobs
+	r.mu.RLock()
+	list := make([]JobTypeStats, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		c := r.counters[jobType]
+		s := JobTypeStats{
+			Type:      jobType,
+			Enqueued:  c.enqueued.Load(),
+			Running:   int(c.running.Load()),
+			Succeeded: c.succeeded.Load(),
+			Failed:    c.failed.Load(),
+			ExecTime:  c.exec.get(),
+		}
+		if msg := c.lastErr.Load(); msg != nil {
+			s.LastError = *msg
+		}
+		list = append(list, s)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
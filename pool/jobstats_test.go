@@ -0,0 +1,66 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type jobCollector struct {
+	*testCollector
+	mu   sync.Mutex
+	jobs map[string]int64
+}
+
+func (c *jobCollector) CountJob(jobType, name string, delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.jobs[jobType+" "+name] += delta
+}
+
+func (c *jobCollector) ObserveJob(jobType, name string, value float64) {}
+
+func TestJobTypes(t *testing.T) {
+	c := &jobCollector{testCollector: newTestCollector(), jobs: map[string]int64{}}
+	p := New(2, WithMetrics(c, time.Hour))
+	defer p.Free()
+	p.Register("send", func(_ context.Context, payload []byte) error {
+		if string(payload) == "bad" {
+			return errors.New("smtp down")
+		}
+		return nil
+	})
+	p.Register("archive", func(context.Context, []byte) error { return nil })
+	for _, s := range []string{"a", "bad", "b"} {
+		if err := p.Enqueue(Job{Type: "send", Payload: []byte(s)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Enqueue(Job{Type: "unknown"}); err == nil {
+		t.Fatal("Enqueue of an unregistered type succeeded")
+	}
+	var list []JobTypeStats
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) { // Enqueue 的 Job 不计入 Wait
+		list = p.JobTypes()
+		if s := list[len(list)-1]; s.Succeeded+s.Failed == 3 || time.Now().After(deadline) {
+			break
+		}
+	}
+	if len(list) != 2 || list[0].Type != "archive" || list[1].Type != "send" {
+		t.Fatalf("JobTypes = %+v", list)
+	}
+	if s := list[0]; s.Enqueued != 0 || s.Succeeded != 0 || s.Failed != 0 {
+		t.Fatalf("archive = %+v", s)
+	}
+	s := list[1]
+	if s.Enqueued != 3 || s.Succeeded != 2 || s.Failed != 1 || s.Running != 0 || s.LastError != "smtp down" || s.ExecTime.Count != 3 {
+		t.Fatalf("send = %+v", s)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.jobs["send "+MetricJobsSucceeded] != 2 || c.jobs["send "+MetricJobsFailed] != 1 {
+		t.Fatalf("job metrics = %v", c.jobs)
+	}
+}
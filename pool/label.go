@@ -0,0 +1,39 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+)
+
+// pool 派生的每个 goroutine 都带有以下 pprof 标签，
+// 便于在 goroutine profile、trace 以及泄漏检测中识别它们属于哪个 pool
+const (
+	LabelPool   = "workerpool"        // pool 实例 ID
+	LabelRole   = "workerpool.role"   // goroutine 角色：worker / helper
+	LabelWorker = "workerpool.worker" // worker 编号，仅 worker 有
+)
+
+const (
+	roleWorker = "worker"
+	roleHelper = "helper"
+)
+
+var poolSeq atomic.Uint64
+
+// ID 返回 pool 实例在进程内的唯一编号，与 goroutine 标签 LabelPool 的值对应
+func (p *Pool) ID() uint64 {
+	return p.id
+}
+
+// 为当前 goroutine 打上 pool 标签，需在 pool 派生的 goroutine 起始处调用
+func (p *Pool) setLabels(role string, kv ...string) {
+	labels := append([]string{LabelPool, strconv.FormatUint(p.id, 10), LabelRole, role}, kv...)
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels(labels...)))
+}
+
+func workerLabel(i int) string {
+	return fmt.Sprintf("%03d", i)
+}
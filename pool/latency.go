@@ -0,0 +1,141 @@
+package workerpool
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyStats 是一类耗时的累计统计，分位数由对数分桶的直方图估计，相对误差在 7% 以内
+type LatencyStats struct {
+	Count uint64
+	Total time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+// Mean 返回平均耗时，没有记录时为 0
+func (s LatencyStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// 直方图把每个 2 的幂区间等分为 latencySub 个桶，小于 latencySub 纳秒的耗时各占一个桶
+const (
+	latencySubBits = 3
+	latencySub     = 1 << latencySubBits
+	latencyBuckets = (64 - latencySubBits + 1) * latencySub
+)
+
+// latencyRecorder 以原子操作记录耗时，每个任务的记录不加锁
+type latencyRecorder struct {
+	count   atomic.Uint64
+	total   atomic.Int64
+	max     atomic.Int64
+	buckets [latencyBuckets]atomic.Uint64
+}
+
+func latencyBucket(d time.Duration) int {
+	v := uint64(max(d, 0))
+	if v < latencySub {
+		return int(v)
+	}
+	e := bits.Len64(v) - 1 // v 的最高位，不小于 latencySubBits
+	sub := int(v>>(e-latencySubBits)) & (latencySub - 1)
+	return (e-latencySubBits+1)*latencySub + sub
+}
+
+// latencyValue 返回第 i 个桶的中点
+func latencyValue(i int) time.Duration {
+	if i < latencySub {
+		return time.Duration(i)
+	}
+	e := i/latencySub + latencySubBits - 1
+	lo := uint64(latencySub+i%latencySub) << (e - latencySubBits)
+	return time.Duration(lo + uint64(1)<<(e-latencySubBits)/2)
+}
+
+func (r *latencyRecorder) record(d time.Duration) {
+	r.count.Add(1)
+	r.total.Add(int64(d))
+	for {
+		m := r.max.Load()
+		if int64(d) <= m || r.max.CompareAndSwap(m, int64(d)) {
+			break
+		}
+	}
+	r.buckets[latencyBucket(d)].Add(1)
+}
+
+// get 返回当前的统计，与并发的 record 之间只保证各字段各自准确
+func (r *latencyRecorder) get() LatencyStats {
+	snap := r.snapshot()
+	return snap.stats(time.Duration(r.max.Load()))
+}
+
+// latencySnapshot 是某一时刻的累计计数，两个快照相减即为其间的记录
+type latencySnapshot struct {
+	count   uint64
+	total   int64
+	buckets [latencyBuckets]uint64
+}
+
+func (r *latencyRecorder) snapshot() latencySnapshot {
+	s := latencySnapshot{count: r.count.Load(), total: r.total.Load()}
+	for i := range r.buckets {
+		s.buckets[i] = r.buckets[i].Load()
+	}
+	return s
+}
+
+// sub 返回 prev 之后的记录
+func (s latencySnapshot) sub(prev latencySnapshot) latencySnapshot {
+	d := latencySnapshot{count: s.count - prev.count, total: s.total - prev.total}
+	for i := range s.buckets {
+		d.buckets[i] = s.buckets[i] - prev.buckets[i]
+	}
+	return d
+}
+
+// stats 以 maxv 为上限估计快照中的分位数
+func (s *latencySnapshot) stats(maxv time.Duration) LatencyStats {
+	var n uint64
+	for _, c := range s.buckets {
+		n += c
+	}
+	ls := LatencyStats{Count: s.count, Total: time.Duration(s.total), Max: maxv}
+	if n == 0 {
+		return ls
+	}
+	quantile := func(q float64) time.Duration {
+		rank := uint64(q*float64(n-1)) + 1 // 第 rank 小的记录所在的桶
+		var seen uint64
+		for i, c := range s.buckets {
+			seen += c
+			if seen >= rank {
+				return min(latencyValue(i), maxv)
+			}
+		}
+		return maxv
+	}
+	ls.P50, ls.P95, ls.P99 = quantile(0.50), quantile(0.95), quantile(0.99)
+	return ls
+}
+
+// interval 返回 prev 之后的统计与新的快照，Max 由最高的非空桶估计
+func (r *latencyRecorder) interval(prev latencySnapshot) (LatencyStats, latencySnapshot) {
+	cur := r.snapshot()
+	d := cur.sub(prev)
+	maxv := time.Duration(0)
+	for i := len(d.buckets) - 1; i >= 0; i-- {
+		if d.buckets[i] > 0 {
+			maxv = min(latencyValue(i), time.Duration(r.max.Load()))
+			break
+		}
+	}
+	return d.stats(maxv), cur
+}
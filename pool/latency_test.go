@@ -0,0 +1,52 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyBuckets(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 7, 8, 15, 16, 1000, time.Millisecond, 3 * time.Second, time.Hour} {
+		got := latencyValue(latencyBucket(d))
+		if diff := (got - d).Abs(); float64(diff) > float64(d)*0.07 {
+			t.Errorf("latencyValue(latencyBucket(%d)) = %d", d, got)
+		}
+	}
+	if latencyBucket(1<<63-1) >= latencyBuckets {
+		t.Fatal("largest duration out of range")
+	}
+}
+
+func TestLatencyPercentiles(t *testing.T) {
+	var r latencyRecorder
+	for i := 1; i <= 1000; i++ {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+	s := r.get()
+	if s.Count != 1000 || s.Max != time.Second || s.Mean() != 500500*time.Microsecond {
+		t.Fatalf("stats = %+v", s)
+	}
+	near := func(got, want time.Duration) bool {
+		return got >= want*93/100 && got <= want*107/100
+	}
+	if !near(s.P50, 500*time.Millisecond) || !near(s.P95, 950*time.Millisecond) || !near(s.P99, 990*time.Millisecond) {
+		t.Fatalf("P50/P95/P99 = %s/%s/%s", s.P50, s.P95, s.P99)
+	}
+	if empty := (&latencyRecorder{}).get(); empty != (LatencyStats{}) {
+		t.Fatalf("empty stats = %+v", empty)
+	}
+}
+
+func TestExecTimeStats(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	p := New(1, WithLogger(nil), WithClock(clock))
+	defer p.Free()
+	if err := p.Schedule(func() { clock.Advance(30 * time.Millisecond) }); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	s := p.Stats().ExecTime
+	if s.Count != 1 || s.Max != 30*time.Millisecond || s.P99 != 30*time.Millisecond {
+		t.Fatalf("ExecTime = %+v", s)
+	}
+}
@@ -0,0 +1,25 @@
+package workerpool
+
+func WithLazyStart() Option { // New 不启动任何 goroutine：辅助 goroutine（优先级调度、容量计划、告警、指标、flight recorder）与 WithPreAllocWorkers 的 worker 推迟到第一次提交时启动，适合初始化时创建、很少使用的 pool；配合 WithIdleTimeout 在空闲后回收 worker
+	return func(p *Pool) {
+		p.lazy = true
+	}
+}
+
+// Started 返回 p 的 goroutine 是否已启动：未设置 WithLazyStart 时总是 true，否则为是否已有过提交
+func (p *Pool) Started() bool {
+	p = p.applied()
+	return !p.lazy || p.lazyStarted.Load()
+}
+
+// startLazy 在第一次提交时启动推迟的 goroutine；需持有 p.closeMu 且 closed 为 false，此时 wg.Add 是安全的
+func (p *Pool) startLazy() {
+	if !p.lazy || p.lazyStarted.Load() {
+		return
+	}
+	p.lazyStart.Do(func() {
+		p.logf("workerpool: lazy start on first use\n")
+		p.startGoroutines()
+		p.lazyStarted.Store(true)
+	})
+}
@@ -0,0 +1,55 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLazyStart(t *testing.T) {
+	c := newTestCollector()
+	p := New(3, WithLogger(nil), WithLazyStart(), WithPreAllocWorkers(true), WithPriorityScheduling(0), WithMetrics(c, time.Millisecond))
+	defer p.Free()
+	time.Sleep(20 * time.Millisecond)
+	c.mu.Lock()
+	gauges := len(c.gauges)
+	c.mu.Unlock()
+	if p.Started() || gauges != 0 || p.Stats().Workers != 0 {
+		t.Fatalf("before first use: started=%t gauges=%d workers=%d", p.Started(), gauges, p.Stats().Workers)
+	}
+	ran := make(chan struct{})
+	if err := p.Schedule(func() { close(ran) }); err != nil {
+		t.Fatal(err)
+	}
+	<-ran
+	if !p.Started() {
+		t.Fatal("not started after the first Schedule")
+	}
+	if w := p.Stats().Workers; w != 3 {
+		t.Fatalf("workers = %d, want the 3 preallocated", w)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		capacity := c.gauges[MetricCapacity]
+		c.mu.Unlock()
+		if capacity == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("metrics not updated after start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 从未使用的 pool 销毁时也没有 goroutine 需要等待
+	q := New(1, WithLogger(nil), WithLazyStart(), WithMetrics(c, time.Millisecond))
+	q.Free()
+	if q.Started() {
+		t.Fatal("unused pool started by Free")
+	}
+	r := New(1, WithLogger(nil))
+	defer r.Free()
+	if !r.Started() {
+		t.Fatal("eager pool reports not started")
+	}
+}
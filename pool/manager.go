@@ -0,0 +1,224 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// RestartPolicy 决定 Manager 何时重启一个 pool
+type RestartPolicy int
+
+const (
+	RestartNever       RestartPolicy = iota // 不重启（默认）
+	RestartOnUnhealthy                      // Healthcheck 报告 worker 卡住或分发无响应时重启，饱和不算
+)
+
+// ManagedPool 描述交给 Manager 管理的一个 pool
+type ManagedPool struct {
+	Name string
+	Pool *Pool
+	// DependsOn 是该 pool 的任务会提交到的其它 pool：关闭时先关闭依赖方，再关闭被依赖的 pool，
+	// 依赖方排空期间提交的任务仍能执行
+	DependsOn []string
+	// ShutdownTimeout 是关闭时等待已提交的任务执行完的上限，到期后 Free（丢弃排队中的任务），0 表示不等待
+	ShutdownTimeout time.Duration
+	Restart         RestartPolicy
+}
+
+// ManagedStats 是 Manager 中一个 pool 的状态
+type ManagedStats struct {
+	PoolStats
+	Restarts int   // 被 Manager 重启的次数
+	Health   error // 最近一次 Healthcheck 的结果，未检查过时为 nil
+}
+
+// Manager 管理多个具名的 pool：汇总状态，按依赖顺序关闭，并按 RestartPolicy 重启不健康的 pool
+// 重启经由 ApplyOptions 以原有的选项替换 pool，持有 *Pool 的调用方无需感知；
+// 卡住的任务在旧的 pool 上继续执行，新的任务交给新的 pool
+type Manager struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	pools map[string]*managed
+	order []string // 加入的顺序
+
+	stop    chan struct{}
+	stopped sync.WaitGroup // 健康检查的 goroutine
+	closed  bool
+}
+
+type managed struct {
+	ManagedPool
+	restarts   int
+	health     error
+	restarting bool
+}
+
+// NewManager 创建 Manager，interval > 0 时每隔 interval 对设置了 RestartOnUnhealthy 的 pool 做一次 Healthcheck
+func NewManager(interval time.Duration) *Manager {
+	m := &Manager{interval: interval, pools: make(map[string]*managed), stop: make(chan struct{})}
+	if interval > 0 {
+		m.stopped.Add(1)
+		go m.supervise()
+	}
+	return m
+}
+
+// Add 将 mp 交给 m 管理；名称重复、依赖了未加入的 pool 时返回错误
+func (m *Manager) Add(mp ManagedPool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return errors.New("workerpool: manager shut down")
+	}
+	if mp.Pool == nil {
+		return fmt.Errorf("workerpool: pool %q is nil", mp.Name)
+	}
+	if _, ok := m.pools[mp.Name]; ok {
+		return fmt.Errorf("workerpool: pool %q already added", mp.Name)
+	}
+	for _, dep := range mp.DependsOn {
+		if _, ok := m.pools[dep]; !ok { // 只能依赖已加入的 pool，因此不会形成环
+			return fmt.Errorf("workerpool: pool %q depends on unknown pool %q", mp.Name, dep)
+		}
+	}
+	m.pools[mp.Name] = &managed{ManagedPool: mp}
+	m.order = append(m.order, mp.Name)
+	return nil
+}
+
+// Get 返回名为 name 的 pool，不存在时返回 nil
+func (m *Manager) Get(name string) *Pool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mp := m.pools[name]; mp != nil {
+		return mp.Pool
+	}
+	return nil
+}
+
+// Stats 返回每个 pool 的状态
+func (m *Manager) Stats() map[string]ManagedStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make(map[string]ManagedStats, len(m.pools))
+	for name, mp := range m.pools {
+		stats[name] = ManagedStats{PoolStats: mp.Pool.Stats(), Restarts: mp.restarts, Health: mp.health}
+	}
+	return stats
+}
+
+// Total 返回所有 pool 的状态之和
+func (m *Manager) Total() PoolStats {
+	var total PoolStats
+	for _, s := range m.Stats() {
+		total.Capacity += s.Capacity
+		total.Workers += s.Workers
+		total.Busy += s.Busy
+		total.Inflight += s.Inflight
+		total.Queued += s.Queued
+		total.Abandoned += s.Abandoned
+	}
+	return total
+}
+
+// Shutdown 停止健康检查，并按依赖顺序逐个关闭所有 pool：先等待已提交的任务执行完（最多 ShutdownTimeout），再 Free；
+// 超时的 pool 以包装 ErrShutdownTimeout 的错误合并返回。ctx 取消时不再等待，剩余的 pool 立即 Free
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.stop)
+	// 依赖只能指向先加入的 pool，逆序关闭即保证依赖方先于被依赖方
+	var pools []*managed
+	for i := len(m.order) - 1; i >= 0; i-- {
+		pools = append(pools, m.pools[m.order[i]])
+	}
+	m.mu.Unlock()
+	m.stopped.Wait()
+
+	var errs []error
+	for _, mp := range pools {
+		if err := drainPool(ctx, mp.Pool, mp.ShutdownTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("pool %s: %w", mp.Name, err))
+		}
+		mp.Pool.Free()
+	}
+	return errors.Join(errs...)
+}
+
+func drainPool(ctx context.Context, p *Pool, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-t.C:
+		return fmt.Errorf("%w after %s", ErrShutdownTimeout, timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) supervise() {
+	defer m.stopped.Done()
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-t.C:
+		}
+		m.mu.Lock()
+		var check []*managed
+		for _, mp := range m.pools {
+			if mp.Restart == RestartOnUnhealthy && !mp.restarting {
+				check = append(check, mp)
+			}
+		}
+		m.mu.Unlock()
+		for _, mp := range check {
+			m.check(mp)
+		}
+	}
+}
+
+func (m *Manager) check(mp *managed) {
+	err := mp.Pool.Healthcheck()
+	restart := errors.Is(err, ErrWorkerStuck) || errors.Is(err, ErrProbeTimeout)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mp.health = err
+	if !restart || m.closed {
+		return
+	}
+	mp.restarting = true
+	mp.restarts++
+	mp.Pool.logf("workerpool: manager restarts pool %s: %s\n", mp.Name, err)
+	go func() {
+		// 不计入 m.stopped，Shutdown 不等待它：新的提交立即转交给新的 pool，旧的 pool 等卡住的任务返回后才销毁，ApplyOptions 可能长时间不返回
+		if err := mp.Pool.ApplyOptions(); err != nil {
+			mp.Pool.logf("workerpool: manager restart pool %s: %s\n", mp.Name, err)
+		}
+		m.mu.Lock()
+		mp.restarting = false
+		m.mu.Unlock()
+	}()
+}
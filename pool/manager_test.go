@@ -0,0 +1,77 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManagerShutdownOrder(t *testing.T) {
+	m := NewManager(0)
+	backend := New(2, WithLogger(nil))
+	frontend := New(2, WithLogger(nil))
+	if err := m.Add(ManagedPool{Name: "backend", Pool: backend, ShutdownTimeout: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(ManagedPool{Name: "frontend", Pool: frontend, DependsOn: []string{"backend"}, ShutdownTimeout: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(ManagedPool{Name: "x", Pool: New(1), DependsOn: []string{"missing"}}); err == nil {
+		t.Fatal("unknown dependency should be rejected")
+	}
+	var forwarded atomic.Int32
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		frontend.Schedule(func() {
+			time.Sleep(time.Millisecond)
+			// frontend 排空期间 backend 仍在运行
+			errs <- backend.Schedule(func() { forwarded.Add(1) })
+		})
+	}
+	if s := m.Total(); s.Capacity != 4 {
+		t.Fatalf("total capacity %d, want 4", s.Capacity)
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("frontend task could not reach backend: %v", err)
+		}
+	}
+	if n := forwarded.Load(); n != 10 {
+		t.Fatalf("%d of 10 forwarded tasks ran", n)
+	}
+}
+
+func TestManagerShutdownTimeout(t *testing.T) {
+	m := NewManager(0)
+	p := New(1, WithLogger(nil))
+	m.Add(ManagedPool{Name: "slow", Pool: p, ShutdownTimeout: 10 * time.Millisecond})
+	p.ScheduleFunc(func(ctx context.Context) { <-ctx.Done() })
+	if err := m.Shutdown(context.Background()); !errors.Is(err, ErrShutdownTimeout) {
+		t.Fatalf("err = %v, want ErrShutdownTimeout", err)
+	}
+}
+
+func TestManagerRestartsStuckPool(t *testing.T) {
+	m := NewManager(5 * time.Millisecond)
+	p := New(1, WithLogger(nil), WithHealthcheck(10*time.Millisecond, 10*time.Millisecond))
+	m.Add(ManagedPool{Name: "p", Pool: p, Restart: RestartOnUnhealthy})
+	stuck := make(chan struct{})
+	defer close(stuck)
+	p.Schedule(func() { <-stuck })
+	ran := make(chan struct{})
+	go p.Schedule(func() { close(ran) })
+	select {
+	case <-ran: // 新的 pool 接替了卡住的 pool
+	case <-time.After(5 * time.Second):
+		t.Fatal("stuck pool was not restarted")
+	}
+	if s := m.Stats()["p"]; s.Restarts == 0 {
+		t.Fatalf("restarts = %d", s.Restarts)
+	}
+	m.Shutdown(context.Background())
+}
@@ -0,0 +1,51 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaxCapacity(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		capacity int
+		opts     []Option
+		want     int
+	}{
+		{"default limit", defaultMaxCapacity * 2, nil, defaultMaxCapacity},
+		{"custom limit", 20, []Option{WithMaxCapacity(5)}, 5},
+		{"within the limit", 3, []Option{WithMaxCapacity(5)}, 3},
+		{"no limit", defaultMaxCapacity * 2, []Option{WithMaxCapacity(0)}, defaultMaxCapacity * 2},
+		{"negative means no limit", defaultMaxCapacity + 1, []Option{WithMaxCapacity(-1)}, defaultMaxCapacity + 1},
+	} {
+		p := New(tc.capacity, append(tc.opts, WithLogger(nil))...)
+		if got := p.Cap(); got != tc.want {
+			t.Errorf("%s: Cap = %d, want %d", tc.name, got, tc.want)
+		}
+		p.Free()
+	}
+}
+
+func TestMaxCapacityNewE(t *testing.T) {
+	if _, err := NewE(6, WithMaxCapacity(5)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("NewE above the limit = %v, want ErrInvalidOption", err)
+	}
+	p, err := NewE(defaultMaxCapacity+1, WithMaxCapacity(0), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("NewE without a limit = %v", err)
+	}
+	defer p.Free()
+
+	q, err := NewE(2, WithMaxCapacity(4), WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Free()
+	// Resize 同样受上限约束
+	if err := q.Resize(8); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("Resize above the limit = %v, want ErrInvalidOption", err)
+	}
+	if err := q.Resize(4); err != nil || q.Cap() != 4 {
+		t.Fatalf("Resize(4) = %v, Cap %d", err, q.Cap())
+	}
+}
@@ -0,0 +1,108 @@
+package workerpool
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Memo 在 SingleFlight 的基础上缓存成功的结果：ttl 内对同一 key 的重复提交直接返回缓存，
+// 不占用 worker；返回错误或 panic 的结果不会被缓存
+type Memo[R any] struct {
+	flight     *SingleFlight[R]
+	clock      Clock
+	ttl        time.Duration
+	maxEntries int // 缓存条目上限，超出时淘汰最久未使用的，0 表示不限
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     list.List // 元素为 *memoEntry[R]，表头为最近使用
+}
+
+type memoEntry[R any] struct {
+	key     string
+	val     R
+	expires time.Time
+}
+
+func NewMemo[R any](p *Pool, ttl time.Duration, maxEntries int) *Memo[R] {
+	return &Memo[R]{
+		flight:     NewSingleFlight[R](p),
+		clock:      p.clock,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Do 返回 key 的结果：缓存有效时 cached 为 true 且 Future 已完成，
+// 否则提交 fn（相同 key 正在执行时共享该执行）
+func (m *Memo[R]) Do(key string, fn func(ctx context.Context) (R, error)) (f *Future[R], cached bool, err error) {
+	if v, ok := m.Get(key); ok {
+		f := newFuture[R]()
+		f.resolve(v, nil)
+		return f, true, nil
+	}
+	f, _, err = m.flight.Do(key, func(ctx context.Context) (R, error) {
+		v, err := fn(ctx)
+		if err == nil {
+			m.store(key, v)
+		}
+		return v, err
+	})
+	return f, false, err
+}
+
+// Get 返回 key 未过期的缓存结果
+func (m *Memo[R]) Get(key string) (R, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		e := el.Value.(*memoEntry[R])
+		if m.clock.Now().Before(e.expires) {
+			m.lru.MoveToFront(el)
+			return e.val, true
+		}
+		m.remove(el)
+	}
+	var zero R
+	return zero, false
+}
+
+// Invalidate 删除 key 的缓存，之后的提交会重新执行
+func (m *Memo[R]) Invalidate(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		m.remove(el)
+	}
+}
+
+// Len 返回缓存条目数（可能包含尚未清理的过期条目）
+func (m *Memo[R]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+func (m *Memo[R]) store(key string, v R) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expires := m.clock.Now().Add(m.ttl)
+	if el, ok := m.entries[key]; ok {
+		e := el.Value.(*memoEntry[R])
+		e.val, e.expires = v, expires
+		m.lru.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.lru.PushFront(&memoEntry[R]{key: key, val: v, expires: expires})
+	for m.maxEntries > 0 && len(m.entries) > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+}
+
+func (m *Memo[R]) remove(el *list.Element) {
+	delete(m.entries, el.Value.(*memoEntry[R]).key)
+	m.lru.Remove(el)
+}
@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemo(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := New(2, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	m := NewMemo[int](p, time.Minute, 0)
+	var calls atomic.Int32
+	load := func(context.Context) (int, error) { return int(calls.Add(1)), nil }
+	do := func(key string, fn func(context.Context) (int, error)) (int, bool, error) {
+		t.Helper()
+		f, cached, err := m.Do(key, fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := f.Wait()
+		return v, cached, err
+	}
+
+	if v, cached, _ := do("k", load); v != 1 || cached {
+		t.Fatalf("first Do = %d, cached %v", v, cached)
+	}
+	if v, cached, _ := do("k", load); v != 1 || !cached {
+		t.Fatalf("second Do = %d, cached %v", v, cached)
+	}
+	clock.Advance(time.Minute) // 过期
+	if v, cached, _ := do("k", load); v != 2 || cached {
+		t.Fatalf("Do after ttl = %d, cached %v", v, cached)
+	}
+	m.Invalidate("k")
+	if _, ok := m.Get("k"); ok {
+		t.Fatal("Get after Invalidate hit the cache")
+	}
+
+	boom := errors.New("boom")
+	if _, _, err := do("err", func(context.Context) (int, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, ok := m.Get("err"); ok || m.Len() != 0 {
+		t.Fatalf("failed result cached, Len = %d", m.Len())
+	}
+}
+
+func TestMemoMaxEntries(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	m := NewMemo[string](p, time.Hour, 2)
+	for _, key := range []string{"a", "b", "a", "c"} { // 再次访问 a 后，最久未使用的是 b
+		f, _, err := m.Do(key, func(context.Context) (string, error) { return key, nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Wait()
+	}
+	if _, ok := m.Get("b"); ok || m.Len() != 2 {
+		t.Fatalf("b not evicted, Len = %d", m.Len())
+	}
+	for _, key := range []string{"a", "c"} {
+		if v, ok := m.Get(key); !ok || v != key {
+			t.Fatalf("Get(%q) = %q, %v", key, v, ok)
+		}
+	}
+}
+
+func TestMemoSharesInflight(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	m := NewMemo[int](p, time.Hour, 0)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(context.Context) (int, error) { calls.Add(1); <-release; return 7, nil }
+	f1, _, _ := m.Do("k", fn)
+	f2, cached, _ := m.Do("k", fn)
+	close(release)
+	v1, _ := f1.Wait()
+	v2, _ := f2.Wait()
+	if cached || v1 != 7 || v2 != 7 || calls.Load() != 1 {
+		t.Fatalf("v1 %d, v2 %d, cached %v, calls %d", v1, v2, cached, calls.Load())
+	}
+}
@@ -0,0 +1,86 @@
+package workerpool
+
+import "time"
+
+// MetricsCollector 接收 pool 产生的指标，由使用方桥接到任意的指标系统（Prometheus、StatsD、OpenTelemetry 等），
+// 本包因此不依赖任何导出器；方法在任务所在的 goroutine 中同步调用，实现应当快速且并发安全
+// 名称见 Metric 开头的常量；多个 pool 共用一个指标系统时，为每个 pool 创建带有各自标签的 collector
+type MetricsCollector interface {
+	Count(name string, delta int64)     // 计数器累加 delta
+	Gauge(name string, value float64)   // 设置当前值
+	Observe(name string, value float64) // 直方图记录一次观测，耗时以秒为单位
+}
+
+// WithMetrics 驱动的指标
+const (
+	MetricSubmitted = "workerpool_tasks_submitted_total" // 计数器：被接受的提交
+	MetricRejected  = "workerpool_tasks_rejected_total"  // 计数器：失败的提交
+	MetricFinished  = "workerpool_tasks_finished_total"  // 计数器：执行完的任务
+	MetricFailed    = "workerpool_tasks_failed_total"    // 计数器：执行中 panic 的任务
+	MetricDiscarded = "workerpool_tasks_discarded_total" // 计数器：被接受后未执行即被丢弃的任务
+
+	MetricQueueWait = "workerpool_task_queue_wait_seconds" // 直方图：从提交到开始执行的等待时间
+	MetricExecTime  = "workerpool_task_exec_seconds"       // 直方图：执行时间，含 panic 的任务
+
+	MetricCapacity = "workerpool_capacity" // gauge：容量
+	MetricWorkers  = "workerpool_workers"  // gauge：存活的 worker 数
+	MetricBusy     = "workerpool_busy"     // gauge：正在执行任务的 worker 数
+	MetricQueued   = "workerpool_queued"   // gauge：排队中的任务数
+	MetricInflight = "workerpool_inflight" // gauge：已提交尚未执行完的任务数
+	MetricBlocked  = "workerpool_blocked"  // gauge：阻塞在提交中的提交方数
+)
+
+func WithMetrics(c MetricsCollector, interval time.Duration) Option { // 把任务计数与耗时实时交给 c，并每 interval 更新一次 gauge（0 表示 1s），见 MetricsCollector
+	return func(p *Pool) {
+		if c == nil {
+			return
+		}
+		if interval <= 0 {
+			interval = time.Second
+		}
+		p.metrics, p.metricsInterval = c, interval
+		p.observers = append(p.observers, func(e Event) {
+			switch e.Kind {
+			case EventSubmitted:
+				c.Count(MetricSubmitted, 1)
+			case EventRejected:
+				c.Count(MetricRejected, 1)
+			case EventFinished:
+				c.Count(MetricFinished, 1)
+				c.Observe(MetricExecTime, e.Duration.Seconds())
+			case EventFailed:
+				c.Count(MetricFailed, 1)
+				c.Observe(MetricExecTime, e.Duration.Seconds())
+			case EventDiscarded:
+				c.Count(MetricDiscarded, 1)
+			}
+		})
+	}
+}
+
+// runMetrics 周期更新 gauge，直到 pool 销毁
+func (p *Pool) runMetrics() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	ticker := p.clock.NewTicker(p.metricsInterval)
+	defer ticker.Stop()
+	for {
+		p.updateGauges()
+		select {
+		case <-ticker.C():
+		case <-p.quit:
+			p.updateGauges()
+			return
+		}
+	}
+}
+
+func (p *Pool) updateGauges() {
+	s, c := p.stats(), p.metrics
+	c.Gauge(MetricCapacity, float64(s.Capacity))
+	c.Gauge(MetricWorkers, float64(s.Workers))
+	c.Gauge(MetricBusy, float64(s.Busy))
+	c.Gauge(MetricQueued, float64(s.Queued))
+	c.Gauge(MetricInflight, float64(s.Inflight))
+	c.Gauge(MetricBlocked, float64(s.Blocked))
+}
@@ -0,0 +1,65 @@
+package workerpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testCollector struct {
+	mu       sync.Mutex
+	counts   map[string]int64
+	gauges   map[string]float64
+	observed map[string]int
+}
+
+func newTestCollector() *testCollector {
+	return &testCollector{counts: map[string]int64{}, gauges: map[string]float64{}, observed: map[string]int{}}
+}
+
+func (c *testCollector) Count(name string, delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[name] += delta
+}
+
+func (c *testCollector) Gauge(name string, v float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges[name] = v
+}
+
+func (c *testCollector) Observe(name string, v float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observed[name]++
+}
+
+func TestWithMetrics(t *testing.T) {
+	c := newTestCollector()
+	p := New(2, WithLogger(nil), WithBlock(false), WithMetrics(c, time.Hour))
+	gate := make(chan struct{})
+	for range 2 {
+		if err := p.Schedule(func() { <-gate }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Schedule(func() {}); err == nil {
+		t.Fatal("third task accepted by a full non-blocking pool")
+	}
+	p.Schedule(func() {}) // 同样被拒绝
+	close(gate)
+	p.Wait()
+	p.Free() // 退出前再更新一次 gauge
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts[MetricSubmitted] != 2 || c.counts[MetricRejected] != 2 || c.counts[MetricFinished] != 2 {
+		t.Fatalf("counts = %v", c.counts)
+	}
+	if c.observed[MetricQueueWait] != 2 || c.observed[MetricExecTime] != 2 {
+		t.Fatalf("observations = %v", c.observed)
+	}
+	if c.gauges[MetricCapacity] != 2 || c.gauges[MetricInflight] != 0 {
+		t.Fatalf("gauges = %v", c.gauges)
+	}
+}
@@ -0,0 +1,99 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type middlewareKey struct{}
+
+func TestMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(s string) {
+		mu.Lock()
+		order = append(order, s)
+		mu.Unlock()
+	}
+	wrap := func(name string) func(Task) Task {
+		return func(next Task) Task {
+			return func() {
+				record(name + " before")
+				next()
+				record(name + " after")
+			}
+		}
+	}
+	p := New(1, WithLogger(nil), WithMiddleware(wrap("outer")), WithMiddleware(wrap("inner")))
+	p.Schedule(func() { record("task") })
+	p.Wait()
+	p.Free()
+	// 先设置的在外层
+	want := []string{"outer before", "inner before", "task", "inner after", "outer after"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestContextMiddleware(t *testing.T) {
+	var seen []any
+	var mu sync.Mutex
+	p := New(2, WithLogger(nil),
+		WithContextMiddleware(func(next TaskFunc) TaskFunc {
+			return func(ctx context.Context) { next(context.WithValue(ctx, middlewareKey{}, "span")) }
+		}),
+		WithMiddleware(func(next Task) Task { return next })) // 与 WithContextMiddleware 混用时 ctx 照常传递
+	defer p.Free()
+	p.ScheduleFunc(func(ctx context.Context) {
+		if _, ok := WorkerID(ctx); !ok {
+			t.Error("middleware replaced the worker ctx")
+		}
+		mu.Lock()
+		seen = append(seen, ctx.Value(middlewareKey{}))
+		mu.Unlock()
+	})
+	p.Schedule(func() {}) // Task 形式的任务同样经过中间件
+	p.Wait()
+	if !slices.Equal(seen, []any{"span"}) {
+		t.Fatalf("task saw %v", seen)
+	}
+}
+
+// 中间件可以 recover 任务的 panic，worker 不会因此退出
+func TestMiddlewareRecover(t *testing.T) {
+	recovered := make(chan any, 1)
+	var logMu sync.Mutex
+	var logs []string
+	logf := func(format string, args ...any) {
+		logMu.Lock()
+		logs = append(logs, fmt.Sprintf(format, args...))
+		logMu.Unlock()
+	}
+	p := New(1, WithLogger(logf), WithMiddleware(func(next Task) Task {
+		return func() {
+			defer func() {
+				if r := recover(); r != nil {
+					recovered <- r
+				}
+			}()
+			next()
+		}
+	}))
+	defer p.Free()
+	p.Schedule(func() { panic("boom") })
+	p.Wait()
+	if r := <-recovered; r != "boom" {
+		t.Fatalf("recovered %v", r)
+	}
+	logMu.Lock()
+	defer logMu.Unlock()
+	for _, l := range logs {
+		if strings.Contains(l, "panic") {
+			t.Fatalf("worker saw the panic: %q", l)
+		}
+	}
+}
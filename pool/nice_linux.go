@@ -0,0 +1,9 @@
+package workerpool
+
+import "syscall"
+
+// setThreadNice 设置当前 OS 线程的 nice 值，调用方须已 LockOSThread
+// Linux 上 setpriority(PRIO_PROCESS, tid) 只作用于该线程
+func setThreadNice(nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, syscall.Gettid(), nice)
+}
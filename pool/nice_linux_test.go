@@ -0,0 +1,27 @@
+package workerpool
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestWorkerNice(t *testing.T) {
+	p := New(1, WithLogger(nil), WithLockOSThread(), WithWorkerNice(5))
+	defer p.Free()
+	got := make(chan int, 1)
+	p.Schedule(func() {
+		prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, syscall.Gettid())
+		if err != nil {
+			got <- -100
+			return
+		}
+		got <- 20 - prio // 系统调用返回 20 - nice
+	})
+	if n := <-got; n < 5 {
+		t.Fatalf("worker thread nice = %d, want at least 5", n)
+	}
+	if _, err := NewE(1, WithWorkerNice(5)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption without WithLockOSThread", err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package workerpool
+
+import "errors"
+
+func setThreadNice(int) error {
+	return errors.New("per-thread priority is not supported on this platform")
+}
@@ -1,5 +1,10 @@
 package workerpool
 
+import (
+	"context"
+	"time"
+)
+
 type Option func(*Pool)
 
 func WithBlock(block bool) Option { // 调用是否阻塞
@@ -8,8 +13,373 @@ func WithBlock(block bool) Option { // 调用是否阻塞
 	}
 }
 
+func WithName(name string) Option { // pool 的名称，作为日志前缀，便于区分同一进程中的多个 pool
+	return func(p *Pool) {
+		p.name = name
+	}
+}
+
+func WithLogger(logf func(format string, args ...any)) Option { // 日志输出函数，默认 fmt.Printf，nil 表示不打印日志
+	return func(p *Pool) {
+		p.logger = logf
+	}
+}
+
+func WithQueueSize(n int) Option { // 没有空闲 worker 时最多接受 n 个任务排队而不阻塞提交方，默认 0 即提交须等到 worker 取走任务
+	return func(p *Pool) {
+		if n < 0 {
+			p.invalid("WithQueueSize(%d): negative size", n)
+			return
+		}
+		p.queueSize = n
+	}
+}
+
 func WithPreAllocWorkers(preAlloc bool) Option { // 是否预创建 worker
 	return func(p *Pool) {
 		p.preAlloc = preAlloc
 	}
 }
+
+func WithMaxCapacity(n int) Option { // capacity 的上限，默认 10000，超出时 New 纠正为 n、NewE 返回错误；n <= 0 表示不限
+	return func(p *Pool) {
+		if n < 0 {
+			n = 0
+		}
+		p.maxCapacity = n
+	}
+}
+
+func WithContext(ctx context.Context) Option { // 任务 ctx 的基础 ctx，其中的值对所有任务可见，取消时所有任务的 ctx 随之取消
+	return func(p *Pool) {
+		if ctx == nil {
+			p.invalid("WithContext(nil)")
+			return
+		}
+		p.ctx = ctx
+	}
+}
+
+func WithContextValues(keys ...any) Option { // ScheduleCtx 提交的任务，其 ctx 中带上提交方 ctx 中这些 key 的值（如请求 ID、租户）
+	return WithContextPropagator(func(from, to context.Context) context.Context {
+		for _, k := range keys {
+			if v := from.Value(k); v != nil {
+				to = context.WithValue(to, k, v)
+			}
+		}
+		return to
+	})
+}
+
+func WithContextPropagator(fn func(from, to context.Context) context.Context) Option { // 自定义从提交方 ctx 到任务 ctx 的传递（如 OTel baggage、trace），可多次设置，按顺序执行
+	return func(p *Pool) {
+		if prev := p.propagate; prev != nil {
+			p.propagate = func(from, to context.Context) context.Context { return fn(from, prev(from, to)) }
+			return
+		}
+		p.propagate = fn
+	}
+}
+
+func WithMiddleware(mw func(next Task) Task) Option { // 包裹每个任务的执行（日志、指标、recover 等），可多次设置，先设置的在外层
+	return WithContextMiddleware(func(next TaskFunc) TaskFunc {
+		return func(ctx context.Context) { mw(func() { next(ctx) })() }
+	})
+}
+
+func WithContextMiddleware(mw func(next TaskFunc) TaskFunc) Option { // 与 WithMiddleware 相同，但可以读取和替换任务的 ctx（如 tracing span、鉴权信息）
+	return func(p *Pool) {
+		if prev := p.middleware; prev != nil {
+			p.middleware = func(next TaskFunc) TaskFunc { return prev(mw(next)) }
+			return
+		}
+		p.middleware = mw
+	}
+}
+
+func WithClock(c Clock) Option { // 注入时钟，测试中可使用 FakeClock 虚拟推进时间
+	return func(p *Pool) {
+		if c == nil {
+			p.invalid("WithClock(nil)")
+			return
+		}
+		p.clock = c
+	}
+}
+
+func WithFaultInjection(cfg FaultConfig) Option { // 混沌测试：按概率注入分发延迟、worker panic 与容量下降，默认关闭
+	return func(p *Pool) {
+		p.faults = newFaultInjector(cfg)
+	}
+}
+
+func WithWorkerInit(init func(workerID int) (any, error)) Option { // worker 启动钩子，返回值在任务中通过 WorkerValue(ctx) 获取
+	return func(p *Pool) {
+		p.workerInit = init
+	}
+}
+
+func WithWorkerTeardown(teardown func(workerID int, v any)) Option { // worker 退出钩子，v 为 WithWorkerInit 的返回值
+	return func(p *Pool) {
+		p.workerTeardown = teardown
+	}
+}
+
+func WithInitBackoff(base, max time.Duration) Option { // WithWorkerInit 连续失败时的重试间隔：从 base 起每次加倍直到 max，成功后重置，默认 100ms 与 10s
+	return func(p *Pool) {
+		if base < 0 || max < 0 || (max > 0 && base > max) {
+			p.invalid("WithInitBackoff(%s, %s): need 0 <= base <= max", base, max)
+			return
+		}
+		p.initDelay, p.initMaxDelay = base, max
+	}
+}
+
+func WithOnWorkerInitFailure(fn func(workerID int, err error, consecutive int)) Option { // WithWorkerInit 每次失败时调用，consecutive 为连续失败的次数，可用于告警
+	return func(p *Pool) {
+		p.onInitFailure = fn
+	}
+}
+
+func WithMaxTasksPerWorker(n int) Option { // worker 执行 n 个任务后退役并由新的 worker 替换，0 表示不限
+	return func(p *Pool) {
+		if n < 0 {
+			p.invalid("WithMaxTasksPerWorker(%d): negative count", n)
+			return
+		}
+		p.maxWorkerTasks = n
+	}
+}
+
+func WithMaxWorkerAge(d time.Duration) Option { // worker 存活 d 后退役并由新的 worker 替换，执行中的任务不受影响
+	return func(p *Pool) {
+		if d < 0 {
+			p.invalid("WithMaxWorkerAge(%s): negative age", d)
+			return
+		}
+		p.maxWorkerAge = d
+	}
+}
+
+func WithIdleTimeout(d time.Duration) Option { // worker 连续空闲 d 后退出，之后有任务时再按需创建，0 表示不退出
+	return func(p *Pool) {
+		if d < 0 {
+			p.invalid("WithIdleTimeout(%s): negative timeout", d)
+			return
+		}
+		p.idleTimeout = d
+	}
+}
+
+func WithFIFO() Option { // 通过 Schedule 系列提交的任务严格按被接受的顺序开始执行；提交依次进行，并发提交的吞吐会降低
+	return func(p *Pool) {
+		p.fifo = true
+	}
+}
+
+func WithFairSubmit() Option { // pool 已满时阻塞的提交方按到达顺序依次得到 worker，新的提交也不插队，避免部分提交方长期饥饿；WithFIFO 与 WithPriorityScheduling 下本就如此
+	return func(p *Pool) {
+		p.fair = make(chan struct{}, 1)
+	}
+}
+
+func WithPriorityScheduling(aging time.Duration) Option { // 排队的任务按 SchedulePriority 给出的优先级分发，每等待 aging 优先级加一以免低优先级任务饿死，0 表示不提升；与 WithFIFO 冲突
+	return func(p *Pool) {
+		if aging < 0 {
+			p.invalid("WithPriorityScheduling(%s): negative aging", aging)
+			return
+		}
+		p.priority, p.aging = true, aging
+	}
+}
+
+func WithPriorityWeights(weights map[int]int) Option { // 配合 WithPriorityScheduling 按权重在各优先级之间出队，如 {2: 8, 1: 2, 0: 1}，未列出的优先级权重为 1；不设置时为严格优先级，总是先分发最高的优先级
+	return func(p *Pool) {
+		w := make(map[int]int, len(weights))
+		for prio, n := range weights {
+			if n <= 0 {
+				p.invalid("WithPriorityWeights: non-positive weight %d for priority %d", n, prio)
+				return
+			}
+			w[prio] = n
+		}
+		p.weights = w
+	}
+}
+
+func WithPriorityEviction() Option { // 配合 WithPriorityScheduling 与 WithQueueSize：队列已满时移除排队中优先级最低的任务（原因为 ErrTaskEvicted，经由 WithOnDiscard 报告），给更高优先级的新任务腾出位置，而不是拒绝或阻塞它
+	return func(p *Pool) {
+		p.evict = true
+	}
+}
+
+func WithReservedWorkers(n int) Option { // 在 capacity 之外预留 n 个 worker，只供 ScheduleUrgent 提交的任务使用，pool 满载时紧急任务仍能立即执行
+	return func(p *Pool) {
+		if n < 0 {
+			p.invalid("WithReservedWorkers(%d): negative count", n)
+			return
+		}
+		p.reserved = nil
+		if n > 0 {
+			p.reserved = make(chan struct{}, n)
+		}
+	}
+}
+
+func WithOverflow(max int, cooldown time.Duration) Option { // 饱和时在 capacity 之外临时启动至多 max 个 worker 吸收突发，它们空闲 cooldown 后退出，平时的 goroutine 数不变；与 WithPriorityScheduling、WithFIFO 冲突
+	return func(p *Pool) {
+		if max < 0 || (max > 0 && cooldown <= 0) {
+			p.invalid("WithOverflow(%d, %s): max must not be negative and cooldown must be positive", max, cooldown)
+			return
+		}
+		p.overflow, p.overflowCooldown = nil, cooldown
+		if max > 0 {
+			p.overflow = make(chan struct{}, max)
+		}
+	}
+}
+
+func WithTaskBudget(d time.Duration) Option { // 任务的默认执行预算：超出时取消任务 ctx 并放弃它，由新的 worker 接替其容量，见 AbandonedTasks；0 表示不限
+	return func(p *Pool) {
+		if d < 0 {
+			p.invalid("WithTaskBudget(%s): negative budget", d)
+			return
+		}
+		p.budget = d
+	}
+}
+
+func WithHealthcheck(probeTimeout, stuckAfter time.Duration) Option { // Healthcheck 探测任务的等待上限（默认 1s），以及执行同一任务超过 stuckAfter 的 worker 视为卡住（0 表示不检查）
+	return func(p *Pool) {
+		if probeTimeout < 0 || stuckAfter < 0 {
+			p.invalid("WithHealthcheck(%s, %s): negative duration", probeTimeout, stuckAfter)
+			return
+		}
+		p.probeTimeout, p.stuckAfter = probeTimeout, stuckAfter
+	}
+}
+
+func WithDropCanceled() Option { // ScheduleCtx 提交的任务轮到执行时，提交方的 ctx 已取消或超时则不再执行，经由 WithOnDiscard 报告（原因为 context.Cause），避免为已经放弃的调用方白白占用容量
+	return func(p *Pool) {
+		p.dropCanceled = true
+	}
+}
+
+func WithDrainOnFree() Option { // Free 先执行完所有已接受的任务（含排队中的）再通知 worker 退出，ScheduleDetached 的任务与 Job 队列除外；期间新的提交返回 ErrPoolClosed
+	return func(p *Pool) {
+		p.drainFree = true
+	}
+}
+
+func WithLockOSThread() Option { // 每个 worker 在整个生命周期内绑定独立的 OS 线程，init 钩子与任务都在该线程上执行
+	return func(p *Pool) {
+		p.lockOSThread = true
+	}
+}
+
+func WithWorkerNice(nice int) Option { // 尽力调整 worker 线程的调度优先级（Linux 的 nice 值，1~19 为调低），用于后台批处理的 pool 给前台请求让出 CPU；需配合 WithLockOSThread，紧急任务的 worker 不受影响
+	return func(p *Pool) {
+		if nice < -20 || nice > 19 {
+			p.invalid("WithWorkerNice(%d): out of range [-20, 19]", nice)
+			return
+		}
+		p.nice = nice
+	}
+}
+
+func WithBlockingTasks(maxThreads int) Option { // 任务会长时间阻塞在系统调用/cgo 中，单独统计占用的线程，maxThreads > 0 时按需调高进程线程上限
+	return func(p *Pool) {
+		if maxThreads < 0 {
+			p.invalid("WithBlockingTasks(%d): negative thread limit", maxThreads)
+			maxThreads = 0
+		}
+		p.blockingMode = true
+		p.maxThreads = maxThreads
+	}
+}
+
+func WithTimerTick(d time.Duration) Option { // 延迟/周期任务所用时间轮的精度，默认 1ms，任务最多晚一个 tick 触发
+	return func(p *Pool) {
+		if d <= 0 {
+			p.invalid("WithTimerTick(%s): tick must be positive", d)
+			return
+		}
+		p.timers.tick = d
+	}
+}
+
+func WithWAL(path string) Option { // Enqueue 提交的 Job 先写入 path 处的预写日志，崩溃后不丢失已接受的 Job
+	return func(p *Pool) {
+		l, err := openWAL(path)
+		if err != nil {
+			p.invalid("WithWAL(%q): %w", path, err)
+			l = &wal{err: err} // 之后的 Enqueue 返回该错误，而不是静默地不持久化
+		}
+		l.logf = p.logf // 调用时读取 p.logger，不受选项顺序影响
+		p.wal = l
+	}
+}
+
+func WithQueue(q Queue) Option { // Enqueue 提交的 Job 存放在 q 中，默认为进程内的 MemoryQueue
+	return func(p *Pool) {
+		if q == nil {
+			p.invalid("WithQueue(nil)")
+			return
+		}
+		p.queue = q
+	}
+}
+
+func WithExplicitAck(timeout time.Duration) Option { // Job 须在处理函数中调用 Ack(ctx) 确认，timeout 内未确认则重新投递
+	return func(p *Pool) {
+		p.visibility = timeout
+		p.explicitAck = true
+	}
+}
+
+func WithVisibilityTimeout(timeout time.Duration) Option { // Job 执行超过 timeout 仍未结束（如任务卡死）时重新投递给其它 worker
+	return func(p *Pool) {
+		if timeout < 0 {
+			p.invalid("WithVisibilityTimeout(%s): negative timeout", timeout)
+			return
+		}
+		p.visibility = timeout
+	}
+}
+
+func WithIdempotency(store IdempotencyStore, ttl time.Duration) Option { // 记录成功执行的 Job.Key 并在 ttl 内跳过重复的 Job，store 为 nil 时使用 MemoryIdempotencyStore
+	return func(p *Pool) {
+		if ttl < 0 {
+			p.invalid("WithIdempotency: negative ttl %s", ttl)
+			ttl = 0
+		}
+		if store == nil {
+			store = NewMemoryIdempotencyStore()
+		}
+		p.idem = store
+		p.idemTTL = ttl
+	}
+}
+
+func WithOnDiscard(fn func(reason error)) Option { // 已提交的任务或 Job 未执行即被丢弃（如 Purge、Free）时调用
+	return func(p *Pool) {
+		p.onDiscard = fn
+	}
+}
+
+func WithReentrantPolicy(policy ReentrantPolicy) Option { // pool 已满时从本 pool 的任务内提交任务的处理方式，默认 ReentrantBlock
+	return func(p *Pool) {
+		if policy < ReentrantBlock || policy > ReentrantError {
+			p.invalid("WithReentrantPolicy(%d): unknown policy", policy)
+			return
+		}
+		p.reentrant = policy
+	}
+}
+
+func WithDeadlockDetection(report func(*DeadlockError)) Option { // 检测 pool 之间（或对自身）的等待环，形成环的提交返回 *DeadlockError 并调用 report，nil 时打印；环中的 pool 都需开启
+	return func(p *Pool) {
+		p.detectDeadlock = true
+		p.onDeadlock = report
+	}
+}
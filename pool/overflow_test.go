@@ -0,0 +1,53 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOverflowWorkers(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	p, release := newBusyPool(t, WithClock(clock), WithOverflow(2, time.Minute))
+	defer p.Free()
+	defer release()
+
+	gate := make(chan struct{})
+	started := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		if err := p.Schedule(func() { started <- struct{}{}; <-gate }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	<-started
+	if s := p.Stats(); s.Overflow != 2 || s.Workers != 1 {
+		t.Fatalf("Overflow = %d, Workers = %d, want 2 and 1", s.Overflow, s.Workers)
+	}
+	if err := p.TryScheduleFunc(func(context.Context) {}); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated beyond the overflow limit", err)
+	}
+
+	close(gate)
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Overflow != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("overflow workers did not retire after the cooldown")
+		}
+		clock.Advance(time.Minute)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOverflowValidation(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithOverflow(-1, time.Second)},
+		{WithOverflow(1, 0)},
+		{WithOverflow(1, time.Second), WithFIFO()},
+	} {
+		if _, err := NewE(1, opts...); !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("err = %v, want ErrInvalidOption", err)
+		}
+	}
+}
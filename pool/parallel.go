@@ -0,0 +1,182 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"runtime/debug"
+	"slices"
+	"sync"
+)
+
+// Map 在 pool 上对 in 的每个元素并发执行 fn，按输入顺序返回结果
+// 所有元素都会被处理，出错元素对应的结果为零值，错误以 "item i: ..." 的形式合并返回；
+// 提交失败（如 pool 已销毁）时剩余元素不再提交，各自记为该错误
+func Map[T, R any](p *Pool, in []T, fn func(T) (R, error)) ([]R, error) {
+	out := make([]R, len(in))
+	errs := make([]error, len(in))
+	var wg sync.WaitGroup
+	for i := range in {
+		i := i
+		wg.Add(1)
+		err := p.scheduleOr(task{fn: func() {
+			defer wg.Done()
+			if r, err := protect(in[i], fn); err != nil {
+				errs[i] = err
+			} else {
+				out[i] = r
+			}
+		}}, func(reason error) {
+			errs[i] = reason
+			wg.Done()
+		})
+		if err != nil {
+			wg.Done()
+			for j := i; j < len(in); j++ {
+				errs[j] = err
+			}
+			break
+		}
+	}
+	wg.Wait()
+	return out, joinItemErrors(errs)
+}
+
+// 调用 fn 并将 panic 转换为 *PanicError，避免 worker 退出导致等待方永远阻塞
+func protect[T, R any](v T, fn func(T) (R, error)) (r R, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = &PanicError{Value: x, Stack: debug.Stack()}
+		}
+	}()
+	return fn(v)
+}
+
+func joinItemErrors(errs []error) error {
+	var list []error
+	for i, err := range errs {
+		if err != nil {
+			list = append(list, fmt.Errorf("item %d: %w", i, err))
+		}
+	}
+	return errors.Join(list...)
+}
+
+// ForEach 在 pool 上对 items 的每个元素并发执行 fn，等待全部结束后返回
+// 任一 fn 返回错误（或 panic）时取消传给其它 fn 的 ctx 并停止提交剩余元素，返回第一个错误；
+// ctx 被取消时同样停止提交，返回 ctx 的错误
+func ForEach[T any](ctx context.Context, p *Pool, items []T, fn func(ctx context.Context, v T) error) error {
+	return ForEachSeq(ctx, p, slices.Values(items), fn)
+}
+
+// ForEachSeq 与 ForEach 相同，元素来自迭代器 seq，提交速度受 pool 容量约束
+func ForEachSeq[T any](ctx context.Context, p *Pool, seq iter.Seq[T], fn func(ctx context.Context, v T) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+	)
+	fail := func(e error) {
+		once.Do(func() {
+			err = e
+			cancel(e)
+		})
+	}
+	for v := range seq {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		serr := p.scheduleOr(task{fn: func() {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
+			}
+			if _, e := protect(v, func(v T) (struct{}, error) { return struct{}{}, fn(ctx, v) }); e != nil {
+				fail(e)
+			}
+		}}, func(reason error) {
+			fail(reason)
+			wg.Done()
+		})
+		if serr != nil {
+			wg.Done()
+			fail(serr)
+			break
+		}
+	}
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	return context.Cause(ctx)
+}
+
+// Filter 在 pool 上并发地对每个元素调用 keep，按输入顺序返回 keep 为 true 的元素，错误处理同 Map
+func Filter[T any](p *Pool, in []T, keep func(T) (bool, error)) ([]T, error) {
+	flags, err := Map(p, in, keep)
+	out := make([]T, 0, len(in))
+	for i, ok := range flags {
+		if ok {
+			out = append(out, in[i])
+		}
+	}
+	return out, err
+}
+
+// Reduce 用满足结合律的 combine 归约 in：元素按 pool 容量切分为若干连续的段，
+// 各段在 worker 上并发归约，再按顺序合并各段结果，因此 combine 无需满足交换律
+// in 为空时返回零值；任一段出错时返回零值与合并后的错误
+func Reduce[T any](p *Pool, in []T, combine func(a, b T) T) (T, error) {
+	var zero T
+	if len(in) == 0 {
+		return zero, nil
+	}
+	partials, err := Map(p, splitChunks(p, in, 0), func(c []T) (T, error) {
+		acc := c[0]
+		for _, v := range c[1:] {
+			acc = combine(acc, v)
+		}
+		return acc, nil
+	})
+	if err != nil {
+		return zero, err
+	}
+	acc := partials[0]
+	for _, v := range partials[1:] {
+		acc = combine(acc, v)
+	}
+	return acc, nil
+}
+
+// ForEachChunk 将 in 切分为若干个连续的段，每段作为一个任务在 pool 上执行 fn，等待全部结束后返回，
+// 避免一个元素一个任务时调度开销超过任务本身；start 为该段在 in 中的起始下标
+// size 为每段的元素数，size <= 0 时按 pool 容量均分；出错的段以 "item i: ..." 合并返回，i 为段的序号
+func ForEachChunk[T any](p *Pool, in []T, size int, fn func(start int, chunk []T) error) error {
+	chunks := splitChunks(p, in, size)
+	idx := make([]int, len(chunks))
+	for i := range idx {
+		idx[i] = i
+	}
+	_, err := Map(p, idx, func(i int) (struct{}, error) {
+		return struct{}{}, fn(i*len(chunks[0]), chunks[i])
+	})
+	return err
+}
+
+// 将 in 切分为每段 size 个元素，size <= 0 时切分为 pool 容量个段
+func splitChunks[T any](p *Pool, in []T, size int) [][]T {
+	if size <= 0 {
+		size = max(1, (len(in)+p.Cap()-1)/p.Cap())
+	}
+	chunks := make([][]T, 0, (len(in)+size-1)/size)
+	for len(in) > 0 {
+		n := min(size, len(in))
+		chunks = append(chunks, in[:n:n])
+		in = in[n:]
+	}
+	return chunks
+}
@@ -0,0 +1,131 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMapZeroOnError(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	boom := errors.New("boom")
+	out, err := Map(p, []int{1, 2, 3}, func(v int) (int, error) {
+		if v == 2 {
+			return 20, boom
+		}
+		return v * 10, nil
+	})
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "item 1") || !slices.Equal(out, []int{10, 0, 30}) {
+		t.Fatalf("Map = %v, %v", out, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	in := make([]int, 100)
+	for i := range in {
+		in[i] = i
+	}
+	even, err := Filter(p, in, func(v int) (bool, error) { return v%2 == 0, nil })
+	if err != nil || len(even) != 50 || !slices.IsSorted(even) || even[49] != 98 {
+		t.Fatalf("Filter = %v, %v", even, err)
+	}
+
+	boom := errors.New("boom")
+	out, err := Filter(p, []int{1, 2, 3}, func(v int) (bool, error) {
+		if v == 2 {
+			return true, boom
+		}
+		return true, nil
+	})
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "item 1") || !slices.Equal(out, []int{1, 3}) {
+		t.Fatalf("Filter with an error = %v, %v", out, err)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	p := New(3, WithLogger(nil))
+	defer p.Free()
+	in := make([]string, 26)
+	for i := range in {
+		in[i] = string(rune('a' + i))
+	}
+	// 字符串拼接满足结合律但不满足交换律，各段须按顺序合并
+	got, err := Reduce(p, in, func(a, b string) string { return a + b })
+	if err != nil || got != "abcdefghijklmnopqrstuvwxyz" {
+		t.Fatalf("Reduce = %q, %v", got, err)
+	}
+	if got, err := Reduce(p, []int{}, func(a, b int) int { return a + b }); got != 0 || err != nil {
+		t.Fatalf("Reduce of nothing = %d, %v", got, err)
+	}
+	if got, _ := Reduce(p, []int{5}, func(a, b int) int { return a + b }); got != 5 {
+		t.Fatalf("Reduce of one element = %d", got)
+	}
+	_, err = Reduce(p, []int{1, 2, 3, 4}, func(a, b int) int { panic("combine") })
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Reduce with a panicking combine = %v", err)
+	}
+}
+
+func TestReduceSum(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	in := make([]int, 1000)
+	for i := range in {
+		in[i] = i + 1
+	}
+	if got, err := Reduce(p, in, func(a, b int) int { return a + b }); got != 500500 || err != nil {
+		t.Fatalf("Reduce = %d, %v", got, err)
+	}
+}
+
+func TestForEachChunk(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	in := make([]int, 10)
+	for i := range in {
+		in[i] = i
+	}
+	var mu sync.Mutex
+	starts := map[int]int{}
+	err := ForEachChunk(p, in, 3, func(start int, chunk []int) error {
+		if chunk[0] != start {
+			return fmt.Errorf("chunk %v starts at %d", chunk, start)
+		}
+		mu.Lock()
+		starts[start] = len(chunk)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil || !maps.Equal(starts, map[int]int{0: 3, 3: 3, 6: 3, 9: 1}) {
+		t.Fatalf("ForEachChunk = %v, chunks %v", err, starts)
+	}
+
+	// size <= 0 时按容量均分为 4 段
+	var n atomic.Int32
+	if err := ForEachChunk(p, in, 0, func(start int, chunk []int) error { n.Add(1); return nil }); err != nil || n.Load() != 4 {
+		t.Fatalf("ForEachChunk(size 0) = %v, %d chunks", err, n.Load())
+	}
+	if err := ForEachChunk(p, []int{}, 0, func(int, []int) error { return errors.New("called") }); err != nil {
+		t.Fatalf("ForEachChunk of nothing = %v", err)
+	}
+
+	boom := errors.New("boom")
+	err = ForEachChunk(p, in, 5, func(start int, chunk []int) error {
+		if start == 5 {
+			return boom
+		}
+		return nil
+	})
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "item 1") {
+		t.Fatalf("ForEachChunk with an error = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package workerpool
+
+import (
+	"sort"
+	"time"
+)
+
+// PendingKind 表示待执行任务的来源
+type PendingKind int
+
+const (
+	PendingAfter   PendingKind = iota // ScheduleAfter
+	PendingAt                         // ScheduleAt
+	PendingEvery                      // ScheduleEvery
+	PendingCron                       // Cron
+	PendingRequeue                    // RequeueSelf
+)
+
+func (k PendingKind) String() string {
+	switch k {
+	case PendingAfter:
+		return "after"
+	case PendingAt:
+		return "at"
+	case PendingEvery:
+		return "every"
+	case PendingCron:
+		return "cron"
+	case PendingRequeue:
+		return "requeue"
+	}
+	return "unknown"
+}
+
+// PendingTask 描述一个尚未触发的任务
+type PendingTask struct {
+	ID   uint64
+	Kind PendingKind
+	Due  time.Time // 下一次触发时间
+}
+
+// Pending 返回所有尚未触发的延迟任务与周期任务，按触发时间排序
+func (p *Pool) Pending() []PendingTask {
+	p = p.applied()
+	w := &p.timers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list := make([]PendingTask, 0, w.count)
+	w.each(func(e *timerEntry) bool {
+		due := w.timeOf(e.expire)
+		if !e.at.IsZero() {
+			due = e.at
+		}
+		list = append(list, PendingTask{ID: e.id, Kind: e.kind, Due: due})
+		return false
+	})
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Due.Equal(list[j].Due) {
+			return list[i].Due.Before(list[j].Due)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// RemovePending 移除编号为 id 的待触发任务，返回是否找到；周期任务移除后不再触发
+func (p *Pool) RemovePending(id uint64) bool {
+	p = p.applied()
+	return p.RemovePendingFunc(func(t PendingTask) bool { return t.ID == id }) > 0
+}
+
+// RemovePendingFunc 移除所有使 match 返回 true 的待触发任务，返回移除的数量
+// match 在内部锁中调用，不能再调用 pool 的方法
+func (p *Pool) RemovePendingFunc(match func(PendingTask) bool) int {
+	p = p.applied()
+	w := &p.timers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := 0
+	w.each(func(e *timerEntry) bool {
+		due := w.timeOf(e.expire)
+		if !e.at.IsZero() {
+			due = e.at
+		}
+		if match(PendingTask{ID: e.id, Kind: e.kind, Due: due}) {
+			n++
+			return true
+		}
+		return false
+	})
+	return n
+}
+
+// 遍历时间轮中的所有任务，fn 返回 true 时将其移除；需持有 w.mu
+func (w *timerWheel) each(fn func(e *timerEntry) bool) {
+	for l := range w.slots {
+		for s := range w.slots[l] {
+			for e := w.slots[l][s]; e != nil; {
+				next := e.next
+				if fn(e) {
+					w.unlink(e)
+				}
+				e = next
+			}
+		}
+	}
+}
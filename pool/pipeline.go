@@ -0,0 +1,120 @@
+package workerpool
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Pipeline 将若干 Stage 串联起来：相邻阶段之间由 channel 连接，
+// 下游来不及处理时上游的 worker 阻塞在发送上，形成背压；
+// 任一阶段出错时取消整条流水线，Wait 返回第一个错误
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup // 流水线内的所有 goroutine 与执行中的任务
+	once   sync.Once
+	err    error
+}
+
+// Stage 是流水线中的一个阶段：在 p 上同时最多执行 workers 个 fn，
+// 将 In 转换为 Out；workers <= 0 时取 p 的容量
+type Stage[In, Out any] struct {
+	p       *Pool
+	workers int
+	fn      func(ctx context.Context, v In) (Out, error)
+}
+
+func NewStage[In, Out any](p *Pool, workers int, fn func(ctx context.Context, v In) (Out, error)) *Stage[In, Out] {
+	if workers <= 0 {
+		workers = p.Cap()
+	}
+	return &Stage[In, Out]{p: p, workers: workers, fn: fn}
+}
+
+func NewPipeline(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// Context 返回流水线的 ctx，流水线出错或被 Cancel 后被取消
+func (pl *Pipeline) Context() context.Context {
+	return pl.ctx
+}
+
+// Cancel 取消流水线，各阶段停止读取新的元素
+func (pl *Pipeline) Cancel() {
+	pl.fail(context.Canceled)
+}
+
+// Wait 等待流水线中所有的 goroutine 与任务结束，返回第一个错误；
+// 调用方自行消费最后一个阶段的输出时，需要先读完（或 Cancel）再 Wait
+func (pl *Pipeline) Wait() error {
+	pl.wg.Wait()
+	if pl.err == nil {
+		pl.fail(context.Cause(pl.ctx)) // 外部 ctx 被取消
+	}
+	pl.cancel(nil)
+	return pl.err
+}
+
+func (pl *Pipeline) fail(err error) {
+	pl.once.Do(func() {
+		pl.err = err
+		pl.cancel(err)
+	})
+}
+
+// Source 将 seq 作为流水线的输入
+func Source[T any](pl *Pipeline, seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	pl.wg.Add(1)
+	go func() {
+		defer pl.wg.Done()
+		defer close(out)
+		for v := range seq {
+			select {
+			case out <- v:
+			case <-pl.ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Pipe 将 in 接入阶段 s，返回 s 的输出；in 关闭且执行中的任务结束后输出随之关闭
+// 输出不保证与输入顺序一致
+func Pipe[In, Out any](pl *Pipeline, in <-chan In, s *Stage[In, Out]) <-chan Out {
+	fn := func(ctx context.Context, v In) (Out, bool) {
+		r, err := protect(v, func(v In) (Out, error) { return s.fn(ctx, v) })
+		if err != nil {
+			pl.fail(err)
+			return r, false
+		}
+		return r, true
+	}
+	return fanOut(pl.ctx, s.p, in, fn, fanOutConfig{workers: s.workers, onErr: pl.fail, wg: &pl.wg})
+}
+
+// Drain 在调用方一侧逐个消费 in，作为流水线的终点；fn 返回错误时取消流水线
+func Drain[T any](pl *Pipeline, in <-chan T, fn func(v T) error) {
+	pl.wg.Add(1)
+	go func() {
+		defer pl.wg.Done()
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				if err := fn(v); err != nil {
+					pl.fail(err)
+					return
+				}
+			case <-pl.ctx.Done():
+				return
+			}
+		}
+	}()
+}
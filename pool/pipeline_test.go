@@ -0,0 +1,118 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	p := New(8, WithLogger(nil))
+	defer p.Free()
+	pl := NewPipeline(context.Background())
+	var running, peak atomic.Int32
+	double := NewStage(p, 2, func(ctx context.Context, v int) (int, error) {
+		n := running.Add(1)
+		for {
+			if old := peak.Load(); n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		defer running.Add(-1)
+		return v * 2, nil
+	})
+	format := NewStage(p, 0, func(ctx context.Context, v int) (string, error) { return strconv.Itoa(v), nil })
+	var mu sync.Mutex
+	var got []string
+	Drain(pl, Pipe(pl, Pipe(pl, Source(pl, slices.Values([]int{1, 2, 3, 4, 5})), double), format), func(s string) error {
+		mu.Lock()
+		got = append(got, s)
+		mu.Unlock()
+		return nil
+	})
+	if err := pl.Wait(); err != nil {
+		t.Fatalf("Wait = %v", err)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"10", "2", "4", "6", "8"}) {
+		t.Fatalf("output = %v", got)
+	}
+	if peak.Load() > 2 {
+		t.Fatalf("stage ran %d at once, want at most 2", peak.Load())
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	boom := errors.New("boom")
+	pl := NewPipeline(context.Background())
+	s := NewStage(p, 2, func(ctx context.Context, v int) (int, error) {
+		if v == 3 {
+			return 0, boom
+		}
+		return v, nil
+	})
+	naturals := func(yield func(int) bool) { // 无限的输入，只有取消才能让流水线结束
+		for i := 0; yield(i); i++ {
+		}
+	}
+	Drain(pl, Pipe(pl, Source(pl, naturals), s), func(int) error { return nil })
+	if err := pl.Wait(); !errors.Is(err, boom) {
+		t.Fatalf("Wait = %v, want %v", err, boom)
+	}
+	if pl.Context().Err() == nil {
+		t.Fatal("pipeline context not canceled after a stage failed")
+	}
+
+	// Stage 中的 panic 以 *PanicError 返回
+	pl = NewPipeline(context.Background())
+	s = NewStage(p, 1, func(ctx context.Context, v int) (int, error) { panic("stage") })
+	Drain(pl, Pipe(pl, Source(pl, naturals), s), func(int) error { return nil })
+	var pe *PanicError
+	if err := pl.Wait(); !errors.As(err, &pe) {
+		t.Fatalf("Wait with a panicking stage = %v", err)
+	}
+
+	// Drain 的错误同样取消流水线
+	pl = NewPipeline(context.Background())
+	s = NewStage(p, 1, func(ctx context.Context, v int) (int, error) { return v, nil })
+	Drain(pl, Pipe(pl, Source(pl, naturals), s), func(int) error { return boom })
+	if err := pl.Wait(); !errors.Is(err, boom) {
+		t.Fatalf("Wait with a failing Drain = %v", err)
+	}
+}
+
+func TestPipelineCancel(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	pl := NewPipeline(context.Background())
+	s := NewStage(p, 1, func(ctx context.Context, v int) (int, error) { return v, nil })
+	out := Pipe(pl, Source(pl, func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}), s)
+	<-out
+	pl.Cancel()
+	for range out { // 取消后输出随之关闭
+	}
+	if err := pl.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait after Cancel = %v", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := errors.New("shutdown")
+	pl = NewPipeline(ctx)
+	Drain(pl, Source(pl, func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}), func(int) error { return nil })
+	cancel(cause)
+	if err := pl.Wait(); !errors.Is(err, cause) {
+		t.Fatalf("Wait after the parent ctx was canceled = %v", err)
+	}
+}
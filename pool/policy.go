@@ -0,0 +1,75 @@
+package workerpool
+
+import (
+	"sort"
+	"time"
+)
+
+// SchedulingPolicy 决定排队任务的准入与出队顺序，用于实现业务相关的公平性、按模型预测排序等内置选项覆盖不到的调度，见 WithSchedulingPolicy。
+// 任务仍由优先级队列保存，Purge、WithPriorityEviction 与销毁照常生效；三个方法均在队列的内部锁中调用，
+// 不能再调用 pool 的方法，应尽快返回
+type SchedulingPolicy interface {
+	// Admit 在每次提交时最先调用，queued 为当前排队的任务数；返回非 nil 时拒绝该任务，提交返回该错误
+	Admit(t QueuedTask, queued int) error
+	// Enqueue 在任务进入队列时调用，供策略维护自己的状态；直接交给空闲 worker 的任务不调用
+	Enqueue(t QueuedTask)
+	// Next 从排队的任务中选出下一个交给 worker 的任务，返回它在 queue 中的下标，越界时取 queue[0]；
+	// queue 不为空，按默认顺序排列（优先级、WithShortestJobFirst、到达顺序），只在本次调用中有效
+	Next(queue []QueuedTask) int
+}
+
+// QueuedTask 是交给 SchedulingPolicy 的排队任务
+type QueuedTask struct {
+	Seq      uint64    // 进入队列的顺序，Admit 时为 0
+	Priority int       // SchedulePriority 或 WithPriority 的优先级
+	Enqueued time.Time // 提交的时刻
+	Info     *TaskInfo // ScheduleWith 提交的任务的元数据，其它任务为 nil；不应修改
+}
+
+func WithSchedulingPolicy(policy SchedulingPolicy) Option { // 由 policy 决定排队任务的准入与出队顺序，见 SchedulingPolicy；开启优先级队列，与 WithFIFO、WithPriorityWeights 冲突
+	return func(p *Pool) {
+		if policy == nil {
+			p.invalid("WithSchedulingPolicy: nil policy")
+			return
+		}
+		p.policy = policy
+	}
+}
+
+func queuedTask(t *task, seq uint64) QueuedTask {
+	return QueuedTask{Seq: seq, Priority: t.prio, Enqueued: t.enqueued, Info: t.info}
+}
+
+// admit 以 WithSchedulingPolicy 的 Admit 决定是否接受 t
+func (p *Pool) admit(t *task) error {
+	s := p.sched
+	if s.policy == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.policy.Admit(queuedTask(t, 0), s.n)
+}
+
+// 需持有 s.mu：由 WithSchedulingPolicy 选出下一个出队的条目，没有排队的条目时返回 nil
+func (s *scheduler) pick() *schedEntry {
+	for _, h := range s.levels {
+		s.order = append(s.order, *h...)
+	}
+	if len(s.order) == 0 {
+		return nil
+	}
+	sort.Slice(s.order, func(i, j int) bool { return ahead(s.order[i], s.order[j]) })
+	for _, e := range s.order {
+		s.view = append(s.view, queuedTask(&e.t, e.seq))
+	}
+	i := s.policy.Next(s.view)
+	if i < 0 || i >= len(s.order) {
+		i = 0
+	}
+	e := s.order[i]
+	clear(s.order) // 不保留已出队的任务
+	clear(s.view)
+	s.order, s.view = s.order[:0], s.view[:0]
+	return e
+}
@@ -0,0 +1,94 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var errTenantBlocked = errors.New("tenant blocked")
+
+// tenantPolicy 在租户之间轮流出队，拒绝被封禁的租户
+type tenantPolicy struct {
+	last     string
+	enqueued int
+}
+
+func tenantOf(t QueuedTask) string {
+	if t.Info == nil {
+		return ""
+	}
+	return t.Info.Labels["tenant"]
+}
+
+func (tp *tenantPolicy) Admit(t QueuedTask, queued int) error {
+	if tenantOf(t) == "blocked" {
+		return errTenantBlocked
+	}
+	return nil
+}
+
+func (tp *tenantPolicy) Enqueue(QueuedTask) { tp.enqueued++ }
+
+func (tp *tenantPolicy) Next(queue []QueuedTask) int {
+	pick := 0
+	for i, t := range queue {
+		if tenantOf(t) != tp.last {
+			pick = i
+			break
+		}
+	}
+	tp.last = tenantOf(queue[pick])
+	return pick
+}
+
+func TestSchedulingPolicy(t *testing.T) {
+	tp := &tenantPolicy{}
+	p, release := newBusyPool(t, WithSchedulingPolicy(tp), WithQueueSize(10))
+	defer p.Free()
+	var mu sync.Mutex
+	var got []string
+	submit := func(tenant, name string) error {
+		return p.ScheduleWith(func(context.Context) {
+			mu.Lock()
+			got = append(got, name)
+			mu.Unlock()
+		}, WithTaskLabel("tenant", tenant))
+	}
+	for _, n := range []string{"a1", "a2", "a3", "a4"} {
+		if err := submit("a", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, n := range []string{"b1", "b2"} {
+		if err := submit("b", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := submit("blocked", "x"); !errors.Is(err, errTenantBlocked) {
+		t.Fatalf("blocked tenant: err = %v", err)
+	}
+	release()
+	p.Wait()
+	// a1 在其它任务到达前已被选出，之后两个租户轮流出队
+	if want := []string{"a1", "b1", "a2", "b2", "a3", "a4"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	if tp.enqueued != 6 {
+		t.Fatalf("Enqueue called %d times, want 6", tp.enqueued)
+	}
+}
+
+func TestSchedulingPolicyConflicts(t *testing.T) {
+	if _, err := NewE(1, WithSchedulingPolicy(&tenantPolicy{}), WithFIFO()); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("WithFIFO: err = %v", err)
+	}
+	if _, err := NewE(1, WithSchedulingPolicy(&tenantPolicy{}), WithPriorityWeights(map[int]int{1: 2})); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("WithPriorityWeights: err = %v", err)
+	}
+	if _, err := NewE(1, WithSchedulingPolicy(nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("nil policy: err = %v", err)
+	}
+}
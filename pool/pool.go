@@ -1,151 +1,1098 @@
 package workerpool
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	defaultCapacity = 100
-	maxCapacity     = 10000
+	defaultCapacity    = 100
+	defaultMaxCapacity = 10000 // 可通过 WithMaxCapacity 修改
 )
 
-var (
-	ErrNoIdleWorkerInPool = errors.New("no idle worker in pool")
-	ErrWorkerPoolFreed    = errors.New("wokerpool freed")
-)
+var ErrInvalidOption = errors.New("invalid option")
 
 type Task func()
 
 type Pool struct {
-	capacity int
-	preAlloc bool // 是否在创建pool的时候，就预创建workers，默认值为：false
+	id          uint64
+	capacity    int
+	workerSeq   atomic.Int64 // worker 编号
+	maxCapacity int          // capacity 的上限，0 表示不限
+	optErrs     []error      // 应用选项时发现的问题，见 NewE
+	name        string       // WithName，出现在日志前缀中
+	queueSize   int          // 已接受但尚未被 worker 取走的任务数上限，0 表示不缓冲
+	handoff     chan task    // 带缓冲时直接交给空闲 worker 的无缓冲 channel，见 handOff
+	preAlloc    bool         // 是否在创建pool的时候，就预创建workers，默认值为：false
 
 	// 当pool满的情况下，新的Schedule调用是否阻塞当前goroutine。默认值：true
 	// 如果block = false，则Schedule返回ErrNoWorkerAvailInPool
 	block  bool
 	active chan struct{}  // 有缓冲 channel，用于记录当前活跃的 worker 数量
-	tasks  chan Task      // 无缓冲 channel
+	tasks  chan task      // 交给空闲 worker 的任务，WithQueueSize 时带缓冲
 	wg     sync.WaitGroup // 销毁时等待所有 worker 退出
 	quit   chan struct{}  // 通知各个 worker 退出的信号
+
+	ctx       context.Context // 所有任务 ctx 的基础，来自 WithContext，Free 时取消
+	cancel    context.CancelCauseFunc
+	freeCause error // FreeWithCause 的原因，在 closed 置位前写入
+
+	closeMu    sync.RWMutex         // 保护 closed：置位后不再有新的提交开始
+	closed     bool                 // Free 已开始
+	submitters sync.WaitGroup       // 进行中的提交，Free 等待它们返回后再清理残留的任务
+	closing    chan struct{}        // Free 开始时关闭，唤醒阻塞中的提交方
+	freed      chan struct{}        // Free 完成时关闭，重复调用 Free 时等待它
+	drainFree  bool                 // WithDrainOnFree
+	successor  atomic.Pointer[Pool] // HandoffTo 的接替者，之后的提交都转交给它
+	forward    atomic.Bool          // 由 ApplyOptions 替换：p 的所有方法都作用于 successor，见 applied
+	applyMu    sync.Mutex           // 串行化 ApplyOptions
+	opts       []Option             // 创建时的选项，ApplyOptions 在此基础上新建 pool
+	global     bool                 // WithGlobalStats
+	allocs     *allocSampler        // WithAllocSampling，nil 表示不采样
+	observers  []func(Event)        // 生命周期事件的接收方，如 WithAuditWriter
+	alerts     []*alert             // WithAlert 的告警条件
+	events     *eventChannel        // WithEvents，nil 表示不发出
+	metrics    MetricsCollector     // WithMetrics，nil 表示不上报
+	blocked    atomic.Int64         // 阻塞在提交中等待 worker 的提交方数，见 PoolStats.Blocked
+	queueWait  latencyRecorder      // 任务从提交到开始执行的等待时间
+	execTime   latencyRecorder      // 任务的执行时间
+	spinUp     latencyRecorder      // worker 从创建到可以执行任务（含 WithWorkerInit）的时间
+	recorder   *eventRecorder       // WithEventRecorder，nil 表示不记录
+
+	fifo     bool          // WithFIFO
+	fifoMu   chan struct{} // WithFIFO 下按到达顺序串行化提交
+	fifoPrev chan struct{} // 最近一个被接受的任务的 fifoTurn.started，由 fifoMu 保护
+
+	priority bool          // WithPriorityScheduling
+	aging    time.Duration // WithPriorityScheduling 的 aging
+	weights  map[int]int   // WithPriorityWeights，nil 表示严格优先级
+	evict    bool          // WithPriorityEviction
+	sched    *scheduler    // 优先级队列，未开启优先级调度时为 nil
+
+	sjf func(TaskInfo) time.Duration // WithShortestJobFirst 的预计时长，nil 表示不按时长排序
+
+	costEstimate func(TaskInfo) int // WithCostEstimator，nil 表示不估计
+
+	stickyRouting bool         // WithStickyRouting
+	stickyQueue   int          // WithStickyRouting 每个位置的队列长度
+	sticky        *stickySlots // 按键路由的位置，未开启时为 nil
+
+	policy SchedulingPolicy // WithSchedulingPolicy，nil 表示使用内置的出队顺序
+
+	autoFree *autoFree // WithAutoFreeAfter，nil 表示不自动销毁
+
+	quota *callerQuota // WithCallerQuota，nil 表示不限
+
+	starvation *starvationMonitor // WithStarvationDetector，nil 表示不监视排队时长
+	starved    atomic.Int64       // 上次检查时排队超过阈值的任务数
+
+	utilization *utilizationRecorder // WithUtilizationRecorder，nil 表示不采样
+
+	reserved chan struct{} // WithReservedWorkers，只供紧急任务使用的容量，nil 表示不预留
+
+	heavy     *Semaphore // ScheduleWeighted 与 ScheduleGang 占住多个 worker 所用的信号量，首次使用时创建
+	heavyOnce sync.Once
+
+	overflow         chan struct{} // WithOverflow，饱和时临时启用的超出 capacity 的容量，nil 表示不启用
+	overflowCooldown time.Duration // WithOverflow，临时 worker 空闲多久后退出
+
+	capLoc     *time.Location   // WithCapacitySchedule
+	capWindows []CapacityWindow // WithCapacitySchedule，nil 表示容量固定
+	shrink     chan struct{}    // 空闲的 worker 从中收到信号时退出，用于降低容量，未设置 WithCapacitySchedule 时为 nil
+	parked     atomic.Int32     // 为降低容量而占住的 p.active 位置数
+
+	queueBytes  int64                // WithQueueBytes，排队任务大小之和的上限，0 表示不限
+	payloadSize func(TaskInfo) int64 // WithQueueBytes 的大小估计
+
+	blackoutLoc *time.Location   // WithBlackout
+	blackouts   []BlackoutWindow // WithBlackout，时段内普通任务留在队列中
+
+	flight *flightRecorder // WithFlightRecorder，nil 表示不录制
+	idle   *idleStacks     // WithDispatchStrategy 的空闲栈，nil 表示 DispatchSharedChannel
+	report *finalReport    // WithFinalReport，nil 表示不统计
+
+	queueTTL  time.Duration     // WithQueueTTL，0 表示不限
+	heartbeat *heartbeatMonitor // WithHeartbeat，nil 表示不监视心跳
+
+	lazy        bool        // WithLazyStart
+	lazyStart   sync.Once   // 第一次提交时执行 startGoroutines
+	lazyStarted atomic.Bool // lazyStart 已执行
+
+	fair        chan struct{} // WithFairSubmit：阻塞中的提交方依次持有，nil 表示不启用，见 fairTurn
+	fairWaiting atomic.Int32  // 持有或等待 fair 的提交方数量
+
+	probeTimeout time.Duration // WithHealthcheck，Healthcheck 探测任务的等待上限
+	stuckAfter   time.Duration // WithHealthcheck，执行同一任务超过该时长的 worker 视为卡住，0 表示不检查
+
+	budget    time.Duration // WithTaskBudget，0 表示不限
+	abandoned atomic.Int32  // 超出预算被放弃、尚未返回的任务数
+
+	seq        queueSeq        // 排队任务的编号，见 QueueInfo
+	throughput throughputMeter // 最近的处理速度，用于估计排队时间
+	taskSeq    atomic.Uint64   // 最近分配的 TaskInfo.ID
+
+	// 将 ScheduleCtx 提交方 ctx 中的值带入任务 ctx，nil 表示不传递
+	propagate func(from, to context.Context) context.Context
+	// 包裹每个任务的执行，见 WithMiddleware
+	middleware func(next TaskFunc) TaskFunc
+
+	inflight  taskCounter        // 已提交尚未执行完的任务数，不含 detached 任务
+	queued    queuedSet          // 已提交尚未开始执行的任务，见 Purge
+	tags      tagIndex           // 带标签、尚未结束的任务，见 CancelByTag
+	onDiscard func(reason error) // 任务未执行即被丢弃时调用
+
+	reentrant ReentrantPolicy // 已满时任务内再次提交的处理方式
+	workers   workerSet       // 按 goroutine id 记录的 worker
+	live      sync.Map        // worker 编号 -> *worker，供 DumpStacks 查找正在执行的任务
+
+	readyWorkers readyCounter // 初始化成功、尚未退出的 worker 数，见 WaitReady
+
+	detectDeadlock bool                 // WithDeadlockDetection
+	onDeadlock     func(*DeadlockError) // 发现等待环时调用，nil 时打印
+
+	logger func(format string, args ...any) // 日志输出，默认 fmt.Printf，nil 表示不打印
+
+	clock  Clock          // 内部所有时间相关操作都经由 clock，便于测试中注入 FakeClock
+	faults *faultInjector // 故障注入，默认 nil 表示关闭
+
+	workerInit     func(workerID int) (any, error) // worker 启动时调用，返回值供该 worker 上的任务使用
+	workerTeardown func(workerID int, v any)       // worker 退出时调用，用于释放 workerInit 创建的资源
+
+	initDelay     time.Duration                                  // WithInitBackoff，0 表示 workerInitRetryDelay
+	initMaxDelay  time.Duration                                  // WithInitBackoff，0 表示 workerInitMaxDelay
+	initFailures  atomic.Int32                                   // 连续初始化失败的次数，成功后清零
+	onInitFailure func(workerID int, err error, consecutive int) // WithOnWorkerInitFailure
+
+	maxWorkerTasks int           // worker 执行多少个任务后退役，0 表示不限
+	maxWorkerAge   time.Duration // worker 存活多久后退役，0 表示不限
+	idleTimeout    time.Duration // worker 空闲多久后退出，0 表示不退出
+	lockOSThread   bool          // worker goroutine 是否独占并绑定一个 OS 线程
+	nice           int           // WithWorkerNice，worker 线程的 nice 值，0 表示不调整
+	dropCanceled   bool          // WithDropCanceled，开始执行前检查提交方的 ctx
+
+	blockingMode bool         // 任务是否会长时间阻塞在系统调用/cgo 中，每个执行中的任务都占用一个 OS 线程
+	maxThreads   int          // blockingMode 下希望的进程线程上限，0 表示不调整
+	blocking     atomic.Int32 // blockingMode 下正在执行的任务数，即被阻塞占用的 OS 线程数
+
+	timers    timerWheel  // 尚未触发的延迟任务与周期任务
+	debounced debounceSet // Debounce 中等待合并的任务
+
+	jobs  jobRegistry // Register 注册的 Job 处理函数
+	queue Queue       // Enqueue 提交的 Job 在此等待执行
+	pump  jobPump     // 从 queue 取出 Job 交给 worker
+	wal   *wal        // WithWAL 启用的预写日志，nil 表示 Job 不持久化
+
+	visibility  time.Duration // 可见性超时，Job 开始执行后超过该时间未完成（或未确认）则重新投递，0 表示不限
+	explicitAck bool          // WithExplicitAck：处理函数返回 nil 后仍须调用 Ack 才算完成
+	acks        ackSet        // 受可见性超时约束、已取出尚未完成的 Job
+
+	idem    IdempotencyStore // WithIdempotency 设置，nil 表示不检查 Job.Key
+	idemTTL time.Duration
+
+	rate  rateLimit   // SetRateLimit 设置的全局限速
+	guard *errorGuard // WithErrorRateGuard，nil 表示不统计失败比例
+	pause pauseGate   // 暂停时 worker 不开始新的任务
+
+	metricsInterval time.Duration // WithMetrics 更新 gauge 的周期
+
+	slog      *slog.Logger // WithSlog，不为 nil 时取代 logger
+	slogAttrs []slog.Attr  // WithSlog 附加在 pool 分组中的属性
 }
 
 // 接收一个 capacity 参数与多个 Option 选项参数
+// 不合理的 capacity 与选项会被纠正或忽略（并打印），需要在出错时失败请使用 NewE
 func New(capacity int, opts ...Option) *Pool {
+	p := newPool(opts)
 	if capacity <= 0 { // 防御性校验，当传入参数不合理是主动纠错
 		capacity = defaultCapacity
 	}
-	if capacity > maxCapacity {
-		capacity = maxCapacity
+	if p.maxCapacity > 0 && capacity > p.maxCapacity {
+		capacity = p.maxCapacity
+	}
+	p.setCapacity(capacity)
+	for _, err := range p.optErrs {
+		p.logf("workerpool: %s\n", err)
 	}
+	p.launch()
+	return p
+}
 
+// NewE 与 New 相同，但 capacity 超出 [1, 上限] 或选项不合理、互相冲突时返回错误（均包装 ErrInvalidOption），
+// 而不是纠正后继续运行
+func NewE(capacity int, opts ...Option) (*Pool, error) {
+	p := newPool(opts)
+	if capacity <= 0 {
+		p.invalid("capacity %d must be positive", capacity)
+	} else if p.maxCapacity > 0 && capacity > p.maxCapacity {
+		p.invalid("capacity %d exceeds the limit %d, see WithMaxCapacity", capacity, p.maxCapacity)
+	}
+	if err := errors.Join(p.optErrs...); err != nil {
+		if p.wal != nil {
+			p.wal.close()
+		}
+		return nil, err
+	}
+	p.setCapacity(capacity)
+	p.launch()
+	return p, nil
+}
+
+// newPool 创建 pool 并应用选项，尚未启动任何 goroutine
+func newPool(opts []Option) *Pool {
 	p := &Pool{
-		capacity: capacity,
-		block:    true,
-		tasks:    make(chan Task),
-		quit:     make(chan struct{}),
-		active:   make(chan struct{}, capacity),
+		id:          poolSeq.Add(1),
+		maxCapacity: defaultMaxCapacity,
+		block:       true,
+		clock:       systemClock,
+		logger:      printf,
+		ctx:         context.Background(),
+		timers:      timerWheel{tick: defaultTimerTick},
+		queue:       NewMemoryQueue(),
+		quit:        make(chan struct{}),
+		closing:     make(chan struct{}),
+		freed:       make(chan struct{}),
 	}
+	p.opts = opts
 	// 遍历 opts，将每个 Option 选项参数应用到 p 上
 	for _, opt := range opts {
 		opt(p)
 	}
-	fmt.Printf("workerpool start(preAlloc=%t)\n", p.preAlloc)
-	// 提前创建 goroutine
-	if p.preAlloc {
-		for i := 0; i < p.capacity; i++ {
-			p.newWorker(i + 1)
-			p.active <- struct{}{}
+	p.validate()
+	p.tasks = make(chan task, p.queueSize)
+	switch {
+	case p.priority: // 排队的任务由优先级队列保存，p.tasks 只用于交给空闲的 worker
+		p.tasks = make(chan task)
+		aging := p.aging
+		if p.sjf != nil {
+			aging = 0 // 已等待的时长由 sjfDue 计入
 		}
+		p.sched = newScheduler(p.queueSize, aging, p.weights, p.clock.Now())
+		p.sched.policy = p.policy
+	case p.queueSize > 0:
+		p.handoff = make(chan task)
+	}
+	if p.fifo {
+		p.fifoMu = make(chan struct{}, 1)
 	}
-	go p.run()
 	return p
 }
 
-// 监听 pool 创建与退出信号
-func (p *Pool) run() {
-	idx := len(p.active)
+func (p *Pool) setCapacity(capacity int) {
+	p.capacity = capacity
+	p.active = make(chan struct{}, capacity)
+	if p.stickyRouting {
+		p.sticky = newStickySlots(capacity, p.stickyQueue)
+	}
+}
+
+func (p *Pool) launch() {
+	if p.detectDeadlock {
+		p.enableDeadlockDetection()
+	}
+	p.ctx, p.cancel = context.WithCancelCause(p.ctx)
+	if p.blockingMode {
+		p.checkThreadBudget()
+	}
+	if p.global {
+		p.registerGlobal()
+	}
+	if p.report != nil {
+		p.report.started = p.clock.Now()
+	}
+	p.logf("workerpool start(preAlloc=%t)\n", p.preAlloc)
+	if !p.lazy {
+		p.startGoroutines()
+	}
+}
 
-	if !p.preAlloc {
-	loop:
-		for t := range p.tasks {
-			p.returnTask(t)
+// startGoroutines 启动辅助 goroutine 并预创建 worker；设置了 WithLazyStart 时推迟到第一次提交，见 startLazy
+func (p *Pool) startGoroutines() {
+	if p.sched != nil {
+		p.wg.Add(1)
+		go p.runScheduler()
+	}
+	if p.capWindows != nil {
+		p.wg.Add(1)
+		go p.runCapacitySchedule()
+	}
+	if p.alerts != nil {
+		p.wg.Add(1)
+		go p.runAlerts()
+	}
+	if p.metrics != nil {
+		p.wg.Add(1)
+		go p.runMetrics()
+	}
+	if p.flight != nil {
+		p.wg.Add(1)
+		go p.runFlightRecorder()
+	}
+	if p.heartbeat != nil {
+		p.wg.Add(1)
+		go p.runHeartbeats()
+	}
+	if p.autoFree != nil {
+		p.wg.Add(1)
+		go p.runAutoFree()
+	}
+	if p.starvation != nil {
+		p.wg.Add(1)
+		go p.runStarvation()
+	}
+	if p.utilization != nil {
+		p.wg.Add(1)
+		go p.runUtilization()
+	}
+	// 提前创建 goroutine
+	if p.preAlloc {
+		for i := 0; i < p.capacity; i++ {
 			select {
-			case <-p.quit:
-				return
 			case p.active <- struct{}{}:
-				idx++
-				p.newWorker(idx)
-			default:
-				break loop
+				p.newWorker(nil)
+			default: // 推迟启动时已有 worker 占用了位置
+				return
 			}
 		}
 	}
+}
 
-	for {
-		select {
-		case <-p.quit:
-			return
-		case p.active <- struct{}{}:
-			// create a new worker
-			idx++
-			p.newWorker(idx)
-		}
+// invalid 记录一个不合理的选项，New 打印后忽略，NewE 返回错误
+func (p *Pool) invalid(format string, args ...any) {
+	p.optErrs = append(p.optErrs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOption}, args...)...))
+}
+
+// validate 检查选项之间的冲突
+func (p *Pool) validate() {
+	if _, mem := p.queue.(*MemoryQueue); p.wal != nil && !mem {
+		p.invalid("WithWAL has no effect with a custom WithQueue")
+	}
+	if p.explicitAck && p.visibility <= 0 {
+		p.invalid("WithExplicitAck requires a positive timeout")
+	}
+	if p.sjf != nil && p.fifo {
+		p.invalid("WithFIFO conflicts with WithShortestJobFirst")
+		p.sjf = nil
+	}
+	if p.policy != nil && p.fifo {
+		p.invalid("WithFIFO conflicts with WithSchedulingPolicy")
+		p.policy = nil
+	}
+	if p.policy != nil && p.weights != nil {
+		p.invalid("WithPriorityWeights conflicts with WithSchedulingPolicy")
+		p.weights = nil
+	}
+	if p.sjf != nil || p.policy != nil {
+		p.priority = true // 由优先级队列按预计时长或 policy 排序
+	}
+	if p.fifo && p.priority {
+		p.invalid("WithFIFO conflicts with WithPriorityScheduling")
+		p.priority = false
+	}
+	if p.weights != nil && !p.priority {
+		p.invalid("WithPriorityWeights requires WithPriorityScheduling")
 	}
+	if p.nice != 0 && !p.lockOSThread {
+		p.invalid("WithWorkerNice requires WithLockOSThread")
+	}
+	if p.evict && (!p.priority || p.queueSize == 0) {
+		p.invalid("WithPriorityEviction requires WithPriorityScheduling and WithQueueSize")
+	}
+	if p.stickyRouting && (p.fifo || p.priority || p.capWindows != nil) {
+		p.invalid("WithStickyRouting conflicts with WithFIFO, WithPriorityScheduling and WithCapacitySchedule")
+		p.stickyRouting = false
+	}
+	if p.idle != nil && p.fifo {
+		p.invalid("WithDispatchStrategy(%s) conflicts with WithFIFO", p.idle.strategy())
+		p.idle = nil
+	}
+	if p.overflow != nil && (p.priority || p.fifo) {
+		p.invalid("WithOverflow conflicts with WithPriorityScheduling and WithFIFO")
+	}
+}
+
+// 没有单独的 dispatcher：提交方在容量未满时直接创建 worker 执行任务（见 tryDispatch），
+// 否则把任务交给空闲的 worker 或放入缓冲。worker 数量始终不超过 capacity：创建前须先占住 p.active 中的一个位置，
+// 退出时归还；每个被接受的任务只交出一次，由一个 worker 取走执行，或在 Free 时丢弃
+// 所有由 pool 派生的 goroutine 都计入 p.wg，Free 返回时它们均已退出，
+// 因此在 testing/synctest 的 bubble 中使用 pool 不会遗留后台 goroutine
+
+// tryDispatch 不阻塞地交出 t：容量未满时创建新的 worker 执行 t，否则交给空闲的 worker 或放入缓冲
+// 调用方须保证此时 Add p.wg 是安全的（进行中的提交、pool 派生的 goroutine，或持锁确认 Free 尚未开始等待 p.wg）
+func (p *Pool) tryDispatch(t task) bool {
+	if p.fifo {
+		return p.dispatchFIFO(t, false, p.quit, nil)
+	}
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(&t)
+		return true
+	default:
+	}
+	if p.handToIdle(t) {
+		return true
+	}
+	p.enqueue(&t)
+	select {
+	case p.tasks <- t:
+		p.ensureWorker()
+		return true
+	default:
+		return false
+	}
+}
+
+// handOff 只在有新的或空闲的 worker 立即接手时交出 t，不放入缓冲，对 p.wg 的要求与 tryDispatch 相同
+func (p *Pool) handOff(t task) bool {
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(&t)
+		return true
+	default:
+	}
+	if p.handToIdle(t) {
+		return true
+	}
+	select {
+	case p.handoffChan() <- t:
+		return true
+	default:
+		return false
+	}
+}
+
+// handoffChan 返回只由空闲 worker 接收的无缓冲 channel
+func (p *Pool) handoffChan() chan task {
+	if p.handoff != nil {
+		return p.handoff
+	}
+	return p.tasks // 不带缓冲，或优先级调度下只用于交给空闲的 worker
+}
+
+// waitDispatch 阻塞直到 t 被交出（返回 true），或 stop、done、t 的丢弃信号之一关闭（返回 false），
+// 对 p.wg 的要求与 tryDispatch 相同
+func (p *Pool) waitDispatch(t task, stop, done <-chan struct{}) bool {
+	if p.fifo {
+		return p.dispatchFIFO(t, true, stop, done)
+	}
+	var discarded <-chan struct{}
+	if t.claim != nil {
+		discarded = t.claim.discarded
+	}
+	p.enqueue(&t)
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(&t)
+		return true
+	case p.tasks <- t:
+		p.ensureWorker()
+		return true
+	case p.overflow <- struct{}{}: // 未设置 WithOverflow 时为 nil，永不就绪
+		p.spawnWorker(&t, true)
+		return true
+	case <-stop:
+	case <-done:
+	case <-discarded:
+	}
+	return false
+}
+
+// ensureWorker 在任务放入缓冲后调用：缓冲非空且容量未满时补充一个 worker，
+// 与 worker 退出时的 replenish 配合，保证缓冲中的任务总有 worker 处理
+func (p *Pool) ensureWorker() {
+	if len(p.tasks) == 0 {
+		return
+	}
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(nil)
+	default:
+	}
+}
+
+// replenish 在 worker 非因 Free 退出、归还容量后调用：always 为 true（预创建模式）或缓冲中仍有任务时补充一个 worker
+// 调用时退出的 worker 尚未 Done，Add p.wg 是安全的
+func (p *Pool) replenish(always bool) {
+	select {
+	case <-p.quit:
+		return
+	default:
+	}
+	if !always && len(p.tasks) == 0 {
+		return
+	}
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(nil)
+	default:
+	}
+}
+
+// newWorker 创建一个 worker，调用方已为它占住 p.active 中的位置；first 不为 nil 时 worker 先执行它
+func (p *Pool) newWorker(first *task) {
+	p.spawnWorker(first, false)
 }
 
-func (p *Pool) newWorker(i int) {
+// spawnWorker 启动一个 worker，overflow 为 true 时占用的是 WithOverflow 的容量：
+// 空闲 overflowCooldown 后退出，退出时不补充，任务超出预算时也不由新的 worker 接替
+func (p *Pool) spawnWorker(first *task, overflow bool) {
+	i := int(p.workerSeq.Add(1))
+	born := p.clock.Now()
 	p.wg.Add(1)
 	go func() {
-		// defer 中需要做：1.捕获 panic 2.active 队列减一 3.pool 的 WaitGroup 置为 Done
+		p.setLabels(roleWorker, LabelWorker, workerLabel(i))
+		if p.lockOSThread {
+			// 不调用 UnlockOSThread：worker 退出时 goroutine 仍绑定线程，runtime 会销毁该线程，
+			// 线程上残留的 C 库状态不会被其它 goroutine 复用
+			runtime.LockOSThread()
+			if p.nice != 0 {
+				// 线程随 worker 退出而销毁，调低的优先级不会影响其它 goroutine
+				if err := setThreadNice(p.nice); err != nil {
+					p.logf("worker[%03d]: set nice %d: %s\n", i, p.nice, err)
+				}
+			}
+		}
+		w := &worker{id: i, replaceable: !overflow}
+		// 只有需要识别来自任务内部的提交时才登记，goid 需要解析 runtime.Stack
+		track := p.reentrant != ReentrantBlock || p.detectDeadlock
+		var gid int64
+		if track {
+			gid = goid()
+			p.workers.add(gid, w)
+		}
+		p.live.Store(i, w)
+		replace := p.preAlloc // 退出后是否立即补充，预创建模式下始终保持 capacity 个 worker
+		// defer 中需要做：1.捕获 panic 2.执行 teardown 3.active 队列减一 4.按需补充 worker 5.pool 的 WaitGroup 置为 Done
 		defer func() {
 			if err := recover(); err != nil {
-				fmt.Printf("worker[%03d]: recover panic[%s] and exit\n", i, err)
+				if info := w.task.Load(); info != nil {
+					p.logf("worker[%03d]: recover panic[%s] in %s and exit\n", i, err, info)
+				} else {
+					p.logf("worker[%03d]: recover panic[%s] and exit\n", i, err)
+				}
+			}
+			if track {
+				p.workers.remove(gid)
+			}
+			p.live.Delete(i)
+			p.teardownWorker(w)
+			if w.ready {
+				p.emitPool(EventWorkerExited, i, nil)
+			}
+			switch {
+			case overflow:
+				<-p.overflow
+			case !w.abandoned && !w.yielded && !w.lent: // 被放弃的 worker 的容量已转交给接替它的 worker
+				vacated := p.releaseSlot(w)
 				<-p.active
+				p.replenish(replace)
+				p.staffSlot(vacated)
 			}
 			p.wg.Done()
 		}()
-		fmt.Printf("worker[%03d]: start\n", i)
-		for {
-			select {
-			case <-p.quit: // 监听 quit
-				fmt.Printf("worker[%03d]: exit\n", i)
-				<-p.active
+		if !p.initWorker(w) {
+			if first != nil { // 交给其它 worker，不因初始化失败丢失
+				p.dispatchAsync(*first)
+			}
+			return
+		}
+		p.spinUp.record(p.clock.Since(born))
+		p.emitPool(EventWorkerStarted, i, nil)
+		p.readyWorkers.add(1)
+		defer p.readyWorkers.add(-1)
+		idleTimeout := p.idleTimeout
+		if overflow {
+			idleTimeout = p.overflowCooldown
+			p.logf("worker[%03d]: start overflow\n", i)
+		} else {
+			p.logf("worker[%03d]: start\n", i)
+		}
+		var expired <-chan time.Time // 达到最大存活时间的信号，未设置时为 nil 永不触发
+		if p.maxWorkerAge > 0 {
+			t := p.clock.NewTimer(p.maxWorkerAge)
+			defer t.Stop()
+			expired = t.C()
+		}
+		var idle Timer // 空闲超时，未设置时为 nil
+		var idleC <-chan time.Time
+		if idleTimeout > 0 {
+			idle = p.clock.NewTimer(idleTimeout)
+			defer idle.Stop()
+			idleC = idle.C()
+		}
+		for n := 1; ; n++ {
+			var t task
+			keyed := p.stickyTasks(w) // 未占据位置时为 nil，永不就绪
+			if first != nil {
+				t, first = *first, nil
+			} else {
+				var inbox chan task // 未使用空闲栈时为 nil，永不就绪
+				if p.idle != nil {
+					p.idle.park(w)
+					inbox = w.inbox
+				}
+				select {
+				case <-p.quit: // 监听 quit
+					p.leaveIdle(w)
+					p.logf("worker[%03d]: exit\n", i)
+					return
+				case <-expired: // 空闲时到期直接退役
+					p.leaveIdle(w)
+					p.logf("worker[%03d]: retire\n", i)
+					return
+				case <-idleC:
+					p.leaveIdle(w)
+					p.logf("worker[%03d]: idle exit\n", i)
+					replace = false
+					return
+				case <-p.shrink:
+					p.leaveIdle(w)
+					p.logf("worker[%03d]: exit to lower capacity\n", i)
+					w.yielded = true
+					return
+				case t = <-p.tasks:
+					p.leaveIdle(w)
+				case t = <-p.handoff:
+					p.leaveIdle(w)
+				case t = <-inbox: // 已由 pop 移出空闲栈
+				case t = <-keyed:
+					p.leaveIdle(w)
+				}
+			}
+			if t.info != nil {
+				p.logf("worker[%03d]: receive %s\n", i, t.info)
+			} else {
+				p.logf("worker[%03d]: receive a task\n", i)
+			}
+			p.runTask(w, t)
+			if w.abandoned {
+				p.logf("worker[%03d]: abandoned task returned, exit\n", i)
+				return
+			}
+			if w.lent {
+				p.logf("worker[%03d]: task left barrier, exit\n", i)
 				return
-			case t := <-p.tasks:
-				fmt.Printf("worker[%03d]: receive a task\n", i)
-				t()
+			}
+			if !p.injectAfterTask() {
+				p.logf("worker[%03d]: exit\n", i)
+				return
+			}
+			if p.shouldRetire(n, expired) {
+				p.logf("worker[%03d]: retire\n", i)
+				return
+			}
+			if idle != nil {
+				p.resetIdle(idle, idleTimeout)
 			}
 		}
 	}()
 }
 
+// Context 返回 pool 的基础 ctx，所有任务收到的 ctx 都由它派生，pool 销毁时被取消
+func (p *Pool) Context() context.Context {
+	p = p.applied()
+	return p.ctx
+}
+
+// Cap 返回 pool 的容量，即 worker 数量上限
+func (p *Pool) Cap() int {
+	p = p.applied()
+	return p.capacity
+}
+
 func (p *Pool) Schedule(t Task) error {
-	select {
-	case <-p.quit:
-		return ErrWorkerPoolFreed
-	case p.tasks <- t:
+	return p.schedule(task{fn: t})
+}
+
+// ScheduleFunc 提交一个 TaskFunc，执行时可通过 ctx 访问所在 worker 的信息
+func (p *Pool) ScheduleFunc(t TaskFunc) error {
+	return p.schedule(task{fnc: t})
+}
+
+// ScheduleCtx 提交一个 TaskFunc，任务的 ctx 在提交方的 ctx 取消时随之取消（Cause 相同），
+// 使调用方放弃后（如 HTTP 客户端断开）执行中的任务可以提前结束；等待空闲 worker 期间 ctx 取消时返回 ctx.Err()
+func (p *Pool) ScheduleCtx(ctx context.Context, t TaskFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.submit(ctx, task{fnc: t, ctx: ctx}, p.block)
+}
+
+// ScheduleTimeout 与 Schedule 相同，但总是阻塞，最多等待 d 得到 worker，超时返回 *ScheduleTimeoutError；
+// 时限只约束等待，不影响任务的执行
+func (p *Pool) ScheduleTimeout(t Task, d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return p.submit(ctx, task{fn: t}, true)
+}
+
+// TryScheduleFunc 与 ScheduleFunc 相同，但不论 WithBlock 如何设置，没有空闲 worker 时都立即返回 ErrPoolSaturated，
+// 用于在过载时快速拒绝请求
+func (p *Pool) TryScheduleFunc(t TaskFunc) error {
+	return p.submit(nil, task{fnc: t}, false)
+}
+
+// ScheduleDetached 提交一个后台任务（如常驻的维护循环）：同样占用一个 worker，但不计入 Wait，
+// 因此正常运行期间不会结束的任务不会让 Wait 永远阻塞；任务应在 ctx 取消（Free）时返回，Free 仍会等待它
+func (p *Pool) ScheduleDetached(t TaskFunc) error {
+	return p.submit(nil, task{fnc: t, detached: true}, p.block)
+}
+
+// ScheduleWithCallback 提交 t，t 结束后在同一 worker 上调用 done，d 为 t 的执行时长：
+// t panic 时 panic 被恢复，err 为 *PanicError，worker 不会因此退出；
+// t 未执行即被丢弃（Purge、Free）时 err 为丢弃的原因，d 为 0。提交失败时不调用 done
+func (p *Pool) ScheduleWithCallback(t Task, done func(err error, d time.Duration)) error {
+	return p.scheduleOr(task{fn: func() {
+		start := p.clock.Now()
+		defer func() {
+			var err error
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+			done(err, p.clock.Since(start))
+		}()
+		t()
+	}}, func(reason error) { done(reason, 0) })
+}
+
+func (p *Pool) schedule(t task) error {
+	return p.submit(nil, t, p.block)
+}
+
+// scheduleOr 提交 t，t 被接受后未执行即被丢弃（Purge、Free）时调用 discarded；
+// t 执行、discarded 被调用、返回错误三者恰有一个发生。完成信号在任务内部的辅助函数（Map、Stream 等）
+// 须经由它提交：WithQueueSize 下被接受的任务可能在缓冲中被丢弃，否则等待方会永远阻塞
+func (p *Pool) scheduleOr(t task, discarded func(reason error)) error {
+	return p.submitOr(nil, t, p.block, discarded)
+}
+
+// submitOr 是以 block 代替 WithBlock 设置的 scheduleOr，阻塞时 ctx（可以为 nil）取消即停止等待
+func (p *Pool) submitOr(ctx context.Context, t task, block bool, discarded func(reason error)) error {
+	var once atomic.Bool
+	t.claim = newTaskClaim()
+	t.claim.onDiscard = func(reason error) {
+		if once.CompareAndSwap(false, true) {
+			discarded(reason)
+		}
+	}
+	err := p.submit(ctx, t, block)
+	if err != nil && !once.CompareAndSwap(false, true) {
+		return nil // 阻塞等待期间被丢弃，已经由 discarded 处理
+	}
+	return err
+}
+
+// submit 将 t 交给 worker，block 为 true 时等待，直到 pool 销毁或 ctx（可以为 nil）取消
+// 除 detached 任务外，成功提交的任务计入 Wait
+// Free 开始后的提交一律返回 ErrPoolClosed；与 Free 并发的提交要么返回该错误，
+// 要么被接受，被接受而来不及执行的任务在 Free 中丢弃（见 drop），不会永久阻塞，也不会无声地丢失
+func (p *Pool) submit(ctx context.Context, t task, block bool) (err error) {
+	defer func() { // HandoffTo 之后转交给接替的 pool，此时已不计入 p.submitters
+		if q := p.successor.Load(); q != nil && errors.Is(err, ErrPoolClosed) {
+			err = q.submit(ctx, t.migrated(), block)
+		}
+	}()
+	if p.observed() {
+		p.taskID(&t)
+		defer func() {
+			switch {
+			case err == nil:
+				p.emit(Event{Kind: EventSubmitted, TaskID: t.id, Info: t.info})
+				p.watermark()
+			case errors.Is(err, ErrTaskDiscarded): // 已作为 discarded 报告
+			case p.successor.Load() != nil && errors.Is(err, ErrPoolClosed): // 转交给接替的 pool，由它报告
+			default:
+				p.emit(Event{Kind: EventRejected, TaskID: t.id, Info: t.info, Err: err})
+			}
+		}()
+	}
+	if !p.enterSubmit() {
+		return p.freedErr()
+	}
+	defer p.submitters.Done()
+	p.touchAutoFree()
+	if p.quota != nil {
+		release, qerr := p.acquireQuota(ctx, &t)
+		if qerr != nil {
+			return qerr
+		}
+		defer func() {
+			if err != nil {
+				release()
+			}
+		}()
+	}
+
+	if t.enqueued.IsZero() {
+		t.enqueued = p.clock.Now()
+	}
+	defer func() {
+		var te *ScheduleTimeoutError
+		if errors.As(err, &te) {
+			te.Waited = p.clock.Since(t.enqueued)
+		}
+	}()
+	if !t.detached {
+		t.counted = true
+		p.inflight.add(1)
+		defer func() {
+			if err != nil {
+				p.inflight.add(-1)
+			}
+		}()
+	}
+	if p.queueBytes > 0 && !p.reserveBytes(&t) {
+		return p.queueBytesErr()
+	}
+	if t.tag != nil {
+		p.tagTask(&t)
+	}
+	if t.claim != nil { // 可以通过句柄或标签取消的任务，从提交起即可被丢弃
+		t.claim.id = t.id
+		p.markQueuedAt(&t)
+		p.queued.add(t.claim)
+	}
+	defer func() {
+		if err != nil && t.claim != nil && !p.unqueue(t) {
+			err = ErrTaskDiscarded // 返回前已被 Purge 丢弃并通知了 OnDiscard
+		}
+		if err != nil && t.tag != nil {
+			p.tags.remove(t.tag)
+		}
+	}()
+	if t.urgent && p.reserved != nil {
+		p.markQueued(&t)
+		return p.submitUrgent(ctx, t, block)
+	}
+	if t.keyed && p.sticky != nil {
+		p.markQueued(&t)
+		return p.submitSticky(ctx, t, block)
+	}
+	if p.fifo {
+		unlock, lerr := p.lockFIFO(ctx, block)
+		if lerr != nil {
+			return lerr
+		}
+		defer unlock()
+		t.turn = p.nextTurn()
+		defer func() {
+			if err == nil && t.turn != nil {
+				p.fifoPrev = t.turn.started
+			}
+		}()
+	}
+	if p.sched != nil {
+		p.markQueued(&t)
+		return p.submitPriority(ctx, t, block)
+	}
+	if p.queueSize > 0 { // 进入缓冲区排队的任务同样可以被 Purge 丢弃
+		p.markQueued(&t)
+	}
+	if !p.queueFair() && (p.tryDispatch(t) || p.tryOverflow(t)) {
 		return nil
-	default:
-		if p.block {
-			p.tasks <- t
+	}
+	if !block {
+		return p.saturatedErr()
+	}
+	if p.fifo && p.reentrant != ReentrantBlock && p.workers.get(goid()) != nil {
+		t.turn = nil // 内联或临时 goroutine 上执行的任务不排队，也不参与开始顺序
+	}
+	if handled, rerr := p.handleReentrant(t); handled {
+		return rerr
+	}
+	unwait, derr := p.enterWait()
+	if derr != nil {
+		return derr
+	}
+	defer unwait()
+	p.blocked.Add(1)
+	defer p.blocked.Add(-1)
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	p.markQueued(&t)
+	if p.fair != nil {
+		release, ok := p.fairTurn(t, done)
+		if ok {
+			defer release()
+		}
+		if ok && p.waitDispatch(t, p.closing, done) {
 			return nil
 		}
-		return ErrNoIdleWorkerInPool
+	} else if p.waitDispatch(t, p.closing, done) {
+		return nil
+	}
+	select {
+	case <-p.closing: // 阻塞等待期间 pool 开始销毁
+		return p.freedErr()
+	case <-t.claim.discarded:
+		return ErrTaskDiscarded
+	default:
+		return p.waitErr(ctx)
 	}
 }
 
+// enterSubmit 登记一个进行中的提交，返回后须调用 p.submitters.Done；Free 已开始时返回 false
+// 进行中的提交可以安全地 Add p.wg：Free 在 submitters 归零后才等待 p.wg
+func (p *Pool) enterSubmit() bool {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return false
+	}
+	p.submitters.Add(1) // closed 为 false，Free 尚未开始等待 submitters
+	p.startLazy()
+	return true
+}
+
 // 发送 quit 信号，等待所有 worker 完成任务退出
+// 返回时 worker 及内部辅助 goroutine 均已退出；已接受但尚未执行的任务被丢弃（设置了 WithDrainOnFree 时先执行完），
+// 对其调用 WithOnDiscard 的回调（原因为 ErrPoolClosed），Future 以同样的错误解析
+// 可以重复调用，之后的调用等待第一次调用完成
 func (p *Pool) Free() {
+	p.FreeWithCause(nil)
+}
+
+// FreeWithCause 与 Free 相同，并记录销毁的原因（如发布、过载、配置变更）：
+// 任务的 ctx 以 cause 取消，可通过 context.Cause 取得；之后及阻塞中的 Schedule 返回包装了 cause 的 ErrPoolClosed
+// cause 为 nil 时等同于 Free，context.Cause 返回 ErrPoolClosed
+func (p *Pool) FreeWithCause(cause error) {
+	p.applied().free(cause)
+}
+
+// FreeAsync 在后台开始 Free 并立即返回，返回的 channel 在 Free 完成时关闭，
+// 调用方可以与服务关闭、超时等信号一起 select，而不必让一个 goroutine 阻塞在 Free 中
+func (p *Pool) FreeAsync() <-chan struct{} {
+	p = p.applied()
+	go p.free(nil)
+	return p.freed
+}
+
+// Drain 停止接受新的任务，执行完所有已接受的任务（同 WithDrainOnFree）后销毁 p；
+// ctx 取消时返回 ctx.Err()，排空在后台继续
+func (p *Pool) Drain(ctx context.Context) error {
+	p = p.applied()
+	p.closeMu.Lock()
+	if !p.closed {
+		p.drainFree = true // 在 free 设置 closed 之前写入，free 随后读取
+	}
+	p.closeMu.Unlock()
+	freed := p.FreeAsync()
+	select {
+	case <-freed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) free(cause error) {
+	p.closeMu.Lock()
+	if p.closed {
+		p.closeMu.Unlock()
+		<-p.freed
+		return
+	}
+	p.freeCause = cause
+	p.closed = true
+	p.closeMu.Unlock()
+	defer close(p.freed)
+	close(p.closing)
+	p.emitPool(EventShutdownBegan, 0, cause)
+	if p.successor.Load() != nil {
+		p.migrate()
+	} else if p.drainFree {
+		p.drain()
+	}
 	close(p.quit)
+	if cause == nil {
+		cause = ErrPoolClosed
+	}
+	p.cancel(cause)
+	if p.detectDeadlock {
+		p.disableDeadlockDetection()
+	}
+	p.stopTimers()
+	p.stopPump()
+	p.stopAcks()
+	p.submitters.Wait() // 均已看到 closing 关闭而返回，之后不再有 worker 由提交方创建
 	p.wg.Wait()
-	fmt.Printf("workerpool freed\n")
+	p.dropUndispatched()
+	if p.wal != nil {
+		if err := p.wal.close(); err != nil {
+			p.logf("workerpool: close wal: %s\n", err)
+		}
+	}
+	if p.global {
+		p.unregisterGlobal()
+	}
+	if p.report != nil {
+		p.finishReport()
+	}
+	p.emitPool(EventShutdownFinished, 0, nil)
+	p.logf("workerpool freed\n")
 }
 
-// 防止 task 阻塞，使用 goroutine 异步发送 task
-func (p *Pool) returnTask(t Task) {
+// Name 返回 WithName 设置的名称
+func (p *Pool) Name() string {
+	return p.name
+}
+
+func printf(format string, args ...any) {
+	fmt.Printf(format, args...)
+}
+
+// logf 输出日志，设置了 WithName 时以名称为前缀
+func (p *Pool) logf(format string, args ...any) {
+	if p.slog != nil {
+		p.logSlog(format, args...)
+		return
+	}
+	if p.logger == nil {
+		return
+	}
+	if p.name != "" {
+		format = p.name + ": " + format
+	}
+	p.logger(format, args...)
+}
+
+// drain 在 Free 通知 worker 退出前等待已接受的任务执行完毕
+// Job 队列先停止取出新的 Job，未取出的 Job 保留在 WAL 或后端中
+func (p *Pool) drain() {
+	p.stopPump()
+	p.submitters.Wait() // 之后不再有新接受的任务
+	<-p.inflight.wait()
+}
+
+// dispatchAsync 交出 t 而不阻塞调用方（如时间轮、cron），没有空闲的 worker 时由一个辅助 goroutine 等待，
+// pool 销毁时放弃并丢弃 t，避免 goroutine 永久阻塞；对 p.wg 的要求与 tryDispatch 相同
+func (p *Pool) dispatchAsync(t task) {
+	p.markQueued(&t)
+	if p.tryDispatch(t) {
+		return
+	}
+	p.wg.Add(1)
 	go func() {
-		p.tasks <- t
+		defer p.wg.Done()
+		p.setLabels(roleHelper)
+		if p.waitDispatch(t, p.quit, nil) {
+			return
+		}
+		select {
+		case <-t.claim.discarded: // 被 Purge 丢弃
+			if t.counted {
+				p.inflight.add(-1)
+			}
+		default:
+			p.drop(t)
+		}
 	}()
 }
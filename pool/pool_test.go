@@ -0,0 +1,136 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// peak 记录一个计数器到达过的最大值
+type peak struct {
+	cur, max atomic.Int64
+}
+
+func (c *peak) inc() {
+	n := c.cur.Add(1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
+
+func (c *peak) dec() { c.cur.Add(-1) }
+
+// 提交方直接创建 worker 的分发方式下，并发的任务与存活的 worker 都不超过 capacity，每个任务恰好执行一次
+func TestDispatchStress(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{"unbuffered", nil},
+		{"buffered", []Option{WithQueueSize(8)}},
+		{"prealloc", []Option{WithPreAllocWorkers(true)}},
+		{"prealloc-buffered", []Option{WithPreAllocWorkers(true), WithQueueSize(8)}},
+		{"recycle", []Option{WithMaxTasksPerWorker(3)}},
+		{"recycle-buffered", []Option{WithMaxTasksPerWorker(3), WithQueueSize(8)}},
+		{"idle-exit", []Option{WithIdleTimeout(time.Microsecond)}},
+		{"idle-exit-buffered", []Option{WithIdleTimeout(time.Microsecond), WithQueueSize(8)}},
+		{"nonblocking", []Option{WithBlock(false), WithQueueSize(4)}},
+		{"fair", []Option{WithFairSubmit()}},
+		{"fair-buffered", []Option{WithFairSubmit(), WithQueueSize(8)}},
+	}
+	const (
+		capacity   = 4
+		submitters = 16
+		perSubmit  = 200
+	)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var running, workers peak
+			opts := append([]Option{
+				WithLogger(nil),
+				WithWorkerInit(func(int) (any, error) { workers.inc(); return nil, nil }),
+				WithWorkerTeardown(func(int, any) { workers.dec() }),
+			}, c.opts...)
+			p := New(capacity, opts...)
+			runs := make([]atomic.Int32, submitters*perSubmit)
+			accepted := make([]bool, len(runs))
+			var wg sync.WaitGroup
+			for s := 0; s < submitters; s++ {
+				wg.Add(1)
+				go func(s int) {
+					defer wg.Done()
+					for i := s * perSubmit; i < (s+1)*perSubmit; i++ {
+						err := p.ScheduleFunc(func(context.Context) {
+							running.inc()
+							defer running.dec()
+							runs[i].Add(1)
+							if i%7 == 0 {
+								time.Sleep(10 * time.Microsecond)
+							}
+						})
+						accepted[i] = err == nil
+						if err != nil && !errors.Is(err, ErrPoolSaturated) {
+							t.Errorf("task %d: %v", i, err)
+						}
+					}
+				}(s)
+			}
+			wg.Wait()
+			p.Wait()
+			p.Free()
+			if m := running.max.Load(); m > capacity {
+				t.Errorf("%d tasks ran concurrently, capacity %d", m, capacity)
+			}
+			if m := workers.max.Load(); m > capacity {
+				t.Errorf("%d workers alive at once, capacity %d", m, capacity)
+			}
+			if n := workers.cur.Load(); n != 0 {
+				t.Errorf("%d workers still alive after Free", n)
+			}
+			for i := range runs {
+				want := int32(0)
+				if accepted[i] {
+					want = 1
+				}
+				if n := runs[i].Load(); n != want {
+					t.Fatalf("task %d (accepted=%t) ran %d times", i, accepted[i], n)
+				}
+			}
+		})
+	}
+}
+
+// 与 Free 并发的提交要么返回错误，要么被执行或经由 WithOnDiscard 通知，不会丢失也不会永久阻塞
+func TestFreeDuringSubmitStress(t *testing.T) {
+	for _, size := range []int{0, 8} {
+		for round := 0; round < 20; round++ {
+			var ran, discarded, rejected atomic.Int64
+			p := New(2, WithQueueSize(size), WithLogger(nil), WithOnDiscard(func(error) { discarded.Add(1) }))
+			var wg sync.WaitGroup
+			for s := 0; s < 8; s++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 50; i++ {
+						if p.Schedule(func() { ran.Add(1) }) != nil {
+							rejected.Add(1)
+						}
+					}
+				}()
+			}
+			time.Sleep(time.Duration(round) * 10 * time.Microsecond)
+			p.Free()
+			wg.Wait()
+			if total := ran.Load() + discarded.Load() + rejected.Load(); total != 8*50 {
+				t.Fatalf("queue size %d: ran %d + discarded %d + rejected %d != %d",
+					size, ran.Load(), discarded.Load(), rejected.Load(), 8*50)
+			}
+		}
+	}
+}
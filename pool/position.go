@@ -0,0 +1,99 @@
+package workerpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QueueInfo 描述一个已提交的任务的排队情况，见 Future.QueueInfo
+type QueueInfo struct {
+	Queued        bool          // 是否仍在等待 worker 取走
+	Position      int           // 在队列中的位置，1 表示下一个被取走，为估计值
+	EstimatedWait time.Duration // 按最近的吞吐估计的剩余等待时间，尚无足够的统计时为 0
+}
+
+// queueSeq 给经由 p.tasks 交出的任务编号：enqueued 为已编号的最大值，dequeued 为 worker 取走的最大值，
+// 二者之差即排在前面的任务数；p.tasks 按发送顺序交出，未能送出的编号只会让位置偏大
+type queueSeq struct {
+	enqueued atomic.Uint64
+	dequeued atomic.Uint64
+}
+
+// enqueue 在经由 p.tasks 交出 t 前调用
+func (p *Pool) enqueue(t *task) {
+	t.seq = p.seq.enqueued.Add(1)
+	if t.claim != nil {
+		t.claim.seq.Store(t.seq)
+	}
+}
+
+// dequeue 在 worker 取走 t 后调用
+func (p *Pool) dequeue(t task) {
+	if t.seq == 0 {
+		return
+	}
+	for {
+		d := p.seq.dequeued.Load()
+		if d >= t.seq || p.seq.dequeued.CompareAndSwap(d, t.seq) {
+			return
+		}
+	}
+}
+
+// backlogged 表示有任务在排队等待 worker
+func (p *Pool) backlogged() bool {
+	if p.sched != nil {
+		return p.sched.len() > 0
+	}
+	return len(p.tasks) > 0
+}
+
+// throughputMeter 统计 pool 忙碌时相邻两次任务完成的平均间隔，用于估计排队时间
+type throughputMeter struct {
+	mu       sync.Mutex
+	last     time.Time
+	interval time.Duration // 指数移动平均
+}
+
+// done 在任务完成时调用，busy 表示仍有任务在排队；空闲期间的间隔不反映处理能力，不计入
+func (m *throughputMeter) done(now time.Time, busy bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if busy && !m.last.IsZero() {
+		dt := now.Sub(m.last)
+		if m.interval == 0 {
+			m.interval = dt
+		} else {
+			m.interval += (dt - m.interval) / 8
+		}
+	}
+	m.last = now
+}
+
+func (m *throughputMeter) estimate(n int) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return time.Duration(n) * m.interval
+}
+
+// queueInfo 返回 c 对应的任务的排队情况，c 为 nil（尚未提交）或任务已开始、被丢弃时 Queued 为 false
+func (p *Pool) queueInfo(c *taskClaim) QueueInfo {
+	if c == nil || c.state.Load() != claimPending {
+		return QueueInfo{}
+	}
+	if p.sched != nil {
+		pos := p.sched.position(c)
+		if pos == 0 {
+			return QueueInfo{}
+		}
+		return QueueInfo{Queued: true, Position: pos, EstimatedWait: p.throughput.estimate(pos)}
+	}
+	seq := c.seq.Load()
+	d := p.seq.dequeued.Load()
+	if seq == 0 || seq <= d { // 直接交给了新建的 worker，或已被取走尚未开始
+		return QueueInfo{}
+	}
+	pos := int(seq - d)
+	return QueueInfo{Queued: true, Position: pos, EstimatedWait: p.throughput.estimate(pos)}
+}
@@ -0,0 +1,58 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueueInfo(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(4))
+	defer p.Free()
+	var fs []*Future[int]
+	for i := range 3 {
+		f, err := Submit(p, func(context.Context) (int, error) { return i, nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, f)
+	}
+	waitQueued(t, p, 3)
+	for i, f := range fs {
+		if info := f.QueueInfo(); !info.Queued || info.Position != i+1 || info.EstimatedWait != 0 {
+			t.Fatalf("future %d: QueueInfo = %+v", i, info)
+		}
+	}
+	if !fs[1].Cancel() {
+		t.Fatal("Cancel of a queued future failed")
+	}
+	if info := fs[1].QueueInfo(); info.Queued {
+		t.Fatalf("canceled future: QueueInfo = %+v", info)
+	}
+	release()
+	for i, f := range fs {
+		f.Wait()
+		if info := f.QueueInfo(); info.Queued {
+			t.Fatalf("finished future %d: QueueInfo = %+v", i, info)
+		}
+	}
+}
+
+func TestThroughputEstimate(t *testing.T) {
+	var m throughputMeter
+	now := time.Unix(0, 0)
+	m.done(now, true)
+	if d := m.estimate(3); d != 0 {
+		t.Fatalf("estimate without an interval = %s", d)
+	}
+	m.done(now.Add(10*time.Millisecond), true)
+	if d := m.estimate(3); d != 30*time.Millisecond {
+		t.Fatalf("estimate = %s, want 30ms", d)
+	}
+	// 空闲后的第一次完成不计入间隔
+	m.done(now.Add(time.Hour), false)
+	m.done(now.Add(time.Hour+18*time.Millisecond), true)
+	if d := m.estimate(1); d != 11*time.Millisecond { // 10ms + (18ms-10ms)/8
+		t.Fatalf("estimate after an idle period = %s, want 11ms", d)
+	}
+}
@@ -0,0 +1,603 @@
+package workerpool
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// 优先级调度：WithPriorityScheduling 开启后，不能立即交给 worker 的任务进入按优先级排序的队列，
+// 由一个 feeder goroutine 依次取出最优先的任务交给 worker。队列中最多 queueSize 个任务被“接受”
+// （提交方已返回），其余的提交方阻塞等待，但同样按优先级参与分发；阻塞的提交方按到达顺序被接受
+//
+// 排队时间越长的任务有效优先级越高（aging）：每等待 aging 时长优先级加一，
+// 持续的高优先级负载下低优先级任务也终会被执行
+//
+// 设置了 WithPriorityWeights 时改为加权出队：每次按平滑加权轮询在有条目的优先级之间选出一个，
+// 取其中最早到达的条目，各优先级出队的次数之比等于权重之比；此时 aging 不起作用
+
+type entryState int8
+
+const (
+	entryQueued     entryState = iota // 在堆中
+	entryHeld                         // 已被 feeder 取出，等待 worker
+	entryDispatched                   // 已交给 worker
+	entryRemoved                      // 未执行即被移除，原因见 err
+)
+
+type schedEntry struct {
+	t        task
+	key      float64 // 静态排序键，越大越优先，见 scheduler.key
+	seq      uint64  // 到达顺序，key 相同时先到先出
+	index    int     // 在堆中的下标
+	state    entryState
+	accepted bool          // 计入 queueSize，提交方已返回
+	done     chan struct{} // 阻塞中的提交方等待：被接受、交出或移除时关闭
+	leave    chan struct{} // 阻塞中的提交方放弃等待时关闭，通知持有它的 feeder
+	err      error         // 被移除的原因
+
+	due time.Duration // WithShortestJobFirst 的排序键，key 相同时越小越先，见 sjfDue
+}
+
+type schedHeap []*schedEntry
+
+func (h schedHeap) Len() int           { return len(h) }
+func (h schedHeap) Less(i, j int) bool { return ahead(h[i], h[j]) }
+func (h schedHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *schedHeap) Push(x any) {
+	e := x.(*schedEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *schedHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	e.index = -1
+	return e
+}
+
+// ahead 表示严格优先级下 a 先于 b 分发
+func ahead(a, b *schedEntry) bool {
+	if a.key != b.key {
+		return a.key > b.key
+	}
+	if a.due != b.due {
+		return a.due < b.due
+	}
+	return a.seq < b.seq
+}
+
+type scheduler struct {
+	mu       sync.Mutex
+	levels   map[int]*schedHeap // 按优先级分组的排队条目
+	n        int                // 排队条目的总数
+	held     *schedEntry        // feeder 取出尚未交出的条目
+	blocked  []*schedEntry      // 尚未被接受的条目，按到达顺序
+	accepted int                // 已接受尚未交出的条目数
+	limit    int                // 最多接受的条目数，即 queueSize
+	seq      uint64
+	signal   chan struct{} // 有新条目时通知 feeder
+	aging    time.Duration // 0 表示不随等待时间提升
+	epoch    time.Time
+	weights  map[int]int // 加权出队时各优先级的权重，nil 表示严格优先级
+	credit   map[int]int // 平滑加权轮询中各优先级的当前值
+
+	policy SchedulingPolicy // WithSchedulingPolicy，nil 表示按 weights 或严格优先级出队
+	order  []*schedEntry    // pick 的缓冲，复用以免每次出队分配
+	view   []QueuedTask
+}
+
+func newScheduler(limit int, aging time.Duration, weights map[int]int, now time.Time) *scheduler {
+	return &scheduler{
+		levels: make(map[int]*schedHeap), limit: limit, signal: make(chan struct{}, 1),
+		aging: aging, epoch: now, weights: weights, credit: make(map[int]int),
+	}
+}
+
+// key 将“优先级 + 已等待时长/aging”转换为不随时间变化的排序键：
+// 所有条目的有效优先级以相同速度增长，比较 prio - 入队时刻/aging 即可
+func (s *scheduler) key(prio int, now time.Time) float64 {
+	if s.aging <= 0 {
+		return float64(prio)
+	}
+	return float64(prio) - float64(now.Sub(s.epoch))/float64(s.aging)
+}
+
+func (s *scheduler) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n
+}
+
+// idle 表示没有等待分发的条目，此时新的任务可以直接交给 worker 而不必排队
+func (s *scheduler) idle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n == 0 && s.held == nil
+}
+
+// 需持有 s.mu
+func (s *scheduler) push(e *schedEntry) {
+	h := s.levels[e.t.prio]
+	if h == nil {
+		h = &schedHeap{}
+		s.levels[e.t.prio] = h
+	}
+	heap.Push(h, e)
+	s.n++
+}
+
+// 需持有 s.mu
+func (s *scheduler) remove(e *schedEntry) {
+	h := s.levels[e.t.prio]
+	heap.Remove(h, e.index)
+	s.n--
+	if h.Len() == 0 {
+		delete(s.levels, e.t.prio)
+		delete(s.credit, e.t.prio)
+	}
+}
+
+// 需持有 s.mu：严格优先级下最先分发的条目，没有排队的条目时返回 nil
+func (s *scheduler) top() *schedEntry {
+	var best *schedEntry
+	for _, h := range s.levels {
+		if e := (*h)[0]; best == nil || ahead(e, best) {
+			best = e
+		}
+	}
+	return best
+}
+
+// 需持有 s.mu：下一个出队的条目；设置了 WithSchedulingPolicy 时由它选出，
+// 加权出队时按平滑加权轮询选出优先级，并计入该优先级的 credit
+func (s *scheduler) next() *schedEntry {
+	if s.policy != nil {
+		return s.pick()
+	}
+	if s.weights == nil {
+		return s.top()
+	}
+	total, pick, found := 0, 0, false
+	for prio := range s.levels {
+		w, ok := s.weights[prio]
+		if !ok {
+			w = 1
+		}
+		s.credit[prio] += w
+		total += w
+		if c := s.credit[prio]; !found || c > s.credit[pick] || c == s.credit[pick] && prio > pick {
+			pick, found = prio, true
+		}
+	}
+	if !found {
+		return nil
+	}
+	s.credit[pick] -= total
+	return (*s.levels[pick])[0]
+}
+
+func (s *scheduler) notify() {
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// pushPriority 将 t 放入优先级队列：未超出 limit 时直接接受（accepted 为 true），
+// 否则 mayBlock 为 true 时作为阻塞等待的条目放入，为 false 时不放入并返回 nil
+// 设置了 WithPriorityEviction 时，队列已满则移除优先级低于 t 的一个已接受的任务，直接接受 t
+func (p *Pool) pushPriority(t task, mayBlock bool) (e *schedEntry, accepted bool) {
+	s := p.sched
+	s.mu.Lock()
+	var evicted *schedEntry
+	defer func() {
+		s.mu.Unlock()
+		if evicted != nil {
+			p.notifyDiscard(evicted.t.claim, ErrTaskEvicted)
+		}
+	}()
+	if s.accepted >= s.limit {
+		p.prune()
+	}
+	if s.accepted >= s.limit && p.evict {
+		evicted = p.evictBelow(t.prio)
+	}
+	now := p.clock.Now()
+	e = &schedEntry{t: t, key: s.key(t.prio, now)}
+	if p.sjf != nil {
+		e.due = p.sjfDue(t, now)
+	}
+	switch {
+	case evicted != nil:
+		e.accepted = true
+		s.accepted++
+	case s.accepted < s.limit && len(s.blocked) == 0:
+		e.accepted = true
+		s.accepted++
+	case mayBlock:
+		e.done = make(chan struct{})
+		e.leave = make(chan struct{})
+		s.blocked = append(s.blocked, e)
+	default:
+		return nil, false
+	}
+	s.seq++
+	e.seq = s.seq
+	s.push(e)
+	if s.policy != nil {
+		s.policy.Enqueue(queuedTask(&e.t, e.seq))
+	}
+	s.notify()
+	return e, e.accepted
+}
+
+// 需持有 s.mu：e 不再占用队列时调用，接受等待中的条目
+func (s *scheduler) release(e *schedEntry) {
+	if e.accepted {
+		s.accepted--
+	} else {
+		for i, b := range s.blocked {
+			if b == e {
+				s.blocked = append(s.blocked[:i], s.blocked[i+1:]...)
+				break
+			}
+		}
+	}
+	for s.accepted < s.limit && len(s.blocked) > 0 {
+		b := s.blocked[0]
+		s.blocked = s.blocked[1:]
+		if b.t.claim.state.Load() == claimDiscarded {
+			continue // 提交方将自行放弃，不占用名额
+		}
+		b.accepted = true
+		s.accepted++
+		close(b.done)
+	}
+}
+
+// 需持有 s.mu
+func (s *scheduler) settle(e *schedEntry, state entryState, err error) {
+	if e.state == entryQueued {
+		s.remove(e)
+	}
+	if s.held == e {
+		s.held = nil
+	}
+	e.state, e.err = state, err
+	waiting := !e.accepted
+	s.release(e)
+	if waiting {
+		close(e.done)
+	}
+}
+
+// take 取出最优先的条目交给 feeder，跳过已被 Purge 丢弃的任务；队列为空时返回 nil
+func (p *Pool) take() *schedEntry {
+	s := p.sched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.policy != nil {
+		p.prune() // 已丢弃的任务不交给 Next
+	}
+	for s.n > 0 {
+		e := s.next()
+		s.remove(e)
+		e.state = entryHeld
+		if e.t.claim.state.Load() == claimDiscarded {
+			p.removeDiscarded(e)
+			continue
+		}
+		s.held = e
+		return e
+	}
+	return nil
+}
+
+// 需持有 s.mu：移除优先级低于 prio 的已接受条目中优先级最低、最晚到达的一个，没有时返回 nil；
+// 返回的条目已标记为丢弃，调用方在释放 s.mu 后通知
+func (p *Pool) evictBelow(prio int) *schedEntry {
+	var victim *schedEntry
+	for _, h := range p.sched.levels {
+		for _, e := range *h {
+			if !e.accepted || e.t.prio >= prio {
+				continue
+			}
+			if victim == nil || e.t.prio < victim.t.prio || e.t.prio == victim.t.prio && e.seq > victim.seq {
+				victim = e
+			}
+		}
+	}
+	if victim == nil || !victim.t.claim.state.CompareAndSwap(claimPending, claimDiscarded) {
+		return nil
+	}
+	p.removeDiscarded(victim)
+	return victim
+}
+
+// 需持有 s.mu：从队列中移除已被 Purge 丢弃的条目，腾出被接受的名额
+func (p *Pool) prune() {
+	var discarded []*schedEntry
+	for _, h := range p.sched.levels {
+		for _, e := range *h {
+			if e.t.claim.state.Load() == claimDiscarded {
+				discarded = append(discarded, e)
+			}
+		}
+	}
+	for _, e := range discarded {
+		p.removeDiscarded(e)
+	}
+}
+
+// 需持有 s.mu：移除已被丢弃的 e，已接受的任务在此撤销计数，阻塞中的提交方自行撤销
+func (p *Pool) removeDiscarded(e *schedEntry) {
+	if e.accepted && e.t.counted {
+		p.inflight.add(-1)
+	}
+	p.sched.settle(e, entryRemoved, ErrTaskDiscarded)
+}
+
+// putBack 在出现更优先的条目时放回 feeder 持有的 e，返回是否放回；加权出队或设置了 WithSchedulingPolicy 时 e 已被选出，不会因新的条目放回
+func (s *scheduler) putBack(e *schedEntry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-e.leave: // 提交方已放弃
+		s.settle(e, entryRemoved, e.err)
+		return true
+	default:
+	}
+	if s.weights != nil || s.policy != nil {
+		return false
+	}
+	if top := s.top(); top == nil || !ahead(top, e) {
+		return false
+	}
+	s.held = nil
+	e.state = entryQueued
+	s.push(e)
+	return true
+}
+
+func (s *scheduler) dispatched(e *schedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settle(e, entryDispatched, nil)
+}
+
+// leave 结束阻塞中的提交方对 e 的等待，返回提交的结果：err 非 nil 表示因 err 放弃，nil 表示 e.done 已关闭
+// e 已被接受或交给 worker 时返回 nil，提交照常成功；e 被移除时返回移除的原因
+func (s *scheduler) leave(e *schedEntry, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && !e.accepted {
+		switch e.state {
+		case entryQueued:
+			s.settle(e, entryRemoved, err)
+		case entryHeld:
+			e.err = err
+			close(e.leave)
+			s.mu.Unlock()
+			<-e.done
+			s.mu.Lock()
+		}
+	}
+	if e.accepted || e.state != entryRemoved {
+		return nil
+	}
+	return e.err
+}
+
+// position 返回 c 对应的条目在队列中的位置，不在队列中时返回 0；加权出队时是按严格优先级估计的位置
+func (s *scheduler) position(c *taskClaim) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var e *schedEntry
+	for _, h := range s.levels {
+		for _, x := range *h {
+			if x.t.claim == c {
+				e = x
+			}
+		}
+	}
+	if e == nil {
+		return 0
+	}
+	pos := 1
+	for _, h := range s.levels {
+		for _, x := range *h {
+			if ahead(x, e) {
+				pos++
+			}
+		}
+	}
+	return pos
+}
+
+// closeScheduler 在 pool 销毁时移除所有条目：已接受的任务被丢弃，阻塞中的提交方返回 ErrPoolClosed
+// feeder 退出时调用一次，所有提交方返回后再调用一次，清理期间新接受的任务
+func (p *Pool) closeScheduler() {
+	s := p.sched
+	s.mu.Lock()
+	var dropped []task
+	for s.held != nil || s.n > 0 {
+		e := s.held
+		if e == nil {
+			e = s.top()
+		}
+		if e.accepted {
+			dropped = append(dropped, e.t)
+		}
+		s.settle(e, entryRemoved, p.freedErr())
+	}
+	s.mu.Unlock()
+	for _, t := range dropped {
+		p.drop(t)
+	}
+}
+
+// submitPriority 是开启优先级调度时的 submit，t 已登记为可丢弃
+func (p *Pool) submitPriority(ctx context.Context, t task, block bool) error {
+	s := p.sched
+	if err := p.admit(&t); err != nil {
+		return err
+	}
+	if s.idle() && p.tryDispatch(t) {
+		return nil
+	}
+	if _, ok := p.pushPriority(t, false); ok {
+		return nil
+	}
+	if !block {
+		return p.saturatedErr()
+	}
+	if handled, rerr := p.handleReentrant(t); handled {
+		return rerr
+	}
+	unwait, derr := p.enterWait()
+	if derr != nil {
+		return derr
+	}
+	defer unwait()
+	p.blocked.Add(1)
+	defer p.blocked.Add(-1)
+	e, ok := p.pushPriority(t, true)
+	if ok {
+		return nil
+	}
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	var err error
+	select {
+	case <-e.done:
+	case <-p.closing:
+		err = p.freedErr()
+	case <-done:
+		err = p.waitErr(ctx)
+	case <-t.claim.discarded:
+		err = ErrTaskDiscarded
+	}
+	return s.leave(e, err)
+}
+
+// runScheduler 是 feeder：依次把最优先的条目交给 worker，容量未满时创建新的 worker
+func (p *Pool) runScheduler() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	s := p.sched
+	for {
+		e := p.take()
+		if e == nil {
+			select {
+			case <-s.signal:
+				continue
+			case <-p.quit:
+				p.closeScheduler()
+				return
+			}
+		}
+		if !p.feed(e) {
+			p.closeScheduler()
+			return
+		}
+	}
+}
+
+// feed 等待空闲的 worker 接收 e，期间出现更优先的条目时将 e 放回；pool 销毁时返回 false
+func (p *Pool) feed(e *schedEntry) bool {
+	s := p.sched
+	for {
+		select {
+		case p.active <- struct{}{}:
+			s.dispatched(e)
+			p.newWorker(&e.t)
+			return true
+		case p.tasks <- e.t:
+			s.dispatched(e)
+			return true
+		case <-s.signal:
+			if s.putBack(e) {
+				s.notify() // 让下一轮 take 取出更优先的条目
+				return true
+			}
+		case <-e.leave:
+			s.mu.Lock()
+			s.settle(e, entryRemoved, e.err)
+			s.mu.Unlock()
+			return true
+		case <-e.t.claim.discarded:
+			s.mu.Lock()
+			p.removeDiscarded(e)
+			s.mu.Unlock()
+			return true
+		case <-p.quit:
+			return false
+		}
+	}
+}
+
+// SchedulePriority 以优先级 priority 提交 t，数值越大越优先，开启 WithPriorityScheduling 时生效，否则等同于 Schedule
+func (p *Pool) SchedulePriority(priority int, t Task) error {
+	return p.schedule(task{fn: t, prio: priority})
+}
+
+// SchedulePriorityFunc 是 TaskFunc 形式的 SchedulePriority
+func (p *Pool) SchedulePriorityFunc(priority int, t TaskFunc) error {
+	return p.schedule(task{fnc: t, prio: priority})
+}
+
+// BoostFunc 把排队中、经由 ScheduleWith 提交且使 match 返回 true 的任务的优先级提升到 priority，
+// 用于后台任务突然变为用户等待的任务；已等待的时长仍计入 aging，优先级已不低于 priority 的任务不变。
+// 返回提升的任务数，未开启 WithPriorityScheduling 时总是返回 0；match 在内部锁中调用，不能再调用 pool 的方法
+func (p *Pool) BoostFunc(match func(TaskInfo) bool, priority int) int {
+	return p.applied().boost(func(t *task) bool { return t.info != nil && match(*t.info) }, priority)
+}
+
+func (p *Pool) boost(match func(t *task) bool, priority int) int {
+	s := p.sched
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var hit []*schedEntry
+	for _, h := range s.levels {
+		for _, e := range *h {
+			if e.t.prio < priority && match(&e.t) {
+				hit = append(hit, e)
+			}
+		}
+	}
+	for _, e := range hit {
+		s.remove(e)
+		s.raise(e, priority)
+		s.push(e)
+	}
+	if e := s.held; e != nil && e.t.prio < priority && match(&e.t) {
+		s.raise(e, priority) // 随后与队列中的条目比较，见 putBack
+		hit = append(hit, e)
+	}
+	if len(hit) > 0 {
+		s.notify()
+	}
+	return len(hit)
+}
+
+// 需持有 s.mu，e 不在堆中
+func (s *scheduler) raise(e *schedEntry, priority int) {
+	e.key += float64(priority - e.t.prio)
+	e.t.prio = priority
+	if e.t.info != nil {
+		e.t.info.Priority = priority
+	}
+}
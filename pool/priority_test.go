@@ -0,0 +1,246 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recorder 按执行顺序记录任务的优先级
+type recorder struct {
+	mu  sync.Mutex
+	got []int
+}
+
+func (r *recorder) task(prio int) Task {
+	return func() {
+		r.mu.Lock()
+		r.got = append(r.got, prio)
+		r.mu.Unlock()
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	p, release := newBusyPool(t, WithPriorityScheduling(0), WithQueueSize(10))
+	defer p.Free()
+	var r recorder
+	// 第一个任务被 feeder 取出等待 worker，之后更优先的任务到达时被放回
+	for _, prio := range []int{1, 5, 3, 9, 0, 5} {
+		if err := p.SchedulePriority(prio, r.task(prio)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release()
+	p.Wait()
+	want := []int{9, 5, 5, 3, 1, 0}
+	for i := range want {
+		if r.got[i] != want[i] {
+			t.Fatalf("ran %v, want %v", r.got, want)
+		}
+	}
+}
+
+func TestPriorityWeights(t *testing.T) {
+	p, release := newBusyPool(t, WithPriorityScheduling(0), WithPriorityWeights(map[int]int{2: 8, 1: 2}), WithQueueSize(200))
+	defer p.Free()
+	var r recorder
+	// 第一个任务被 feeder 取出等待 worker，其余的按 8:2:1 出队
+	for _, n := range []struct{ prio, count int }{{2, 81}, {1, 20}, {0, 10}} {
+		for i := 0; i < n.count; i++ {
+			if err := p.SchedulePriority(n.prio, r.task(n.prio)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	release()
+	p.Wait()
+	counts := map[int]int{}
+	for _, prio := range r.got[1:56] {
+		counts[prio]++
+	}
+	if counts[2] != 40 || counts[1] != 10 || counts[0] != 5 {
+		t.Fatalf("first 55 dequeues by priority: %v, want 40:10:5", counts)
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+	p, release := newBusyPool(t, WithPriorityScheduling(time.Millisecond), WithQueueSize(10))
+	defer p.Free()
+	var r recorder
+	p.SchedulePriority(0, r.task(0))
+	time.Sleep(30 * time.Millisecond) // 等待 30 个 aging 周期，有效优先级超过后来的 5
+	p.SchedulePriority(5, r.task(5))
+	release()
+	p.Wait()
+	if len(r.got) != 2 || r.got[0] != 0 {
+		t.Fatalf("ran %v, want the aged task first", r.got)
+	}
+}
+
+func TestPriorityBoost(t *testing.T) {
+	p, release := newBusyPool(t, WithPriorityScheduling(0), WithQueueSize(10))
+	defer p.Free()
+	var r recorder
+	p.SchedulePriority(1, r.task(1)) // 被 feeder 取出等待 worker，提升其它任务后被放回
+	bg := r.task(5)
+	if err := p.ScheduleWith(func(context.Context) { bg() }, WithTaskName("report"), WithPriority(-1)); err != nil {
+		t.Fatal(err)
+	}
+	p.SchedulePriority(2, r.task(2))
+	f, err := Submit(p, func(context.Context) (int, error) { r.task(9)(); return 0, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := p.BoostFunc(func(info TaskInfo) bool { return info.Name == "report" }, 5); n != 1 {
+		t.Fatalf("boosted %d tasks, want 1", n)
+	}
+	if !f.Boost(9) || f.Boost(3) {
+		t.Fatal("Boost should only raise the priority")
+	}
+	release()
+	p.Wait()
+	want := []int{9, 5, 2, 1}
+	for i := range want {
+		if r.got[i] != want[i] {
+			t.Fatalf("ran %v, want %v", r.got, want)
+		}
+	}
+	if f.Boost(10) {
+		t.Fatal("Boost of a finished task should report false")
+	}
+}
+
+func TestPriorityBlockedSubmitters(t *testing.T) {
+	p, release := newBusyPool(t, WithPriorityScheduling(0))
+	defer p.Free()
+	var ran atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := p.SchedulePriority(i, func() { ran.Add(1) }); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.ScheduleCtx(ctx, func(context.Context) {}); !errors.Is(err, ErrScheduleTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrScheduleTimeout wrapping context.DeadlineExceeded", err)
+	}
+	if err := p.TryScheduleFunc(func(context.Context) {}); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated", err)
+	}
+	release()
+	wg.Wait()
+	p.Wait()
+	if n := ran.Load(); n != 50 {
+		t.Fatalf("%d of 50 tasks ran", n)
+	}
+}
+
+func TestPriorityPurgeAndFree(t *testing.T) {
+	var discarded atomic.Int32
+	p, release := newBusyPool(t, WithPriorityScheduling(0), WithQueueSize(5), WithOnDiscard(func(error) { discarded.Add(1) }))
+	for i := 0; i < 5; i++ {
+		if err := p.SchedulePriority(i, func() { t.Error("purged task ran") }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { errs <- p.SchedulePriority(1, func() { t.Error("purged task ran") }) }()
+	}
+	waitQueued(t, p, 8)
+	if n := p.Purge(); n != 8 {
+		t.Fatalf("Purge() = %d, want 8", n)
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errs; !errors.Is(err, ErrTaskDiscarded) {
+			t.Fatalf("blocked submitter got %v, want ErrTaskDiscarded", err)
+		}
+	}
+
+	// 丢弃的任务腾出了队列；Free 时已接受的任务要么执行要么被丢弃，阻塞中的提交方返回 ErrPoolClosed 或在 Free 前被接受
+	var ran atomic.Int32
+	for i := 0; i < 5; i++ {
+		if err := p.SchedulePriority(i, func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go func() { errs <- p.SchedulePriority(1, func() { ran.Add(1) }) }()
+	waitQueued(t, p, 6)
+	discarded.Store(0)
+	p.Free()
+	release()
+	want := int32(5)
+	switch err := <-errs; {
+	case err == nil:
+		want++
+	case !errors.Is(err, ErrPoolClosed):
+		t.Fatalf("blocked submitter got %v, want ErrPoolClosed", err)
+	}
+	if n := ran.Load() + discarded.Load(); n != want {
+		t.Fatalf("ran %d + discarded %d, want %d", ran.Load(), discarded.Load(), want)
+	}
+}
+
+// 并发的提交、ctx 取消、Purge 与 Free 下，每个被接受的任务要么执行一次，要么被报告丢弃
+func TestPriorityStress(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		var ran, discarded, rejected atomic.Int64
+		p := New(3, WithPriorityScheduling(time.Microsecond), WithQueueSize(round%4), WithLogger(nil),
+			WithOnDiscard(func(error) { discarded.Add(1) }))
+		var wg sync.WaitGroup
+		for s := 0; s < 8; s++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 60; i++ {
+					ctx, cancel := context.WithTimeout(context.Background(), time.Duration(i%3)*50*time.Microsecond)
+					var err error
+					if i%2 == 0 {
+						err = p.ScheduleCtx(ctx, func(context.Context) { ran.Add(1) })
+					} else {
+						err = p.SchedulePriority(i%5, func() { ran.Add(1) })
+					}
+					cancel()
+					if err != nil && !errors.Is(err, ErrTaskDiscarded) { // 被丢弃的任务已由 OnDiscard 计数
+						rejected.Add(1)
+					}
+				}
+			}()
+		}
+		stop := make(chan struct{})
+		purged := make(chan struct{})
+		go func() {
+			defer close(purged)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					p.Purge()
+					time.Sleep(20 * time.Microsecond)
+				}
+			}
+		}()
+		time.Sleep(time.Duration(round) * 100 * time.Microsecond)
+		if round%2 == 0 {
+			p.Free()
+		}
+		wg.Wait()
+		close(stop)
+		<-purged
+		p.Wait()
+		p.Free()
+		if total := ran.Load() + discarded.Load() + rejected.Load(); total != 8*60 {
+			t.Fatalf("round %d: ran %d + discarded %d + rejected %d != %d",
+				round, ran.Load(), discarded.Load(), rejected.Load(), 8*60)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressUpdate 是某一时刻一批任务的完成情况，Done 包含了 Failed
+type ProgressUpdate struct {
+	Done   int
+	Failed int
+	Total  int
+}
+
+// Progress 统计一批任务的进度，供命令行或界面渲染进度条：
+//
+//	pr := NewProgress(len(items), func(u ProgressUpdate) { bar.Set(u.Done, u.Total) })
+//	out, err := Map(p, items, Track(pr, fn))
+//
+// 回调在完成任务的 worker 上串行调用，应尽快返回
+type Progress struct {
+	mu       sync.Mutex
+	cur      ProgressUpdate
+	onUpdate func(ProgressUpdate)
+	updates  chan ProgressUpdate // 由 Updates 按需创建
+	closed   bool
+}
+
+// NewProgress 创建一个总数为 total 的 Progress，onUpdate 可为 nil
+func NewProgress(total int, onUpdate func(ProgressUpdate)) *Progress {
+	return &Progress{cur: ProgressUpdate{Total: total}, onUpdate: onUpdate}
+}
+
+// Track 包装 Map/Filter 形式的 fn，每次调用结束时记一次完成，返回错误或 panic 时同时记为失败
+func Track[T, R any](pr *Progress, fn func(T) (R, error)) func(T) (R, error) {
+	return func(v T) (r R, err error) {
+		returned := false // fn panic 时仍为 false
+		defer func() { pr.Step(!returned || err != nil) }()
+		r, err = fn(v)
+		returned = true
+		return r, err
+	}
+}
+
+// TrackEach 与 Track 相同，用于 ForEach/ForEachSeq 形式的 fn
+func TrackEach[T any](pr *Progress, fn func(ctx context.Context, v T) error) func(ctx context.Context, v T) error {
+	return func(ctx context.Context, v T) (err error) {
+		returned := false
+		defer func() { pr.Step(!returned || err != nil) }()
+		err = fn(ctx, v)
+		returned = true
+		return err
+	}
+}
+
+// AddTotal 在总数事先未知时（如 ForEachSeq）逐步增加总数
+func (pr *Progress) AddTotal(n int) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.cur.Total += n
+	pr.publish()
+}
+
+// Step 记一次完成，failed 为 true 时同时记为失败
+func (pr *Progress) Step(failed bool) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.cur.Done++
+	if failed {
+		pr.cur.Failed++
+	}
+	pr.publish()
+}
+
+// Snapshot 返回当前进度
+func (pr *Progress) Snapshot() ProgressUpdate {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	return pr.cur
+}
+
+// Updates 返回一个进度 channel，消费不及时的旧进度会被最新的进度替换；
+// 完成数达到总数时 channel 关闭，因此可以直接 range
+func (pr *Progress) Updates() <-chan ProgressUpdate {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.updates == nil {
+		pr.updates = make(chan ProgressUpdate, 1)
+		pr.publish()
+	}
+	return pr.updates
+}
+
+// 需持有 pr.mu
+func (pr *Progress) publish() {
+	if pr.onUpdate != nil {
+		pr.onUpdate(pr.cur)
+	}
+	if pr.updates == nil || pr.closed {
+		return
+	}
+	select {
+	case <-pr.updates: // 丢弃尚未被消费的旧进度
+	default:
+	}
+	pr.updates <- pr.cur
+	if pr.cur.Total > 0 && pr.cur.Done >= pr.cur.Total {
+		pr.closed = true
+		close(pr.updates)
+	}
+}
@@ -0,0 +1,84 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestProgressTrack(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	in := []int{1, 2, 3, 4, 5, 6}
+	var last ProgressUpdate
+	calls := 0
+	pr := NewProgress(len(in), func(u ProgressUpdate) { // 串行调用，无需加锁
+		last = u
+		calls++
+	})
+	boom := errors.New("boom")
+	Map(p, in, Track(pr, func(v int) (int, error) {
+		switch v {
+		case 2:
+			return 0, boom
+		case 5:
+			panic("track")
+		}
+		return v, nil
+	}))
+	want := ProgressUpdate{Done: 6, Failed: 2, Total: 6}
+	if got := pr.Snapshot(); got != want || last != want || calls != 6 {
+		t.Fatalf("Snapshot = %+v, last update %+v after %d calls, want %+v", got, last, calls, want)
+	}
+}
+
+func TestProgressTrackEach(t *testing.T) {
+	p := New(1, WithLogger(nil)) // 逐个执行，失败的最后一个元素不会让前面的元素被跳过
+	defer p.Free()
+	pr := NewProgress(0, nil)
+	err := ForEachSeq(context.Background(), p, func(yield func(int) bool) {
+		for i := range 4 {
+			pr.AddTotal(1) // 总数事先未知
+			if !yield(i) {
+				return
+			}
+		}
+	}, TrackEach(pr, func(ctx context.Context, v int) error {
+		if v == 3 {
+			return errors.New("three")
+		}
+		return nil
+	}))
+	if err == nil {
+		t.Fatal("ForEachSeq did not report the failed item")
+	}
+	if got := pr.Snapshot(); got != (ProgressUpdate{Done: 4, Failed: 1, Total: 4}) {
+		t.Fatalf("Snapshot = %+v", got)
+	}
+}
+
+func TestProgressUpdates(t *testing.T) {
+	pr := NewProgress(3, nil)
+	updates := pr.Updates()
+	if u := <-updates; u != (ProgressUpdate{Total: 3}) {
+		t.Fatalf("first update = %+v", u)
+	}
+	pr.Step(false)
+	pr.Step(true) // 未被消费的 Done: 1 被替换
+	if u := <-updates; u != (ProgressUpdate{Done: 2, Failed: 1, Total: 3}) {
+		t.Fatalf("latest update = %+v", u)
+	}
+	pr.Step(false)
+	var rest []ProgressUpdate
+	for u := range updates { // 完成数达到总数后关闭
+		rest = append(rest, u)
+	}
+	if !slices.Equal(rest, []ProgressUpdate{{Done: 3, Failed: 1, Total: 3}}) {
+		t.Fatalf("updates after completion = %+v", rest)
+	}
+	pr.Step(false) // 关闭后再 Step 不会 panic
+	if pr.Updates() != updates {
+		t.Fatal("Updates returned a new channel")
+	}
+}
@@ -0,0 +1,231 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrTaskDiscarded = errors.New("task discarded before start")
+	ErrTaskEvicted   = errors.New("task evicted by a higher-priority task") // 见 WithPriorityEviction
+)
+
+const (
+	claimPending int32 = iota
+	claimStarted
+	claimDiscarded
+)
+
+// taskClaim 记录一个已提交但尚未开始执行的任务，执行与丢弃（Purge）二者只有一个能成功
+// 持有任务的一方（阻塞中的提交方、dispatchAsync、worker）看到丢弃后放弃该任务
+type taskClaim struct {
+	state     atomic.Int32
+	discarded chan struct{}      // 丢弃时关闭
+	onDiscard func(reason error) // 丢弃时调用，如以 reason 解析 Future
+	info      *TaskInfo          // ScheduleWith 提交的任务，丢弃的原因包装为 *TaskError
+	id        uint64             // 任务在生命周期事件中的编号，见 Event
+	seq       atomic.Uint64      // 经由 p.tasks 交出时的编号，见 QueueInfo
+	size      int64              // WithQueueBytes 估计的大小，登记在 p.queued 期间计入
+	queuedAt  atomic.Int64       // 提交时刻的 UnixNano，见 WithStarvationDetector
+}
+
+func newTaskClaim() *taskClaim {
+	return &taskClaim{discarded: make(chan struct{})}
+}
+
+// queuedSet 是所有尚未开始执行的 taskClaim
+type queuedSet struct {
+	mu    sync.Mutex
+	m     map[*taskClaim]struct{}
+	bytes int64 // 其中各 taskClaim.size 之和
+}
+
+func (s *queuedSet) add(c *taskClaim) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addLocked(c)
+}
+
+// 需持有 s.mu
+func (s *queuedSet) addLocked(c *taskClaim) {
+	if s.m == nil {
+		s.m = make(map[*taskClaim]struct{})
+	}
+	if _, ok := s.m[c]; !ok {
+		s.m[c] = struct{}{}
+		s.bytes += c.size
+	}
+}
+
+// reserve 与 add 相同，但加入 c 后大小之和超出 limit 时不加入并返回 false；为空时总是加入
+func (s *queuedSet) reserve(c *taskClaim, limit int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.m[c]; !ok && s.bytes > 0 && s.bytes+c.size > limit {
+		return false
+	}
+	s.addLocked(c)
+	return true
+}
+
+func (s *queuedSet) remove(c *taskClaim) {
+	s.mu.Lock()
+	if _, ok := s.m[c]; ok {
+		delete(s.m, c)
+		s.bytes -= c.size
+	}
+	s.mu.Unlock()
+}
+
+// markQueued 在 t 进入等待（阻塞提交或等待重新分发）前登记，使其可以被 Purge 丢弃
+func (p *Pool) markQueued(t *task) {
+	if t.claim == nil {
+		t.claim = newTaskClaim()
+	}
+	t.claim.id = t.id
+	p.markQueuedAt(t)
+	p.queued.add(t.claim)
+}
+
+// unqueue 在提交失败（pool 销毁、ctx 取消）时撤销登记，之后不再被 Purge 计数；返回 false 表示 t 已被丢弃
+func (p *Pool) unqueue(t task) bool {
+	if !t.claim.state.CompareAndSwap(claimPending, claimDiscarded) {
+		return false
+	}
+	p.queued.remove(t.claim)
+	return true
+}
+
+// start 在 worker 开始执行 t 前调用，返回 false 表示 t 已被丢弃
+func (p *Pool) start(t task) bool {
+	c := t.claim
+	if c == nil {
+		return true
+	}
+	if !c.state.CompareAndSwap(claimPending, claimStarted) {
+		return false
+	}
+	p.queued.remove(c)
+	return true
+}
+
+// discard 以 reason 丢弃尚未开始的 c，返回是否成功
+func (p *Pool) discard(c *taskClaim, reason error) bool {
+	if !c.state.CompareAndSwap(claimPending, claimDiscarded) {
+		return false
+	}
+	p.notifyDiscard(c, reason)
+	return true
+}
+
+// notifyDiscard 在 c 被标记为丢弃后通知等待方与回调
+func (p *Pool) notifyDiscard(c *taskClaim, reason error) {
+	close(c.discarded)
+	p.queued.remove(c)
+	if p.observed() {
+		p.emit(Event{Kind: EventDiscarded, TaskID: c.id, Info: c.info, Err: reason})
+	}
+	if c.info != nil {
+		reason = &TaskError{Info: *c.info, Err: reason}
+	}
+	if c.onDiscard != nil {
+		c.onDiscard(reason)
+	}
+	if p.onDiscard != nil {
+		p.onDiscard(reason)
+	}
+}
+
+// drop 丢弃 pool 销毁时已被接受、但再也不会执行的 t
+// 设置了 HandoffTo 时改为迁移到接替的 pool，内部任务除外
+func (p *Pool) drop(t task) {
+	if q := p.successor.Load(); q != nil && (t.claim != nil || t.counted || t.detached) {
+		p.moveTo(q, t)
+		return
+	}
+	if t.counted {
+		p.inflight.add(-1)
+	}
+	switch {
+	case t.claim != nil:
+		p.discard(t.claim, p.freedErr())
+	case t.counted || t.detached: // 内部任务（如 Semaphore 的占位任务）不通知
+		p.reportDiscard(t, p.freedErr())
+	}
+}
+
+// reportDiscard 通知没有 claim 的 t 被丢弃
+func (p *Pool) reportDiscard(t task, reason error) {
+	if p.observed() {
+		p.emit(Event{Kind: EventDiscarded, TaskID: t.id, Info: t.info, Err: reason})
+	}
+	if p.onDiscard != nil {
+		p.onDiscard(reason)
+	}
+}
+
+// skipCanceled 在 worker 取走 t 后、开始执行前检查提交方的 ctx，已取消时丢弃 t（原因为 context.Cause），
+// 返回 true 表示 t 不再执行；见 WithDropCanceled
+func (p *Pool) skipCanceled(t task) bool {
+	if !p.dropCanceled || t.ctx == nil || t.ctx.Err() == nil {
+		return false
+	}
+	reason := context.Cause(t.ctx)
+	if t.claim != nil {
+		return p.discard(t.claim, reason) // 失败时已被丢弃，随后的 start 同样失败
+	}
+	p.reportDiscard(t, reason)
+	return true
+}
+
+// dropUndispatched 在所有 worker 与提交方退出后丢弃 p.tasks 中缓冲的任务
+func (p *Pool) dropUndispatched() {
+	for {
+		select {
+		case t := <-p.tasks:
+			p.drop(t)
+		default:
+			if p.sched != nil {
+				p.closeScheduler()
+			}
+			if p.sticky != nil {
+				p.dropSticky()
+			}
+			return
+		}
+	}
+}
+
+// Purge 丢弃所有已提交但尚未开始执行的任务，返回丢弃的数量，对每个丢弃的任务调用 WithOnDiscard 设置的回调：
+// 包括阻塞在 Schedule 中的任务（Schedule 返回 ErrTaskDiscarded）、已被接受等待分发的任务，
+// 以及默认 MemoryQueue 中等待执行的 Job（启用 WAL 时标记为完成）；正在执行的任务不受影响
+func (p *Pool) Purge() int {
+	p = p.applied()
+	s := &p.queued
+	s.mu.Lock()
+	claims := make([]*taskClaim, 0, len(s.m))
+	for c := range s.m {
+		claims = append(claims, c)
+	}
+	s.mu.Unlock()
+	n := 0
+	for _, c := range claims {
+		if p.discard(c, ErrTaskDiscarded) {
+			n++
+		}
+	}
+	if q, ok := p.queue.(*MemoryQueue); ok {
+		for _, j := range q.purge() {
+			if p.usesWAL() {
+				p.wal.complete(j.walID)
+			}
+			if p.onDiscard != nil {
+				p.onDiscard(ErrTaskDiscarded)
+			}
+			n++
+		}
+	}
+	return n
+}
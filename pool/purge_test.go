@@ -0,0 +1,163 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newBusyPool 返回一个容量为 1 的 pool，唯一的 worker 阻塞到 pool 销毁或 release 被调用
+func newBusyPool(t *testing.T, opts ...Option) (p *Pool, release func()) {
+	t.Helper()
+	p = New(1, append([]Option{WithLogger(nil)}, opts...)...)
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	if err := p.ScheduleFunc(func(ctx context.Context) {
+		close(started)
+		select {
+		case <-gate:
+		case <-ctx.Done():
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	return p, func() { close(gate) }
+}
+
+func waitQueued(t *testing.T, p *Pool, n int) {
+	t.Helper()
+	queued := func() int {
+		p.queued.mu.Lock()
+		defer p.queued.mu.Unlock()
+		return len(p.queued.m)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for queued() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", queued(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waitDone(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("helper did not return after its tasks were discarded")
+		return nil
+	}
+}
+
+// 缓冲中的任务被 Purge 或 Free 丢弃后，完成信号在任务内部的辅助函数仍然返回
+func TestHelpersReturnWhenBufferedTasksDiscarded(t *testing.T) {
+	helpers := map[string]func(p *Pool) error{
+		"Map": func(p *Pool) error {
+			_, err := Map(p, []int{1, 2}, func(v int) (int, error) { return v, nil })
+			return err
+		},
+		"ForEachSeq": func(p *Pool) error {
+			return ForEach(context.Background(), p, []int{1, 2}, func(context.Context, int) error { return nil })
+		},
+		"Graph": func(p *Pool) error {
+			g := NewGraph(p)
+			g.Add("a", func(context.Context) error { return nil })
+			g.Add("b", func(context.Context) error { return nil })
+			return g.Run(context.Background())
+		},
+		"Stream": func(p *Pool) error {
+			s := NewStream(p, 2)
+			for i := 0; i < 2; i++ {
+				if err := s.Go(func() StreamCallback { return nil }); err != nil {
+					return err
+				}
+			}
+			s.Wait()
+			return ErrTaskDiscarded // Stream 不报告任务的错误
+		},
+		"ResultGroup": func(p *Pool) error {
+			g := NewResultGroup[int](p)
+			for i := 0; i < 2; i++ {
+				g.Go(func(context.Context) (int, error) { return 0, nil })
+			}
+			g.Close()
+			var errs []error
+			for _, err := range g.Results() {
+				errs = append(errs, err)
+			}
+			return errors.Join(errs...)
+		},
+	}
+	for name, run := range helpers {
+		t.Run(name+"/Purge", func(t *testing.T) {
+			p, release := newBusyPool(t, WithQueueSize(4))
+			defer p.Free()
+			defer release()
+			done := make(chan error, 1)
+			go func() { done <- run(p) }()
+			waitQueued(t, p, 2)
+			if n := p.Purge(); n != 2 {
+				t.Fatalf("Purge() = %d, want 2", n)
+			}
+			if err := waitDone(t, done); !errors.Is(err, ErrTaskDiscarded) {
+				t.Fatalf("err = %v, want ErrTaskDiscarded", err)
+			}
+		})
+		t.Run(name+"/Free", func(t *testing.T) {
+			p, _ := newBusyPool(t, WithQueueSize(4))
+			done := make(chan error, 1)
+			go func() { done <- run(p) }()
+			waitQueued(t, p, 2)
+			p.Free()
+			waitDone(t, done)
+		})
+	}
+}
+
+func TestPurgeBufferedSchedule(t *testing.T) {
+	var discarded []error
+	p, release := newBusyPool(t, WithQueueSize(2), WithOnDiscard(func(reason error) { discarded = append(discarded, reason) }))
+	ran := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		if err := p.Schedule(func() { ran <- struct{}{} }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := p.Purge(); n != 2 {
+		t.Fatalf("Purge() = %d, want 2", n)
+	}
+	release()
+	p.Wait()
+	p.Free()
+	if len(ran) != 0 {
+		t.Fatalf("%d purged tasks ran", len(ran))
+	}
+	if len(discarded) != 2 || !errors.Is(discarded[0], ErrTaskDiscarded) {
+		t.Fatalf("discarded = %v", discarded)
+	}
+}
+
+func TestScheduleWithCallbackDiscardedOnFree(t *testing.T) {
+	p, _ := newBusyPool(t, WithQueueSize(2))
+	got := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		if err := p.ScheduleWithCallback(func() {}, func(err error, _ time.Duration) { got <- err }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Free()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-got:
+			if err != nil && !errors.Is(err, ErrPoolClosed) {
+				t.Fatalf("err = %v", err)
+			}
+		default:
+			t.Fatal("callback not called for a task dropped by Free")
+		}
+	}
+}
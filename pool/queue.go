@@ -0,0 +1,228 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Queue 是 Enqueue 提交的 Job 在等待执行期间存放的位置，默认为进程内的 MemoryQueue，
+// 可以通过 WithQueue 换成磁盘、Redis、SQS 等后端；pool 从 Queue 中取出 Job 执行，结束后调用 Ack
+type Queue interface {
+	Push(ctx context.Context, j Job) error
+	// Pop 阻塞直到取出一个 Job 或 ctx 被取消
+	Pop(ctx context.Context) (Delivery, error)
+	Len(ctx context.Context) (int, error)
+	// Ack 报告 d 的执行结果，err 为 nil 表示成功，后端据此删除、重投或转入失败队列
+	Ack(ctx context.Context, d Delivery, err error) error
+}
+
+// Delivery 是从 Queue 中取出的一个 Job，Token 由后端用来在 Ack 时定位该 Job
+type Delivery struct {
+	Job   Job
+	Token any
+}
+
+// MemoryQueue 是默认的进程内 Queue，进程退出后其中的 Job 随之丢失（配合 WithWAL 可以恢复）
+type MemoryQueue struct {
+	mu    sync.Mutex
+	jobs  []Job
+	ready chan struct{} // 有 Job 时非空
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *MemoryQueue) Push(_ context.Context, j Job) error {
+	q.mu.Lock()
+	q.jobs = append(q.jobs, j)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *MemoryQueue) Pop(ctx context.Context) (Delivery, error) {
+	for {
+		q.mu.Lock()
+		if len(q.jobs) > 0 {
+			j := q.jobs[0]
+			q.jobs[0] = Job{}
+			q.jobs = q.jobs[1:]
+			j.Attempt++
+			more := len(q.jobs) > 0
+			q.mu.Unlock()
+			if more { // 传递给下一个等待的 Pop
+				select {
+				case q.ready <- struct{}{}:
+				default:
+				}
+			}
+			return Delivery{Job: j}, nil
+		}
+		q.mu.Unlock()
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return Delivery{}, ctx.Err()
+		}
+	}
+}
+
+// purge 取出所有等待中的 Job
+func (q *MemoryQueue) purge() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := q.jobs
+	q.jobs = nil
+	return jobs
+}
+
+func (q *MemoryQueue) Len(context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs), nil
+}
+
+// Ack 对超时未完成的 Job 重新入队（Attempt 在下次 Pop 时加一），其它结果无需处理
+func (q *MemoryQueue) Ack(ctx context.Context, d Delivery, err error) error {
+	if errors.Is(err, ErrAckTimeout) {
+		return q.Push(ctx, d.Job)
+	}
+	return nil
+}
+
+// Pop 出错（如后端连接断开）后等待一段时间再重试
+const queuePopRetryDelay = time.Second
+
+// jobPump 从 Queue 取出 Job 交给 worker 执行，在首次 Enqueue 或 StartQueue 时启动
+type jobPump struct {
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	cancel  context.CancelFunc
+}
+
+// WithWAL 只作用于默认的 MemoryQueue，自定义 Queue 自行负责持久化
+func (p *Pool) usesWAL() bool {
+	_, mem := p.queue.(*MemoryQueue)
+	return p.wal != nil && mem
+}
+
+// push 将 j 放入队列并确保 pump 在运行
+func (p *Pool) push(j Job) error {
+	pump := &p.pump
+	pump.mu.Lock()
+	defer pump.mu.Unlock()
+	if pump.closed {
+		return p.freedErr()
+	}
+	p.startPump()
+	return p.queue.Push(context.Background(), j)
+}
+
+// StartQueue 开始从 Queue 中取出 Job 执行，Enqueue 时会自动开始；
+// 只消费、不提交的进程（如共享 Redis 队列的其它实例）应在 Register 所有处理函数后调用，
+// 否则取出的 Job 会因类型未注册而失败
+func (p *Pool) StartQueue() error {
+	p = p.applied()
+	pump := &p.pump
+	pump.mu.Lock()
+	defer pump.mu.Unlock()
+	if pump.closed {
+		return p.freedErr()
+	}
+	p.startPump()
+	return nil
+}
+
+// 需持有 p.pump.mu 且 closed 为 false：说明 Free 尚未开始等待 p.wg，此时 Add 是安全的
+func (p *Pool) startPump() {
+	pump := &p.pump
+	if pump.started {
+		return
+	}
+	pump.started = true
+	ctx, cancel := context.WithCancel(context.Background())
+	pump.cancel = cancel
+	p.wg.Add(1)
+	go p.runPump(ctx)
+}
+
+func (p *Pool) runPump(ctx context.Context) {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	for {
+		d, err := p.queue.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			p.logf("workerpool: pop job: %s\n", err)
+			t := p.clock.NewTimer(queuePopRetryDelay)
+			select {
+			case <-t.C():
+			case <-ctx.Done():
+				t.Stop()
+				return
+			}
+			continue
+		}
+		var st *ackState
+		done := func(err error) {
+			if err != nil {
+				p.logf("job[%s]: %s\n", d.Job.Type, err)
+			}
+			p.ack(d, err)
+		}
+		if p.visibility > 0 {
+			st = p.trackAck(d)
+			done = func(err error) {
+				if err == nil && p.explicitAck {
+					return // 等待 Ack
+				}
+				// 已超时重投时本次执行的结果作废
+				if st.settle(err) && err != nil {
+					p.logf("job[%s]: %s\n", d.Job.Type, err)
+				}
+			}
+		}
+		t, err := p.jobTask(d.Job, st, done)
+		if err != nil {
+			p.logf("workerpool: %s\n", err)
+			if st != nil {
+				st.settle(err)
+			} else {
+				p.ack(d, err)
+			}
+			continue
+		}
+		// 无论 WithBlock 如何设置都等待空闲的 worker，pool 销毁时放弃
+		if !p.waitDispatch(t, p.quit, nil) {
+			return // 未执行的 Job 保留在 WAL 或后端中
+		}
+	}
+}
+
+func (p *Pool) ack(d Delivery, err error) {
+	if p.usesWAL() && !errors.Is(err, ErrAckTimeout) { // 超时的 Job 由 MemoryQueue 重新入队，仍未完成
+		p.wal.complete(d.Job.walID)
+	}
+	if aerr := p.queue.Ack(context.Background(), d, err); aerr != nil {
+		p.logf("job[%s]: ack: %s\n", d.Job.Type, aerr)
+	}
+}
+
+func (p *Pool) stopPump() {
+	pump := &p.pump
+	pump.mu.Lock()
+	defer pump.mu.Unlock()
+	pump.closed = true
+	if pump.cancel != nil {
+		pump.cancel()
+	}
+}
@@ -0,0 +1,45 @@
+package workerpool
+
+// 按字节数限制排队：WithQueueBytes 为每个任务估计大小（如其负载的字节数），已接受尚未开始执行的任务
+// 大小之和不超过上限，超出时提交立即返回 *QueueFullError 而不是排队或阻塞；
+// 大量的小任务只受 WithQueueSize 的数量限制，少量的大负载则由字节数限制住内存
+
+func WithQueueBytes(limit int64, size func(TaskInfo) int64) Option { // 排队任务的估计大小之和不超过 limit，超出时提交返回 *QueueFullError；size 为 nil 时使用 WithPayloadSize 给出的大小，大小为 0 的任务不计入；队列为空时单个超出上限的任务仍被接受
+	return func(p *Pool) {
+		if limit <= 0 {
+			p.invalid("WithQueueBytes(%d): limit must be positive", limit)
+			return
+		}
+		if size == nil {
+			size = func(info TaskInfo) int64 { return info.Size }
+		}
+		p.queueBytes, p.payloadSize = limit, size
+	}
+}
+
+func WithPayloadSize(n int64) TaskOption { // 任务负载的字节数，供 WithQueueBytes 计入排队的大小
+	return func(t *task) {
+		t.info.Size = n
+	}
+}
+
+// reserveBytes 在 t 进入排队前计入其大小，超出上限时返回 false；大小随 t 离开 p.queued 时扣除
+func (p *Pool) reserveBytes(t *task) bool {
+	var info TaskInfo
+	if t.info != nil {
+		info = *t.info
+	}
+	size := p.payloadSize(info)
+	if size <= 0 {
+		return true
+	}
+	if t.claim == nil {
+		t.claim = newTaskClaim()
+	}
+	t.claim.id, t.claim.size = t.id, size
+	return p.queued.reserve(t.claim, p.queueBytes)
+}
+
+func (p *Pool) queueBytesErr() error {
+	return &QueueFullError{Pool: p.name, Capacity: p.capacity, QueueSize: p.queueSize, QueueBytes: p.queueBytes, stats: p.stats()}
+}
@@ -0,0 +1,42 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestQueueBytes(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(10), WithQueueBytes(100, nil))
+	defer p.Free()
+	submit := func(size int64) error {
+		return p.ScheduleWith(func(context.Context) {}, WithPayloadSize(size))
+	}
+	for _, size := range []int64{60, 30, 0} {
+		if err := submit(size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := submit(20)
+	var qe *QueueFullError
+	if !errors.Is(err, ErrQueueFull) || !errors.As(err, &qe) || qe.QueueBytes != 100 {
+		t.Fatalf("submit over the byte budget: %v", err)
+	}
+	if s := p.Stats(); s.QueuedBytes != 90 || s.Queued != 3 {
+		t.Fatalf("queued %d tasks, %d bytes", s.Queued, s.QueuedBytes)
+	}
+	if n := p.Purge(); n != 3 {
+		t.Fatalf("purged %d", n)
+	}
+	if s := p.Stats(); s.QueuedBytes != 0 {
+		t.Fatalf("%d bytes left after Purge", s.QueuedBytes)
+	}
+	if err := submit(500); err != nil { // 队列为空时超出上限的单个任务仍被接受
+		t.Fatal(err)
+	}
+	release()
+	p.Wait()
+	if s := p.Stats(); s.QueuedBytes != 0 {
+		t.Fatalf("%d bytes left after Wait", s.QueuedBytes)
+	}
+}
@@ -0,0 +1,37 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrTaskExpired = errors.New("task expired in queue") // 见 WithQueueTTL
+
+func WithQueueTTL(d time.Duration) Option { // 任务从提交到轮到执行等待超过 d 时不再执行，经由 WithOnDiscard 报告（原因包装 ErrTaskExpired），避免执行早已失去意义的任务（如过期的缓存刷新）；0 表示不限
+	return func(p *Pool) {
+		if d < 0 {
+			p.invalid("WithQueueTTL(%s): negative ttl", d)
+			return
+		}
+		p.queueTTL = d
+	}
+}
+
+// skipExpired 在 worker 取走 t 后、开始执行前检查 t 等待的时长，超过 WithQueueTTL 时丢弃 t，返回 true 表示 t 不再执行
+func (p *Pool) skipExpired(t task) bool {
+	if p.queueTTL <= 0 || t.enqueued.IsZero() { // 内部任务不经由 submit，enqueued 为零
+		return false
+	}
+	waited := p.clock.Since(t.enqueued)
+	if waited <= p.queueTTL {
+		return false
+	}
+	reason := fmt.Errorf("%w after %s", ErrTaskExpired, waited)
+	p.logf("workerpool: %s\n", reason)
+	if t.claim != nil {
+		return p.discard(t.claim, reason) // 失败时已被丢弃，随后的 start 同样失败
+	}
+	p.reportDiscard(t, reason)
+	return true
+}
@@ -0,0 +1,51 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueueTTL(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var mu sync.Mutex
+	var reasons []error
+	p, release := newBusyPool(t, WithClock(clock), WithQueueSize(5), WithQueueTTL(time.Minute), WithOnDiscard(func(reason error) {
+		mu.Lock()
+		reasons = append(reasons, reason)
+		mu.Unlock()
+	}))
+	defer p.Free()
+	var ran atomic.Int32
+	for range 2 {
+		if err := p.ScheduleWith(func(context.Context) { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.Advance(2 * time.Minute)
+	if err := p.ScheduleWith(func(context.Context) { ran.Add(10) }); err != nil { // 刚提交，未过期
+		t.Fatal(err)
+	}
+	release()
+	p.Wait()
+	if n := ran.Load(); n != 10 {
+		t.Fatalf("ran = %d, want only the fresh task", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reasons) != 2 {
+		t.Fatalf("discarded %d tasks, want 2", len(reasons))
+	}
+	for _, r := range reasons {
+		var te *TaskError
+		if !errors.Is(r, ErrTaskExpired) || !errors.As(r, &te) {
+			t.Fatalf("discard reason = %v", r)
+		}
+	}
+	if c := p.ConfigSnapshot(); c.QueueTTL != time.Minute {
+		t.Fatalf("config queue ttl = %s", c.QueueTTL)
+	}
+}
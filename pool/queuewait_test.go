@@ -0,0 +1,44 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueueWaitStats(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	p, release := newBusyPool(t, WithClock(clock))
+	defer p.Free()
+	done := make(chan error, 1)
+	go func() { done <- p.Schedule(func() {}) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Blocked != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("submitter did not block")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(50 * time.Millisecond)
+	release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	// 占住 worker 的任务立即开始，等待为 0
+	w := p.Stats().QueueWait
+	if w.Count != 2 || w.Max != 50*time.Millisecond || w.Mean() != 25*time.Millisecond {
+		t.Fatalf("QueueWait = %+v", w)
+	}
+}
+
+func TestScheduleTimeoutReportsWait(t *testing.T) {
+	p, release := newBusyPool(t)
+	defer p.Free()
+	defer release()
+	err := p.ScheduleTimeout(func() {}, 20*time.Millisecond)
+	var te *ScheduleTimeoutError
+	if !errors.As(err, &te) || te.Waited < 20*time.Millisecond {
+		t.Fatalf("err = %v, want a *ScheduleTimeoutError with Waited >= 20ms", err)
+	}
+}
@@ -0,0 +1,133 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrCallerQuotaExceeded = errors.New("caller quota exceeded")
+
+// CallerQuotaError 是调用方未执行完的任务已达 WithCallerQuota 的上限时提交返回的错误
+type CallerQuotaError struct {
+	Pool   string // WithName
+	Caller string
+	Limit  int
+}
+
+func (e *CallerQuotaError) Error() string {
+	msg := fmt.Sprintf("caller %q has %d unfinished tasks", e.Caller, e.Limit)
+	if e.Pool != "" {
+		msg = "pool " + e.Pool + ": " + msg
+	}
+	return msg
+}
+
+func (e *CallerQuotaError) Is(target error) bool { return target == ErrCallerQuotaExceeded }
+
+type callerKey struct{}
+
+// ContextWithCaller 返回标记了调用方 caller 的 ctx，以它经由 ScheduleCtx 等提交的任务计入 caller 的 WithCallerQuota 配额
+func ContextWithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom 返回 ContextWithCaller 标记的调用方，没有时为空
+func CallerFrom(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+func WithCaller(caller string) TaskOption { // 任务所属的调用方，计入 caller 的 WithCallerQuota 配额，优先于 ctx 中的调用方
+	return func(t *task) {
+		t.caller = caller
+	}
+}
+
+type callerQuota struct {
+	limit int
+	key   func(context.Context) string
+
+	mu          sync.Mutex
+	outstanding map[string]int // 各调用方已提交尚未执行完的任务数
+}
+
+func WithCallerQuota(limit int, key func(ctx context.Context) string) Option { // 每个调用方最多同时有 limit 个已提交尚未执行完（含排队中）的任务，超出时提交立即返回 *CallerQuotaError（匹配 ErrCallerQuotaExceeded），避免单个组件占满共享的容量；调用方取自 WithCaller，没有时取自提交时的 ctx：key 为 nil 时使用 CallerFrom，调用方为空的任务不受限
+	return func(p *Pool) {
+		if limit <= 0 {
+			p.invalid("WithCallerQuota(%d): limit must be positive", limit)
+			return
+		}
+		if key == nil {
+			key = CallerFrom
+		}
+		p.quota = &callerQuota{limit: limit, key: key, outstanding: make(map[string]int)}
+	}
+}
+
+// callerOf 返回 t 所属的调用方，ctx 为提交时的 ctx，可以为 nil
+func (q *callerQuota) callerOf(ctx context.Context, t *task) string {
+	if t.caller != "" {
+		return t.caller
+	}
+	if ctx == nil {
+		ctx = t.ctx
+	}
+	if ctx == nil {
+		return ""
+	}
+	return q.key(ctx)
+}
+
+// acquireQuota 为 t 的调用方占用一个配额，t 执行完或被丢弃时归还；返回的 release 在提交失败时由调用方调用，可以重复调用
+func (p *Pool) acquireQuota(ctx context.Context, t *task) (release func(), err error) {
+	q := p.quota
+	caller := q.callerOf(ctx, t)
+	if caller == "" {
+		return func() {}, nil
+	}
+	q.mu.Lock()
+	if q.outstanding[caller] >= q.limit {
+		q.mu.Unlock()
+		return nil, &CallerQuotaError{Pool: p.name, Caller: caller, Limit: q.limit}
+	}
+	q.outstanding[caller]++
+	q.mu.Unlock()
+	release = sync.OnceFunc(func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if q.outstanding[caller]--; q.outstanding[caller] <= 0 {
+			delete(q.outstanding, caller)
+		}
+	})
+	inner := *t
+	inner.ctx = nil // 由 t.exec 传递提交方的取消
+	t.fn, t.argFn, t.arg, t.argRun = nil, nil, nil, nil
+	t.fnc = func(ctx context.Context) {
+		defer release()
+		inner.exec(ctx)
+	}
+	if t.claim == nil {
+		t.claim = newTaskClaim()
+	}
+	prev := t.claim.onDiscard
+	t.claim.onDiscard = func(reason error) {
+		release()
+		if prev != nil {
+			prev(reason)
+		}
+	}
+	return release, nil
+}
+
+// CallerOutstanding 返回 caller 已提交尚未执行完的任务数，见 WithCallerQuota
+func (p *Pool) CallerOutstanding(caller string) int {
+	p = p.applied()
+	if p.quota == nil {
+		return 0
+	}
+	p.quota.mu.Lock()
+	defer p.quota.mu.Unlock()
+	return p.quota.outstanding[caller]
+}
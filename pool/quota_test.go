@@ -0,0 +1,67 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCallerQuota(t *testing.T) {
+	p := New(4, WithLogger(nil), WithCallerQuota(2, nil))
+	defer p.Free()
+	gate := make(chan struct{})
+	wait := func(context.Context) { <-gate }
+	for i := 0; i < 2; i++ {
+		if err := p.ScheduleWith(wait, WithCaller("a")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := p.ScheduleWith(wait, WithCaller("a"))
+	var qe *CallerQuotaError
+	if !errors.Is(err, ErrCallerQuotaExceeded) || !errors.As(err, &qe) || qe.Caller != "a" || qe.Limit != 2 {
+		t.Fatalf("third task of a: err = %v", err)
+	}
+	if err := p.ScheduleCtx(ContextWithCaller(context.Background(), "a"), wait); !errors.Is(err, ErrCallerQuotaExceeded) {
+		t.Fatalf("ScheduleCtx for a: err = %v", err)
+	}
+	if err := p.ScheduleCtx(ContextWithCaller(context.Background(), "b"), wait); err != nil {
+		t.Fatalf("other caller: err = %v", err)
+	}
+	if err := p.Schedule(func() {}); err != nil { // 没有调用方的任务不受限
+		t.Fatal(err)
+	}
+	if n := p.CallerOutstanding("a"); n != 2 {
+		t.Fatalf("outstanding a = %d", n)
+	}
+	close(gate)
+	p.Wait()
+	if n := p.CallerOutstanding("a") + p.CallerOutstanding("b"); n != 0 {
+		t.Fatalf("outstanding after Wait = %d", n)
+	}
+	if err := p.ScheduleWith(func(context.Context) { panic("boom") }, WithCaller("a")); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	if n := p.CallerOutstanding("a"); n != 0 {
+		t.Fatalf("outstanding after panic = %d", n)
+	}
+}
+
+func TestCallerQuotaDiscarded(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(4), WithCallerQuota(1, nil))
+	defer p.Free()
+	if err := p.ScheduleWith(func(context.Context) {}, WithCaller("a")); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Purge(); n != 1 {
+		t.Fatalf("Purge = %d", n)
+	}
+	if n := p.CallerOutstanding("a"); n != 0 {
+		t.Fatalf("outstanding after Purge = %d", n)
+	}
+	if err := p.ScheduleWith(func(context.Context) {}, WithCaller("a")); err != nil {
+		t.Fatal(err)
+	}
+	release()
+	p.Wait()
+}
@@ -0,0 +1,5 @@
+//go:build race
+
+package workerpool
+
+const raceEnabled = true // 竞态检测会改变分配行为，依赖分配次数的测试据此跳过
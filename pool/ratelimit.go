@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimit 限制 worker 开始执行任务的速率：相邻两个任务的开始时刻至少相隔 interval，
+// 等待中的 worker 仍占用容量，排队的任务随之在缓冲中等待，不会被拒绝
+type rateLimit struct {
+	mu       sync.Mutex
+	interval time.Duration // 0 表示不限速
+	last     time.Time     // 上一个任务开始的时刻，变更速率后下一个任务按新的间隔计算
+	changed  chan struct{} // 限速变更时关闭并替换，唤醒等待中的 worker 按新的速率重新计算
+}
+
+// SetRateLimit 在运行时把 p 开始执行任务的速率限制为每秒 perSecond 个，用于下游故障期间临时降低处理速度，
+// 无需重新部署或调整容量；已在等待的 worker 立即按新的速率重新计算，perSecond 不为正数时等同于 ClearRateLimit
+func (p *Pool) SetRateLimit(perSecond float64) {
+	p = p.applied()
+	if perSecond <= 0 {
+		p.ClearRateLimit()
+		return
+	}
+	p.rate.set(max(time.Duration(float64(time.Second)/perSecond), 1))
+	p.logf("workerpool: rate limit %g/s\n", perSecond)
+}
+
+// ClearRateLimit 取消 SetRateLimit 设置的限速
+func (p *Pool) ClearRateLimit() {
+	p = p.applied()
+	if p.rate.set(0) {
+		p.logf("workerpool: rate limit cleared\n")
+	}
+}
+
+// RateLimit 返回 SetRateLimit 设置的每秒任务数，未限速时为 0
+func (p *Pool) RateLimit() float64 {
+	p = p.applied()
+	p.rate.mu.Lock()
+	defer p.rate.mu.Unlock()
+	if p.rate.interval == 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(p.rate.interval)
+}
+
+// set 替换 interval，返回之前是否在限速
+func (r *rateLimit) set(interval time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	was := r.interval > 0
+	r.interval = interval
+	if r.changed != nil {
+		close(r.changed)
+	}
+	r.changed = make(chan struct{})
+	return was
+}
+
+// wait 阻塞到当前 worker 可以开始下一个任务或 p 销毁，未限速时立即返回；WithDrainOnFree 排空时同样限速
+func (r *rateLimit) wait(p *Pool) {
+	for {
+		r.mu.Lock()
+		if r.interval == 0 {
+			r.mu.Unlock()
+			return
+		}
+		now := p.clock.Now()
+		next := r.last.Add(r.interval)
+		if !next.After(now) {
+			r.last = now
+			r.mu.Unlock()
+			return
+		}
+		d, changed := next.Sub(now), r.changed
+		r.mu.Unlock()
+		timer := p.clock.NewTimer(d)
+		select {
+		case <-timer.C():
+		case <-changed:
+		case <-p.quit:
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+	}
+}
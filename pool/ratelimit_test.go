@@ -0,0 +1,54 @@
+package workerpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetRateLimit(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	p := New(4, WithClock(clk), WithLogger(nil))
+	defer p.Free()
+	p.SetRateLimit(1)
+	if r := p.RateLimit(); r != 1 {
+		t.Fatalf("RateLimit = %g, want 1", r)
+	}
+	var ran atomic.Int32
+	for range 3 {
+		if err := p.Schedule(func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitRan := func(want int32) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for ran.Load() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("ran = %d, want %d", ran.Load(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitRan(1)
+	time.Sleep(10 * time.Millisecond)
+	if n := ran.Load(); n != 1 {
+		t.Fatalf("ran = %d before the next second, want 1", n)
+	}
+	clk.Advance(time.Second)
+	waitRan(2)
+	// 提高速率后等待中的 worker 按新的间隔重新计算
+	p.SetRateLimit(1000)
+	clk.Advance(time.Millisecond)
+	waitRan(3)
+
+	p.ClearRateLimit()
+	if r := p.RateLimit(); r != 0 {
+		t.Fatalf("RateLimit after clear = %g, want 0", r)
+	}
+	for range 4 {
+		p.Schedule(func() { ran.Add(1) })
+	}
+	waitRan(7)
+	p.Wait()
+}
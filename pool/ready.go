@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// readyCounter 统计初始化成功、尚未退出的 worker，供 WaitReady 等待
+type readyCounter struct {
+	mu      sync.Mutex
+	n       int
+	changed chan struct{} // n 每次变化时关闭并替换
+}
+
+func (c *readyCounter) add(d int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n += d
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+// get 返回当前的数量以及它下一次变化时关闭的 channel
+func (c *readyCounter) get() (int, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.changed == nil {
+		c.changed = make(chan struct{})
+	}
+	return c.n, c.changed
+}
+
+// WaitReady 阻塞直到至少 n 个 worker 已经启动（WithWorkerInit 的钩子已成功返回），
+// 配合 WithPreAllocWorkers 使用，可以在 pool 真正能够处理任务后再报告服务就绪；
+// ctx 取消时返回 ctx.Err()，pool 销毁时返回 ErrPoolClosed，n 超出容量时立即返回错误
+func (p *Pool) WaitReady(ctx context.Context, n int) error {
+	p = p.applied()
+	if n > p.capacity {
+		return fmt.Errorf("workerpool: WaitReady(%d) exceeds the capacity %d", n, p.capacity)
+	}
+	for {
+		ready, changed := p.readyWorkers.get()
+		if ready >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-p.closing:
+			return p.freedErr()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitReady(t *testing.T) {
+	gate := make(chan struct{})
+	var inits atomic.Int32
+	p := New(3, WithLogger(nil), WithPreAllocWorkers(true), WithWorkerInit(func(int) (any, error) {
+		if inits.Add(1) > 1 {
+			<-gate // 只有第一个 worker 能立即完成初始化
+		}
+		return nil, nil
+	}))
+	defer p.Free()
+	if err := p.WaitReady(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.WaitReady(ctx, 3); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want DeadlineExceeded while inits are blocked", err)
+	}
+	close(gate)
+	if err := p.WaitReady(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitReady(context.Background(), 4); err == nil {
+		t.Fatal("WaitReady beyond capacity should fail")
+	}
+}
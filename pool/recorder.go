@@ -0,0 +1,89 @@
+package workerpool
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// eventRecorder 是保存最近 size 个生命周期事件的环形缓冲，见 WithEventRecorder
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+	next   int  // 下一个事件写入的位置
+	full   bool // 已写满一轮，next 处是最早的事件
+}
+
+func WithEventRecorder(size int) Option { // 在内存中保留最近 size 个任务生命周期事件，供事后由 RecentEvents、DumpEvents 还原 pool 的行为；0 表示关闭
+	return func(p *Pool) {
+		if size <= 0 {
+			return
+		}
+		r := &eventRecorder{events: make([]Event, size)}
+		p.recorder = r
+		p.observers = append(p.observers, r.record)
+	}
+}
+
+func (r *eventRecorder) record(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[r.next] = e
+	r.next++
+	if r.next == len(r.events) {
+		r.next, r.full = 0, true
+	}
+}
+
+// snapshot 按发生顺序返回 since 及之后的事件
+func (r *eventRecorder) snapshot(since time.Time) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []Event
+	if r.full {
+		events = append(events, r.events[r.next:]...)
+	}
+	events = append(events, r.events[:r.next]...)
+	i := 0
+	for i < len(events) && events[i].Time.Before(since) {
+		i++
+	}
+	return events[i:]
+}
+
+// RecentEvents 按发生顺序返回最近 window 内记录的事件，window 为 0 时返回全部；未设置 WithEventRecorder 时返回 nil
+func (p *Pool) RecentEvents(window time.Duration) []Event {
+	p = p.applied()
+	if p.recorder == nil {
+		return nil
+	}
+	var since time.Time
+	if window > 0 {
+		since = p.clock.Now().Add(-window)
+	}
+	return p.recorder.snapshot(since)
+}
+
+// DumpEvents 将 RecentEvents(window) 逐行写入 w，用于事故后还原 pool 在最后一段时间内做了什么
+func (p *Pool) DumpEvents(w io.Writer, window time.Duration) error {
+	for _, e := range p.RecentEvents(window) {
+		line := fmt.Sprintf("%s %-9s task[%d]", e.Time.Format(time.RFC3339Nano), e.Kind, e.TaskID)
+		if e.Info != nil && e.Info.Name != "" {
+			line += " " + e.Info.Name
+		}
+		if e.Worker != 0 {
+			line += fmt.Sprintf(" worker[%03d]", e.Worker)
+		}
+		if e.Duration != 0 {
+			line += " " + e.Duration.String()
+		}
+		if e.Err != nil {
+			line += " error[" + e.Err.Error() + "]"
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventRecorderKeepsLatest(t *testing.T) {
+	p := New(1, WithLogger(nil), WithEventRecorder(4))
+	defer p.Free()
+	for i := 0; i < 3; i++ {
+		if err := p.ScheduleWith(func(ctx context.Context) {}, WithTaskName("t")); err != nil {
+			t.Fatal(err)
+		}
+		p.Wait()
+	}
+	events := p.RecentEvents(0)
+	if len(events) != 4 {
+		t.Fatalf("len(events) = %d, want 4", len(events))
+	}
+	// Wait 返回时之前任务的事件都已记录，留下的是第 2 个任务的 finished 与第 3 个任务的全部事件
+	if e := events[0]; e.Kind != EventFinished || e.Info.ID != 2 {
+		t.Fatalf("oldest event = %+v", e)
+	}
+	kinds := map[EventKind]bool{}
+	for _, e := range events[1:] {
+		if e.Info.ID != 3 {
+			t.Fatalf("event = %+v, want task 3", e)
+		}
+		kinds[e.Kind] = true
+	}
+	if !kinds[EventSubmitted] || !kinds[EventStarted] || !kinds[EventFinished] {
+		t.Fatalf("events of task 3 = %+v", events[1:])
+	}
+}
+
+func TestEventRecorderWindow(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	p := New(1, WithLogger(nil), WithClock(clock), WithEventRecorder(16))
+	defer p.Free()
+	if err := p.ScheduleWith(func(ctx context.Context) {}, WithTaskName("old")); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	clock.Advance(time.Minute)
+	if err := p.ScheduleWith(func(ctx context.Context) {}, WithTaskName("new")); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+
+	if n := len(p.RecentEvents(0)); n != 6 {
+		t.Fatalf("len(RecentEvents(0)) = %d, want 6", n)
+	}
+	var buf bytes.Buffer
+	if err := p.DumpEvents(&buf, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Count(out, "\n") != 3 || strings.Contains(out, "old") || !strings.Contains(out, "finished  task[2] new worker[") {
+		t.Fatalf("DumpEvents =\n%s", out)
+	}
+}
+
+func TestEventRecorderDisabled(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	if events := p.RecentEvents(0); events != nil {
+		t.Fatalf("RecentEvents = %v, want nil", events)
+	}
+}
@@ -0,0 +1,66 @@
+package workerpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventually 等待 cond 成立，超过 5s 时使测试失败
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMaxTasksPerWorker(t *testing.T) {
+	var inits atomic.Int32
+	p := New(1, WithMaxTasksPerWorker(2), WithLogger(nil),
+		WithWorkerInit(func(int) (any, error) { inits.Add(1); return nil, nil }))
+	defer p.Free()
+	var ran atomic.Int32
+	for range 6 {
+		if err := p.Schedule(func() { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+	if ran.Load() != 6 {
+		t.Fatalf("ran %d of 6 tasks", ran.Load())
+	}
+	eventually(t, "replacement workers", func() bool { return inits.Load() >= 3 })
+	if n := inits.Load(); n > 4 { // 第三个 worker 退役后可能已经预先创建了替换者
+		t.Fatalf("%d workers created for 6 tasks with 2 tasks per worker", n)
+	}
+}
+
+func TestMaxWorkerAge(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	var inits, teardowns atomic.Int32
+	p := New(1, WithClock(c), WithMaxWorkerAge(time.Minute), WithLogger(nil),
+		WithWorkerInit(func(int) (any, error) { inits.Add(1); return nil, nil }),
+		WithWorkerTeardown(func(int, any) { teardowns.Add(1) }))
+	defer p.Free()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	p.Schedule(func() { close(started); <-release })
+	<-started
+	c.Advance(2 * time.Minute) // 执行中的任务不受影响
+	if teardowns.Load() != 0 {
+		t.Fatal("worker retired while running a task")
+	}
+	close(release)
+	eventually(t, "retirement after the task", func() bool { return teardowns.Load() == 1 })
+
+	done := make(chan struct{})
+	p.Schedule(func() { close(done) })
+	<-done
+	if inits.Load() < 2 {
+		t.Fatalf("inits = %d, retired worker was reused", inits.Load())
+	}
+}
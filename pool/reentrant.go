@@ -0,0 +1,100 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var ErrReentrantSchedule = errors.New("schedule from a task of the same saturated pool")
+
+// ReentrantPolicy 决定在 pool 已满且 block 为 true 时，本 pool 的任务内再次提交任务如何处理：
+// 所有 worker 都在等待自己提交的任务被执行时，阻塞等待会永远死锁
+type ReentrantPolicy int
+
+const (
+	ReentrantBlock  ReentrantPolicy = iota // 与其它调用方一样阻塞等待（默认）
+	ReentrantInline                        // 在当前 worker 上直接执行，执行完后返回
+	ReentrantSpawn                         // 在一个临时的额外 goroutine 上执行，不占用容量
+	ReentrantError                         // 返回 ErrReentrantSchedule
+)
+
+// workerSet 按 goroutine id 记录本 pool 的 worker，用于识别来自任务内部的提交
+type workerSet struct {
+	mu sync.Mutex
+	m  map[int64]*worker
+}
+
+func (s *workerSet) add(gid int64, w *worker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[int64]*worker)
+	}
+	s.m[gid] = w
+}
+
+func (s *workerSet) remove(gid int64) {
+	s.mu.Lock()
+	delete(s.m, gid)
+	s.mu.Unlock()
+}
+
+func (s *workerSet) get(gid int64) *worker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[gid]
+}
+
+// goid 从 runtime.Stack 的首行 "goroutine 123 [running]:" 中解析当前 goroutine 的 id
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
+
+// handleReentrant 在提交需要阻塞等待时调用：调用方是本 pool 的 worker 时按 p.reentrant 处理 t，
+// handled 为 true 表示 t 已处理（或以 err 拒绝），否则照常阻塞等待
+func (p *Pool) handleReentrant(t task) (handled bool, err error) {
+	if p.reentrant == ReentrantBlock {
+		return false, nil
+	}
+	w := p.workers.get(goid())
+	if w == nil {
+		return false, nil
+	}
+	switch p.reentrant {
+	case ReentrantInline:
+		p.runNested(w, t)
+	case ReentrantSpawn:
+		// 当前 worker 计入 p.wg 且尚未结束，此时 Add 不会与 Free 中的 Wait 竞争
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.setLabels(roleHelper)
+			p.runNested(&worker{id: w.id, ctx: context.WithValue(p.ctx, workerKey{}, w)}, t)
+		}()
+	case ReentrantError:
+		return true, ErrReentrantSchedule
+	}
+	return true, nil
+}
+
+// runNested 执行内联或临时 goroutine 上的任务，panic 不影响发起提交的任务
+func (p *Pool) runNested(w *worker, t task) {
+	defer func() {
+		if err := recover(); err != nil {
+			p.logf("worker[%03d]: recover panic[%s] in nested task\n", w.id, err)
+		}
+	}()
+	// 内联时 w 仍在执行发起提交的任务：不能因预算而放弃它，也不覆盖它正在执行的任务
+	p.runTask(&worker{id: w.id, value: w.value, ready: w.ready, ctx: w.ctx}, t)
+}
@@ -0,0 +1,32 @@
+package workerpool
+
+import (
+	"testing"
+)
+
+func workerCount(p *Pool) int {
+	p.workers.mu.Lock()
+	defer p.workers.mu.Unlock()
+	return len(p.workers.m)
+}
+
+func TestWorkersRegisteredOnlyWhenNeeded(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	inside := make(chan int, 1)
+	p.Schedule(func() { inside <- workerCount(p) })
+	if n := <-inside; n != 0 {
+		t.Fatalf("default pool registered %d workers", n)
+	}
+	p.Free()
+
+	p = New(1, WithLogger(nil), WithReentrantPolicy(ReentrantInline))
+	defer p.Free()
+	done := make(chan int, 1)
+	p.Schedule(func() {
+		// 唯一的 worker 正在执行本任务，内部的提交须在当前 worker 上内联执行，否则永远阻塞
+		p.Schedule(func() { done <- workerCount(p) })
+	})
+	if n := <-done; n != 1 {
+		t.Fatalf("reentrant pool registered %d workers, want 1", n)
+	}
+}
@@ -0,0 +1,76 @@
+package workerpool
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// GlobalStats 是所有以 WithGlobalStats 登记的 pool 的汇总状态
+type GlobalStats struct {
+	Pools    int                  // pool 数量
+	Workers  int                  // 存活的 worker 总数
+	Busy     int                  // 正在执行任务的 worker 总数
+	Inflight int                  // 已提交尚未执行完的任务总数
+	Queued   int                  // 排队中的任务总数
+	PerPool  map[string]PoolStats // 按名称（WithName，未设置时为 ID；重名时加上 "#ID"）分列
+}
+
+// globalRegistry 是进程内所有以 WithGlobalStats 登记、尚未销毁的 pool
+var globalRegistry struct {
+	mu    sync.Mutex
+	pools map[*Pool]struct{}
+}
+
+func WithGlobalStats() Option { // 将 pool 登记到进程级的统计中，由 ReadGlobalStats 一并读取，Free 时自动注销
+	return func(p *Pool) {
+		p.global = true
+	}
+}
+
+func (p *Pool) registerGlobal() {
+	r := &globalRegistry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pools == nil {
+		r.pools = make(map[*Pool]struct{})
+	}
+	r.pools[p] = struct{}{}
+}
+
+func (p *Pool) unregisterGlobal() {
+	r := &globalRegistry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pools, p)
+}
+
+// ReadGlobalStats 汇总所有以 WithGlobalStats 登记的 pool 的状态，供单个导出器覆盖进程内的所有 pool
+func ReadGlobalStats() GlobalStats {
+	r := &globalRegistry
+	r.mu.Lock()
+	pools := make([]*Pool, 0, len(r.pools))
+	for p := range r.pools {
+		pools = append(pools, p)
+	}
+	r.mu.Unlock()
+	sort.Slice(pools, func(i, j int) bool { return pools[i].id < pools[j].id })
+
+	g := GlobalStats{Pools: len(pools), PerPool: make(map[string]PoolStats, len(pools))}
+	for _, p := range pools {
+		s := p.Stats()
+		g.Workers += s.Workers
+		g.Busy += s.Busy
+		g.Inflight += s.Inflight
+		g.Queued += s.Queued
+		key := p.name
+		if key == "" {
+			key = strconv.FormatUint(p.id, 10)
+		}
+		if _, dup := g.PerPool[key]; dup {
+			key += "#" + strconv.FormatUint(p.id, 10)
+		}
+		g.PerPool[key] = s
+	}
+	return g
+}
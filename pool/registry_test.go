@@ -0,0 +1,22 @@
+package workerpool
+
+import "testing"
+
+func TestGlobalStats(t *testing.T) {
+	a := New(2, WithLogger(nil), WithName("a"), WithGlobalStats(), WithPreAllocWorkers(true))
+	b := New(3, WithLogger(nil), WithName("a"), WithGlobalStats(), WithPreAllocWorkers(true))
+	c := New(4, WithLogger(nil), WithPreAllocWorkers(true)) // 未登记
+	defer c.Free()
+	g := ReadGlobalStats()
+	if g.Pools != 2 || g.Workers != 5 || len(g.PerPool) != 2 {
+		t.Fatalf("stats = %+v, want 2 pools with 5 workers", g)
+	}
+	if g.PerPool["a"].Capacity != 2 {
+		t.Fatalf("per-pool stats = %+v", g.PerPool)
+	}
+	a.Free()
+	b.Free()
+	if g := ReadGlobalStats(); g.Pools != 0 {
+		t.Fatalf("%d pools still registered after Free", g.Pools)
+	}
+}
@@ -0,0 +1,129 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// FinalReport 是 pool 整个生命周期的汇总，Free 完成时交给 WithFinalReport 的回调，供批处理任务在结束时记录可信的运行摘要
+type FinalReport struct {
+	Name      string
+	Uptime    time.Duration // 从创建到 Free 完成
+	Cause     error         // FreeWithCause 的原因，Free 时为 nil
+	Submitted uint64        // 被接受的提交
+	Rejected  uint64        // 失败的提交
+	Completed uint64        // 执行完的任务，含失败与 panic 的任务
+	Failed    uint64        // 由 MarkFailed 计为失败的任务
+	Panicked  uint64        // 执行中 panic 的任务
+	Discarded uint64        // 被接受后未执行即被丢弃的任务
+	MaxQueued int           // 排队的任务数的峰值
+	QueueWait LatencyStats  // 从提交到开始执行的等待时间，ApplyOptions 与 Resize 之后只含替换后的 pool
+	ExecTime  LatencyStats  // 执行时间，同 QueueWait
+}
+
+func (r FinalReport) String() string {
+	name := r.Name
+	if name == "" {
+		name = "workerpool"
+	}
+	return fmt.Sprintf("%s: uptime %s, %d submitted, %d rejected, %d completed (%d failed, %d panicked), %d discarded, max queued %d, p95 wait %s, p95 exec %s",
+		name, r.Uptime.Round(time.Millisecond), r.Submitted, r.Rejected, r.Completed, r.Failed, r.Panicked, r.Discarded,
+		r.MaxQueued, r.QueueWait.P95, r.ExecTime.P95)
+}
+
+type finalReport struct {
+	fn      func(FinalReport)
+	started time.Time    // launch 的时刻
+	carried atomic.Int64 // 被替换的 pool 的运行时长，见 carry
+
+	submitted, rejected, completed, failed, panicked, discarded atomic.Uint64
+	maxQueued                                                   atomic.Int64
+}
+
+func WithFinalReport(fn func(FinalReport)) Option { // 统计 pool 整个生命周期的任务数、排队峰值与耗时，Free 完成时交给 fn，见 FinalReport；fn 为 nil 时以日志输出
+	return func(p *Pool) {
+		r := &finalReport{fn: fn}
+		p.report = r
+		p.observers = append(p.observers, func(e Event) {
+			switch e.Kind {
+			case EventSubmitted:
+				r.submitted.Add(1)
+				p.queued.mu.Lock()
+				n := int64(len(p.queued.m))
+				p.queued.mu.Unlock()
+				r.queuedPeak(n)
+			case EventRejected:
+				r.rejected.Add(1)
+			case EventFinished:
+				r.completed.Add(1)
+			case EventFailed:
+				r.completed.Add(1)
+				r.panicked.Add(1)
+			case EventDiscarded:
+				r.discarded.Add(1)
+			}
+		})
+	}
+}
+
+// recordFailed 在任务结束时记录 MarkFailed；WithErrorRateGuard 之后清除 w.failed，未设置时由这里清除
+func (p *Pool) recordFailed(w *worker) {
+	if w.failed {
+		p.report.failed.Add(1)
+	}
+	if p.guard == nil {
+		w.failed = false
+	}
+}
+
+// finishReport 在 Free 的最后汇总并交出 FinalReport，此时所有任务均已结束或被丢弃；
+// p 被 ApplyOptions 或 Resize 替换时不交出，计数转入接替的 pool，在它 Free 时一并交出
+func (p *Pool) finishReport() {
+	r := p.report
+	if q := p.successor.Load(); q != nil && q.report != nil && errors.Is(p.freeCause, ErrPoolReplaced) {
+		q.report.carry(r, q.report.started.Sub(r.started)) // 接替的 pool 启动后两者的运行时间重叠
+		return
+	}
+	rep := FinalReport{
+		Name:      p.name,
+		Uptime:    time.Duration(r.carried.Load()) + p.clock.Since(r.started),
+		Cause:     p.freeCause,
+		Submitted: r.submitted.Load(),
+		Rejected:  r.rejected.Load(),
+		Completed: r.completed.Load(),
+		Failed:    r.failed.Load(),
+		Panicked:  r.panicked.Load(),
+		Discarded: r.discarded.Load(),
+		MaxQueued: int(r.maxQueued.Load()),
+		QueueWait: p.queueWait.get(),
+		ExecTime:  p.execTime.get(),
+	}
+	if r.fn == nil {
+		p.logf("workerpool final report: %s\n", rep)
+		return
+	}
+	r.fn(rep)
+}
+
+// carry 把被替换的 pool 的计数与接替之前的运行时长并入 r
+func (r *finalReport) carry(from *finalReport, uptime time.Duration) {
+	r.carried.Add(from.carried.Load() + int64(uptime))
+	r.submitted.Add(from.submitted.Load())
+	r.rejected.Add(from.rejected.Load())
+	r.completed.Add(from.completed.Load())
+	r.failed.Add(from.failed.Load())
+	r.panicked.Add(from.panicked.Load())
+	r.discarded.Add(from.discarded.Load())
+	r.queuedPeak(from.maxQueued.Load())
+}
+
+func (r *finalReport) queuedPeak(n int64) {
+	for {
+		m := r.maxQueued.Load()
+		if n <= m || r.maxQueued.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
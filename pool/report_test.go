@@ -0,0 +1,66 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFinalReport(t *testing.T) {
+	var reports []FinalReport
+	p := New(1, WithLogger(nil), WithBlock(false), WithName("batch"), WithFinalReport(func(r FinalReport) { reports = append(reports, r) }))
+	gate := make(chan struct{})
+	if err := p.Schedule(func() { <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Schedule(func() {}); err == nil {
+		t.Fatal("second task accepted by a full non-blocking pool")
+	}
+	close(gate)
+	p.Wait()
+	p.ScheduleFunc(func(ctx context.Context) { MarkFailed(ctx) })
+	p.Wait()
+	p.Schedule(func() { panic("boom") })
+	p.Wait()
+	if err := p.Resize(2); err != nil { // 替换时不交出，计数转入新的 pool
+		t.Fatal(err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("report on Resize: %v", reports)
+	}
+	p.Schedule(func() {})
+	p.Wait()
+	cause := errors.New("batch done")
+	p.FreeWithCause(cause)
+	if len(reports) != 1 {
+		t.Fatalf("%d reports after Free", len(reports))
+	}
+	r := reports[0]
+	if r.Name != "batch" || r.Cause != cause || r.Uptime <= 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.Submitted != 4 || r.Rejected != 1 || r.Completed != 4 || r.Failed != 1 || r.Panicked != 1 || r.Discarded != 0 {
+		t.Fatalf("counts = %s", r)
+	}
+	if r.ExecTime.Count == 0 {
+		t.Fatalf("exec time = %+v", r.ExecTime)
+	}
+}
+
+func TestFinalReportQueued(t *testing.T) {
+	var r FinalReport
+	p, release := newBusyPool(t, WithQueueSize(5), WithFinalReport(func(rep FinalReport) { r = rep }))
+	for range 3 {
+		if err := p.ScheduleWith(func(context.Context) {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := p.Purge(); n != 3 {
+		t.Fatalf("purged %d", n)
+	}
+	release()
+	p.Free()
+	if r.MaxQueued != 3 || r.Discarded != 3 || r.Submitted != 4 || r.Completed != 1 {
+		t.Fatalf("report = %s", r)
+	}
+}
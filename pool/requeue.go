@@ -0,0 +1,151 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrNotRequeueable = errors.New("task cannot requeue itself")
+
+// RequeueOption 是 RequeueSelf 的选项
+type RequeueOption func(*requeueRequest)
+
+type requeueRequest struct {
+	after      time.Duration
+	payload    any
+	hasPayload bool
+	attempt    int // 0 表示本次加一
+}
+
+func RequeueAfter(d time.Duration) RequeueOption { // 本次执行返回 d 之后再执行，默认立即重新排队
+	return func(r *requeueRequest) {
+		r.after = d
+	}
+}
+
+func RequeuePayload(payload any) RequeueOption { // 下一次执行的负载，须为 ScheduleSelf 的 T，Job 处理函数中须为 []byte；默认沿用本次的负载
+	return func(r *requeueRequest) {
+		r.payload, r.hasPayload = payload, true
+	}
+}
+
+func RequeueAttempt(n int) RequeueOption { // 下一次执行是第几次尝试（TaskInfo.Attempt 或 JobAttempt），默认为本次加一
+	return func(r *requeueRequest) {
+		r.attempt = n
+	}
+}
+
+type requeueKey struct{}
+
+// selfHandle 是注入到 ScheduleSelf 任务与 Job 处理函数 ctx 中的句柄，记录本次执行中的 RequeueSelf
+type selfHandle struct {
+	p      *Pool
+	accept func(payload any) bool // 负载的类型是否相符
+
+	mu  sync.Mutex
+	req *requeueRequest
+}
+
+// RequeueSelf 请求在当前任务返回后以 opts 再执行一次，多次调用时以最后一次为准；
+// 用于轮询与分批推进的任务，不必借助外部的协调或在闭包中递归提交。当前任务 panic 或 Job 处理函数返回错误时不重新排队
+// ctx 不是 ScheduleSelf 的任务或 Job 处理函数收到的 ctx 时返回 ErrNotRequeueable，负载类型不符时返回错误，pool 正在销毁时返回 *PoolClosedError
+func RequeueSelf(ctx context.Context, opts ...RequeueOption) error {
+	h, ok := ctx.Value(requeueKey{}).(*selfHandle)
+	if !ok {
+		return ErrNotRequeueable
+	}
+	r := &requeueRequest{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.hasPayload && !h.accept(r.payload) {
+		return fmt.Errorf("requeue payload %T does not match the payload type of the task", r.payload)
+	}
+	select {
+	case <-h.p.closing:
+		return h.p.freedErr()
+	default:
+	}
+	h.mu.Lock()
+	h.req = r
+	h.mu.Unlock()
+	return nil
+}
+
+// take 返回本次执行中最后一次 RequeueSelf 的请求，没有时为 nil
+func (h *selfHandle) take() *requeueRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.req
+}
+
+// ScheduleSelf 以 opts 提交 fn(ctx, payload)，其余与 ScheduleWith 相同；fn 可以调用 RequeueSelf 在返回后以新的负载再次执行
+// 重新排队的执行由 pool 异步投递，与 ScheduleAfter 一样不计入 Wait，出现在 Pending 中（PendingRequeue）；
+// 它以新的 TaskInfo（新的 ID 与提交时刻，Attempt 见 RequeueAttempt）重新应用 opts，投递失败时记录日志
+func ScheduleSelf[T any](p *Pool, fn func(ctx context.Context, payload T), payload T, opts ...TaskOption) error {
+	p = p.applied()
+	return p.ScheduleWith(selfTask(p, fn, payload, slices.Clip(opts)), opts...)
+}
+
+func selfTask[T any](p *Pool, fn func(context.Context, T), payload T, opts []TaskOption) TaskFunc {
+	return func(ctx context.Context) {
+		h := &selfHandle{p: p, accept: accepts[T]}
+		fn(context.WithValue(ctx, requeueKey{}, h), payload)
+		r := h.take()
+		if r == nil {
+			return
+		}
+		if r.hasPayload {
+			payload, _ = r.payload.(T) // nil 只在 T 为接口时被接受
+		}
+		attempt := r.attempt
+		if attempt <= 0 {
+			info, _ := TaskInfoFrom(ctx)
+			attempt = info.Attempt + 1
+		}
+		next := p.taskWith(selfTask(p, fn, payload, opts), append(opts, WithAttempt(attempt)))
+		p.requeue(next, r.after, next.info.String())
+	}
+}
+
+// accepts 返回 v 能否作为 T 类型的负载
+func accepts[T any](v any) bool {
+	if _, ok := v.(T); ok {
+		return true
+	}
+	var zero T
+	return v == nil && any(zero) == nil
+}
+
+// requeueJob 在 Job 处理函数调用 RequeueSelf 后重新 Enqueue j；Key 不再保留，否则会被 WithIdempotency 视为重复投递
+// Attempt 由 Queue 在 Pop 时加一，不保存 Attempt 的后端从头计数；延迟期间进程退出时请求丢失
+func (p *Pool) requeueJob(j Job, r *requeueRequest) {
+	next := Job{Type: j.Type, Payload: j.Payload, Attempt: j.Attempt}
+	if r.hasPayload {
+		next.Payload, _ = r.payload.([]byte)
+	}
+	if r.attempt > 0 {
+		next.Attempt = r.attempt - 1
+	}
+	enqueue := func() {
+		if err := p.Enqueue(next); err != nil {
+			p.logf("workerpool: requeue job %s: %v\n", next.Type, err)
+		}
+	}
+	if r.after <= 0 {
+		enqueue()
+		return
+	}
+	p.requeue(task{fn: enqueue}, r.after, "job "+next.Type)
+}
+
+// requeue 在 d 之后异步投递 t，what 用于日志
+func (p *Pool) requeue(t task, d time.Duration, what string) {
+	if _, err := p.addTimer(&timerEntry{p: p, kind: PendingRequeue, t: t}, d); err != nil {
+		p.logf("workerpool: requeue %s: %v\n", what, err)
+	}
+}
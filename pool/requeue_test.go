@@ -0,0 +1,102 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestScheduleSelf(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	type run struct {
+		n, attempt int
+	}
+	runs := make(chan run, 8)
+	err := ScheduleSelf(p, func(ctx context.Context, n int) {
+		info, _ := TaskInfoFrom(ctx)
+		runs <- run{n, info.Attempt}
+		if n < 3 {
+			if err := RequeueSelf(ctx, RequeuePayload(n+1), RequeueAfter(time.Millisecond)); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := RequeueSelf(ctx, RequeuePayload("x")); err == nil {
+			t.Error("mismatched payload accepted")
+		}
+	}, 1, WithTaskName("poll"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for want := 1; want <= 3; want++ {
+		select {
+		case r := <-runs:
+			if r.n != want || r.attempt != want {
+				t.Fatalf("run = %+v, want payload and attempt %d", r, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("run %d not requeued", want)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(runs) != 0 || len(p.Pending()) != 0 {
+		t.Fatalf("requeued after the last run: %d runs, %d pending", len(runs), len(p.Pending()))
+	}
+	if err := RequeueSelf(context.Background()); !errors.Is(err, ErrNotRequeueable) {
+		t.Fatalf("outside a task: err = %v", err)
+	}
+}
+
+func TestRequeueSelfJob(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	done := make(chan string, 4)
+	p.Register("page", func(ctx context.Context, payload []byte) error {
+		page, _ := strconv.Atoi(string(payload))
+		done <- string(payload) + "@" + strconv.Itoa(JobAttempt(ctx))
+		if page < 2 {
+			return RequeueSelf(ctx, RequeuePayload([]byte(strconv.Itoa(page+1))))
+		}
+		return nil
+	})
+	if err := p.Enqueue(Job{Type: "page", Payload: []byte("0")}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"0@1", "1@2", "2@3"} {
+		select {
+		case got := <-done:
+			if got != want {
+				t.Fatalf("got %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s not requeued", want)
+		}
+	}
+}
+
+func TestRequeueSelfJobFailed(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	runs := make(chan struct{}, 4)
+	p.Register("poll", func(ctx context.Context, payload []byte) error {
+		runs <- struct{}{}
+		if err := RequeueSelf(ctx); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+	if err := p.Enqueue(Job{Type: "poll"}); err != nil {
+		t.Fatal(err)
+	}
+	<-runs
+	select {
+	case <-runs:
+		t.Fatal("failed job requeued itself")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if s := p.JobTypes()[0]; s.Failed != 1 || s.Succeeded != 0 {
+		t.Fatalf("job stats = %+v", s)
+	}
+}
@@ -0,0 +1,129 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+var ErrResultGroupClosed = errors.New("result group closed")
+
+// ResultGroup 收集一组带返回值任务的结果，调用方通过 Results 按完成顺序逐个取出，
+// 或用 Collect 一次取得按提交顺序排列、与输入位置对应的结果：
+//
+//	g := NewResultGroup[int](p)
+//	for _, v := range items {
+//		g.Go(...)
+//	}
+//	g.Close()
+//	for r, err := range g.Results() {
+//		...
+//	}
+type ResultGroup[R any] struct {
+	p *Pool
+
+	mu      sync.Mutex
+	cond    sync.Cond
+	queue   []groupResult[R] // 已完成但尚未被取走的结果
+	running int              // 已提交但尚未完成的任务数
+	closed  bool
+
+	next     int              // 下一次 Go 的提交序号
+	rejected []groupResult[R] // 提交失败的 Go，只在 Collect 中占位
+}
+
+type groupResult[R any] struct {
+	index int // Go 的提交序号，从 0 开始
+	val   R
+	err   error
+}
+
+func NewResultGroup[R any](p *Pool) *ResultGroup[R] {
+	g := &ResultGroup[R]{p: p}
+	g.cond.L = &g.mu
+	return g
+}
+
+// Go 将 fn 提交到 pool 执行，其结果稍后由 Results 交付；fn panic 时结果的错误为 *PanicError
+// 结果在取走之前暂存在 group 中，执行 fn 的 worker 不会因调用方消费慢而阻塞
+// 每次调用（包括提交失败的）按调用顺序得到一个序号，即结果在 Collect 中的位置
+func (g *ResultGroup[R]) Go(fn func(ctx context.Context) (R, error)) error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return ErrResultGroupClosed
+	}
+	g.running++
+	index := g.next
+	g.next++
+	g.mu.Unlock()
+	err := g.p.scheduleOr(task{fnc: func(ctx context.Context) {
+		v, err := protect(ctx, fn)
+		g.done(groupResult[R]{index: index, val: v, err: err})
+	}}, func(reason error) { g.done(groupResult[R]{index: index, err: reason}) })
+	if err != nil {
+		g.mu.Lock()
+		g.running--
+		g.rejected = append(g.rejected, groupResult[R]{index: index, err: err})
+		g.cond.Broadcast()
+		g.mu.Unlock()
+	}
+	return err
+}
+
+// Close 表示不再提交新的任务，Results 在交付完剩余结果后结束
+func (g *ResultGroup[R]) Close() {
+	g.mu.Lock()
+	g.closed = true
+	g.cond.Broadcast()
+	g.mu.Unlock()
+}
+
+// Results 按完成顺序交付结果，阻塞等待尚未完成的任务，Close 之后全部交付完毕时结束；
+// 提前 break 时剩余结果保留在 group 中，可以再次调用 Results 继续取出
+func (g *ResultGroup[R]) Results() iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		for {
+			g.mu.Lock()
+			for len(g.queue) == 0 && !(g.closed && g.running == 0) {
+				g.cond.Wait()
+			}
+			if len(g.queue) == 0 {
+				g.mu.Unlock()
+				return
+			}
+			r := g.queue[0]
+			g.queue[0] = groupResult[R]{}
+			g.queue = g.queue[1:]
+			g.mu.Unlock()
+			if !yield(r.val, r.err) {
+				return
+			}
+		}
+	}
+}
+
+func (g *ResultGroup[R]) done(r groupResult[R]) {
+	g.mu.Lock()
+	g.queue = append(g.queue, r)
+	g.running--
+	g.cond.Broadcast()
+	g.mu.Unlock()
+}
+
+// Collect 阻塞到 Close 之后所有任务完成，按提交顺序返回结果：vals[i] 与 errs[i] 对应第 i 次 Go，
+// 提交失败的位置为其提交错误；已经由 Results 取走的结果不再交付，对应位置为零值
+func (g *ResultGroup[R]) Collect() (vals []R, errs []error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for !(g.closed && g.running == 0) {
+		g.cond.Wait()
+	}
+	vals, errs = make([]R, g.next), make([]error, g.next)
+	for _, r := range append(g.queue, g.rejected...) {
+		vals[r.index], errs[r.index] = r.val, r.err
+	}
+	g.queue, g.rejected = nil, nil
+	return vals, errs
+}
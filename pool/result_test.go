@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResultGroupCollect(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	g := NewResultGroup[int](p)
+	failed := errors.New("failed")
+	for i := 0; i < 8; i++ {
+		if err := g.Go(func(context.Context) (int, error) {
+			time.Sleep(time.Duration(8-i) * time.Millisecond) // 先提交的后完成
+			if i == 5 {
+				return 0, failed
+			}
+			return i * 10, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Close()
+	vals, errs := g.Collect()
+	if len(vals) != 8 || len(errs) != 8 {
+		t.Fatalf("got %d values, %d errors", len(vals), len(errs))
+	}
+	for i, v := range vals {
+		switch {
+		case i == 5 && !errors.Is(errs[i], failed):
+			t.Fatalf("errs[5] = %v", errs[i])
+		case i != 5 && (errs[i] != nil || v != i*10):
+			t.Fatalf("vals[%d] = %d, %v", i, v, errs[i])
+		}
+	}
+}
+
+func TestResultGroupCollectRejected(t *testing.T) {
+	p, release := newBusyPool(t, WithBlock(false), WithQueueSize(1))
+	defer p.Free()
+	g := NewResultGroup[int](p)
+	if err := g.Go(func(context.Context) (int, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Go(func(context.Context) (int, error) { return 2, nil }); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("Go on a saturated pool: %v", err)
+	}
+	release()
+	g.Close()
+	vals, errs := g.Collect()
+	if len(vals) != 2 || vals[0] != 1 || errs[0] != nil || !errors.Is(errs[1], ErrPoolSaturated) {
+		t.Fatalf("vals %v errs %v", vals, errs)
+	}
+}
@@ -0,0 +1,25 @@
+package workerpool
+
+// Result 是 ScheduleResult 交付的任务结果
+type Result struct {
+	Pool  string // 执行任务的 pool 的名称，见 WithName，用于区分汇入同一 channel 的多个 pool
+	Value any
+	Err   error // t 返回的错误；t panic 时为 *PanicError，被接受后未执行即被丢弃（Purge、Free）时为丢弃的原因
+}
+
+// ScheduleResult 提交 t，t 结束后把结果发送到调用方持有的 results，便于把多个 pool、多个任务的结果汇入已有的 select 循环；
+// 被接受的任务恰好交付一个 Result，提交失败时返回错误且不交付。results 已满时改由一个临时 goroutine 等待发送，
+// worker 不会因调用方消费慢而阻塞，此时结果不再按完成顺序到达；results 不能为 nil，调用方应当读取所有结果
+func (p *Pool) ScheduleResult(t func() (any, error), results chan<- Result) error {
+	deliver := func(r Result) {
+		select {
+		case results <- r:
+		default:
+			go func() { results <- r }()
+		}
+	}
+	return p.scheduleOr(task{fn: func() {
+		v, err := protect(struct{}{}, func(struct{}) (any, error) { return t() })
+		deliver(Result{Pool: p.name, Value: v, Err: err})
+	}}, func(reason error) { deliver(Result{Pool: p.name, Err: reason}) })
+}
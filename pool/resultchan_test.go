@@ -0,0 +1,64 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScheduleResult(t *testing.T) {
+	a := New(2, WithLogger(nil), WithName("a"))
+	defer a.Free()
+	b := New(2, WithLogger(nil), WithName("b"))
+	defer b.Free()
+	results := make(chan Result) // 无缓冲：结果由临时 goroutine 交付，worker 不阻塞
+	failed := errors.New("failed")
+	for _, p := range []*Pool{a, b} {
+		if err := p.ScheduleResult(func() (any, error) { return p.Name() + "-ok", nil }, results); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.ScheduleResult(func() (any, error) { return nil, failed }, results); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.ScheduleResult(func() (any, error) { panic("boom") }, results); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.Wait()
+	b.Wait()
+	counts := map[string]int{}
+	for i := 0; i < 6; i++ {
+		r := <-results
+		var pe *PanicError
+		switch {
+		case r.Err == nil && r.Value == r.Pool+"-ok":
+			counts[r.Pool+" ok"]++
+		case errors.Is(r.Err, failed):
+			counts[r.Pool+" failed"]++
+		case errors.As(r.Err, &pe) && pe.Value == "boom":
+			counts[r.Pool+" panic"]++
+		default:
+			t.Fatalf("unexpected result %+v", r)
+		}
+	}
+	for _, k := range []string{"a ok", "a failed", "a panic", "b ok", "b failed", "b panic"} {
+		if counts[k] != 1 {
+			t.Fatalf("results = %v", counts)
+		}
+	}
+}
+
+func TestScheduleResultDiscarded(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(1))
+	defer p.Free()
+	results := make(chan Result, 1)
+	if err := p.ScheduleResult(func() (any, error) { return 1, nil }, results); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Purge(); n != 1 {
+		t.Fatalf("Purge = %d", n)
+	}
+	if r := <-results; !errors.Is(r.Err, ErrTaskDiscarded) {
+		t.Fatalf("result = %+v", r)
+	}
+	release()
+}
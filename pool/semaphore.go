@@ -0,0 +1,147 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// Semaphore 以与 golang.org/x/sync/semaphore.Weighted 相同的方法暴露 pool 的容量：
+// 每获取一个单位就有一个 worker 被占住直到 Release，因此外部预留的“槽位”与 pool 中的任务共享同一份并发预算，
+// 已经依赖 *semaphore.Weighted 的代码可以改为依赖只含这三个方法的接口后直接换用
+type Semaphore struct {
+	p   *Pool
+	acq chan struct{} // 同一时间只有一个 Acquire 在占用 worker，避免两个部分获取的调用互相等待
+
+	mu   sync.Mutex
+	held []chan func() // 每个已获取单位对应的 holder 任务，关闭后释放其 worker，发送函数则在该 worker 上执行后释放
+}
+
+// Semaphore 返回共享 p 容量的信号量，大小即 p.Cap()
+func (p *Pool) Semaphore() *Semaphore {
+	p = p.applied()
+	return &Semaphore{p: p, acq: make(chan struct{}, 1)}
+}
+
+// Acquire 获取 n 个单位，阻塞直到成功、ctx 取消或 pool 销毁；失败时不持有任何单位
+// 与 semaphore.Weighted 一致，n 大于容量时一直阻塞到 ctx 取消，等待期间不妨碍其它调用获取
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if n > int64(s.p.capacity) {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	select {
+	case s.acq <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.acq }()
+	got, err := s.take(ctx, int(n), true)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.held = append(s.held, got...)
+	s.mu.Unlock()
+	return nil
+}
+
+// TryAcquire 在不等待空闲 worker 的情况下获取 n 个单位，成功时返回 true
+func (s *Semaphore) TryAcquire(n int64) bool {
+	if n > int64(s.p.capacity) {
+		return false
+	}
+	select {
+	case s.acq <- struct{}{}:
+	default:
+		return false
+	}
+	defer func() { <-s.acq }()
+	got, err := s.take(context.Background(), int(n), false)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	s.held = append(s.held, got...)
+	s.mu.Unlock()
+	return true
+}
+
+// Release 释放 n 个单位，释放的数量超过持有的数量时 panic
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	if n > int64(len(s.held)) {
+		s.mu.Unlock()
+		panic("workerpool: semaphore released more than held")
+	}
+	rel := s.held[len(s.held)-int(n):]
+	s.held = s.held[:len(s.held)-int(n)]
+	s.mu.Unlock()
+	closeAll(rel)
+}
+
+// take 依次占住 n 个 worker，失败时释放已占住的，调用方须持有 s.acq
+func (s *Semaphore) take(ctx context.Context, n int, wait bool) ([]chan func(), error) {
+	var got []chan func()
+	for range n {
+		release, err := s.hold(ctx, wait)
+		if err != nil {
+			closeAll(got)
+			return nil, err
+		}
+		got = append(got, release)
+	}
+	return got, nil
+}
+
+// hold 提交一个占住 worker 的任务，返回在其开始执行后用于释放的 channel
+func (s *Semaphore) hold(ctx context.Context, wait bool) (chan func(), error) {
+	p := s.p
+	started := make(chan struct{})
+	release := make(chan func())
+	t := task{fnc: func(context.Context) {
+		close(started)
+		select {
+		case fn := <-release:
+			if fn != nil {
+				fn()
+			}
+		case <-p.quit:
+		}
+	}}
+	if !p.enterSubmit() {
+		return nil, p.freedErr()
+	}
+	var ok bool
+	if wait {
+		ok = p.tryDispatch(t) || p.waitDispatch(t, p.closing, ctx.Done())
+	} else {
+		ok = p.handOff(t) // 放入缓冲的任务要等到有 worker 空闲才开始，TryAcquire 不能等待
+	}
+	p.submitters.Done()
+	if !ok {
+		select {
+		case <-p.closing:
+			return nil, p.freedErr()
+		default:
+		}
+		if !wait {
+			return nil, &SaturatedError{Pool: p.name, Capacity: p.capacity, stats: p.stats()}
+		}
+		return nil, ctx.Err()
+	}
+	select {
+	case <-started:
+		return release, nil
+	case <-ctx.Done():
+		close(release) // 已提交的任务开始执行后立即返回
+		return nil, ctx.Err()
+	case <-p.quit:
+		return nil, p.freedErr()
+	}
+}
+
+func closeAll(chs []chan func()) {
+	for _, ch := range chs {
+		close(ch)
+	}
+}
@@ -0,0 +1,73 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTryAcquireDoesNotWaitForBufferedSlot(t *testing.T) {
+	for _, size := range []int{0, 2} {
+		p, release := newBusyPool(t, WithQueueSize(size))
+		s := p.Semaphore()
+		done := make(chan bool)
+		go func() { done <- s.TryAcquire(1) }()
+		select {
+		case ok := <-done:
+			if ok {
+				t.Fatalf("queue size %d: TryAcquire succeeded while the only worker is busy", size)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("queue size %d: TryAcquire is waiting for a worker", size)
+		}
+
+		release()
+		p.Wait()
+		deadline := time.Now().Add(5 * time.Second)
+		for !s.TryAcquire(1) { // 空闲的 worker 回到等待状态之前可能失败
+			if time.Now().After(deadline) {
+				t.Fatalf("queue size %d: TryAcquire failed with an idle worker", size)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if s.TryAcquire(1) {
+			t.Fatalf("queue size %d: acquired more than capacity", size)
+		}
+		s.Release(1)
+		p.Free()
+	}
+}
+
+func TestAcquireOversizeDoesNotBlockOthers(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	s := p.Semaphore()
+	ctx, cancel := context.WithCancel(context.Background())
+	oversize := make(chan error, 1)
+	go func() { oversize <- s.Acquire(ctx, 3) }()
+	time.Sleep(20 * time.Millisecond) // 让超出容量的调用先开始等待
+
+	acquired := make(chan error, 1)
+	go func() {
+		actx, acancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer acancel()
+		acquired <- s.Acquire(actx, 2)
+	}()
+	if err := <-acquired; err != nil {
+		t.Fatalf("Acquire(2) behind an oversize request: %v", err)
+	}
+	if s.TryAcquire(3) {
+		t.Fatal("TryAcquire beyond capacity succeeded")
+	}
+	s.Release(2)
+	select {
+	case err := <-oversize:
+		t.Fatalf("oversize Acquire returned early: %v", err)
+	default:
+	}
+	cancel()
+	if err := <-oversize; !errors.Is(err, context.Canceled) {
+		t.Fatalf("oversize Acquire: err = %v", err)
+	}
+}
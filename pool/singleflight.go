@@ -0,0 +1,60 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// SingleFlight 按 key 对提交去重：key 相同的任务正在排队或执行时，
+// 新的提交不会再执行一次，而是共享已有执行的 Future
+type SingleFlight[R any] struct {
+	p     *Pool
+	mu    sync.Mutex
+	calls map[string]*Future[R]
+}
+
+func NewSingleFlight[R any](p *Pool) *SingleFlight[R] {
+	return &SingleFlight[R]{p: p, calls: make(map[string]*Future[R])}
+}
+
+// Do 提交 key 对应的 fn，shared 为 true 表示复用了已有的执行，此时 fn 不会被调用
+// 共享的 Future 被任意一方 Cancel 时，所有共享方都会受到影响
+func (g *SingleFlight[R]) Do(key string, fn func(ctx context.Context) (R, error)) (f *Future[R], shared bool, err error) {
+	g.mu.Lock()
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return f, true, nil
+	}
+	f = newFuture[R]()
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	// 提交时不持有锁：pool 满且阻塞时，其它 key 的任务结束后仍能移除自己的记录
+	forget := func() { g.forget(key, f) }
+	err = f.submitOr(g.p, func(ctx context.Context) (R, error) {
+		defer forget()
+		return fn(ctx)
+	}, g.p.block, forget) // 被 Purge、Free 或 Cancel 丢弃时同样移除，之后的提交重新执行
+	if err != nil {
+		forget()
+		f.resolve(*new(R), err) // 提交期间加入的共享方同样得到该错误
+		return nil, false, err
+	}
+	return f, false, nil
+}
+
+// Forget 让之后对 key 的提交重新执行，而不是共享当前的执行
+func (g *SingleFlight[R]) Forget(key string) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
+
+// 仅当 key 仍对应 f 时才移除，避免误删 Forget 之后新发起的执行
+func (g *SingleFlight[R]) forget(key string, f *Future[R]) {
+	g.mu.Lock()
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+}
@@ -0,0 +1,79 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSingleFlightShares(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	g := NewSingleFlight[int](p)
+	gate := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-gate
+		return 7, nil
+	}
+	f1, shared, err := g.Do("k", fn)
+	if err != nil || shared {
+		t.Fatalf("first Do: shared = %v, err = %v", shared, err)
+	}
+	f2, shared, err := g.Do("k", fn)
+	if err != nil || !shared || f2 != f1 {
+		t.Fatalf("second Do: shared = %v, err = %v", shared, err)
+	}
+	close(gate)
+	if v, err := f2.Wait(); v != 7 || err != nil {
+		t.Fatalf("Wait = %d, %v", v, err)
+	}
+	if _, shared, _ := g.Do("k", fn); shared {
+		t.Fatal("finished call still shared")
+	}
+	p.Wait()
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("fn called %d times", n)
+	}
+}
+
+func TestSingleFlightDiscarded(t *testing.T) {
+	p, release := newBusyPool(t, WithQueueSize(4))
+	defer p.Free()
+	g := NewSingleFlight[int](p)
+	var calls atomic.Int32
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		return 1, nil
+	}
+	f, _, err := g.Do("k", fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Cancel() {
+		t.Fatal("Cancel of a queued task failed")
+	}
+	if _, err := f.Wait(); !errors.Is(err, ErrTaskDiscarded) {
+		t.Fatalf("canceled Wait: err = %v", err)
+	}
+	f, shared, err := g.Do("k", fn)
+	if err != nil || shared {
+		t.Fatalf("Do after Cancel: shared = %v, err = %v", shared, err)
+	}
+	if n := p.Purge(); n != 1 {
+		t.Fatalf("Purge = %d", n)
+	}
+	f, shared, err = g.Do("k", fn)
+	if err != nil || shared {
+		t.Fatalf("Do after Purge: shared = %v, err = %v", shared, err)
+	}
+	release()
+	if v, err := f.Wait(); v != 1 || err != nil {
+		t.Fatalf("Wait = %d, %v", v, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fn called %d times", n)
+	}
+}
@@ -0,0 +1,38 @@
+package workerpool
+
+import "time"
+
+// 最短任务优先：WithShortestJobFirst 开启后，排队的任务在同一优先级内按预计时长从短到长分发，
+// 短任务不必等在长任务之后，混合负载下的平均延迟大幅降低
+//
+// 排序键为“入队时刻 + 预计时长”：同时到达的任务短的先执行，已等待的时长抵消预计时长，
+// 因此一个任务最多比之后到达的短任务多等待它自身的预计时长，持续的短任务负载下长任务也不会饿死
+
+func WithShortestJobFirst(estimate func(TaskInfo) time.Duration) Option { // 排队的任务按预计时长从短到长分发（优先级仍然优先），estimate 为 nil 时使用 WithExpectedDuration 的提示，没有提示的任务按 TaskInfo.Cost 估计（见 WithCostEstimator），均没有时视为 0；开启优先级队列，WithPriorityScheduling 的 aging 不再起作用，与 WithFIFO 冲突
+	return func(p *Pool) {
+		if estimate == nil {
+			estimate = func(info TaskInfo) time.Duration {
+				if info.Expected == 0 {
+					return time.Duration(info.Cost) * costUnit // 见 WithCostEstimator
+				}
+				return info.Expected
+			}
+		}
+		p.sjf = estimate
+	}
+}
+
+func WithExpectedDuration(d time.Duration) TaskOption { // 任务预计的执行时长，供 WithShortestJobFirst 排序
+	return func(t *task) {
+		t.info.Expected = d
+	}
+}
+
+// sjfDue 返回 t 在 WithShortestJobFirst 下的排序键，越小越先分发
+func (p *Pool) sjfDue(t task, now time.Time) time.Duration {
+	var info TaskInfo
+	if t.info != nil {
+		info = *t.info
+	}
+	return now.Sub(p.sched.epoch) + max(p.sjf(info), 0)
+}
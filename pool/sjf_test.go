@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestShortestJobFirst(t *testing.T) {
+	p, release := newBusyPool(t, WithShortestJobFirst(nil), WithQueueSize(10))
+	defer p.Free()
+	var r recorder
+	for _, secs := range []int{5, 1, 3, 0, 2} {
+		run := r.task(secs)
+		if err := p.ScheduleWith(func(context.Context) { run() }, WithExpectedDuration(time.Duration(secs)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release()
+	p.Wait()
+	want := []int{0, 1, 2, 3, 5}
+	for i := range want {
+		if r.got[i] != want[i] {
+			t.Fatalf("ran %v, want %v", r.got, want)
+		}
+	}
+}
+
+func TestShortestJobFirstWaited(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	estimate := func(info TaskInfo) time.Duration {
+		d, _ := time.ParseDuration(info.Name)
+		return d
+	}
+	p, release := newBusyPool(t, WithClock(clock), WithShortestJobFirst(estimate), WithQueueSize(10))
+	defer p.Free()
+	var r recorder
+	submit := func(secs int) {
+		run := r.task(secs)
+		if err := p.ScheduleWith(func(context.Context) { run() }, WithTaskName(fmt.Sprintf("%ds", secs))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submit(60) // 被 feeder 取出等待 worker
+	submit(10)
+	clock.Advance(11 * time.Second) // 已等待的时长超过自身的预计时长，先于之后到达的短任务
+	submit(1)
+	release()
+	p.Wait()
+	want := []int{10, 1, 60}
+	for i := range want {
+		if r.got[i] != want[i] {
+			t.Fatalf("ran %v, want %v", r.got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+func WithSlog(l *slog.Logger, attrs ...slog.Attr) Option { // 日志改为经由 l 输出：每条日志带有 pool 分组（name、capacity、id 以及 attrs），多个 pool 的服务可按属性查询；覆盖 WithLogger
+	return func(p *Pool) {
+		if l == nil {
+			p.invalid("WithSlog: nil logger")
+			return
+		}
+		p.slog, p.slogAttrs = l, attrs
+	}
+}
+
+// logSlog 以 Info 级别输出一条 logf 格式的日志，去掉末尾的换行
+func (p *Pool) logSlog(format string, args ...any) {
+	ctx := context.Background()
+	if !p.slog.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	group := make([]any, 0, 3+len(p.slogAttrs))
+	group = append(group, slog.String("name", p.name), slog.Int("capacity", p.capacity), slog.Uint64("id", p.id))
+	for _, a := range p.slogAttrs {
+		group = append(group, a)
+	}
+	p.slog.LogAttrs(ctx, slog.LevelInfo, msg, slog.Group("pool", group...))
+}
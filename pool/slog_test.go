@@ -0,0 +1,40 @@
+package workerpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+	p := New(3, WithName("images"), WithSlog(l, slog.String("team", "media")))
+	p.Free()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("got %d log lines, want start and freed", len(lines))
+	}
+	for _, line := range lines {
+		var rec struct {
+			Msg  string
+			Pool struct {
+				Name     string
+				Capacity int
+				ID       uint64
+				Team     string
+			}
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		if rec.Pool.Name != "images" || rec.Pool.Capacity != 3 || rec.Pool.ID != p.id || rec.Pool.Team != "media" {
+			t.Fatalf("pool group = %+v in %s", rec.Pool, line)
+		}
+		if strings.HasSuffix(rec.Msg, "\n") || strings.HasPrefix(rec.Msg, "images:") {
+			t.Fatalf("msg = %q, want no name prefix or trailing newline", rec.Msg)
+		}
+	}
+}
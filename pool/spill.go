@@ -0,0 +1,223 @@
+package workerpool
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SpillQueue 是内存与磁盘混合的 Queue：队首最多 limit 个 Job 保存在内存中，超出的部分依次写入 dir 下的段文件，
+// 内存中的 Job 消耗过半时再从磁盘读回，突发的积压既不必拒绝，也不会让内存无限增长。
+// 段文件只用于溢出，不保证持久：进程退出后其中的 Job 不再恢复，需要时使用 WithWAL 或外部队列
+//
+// 段文件的记录格式与 WAL 的 walAdd 相同，id 字段保存 Job.Attempt
+type SpillQueue struct {
+	dir   string
+	limit int
+
+	mu      sync.Mutex
+	head    []Job
+	ready   chan struct{}   // 有 Job 时非空
+	segs    []*spillSegment // 按写入顺序，第一个正在读回，最后一个正在写入
+	spilled int             // 磁盘上尚未读回的 Job 数
+	seq     int             // 最近创建的段文件编号
+	err     error           // 读写段文件失败后不再继续
+	closed  bool
+}
+
+var ErrSpillQueueClosed = errors.New("spill queue closed")
+
+// spillSegmentSize 是单个段文件的大小上限，超出后写入新的段；读完的段随即删除
+const spillSegmentSize = 64 << 20
+
+type spillSegment struct {
+	path    string
+	w       *os.File
+	bw      *bufio.Writer
+	r       *os.File // 首次读回时打开
+	br      *bufio.Reader
+	size    int64
+	written int
+	read    int
+}
+
+// NewSpillQueue 创建内存中最多保存 limit 个 Job 的 SpillQueue，溢出的 Job 写入 dir（不存在时创建）
+func NewSpillQueue(dir string, limit int) (*SpillQueue, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: spill queue limit %d must be positive", ErrInvalidOption, limit)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &SpillQueue{dir: dir, limit: limit, ready: make(chan struct{}, 1)}, nil
+}
+
+func (q *SpillQueue) Push(_ context.Context, j Job) error {
+	if err := checkAddJob(j); err != nil {
+		return fmt.Errorf("spill queue: %w", err)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch {
+	case q.closed:
+		return ErrSpillQueueClosed
+	case q.err != nil:
+		return q.err
+	case q.spilled == 0 && len(q.head) < q.limit: // 磁盘上有 Job 时新的 Job 排在其后，保持先进先出
+		q.head = append(q.head, j)
+	default:
+		if err := q.spill(j); err != nil {
+			q.err = fmt.Errorf("spill queue %s: %w", q.dir, err)
+			return q.err
+		}
+	}
+	q.signal()
+	return nil
+}
+
+func (q *SpillQueue) Pop(ctx context.Context) (Delivery, error) {
+	for {
+		q.mu.Lock()
+		if len(q.head) <= q.limit/2 && q.spilled > 0 && q.err == nil {
+			if err := q.reload(); err != nil {
+				q.err = fmt.Errorf("spill queue %s: %w", q.dir, err)
+			}
+		}
+		if len(q.head) > 0 {
+			j := q.head[0]
+			q.head[0] = Job{}
+			q.head = q.head[1:]
+			j.Attempt++
+			more := len(q.head) > 0 || q.spilled > 0
+			q.mu.Unlock()
+			if more { // 传递给下一个等待的 Pop
+				q.signal()
+			}
+			return Delivery{Job: j}, nil
+		}
+		err := q.err
+		q.mu.Unlock()
+		if err != nil {
+			return Delivery{}, err
+		}
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return Delivery{}, ctx.Err()
+		}
+	}
+}
+
+func (q *SpillQueue) Len(context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.head) + q.spilled, nil
+}
+
+// Spilled 返回当前写在磁盘上、尚未读回内存的 Job 数
+func (q *SpillQueue) Spilled() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.spilled
+}
+
+// Ack 对超时未完成的 Job 重新入队，其它结果无需处理，与 MemoryQueue 相同
+func (q *SpillQueue) Ack(ctx context.Context, d Delivery, err error) error {
+	if errors.Is(err, ErrAckTimeout) {
+		return q.Push(ctx, d.Job)
+	}
+	return nil
+}
+
+// Close 丢弃其中的 Job 并删除段文件，之后的 Push 返回 ErrSpillQueueClosed
+func (q *SpillQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.head, q.spilled = nil, 0
+	var errs []error
+	for _, s := range q.segs {
+		errs = append(errs, s.remove())
+	}
+	q.segs = nil
+	return errors.Join(errs...)
+}
+
+func (q *SpillQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// 需持有 q.mu
+func (q *SpillQueue) spill(j Job) error {
+	var s *spillSegment
+	if n := len(q.segs); n > 0 && q.segs[n-1].size < spillSegmentSize {
+		s = q.segs[n-1]
+	} else {
+		q.seq++
+		path := filepath.Join(q.dir, fmt.Sprintf("spill-%06d.seg", q.seq))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		s = &spillSegment{path: path, w: f, bw: bufio.NewWriter(f)}
+		q.segs = append(q.segs, s)
+	}
+	body := walAddBody(uint64(j.Attempt), j)
+	if err := writeWALRecord(s.bw, body); err != nil {
+		return err
+	}
+	s.size += int64(8 + len(body))
+	s.written++
+	q.spilled++
+	return nil
+}
+
+// reload 从磁盘读回 Job，直到内存中有 limit 个或磁盘上没有；需持有 q.mu
+func (q *SpillQueue) reload() error {
+	for len(q.head) < q.limit && q.spilled > 0 {
+		s := q.segs[0]
+		if s.r == nil {
+			f, err := os.Open(s.path)
+			if err != nil {
+				return err
+			}
+			s.r, s.br = f, bufio.NewReader(f)
+		}
+		if err := s.bw.Flush(); err != nil { // 读回之前须把缓冲的记录写入文件
+			return err
+		}
+		body, _, err := readWALRecord(s.br)
+		if err != nil {
+			return err
+		}
+		j := walAddJob(body)
+		j.Attempt = int(binary.BigEndian.Uint64(body[1:9]))
+		q.head = append(q.head, j)
+		s.read++
+		q.spilled--
+		if s.read == s.written {
+			q.segs = q.segs[1:]
+			if err := s.remove(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *spillSegment) remove() error {
+	errs := []error{s.w.Close()}
+	if s.r != nil {
+		errs = append(errs, s.r.Close())
+	}
+	errs = append(errs, os.Remove(s.path))
+	return errors.Join(errs...)
+}
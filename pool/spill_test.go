@@ -0,0 +1,78 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSpillQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewSpillQueue(dir, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		if err := q.Push(ctx, Job{Type: "n", Key: fmt.Sprint(i), Payload: []byte{byte(i)}, Attempt: i % 3}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := q.Len(ctx); n != 20 || q.Spilled() != 16 {
+		t.Fatalf("len %d, spilled %d", n, q.Spilled())
+	}
+	for i := 0; i < 20; i++ {
+		d, err := q.Pop(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j := d.Job; j.Key != fmt.Sprint(i) || j.Payload[0] != byte(i) || j.Attempt != i%3+1 {
+			t.Fatalf("pop %d: %+v", i, j)
+		}
+		if i == 0 && q.Spilled() != 16 {
+			t.Fatal("reloaded before the head drained")
+		}
+	}
+	if segs, _ := filepath.Glob(filepath.Join(dir, "*.seg")); len(segs) != 0 || q.Spilled() != 0 {
+		t.Fatalf("segments %v left after draining", segs)
+	}
+	if err := q.Push(ctx, Job{Type: "n"}); err != nil { // 排空后新的 Job 回到内存中
+		t.Fatal(err)
+	}
+	if q.Spilled() != 0 {
+		t.Fatal("job spilled into an empty queue")
+	}
+}
+
+func TestSpillQueuePool(t *testing.T) {
+	q, err := NewSpillQueue(t.TempDir(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	p := New(2, WithLogger(nil), WithQueue(q))
+	defer p.Free()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := map[string]bool{}
+	p.Register("echo", func(_ context.Context, payload []byte) error {
+		mu.Lock()
+		seen[string(payload)] = true
+		mu.Unlock()
+		wg.Done()
+		return nil
+	})
+	wg.Add(50)
+	for i := 0; i < 50; i++ {
+		if err := p.Enqueue(Job{Type: "echo", Payload: []byte(fmt.Sprint(i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if len(seen) != 50 {
+		t.Fatalf("ran %d distinct jobs", len(seen))
+	}
+}
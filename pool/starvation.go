@@ -0,0 +1,99 @@
+package workerpool
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StarvedTask 描述一个排队过久的任务，见 WithStarvationDetector
+type StarvedTask struct {
+	Info   *TaskInfo     // ScheduleWith 提交的任务的元数据，其它任务为 nil
+	Waited time.Duration // 已排队的时长
+}
+
+func (s StarvedTask) String() string {
+	task := "a task"
+	if s.Info != nil {
+		task = s.Info.String()
+	}
+	return fmt.Sprintf("%s queued for %s", task, s.Waited.Round(time.Millisecond))
+}
+
+type starvationMonitor struct {
+	threshold time.Duration
+	onStarve  func(StarvedTask)
+}
+
+func WithStarvationDetector(threshold time.Duration, onStarve func(StarvedTask)) Option { // 已提交的任务排队超过 threshold 仍未开始执行时调用 onStarve（可为 nil，只记录日志），每个任务只报告一次；与执行慢的检测（WithHealthcheck、WithHeartbeat）无关，用于及时发现优先级、公平调度或配额的配置使部分任务长期得不到执行，期间 PoolStats.Starved 计入
+	return func(p *Pool) {
+		if threshold <= 0 {
+			p.invalid("WithStarvationDetector(%s): threshold must be positive", threshold)
+			return
+		}
+		p.starvation = &starvationMonitor{threshold: threshold, onStarve: onStarve}
+	}
+}
+
+// markQueuedAt 在 c 第一次登记为等待时记录时刻，供 runStarvation 计算排队时长
+func (p *Pool) markQueuedAt(t *task) {
+	if p.starvation != nil && !t.enqueued.IsZero() {
+		t.claim.queuedAt.CompareAndSwap(0, t.enqueued.UnixNano())
+	}
+}
+
+// runStarvation 每 threshold/4 检查一次排队中的任务，直到 pool 销毁
+func (p *Pool) runStarvation() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	m := p.starvation
+	ticker := p.clock.NewTicker(max(m.threshold/4, time.Millisecond))
+	defer ticker.Stop()
+	reported := make(map[*taskClaim]bool)
+	for {
+		select {
+		case <-ticker.C():
+		case <-p.quit:
+			return
+		}
+		now := p.clock.Now().UnixNano()
+		var starved []StarvedTask
+		n := 0
+		s := &p.queued
+		s.mu.Lock()
+		for c := range reported {
+			if _, ok := s.m[c]; !ok {
+				delete(reported, c)
+			}
+		}
+		for c := range s.m {
+			at := c.queuedAt.Load()
+			if at == 0 || time.Duration(now-at) <= m.threshold { // 内部任务没有记录时刻，不监视
+				continue
+			}
+			n++
+			if !reported[c] {
+				reported[c] = true
+				starved = append(starved, StarvedTask{Info: c.info, Waited: time.Duration(now - at)})
+			}
+		}
+		s.mu.Unlock()
+		p.starved.Store(int64(n))
+		sort.Slice(starved, func(i, j int) bool { return starved[i].Waited > starved[j].Waited })
+		for _, st := range starved {
+			p.logf("workerpool: starving: %s\n", st)
+			if m.onStarve != nil {
+				m.onStarve(st)
+			}
+		}
+	}
+}
+
+// TaskStarved 在有任务持续 d 排队超过 WithStarvationDetector 的 threshold 时告警
+func TaskStarved(d time.Duration) AlertCondition {
+	return AlertCondition{
+		Name:  fmt.Sprintf("queued tasks starved for %s", d),
+		Check: func(s PoolStats) bool { return s.Starved > 0 },
+		For:   d,
+	}
+}
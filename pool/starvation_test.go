@@ -0,0 +1,44 @@
+package workerpool
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStarvationDetector(t *testing.T) {
+	starved := make(chan StarvedTask, 4)
+	p, release := newBusyPool(t, WithQueueSize(4), WithStarvationDetector(20*time.Millisecond, func(s StarvedTask) { starved <- s }))
+	defer p.Free()
+	if err := p.ScheduleWith(func(context.Context) {}, WithTaskName("report")); err != nil {
+		t.Fatal(err)
+	}
+	var s StarvedTask
+	select {
+	case s = <-starved:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no starvation reported")
+	}
+	if s.Info == nil || s.Info.Name != "report" || s.Waited < 20*time.Millisecond || !strings.Contains(s.String(), "report") {
+		t.Fatalf("starved = %+v", s)
+	}
+	if n := p.Stats().Starved; n != 1 {
+		t.Fatalf("Stats().Starved = %d", n)
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case s := <-starved:
+		t.Fatalf("reported twice: %+v", s)
+	default:
+	}
+	release()
+	p.Wait()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Stats().Starved != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Starved not cleared after the task ran")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
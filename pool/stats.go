@@ -0,0 +1,56 @@
+package workerpool
+
+// PoolStats 是 pool 某一时刻的状态
+type PoolStats struct {
+	Capacity  int          // 容量，WithCapacitySchedule 下为当前时段的容量
+	Workers   int          // 存活的 worker 数
+	Busy      int          // 正在执行任务的 worker 数
+	Inflight  int          // 已提交尚未执行完的任务数，不含 ScheduleDetached 的任务
+	Queued    int          // 已提交尚未开始执行、可以被 Purge 丢弃的任务数
+	Abandoned int          // 超出预算被放弃、尚未返回的任务数，见 WithTaskBudget
+	Blocked   int          // 阻塞在提交中等待 worker 的提交方数
+	Overflow  int          // WithOverflow 的临时 worker 数，不含在 Workers 中
+	QueueWait LatencyStats // 已开始执行的任务从提交到开始执行的等待时间，用于区分任务慢与分发慢
+	ExecTime  LatencyStats // 已执行完的任务的执行时间
+	SpinUp    LatencyStats // worker 从创建到可以执行任务的时间，含 WithWorkerInit，即冷启动的开销
+
+	QueuedBytes int64 // 排队任务的估计大小之和，见 WithQueueBytes
+	Stalled     int   // 心跳已停止的任务数，见 WithHeartbeat
+	Starved     int   // 排队超过阈值仍未开始的任务数，见 WithStarvationDetector
+}
+
+// Stats 返回 p 当前的状态
+func (p *Pool) Stats() PoolStats {
+	return p.applied().stats()
+}
+
+func (p *Pool) stats() PoolStats {
+	parked := int(p.parked.Load())
+	s := PoolStats{
+		Capacity:  p.capacity - parked,
+		Workers:   max(len(p.active)-parked, 0),
+		Inflight:  p.inflight.count(),
+		Abandoned: int(p.abandoned.Load()),
+		Blocked:   int(p.blocked.Load()),
+		Overflow:  len(p.overflow),
+		QueueWait: p.queueWait.get(),
+		ExecTime:  p.execTime.get(),
+		SpinUp:    p.spinUp.get(),
+	}
+	s.Starved = int(p.starved.Load())
+	p.queued.mu.Lock()
+	s.Queued = len(p.queued.m)
+	s.QueuedBytes = p.queued.bytes
+	p.queued.mu.Unlock()
+	p.live.Range(func(_, v any) bool {
+		w := v.(*worker)
+		if w.busySince.Load() != 0 {
+			s.Busy++
+		}
+		if w.stalled.Load() {
+			s.Stalled++
+		}
+		return true
+	})
+	return s
+}
@@ -0,0 +1,208 @@
+package workerpool
+
+import (
+	"context"
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// 按键路由：WithStickyRouting 开启后，pool 为容量中的每个位置（slot）各保留一个队列，每个普通 worker 占据一个空闲的位置，
+// 以 WithStickyKey 提交的任务按键的一致性哈希（jump consistent hash）进入对应位置的队列，同一个键总由同一个 worker 执行，
+// worker 本地的缓存（WorkerLocal、WithWorkerInit）与有状态的处理函数命中率更高。占据位置的 worker 退出后由新的 worker 接替该位置
+//
+// 一致性哈希使 Resize 改变容量时只有少量的键换到其它位置（从 n 扩到 m 时约 1-n/m），按 StickySlot 索引的外部缓存大多仍然有效。
+// 按键路由只保证亲和性而不保证顺序：worker 在 Barrier 上等待或任务超出预算时位置随容量转交给新的 worker，
+// 同一个键的新旧任务可能同时执行；没有键的任务照常交给任意 worker
+
+type stickySlots struct {
+	slots []stickySlot
+	free  atomic.Int32 // 没有 worker 占据的位置数
+}
+
+type stickySlot struct {
+	tasks chan task
+	owner atomic.Pointer[worker]
+}
+
+func newStickySlots(capacity, queue int) *stickySlots {
+	s := &stickySlots{slots: make([]stickySlot, capacity)}
+	for i := range s.slots {
+		s.slots[i].tasks = make(chan task, queue)
+	}
+	s.free.Store(int32(capacity))
+	return s
+}
+
+func WithStickyRouting(queue int) Option { // 以 WithStickyKey 提交的任务按键交给固定的 worker，每个 worker 最多为它的键排队 queue 个任务，已满时提交按 WithBlock 阻塞或失败，见 StickySlot；与 WithFIFO、WithPriorityScheduling、WithCapacitySchedule 冲突
+	return func(p *Pool) {
+		if queue <= 0 {
+			p.invalid("WithStickyRouting(%d): queue must be positive", queue)
+			return
+		}
+		p.stickyRouting, p.stickyQueue = true, queue
+	}
+}
+
+func WithStickyKey(key string) TaskOption { // 配合 WithStickyRouting 把任务交给负责 key 的 worker，未开启时不起作用
+	return func(t *task) {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		t.affinity, t.keyed = h.Sum64(), true
+	}
+}
+
+// StickySlot 返回执行当前任务的 worker 占据的位置，范围为 [0, 容量)，同一个键的任务总在同一个位置上执行；
+// ctx 不是 TaskFunc 收到的 ctx、未开启 WithStickyRouting 或 worker 没有占据位置时 ok 为 false
+func StickySlot(ctx context.Context) (slot int, ok bool) {
+	w, ok := ctx.Value(workerKey{}).(*worker)
+	if !ok {
+		return 0, false
+	}
+	n := int(w.slot.Load())
+	return n - 1, n > 0
+}
+
+// jumpHash 是 Lamping 与 Veach 的 jump consistent hash，把 key 映射到 [0, n)
+func jumpHash(key uint64, n int) int {
+	b, j := int64(-1), int64(0)
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// slotOf 返回 t 的键对应的位置
+func (s *stickySlots) slotOf(t *task) int {
+	return jumpHash(t.affinity, len(s.slots))
+}
+
+// claim 让 w 占据一个空闲的位置：优先有任务等待的位置，其次编号最小的位置；没有空闲的位置时返回 false
+func (s *stickySlots) claim(w *worker) bool {
+	if s.free.Load() == 0 {
+		return false
+	}
+	for i := range s.slots {
+		if len(s.slots[i].tasks) > 0 && s.take(w, i) {
+			return true
+		}
+	}
+	for i := range s.slots {
+		if s.take(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stickySlots) take(w *worker, i int) bool {
+	if !s.slots[i].owner.CompareAndSwap(nil, w) {
+		return false
+	}
+	s.free.Add(-1)
+	w.slot.Store(int32(i + 1))
+	return true
+}
+
+// stickyTasks 返回 w 应当接收的按键路由的队列，w 尚未占据位置时先尝试占据一个；
+// 未开启 WithStickyRouting、w 是临时 worker 或没有空闲的位置时返回 nil
+func (p *Pool) stickyTasks(w *worker) chan task {
+	s := p.sticky
+	if s == nil || !w.replaceable {
+		return nil
+	}
+	if n := int(w.slot.Load()); n > 0 {
+		return s.slots[n-1].tasks
+	}
+	if !s.claim(w) {
+		return nil
+	}
+	return s.slots[w.slot.Load()-1].tasks
+}
+
+// releaseSlot 在 w 退出或转交容量时让出它占据的位置，返回让出的位置，没有时返回 -1
+func (p *Pool) releaseSlot(w *worker) int {
+	s := p.sticky
+	if s == nil {
+		return -1
+	}
+	i := int(w.slot.Swap(0)) - 1
+	if i < 0 {
+		return -1
+	}
+	s.slots[i].owner.Store(nil)
+	s.free.Add(1)
+	return i
+}
+
+// staffSlot 在位置 i 没有 worker 但有任务等待时，容量未满则创建一个 worker 接替，对 p.wg 的要求与 tryDispatch 相同
+func (p *Pool) staffSlot(i int) {
+	if i < 0 {
+		return
+	}
+	sl := &p.sticky.slots[i]
+	if sl.owner.Load() != nil || len(sl.tasks) == 0 {
+		return
+	}
+	select {
+	case <-p.quit:
+		return
+	default:
+	}
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(nil) // 优先占据有任务等待的位置，见 claim
+	default:
+	}
+}
+
+// submitSticky 是开启 WithStickyRouting 时以 WithStickyKey 提交的任务的 submit，t 已登记为可丢弃；
+// t 总是经由位置的队列交出，不作为新 worker 的第一个任务，否则同时创建的 worker 争抢位置时 t 可能在其它位置上执行
+func (p *Pool) submitSticky(ctx context.Context, t task, block bool) error {
+	i := p.sticky.slotOf(&t)
+	sl := &p.sticky.slots[i]
+	select {
+	case sl.tasks <- t:
+		p.staffSlot(i)
+		return nil
+	default:
+	}
+	if !block {
+		return p.saturatedErr()
+	}
+	if handled, rerr := p.handleReentrant(t); handled {
+		return rerr
+	}
+	unwait, derr := p.enterWait()
+	if derr != nil {
+		return derr
+	}
+	defer unwait()
+	p.blocked.Add(1)
+	defer p.blocked.Add(-1)
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case sl.tasks <- t:
+		p.staffSlot(i)
+		return nil
+	case <-p.closing:
+		return p.freedErr()
+	case <-t.claim.discarded:
+		return ErrTaskDiscarded
+	case <-done:
+		return p.waitErr(ctx)
+	}
+}
+
+// dropSticky 在所有 worker 与提交方退出后丢弃各位置的队列中的任务
+func (p *Pool) dropSticky() {
+	for i := range p.sticky.slots {
+		for len(p.sticky.slots[i].tasks) > 0 {
+			p.drop(<-p.sticky.slots[i].tasks)
+		}
+	}
+}
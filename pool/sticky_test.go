@@ -0,0 +1,87 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestStickyRouting(t *testing.T) {
+	p := New(4, WithLogger(nil), WithStickyRouting(16))
+	defer p.Free()
+	var mu sync.Mutex
+	workers := map[string]map[int]bool{}
+	slots := map[string]map[int]bool{}
+	for i := 0; i < 80; i++ {
+		key := fmt.Sprint("user-", i%8)
+		err := p.ScheduleWith(func(ctx context.Context) {
+			id, _ := WorkerID(ctx)
+			slot, ok := StickySlot(ctx)
+			if !ok {
+				t.Error("StickySlot: not ok")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if workers[key] == nil {
+				workers[key], slots[key] = map[int]bool{}, map[int]bool{}
+			}
+			workers[key][id] = true
+			slots[key][slot] = true
+		}, WithStickyKey(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+	for key := range workers {
+		if len(workers[key]) != 1 || len(slots[key]) != 1 {
+			t.Errorf("%s ran on workers %v, slots %v", key, workers[key], slots[key])
+		}
+	}
+}
+
+func TestStickyQueueFull(t *testing.T) {
+	var discarded int
+	p, release := newBusyPool(t, WithStickyRouting(1), WithBlock(false), WithOnDiscard(func(error) { discarded++ }))
+	defer p.Free()
+	// 唯一的 worker 正忙，键的任务在它的队列中等待
+	if err := p.ScheduleWith(func(context.Context) {}, WithStickyKey("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ScheduleWith(func(context.Context) {}, WithStickyKey("b")); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("second keyed task: err = %v", err)
+	}
+	if n := p.Purge(); n != 1 || discarded != 1 {
+		t.Fatalf("Purge = %d, discarded %d", n, discarded)
+	}
+	release()
+	p.Wait()
+}
+
+func TestJumpHashConsistent(t *testing.T) {
+	moved := 0
+	for k := uint64(0); k < 10000; k++ {
+		key := k * 0x9e3779b97f4a7c15
+		a, b := jumpHash(key, 4), jumpHash(key, 5)
+		if a != b {
+			if b != 4 {
+				t.Fatalf("key %d moved from %d to %d", key, a, b)
+			}
+			moved++
+		}
+	}
+	if moved < 1500 || moved > 2500 { // 约 1/5
+		t.Fatalf("%d of 10000 keys moved", moved)
+	}
+}
+
+func TestStickyRoutingConflicts(t *testing.T) {
+	if _, err := NewE(1, WithStickyRouting(1), WithFIFO()); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("WithFIFO: err = %v", err)
+	}
+	if _, err := NewE(1, WithStickyRouting(0)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("zero queue: err = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package workerpool
+
+import "sync"
+
+// StreamCallback 在提交该任务的 Stream 中按提交顺序被串行调用
+type StreamCallback func()
+
+// Stream 并发执行提交的任务，但按提交顺序串行调用各任务返回的回调，
+// 适合“并发抓取、顺序写入”的场景：
+//
+//	s := NewStream(p, 8)
+//	for _, url := range urls {
+//		s.Go(func() StreamCallback {
+//			body := fetch(url)
+//			return func() { w.Write(body) }
+//		})
+//	}
+//	s.Wait()
+//
+// 同时在执行或等待回调的任务最多 window 个，超出时 Go 阻塞
+type Stream struct {
+	p      *Pool
+	window chan struct{} // 在途任务的令牌
+
+	mu      sync.Mutex
+	pending []*streamSlot // 按提交顺序等待回调的任务
+	running bool          // 是否有 goroutine 正在执行回调
+	wg      sync.WaitGroup
+}
+
+type streamSlot struct {
+	done bool
+	cb   StreamCallback
+}
+
+// NewStream 创建一个 Stream，window <= 0 时取 p 的容量
+func NewStream(p *Pool, window int) *Stream {
+	if window <= 0 {
+		window = p.Cap()
+	}
+	return &Stream{p: p, window: make(chan struct{}, window)}
+}
+
+// Go 提交一个任务，fn 在 pool 上执行，其返回的回调（可为 nil）在此前提交的回调都执行完后调用；
+// fn panic 时视为返回 nil 回调，panic 不会卡住后续回调
+func (s *Stream) Go(fn func() StreamCallback) error {
+	s.window <- struct{}{}
+	slot := &streamSlot{}
+	s.mu.Lock()
+	s.pending = append(s.pending, slot)
+	s.mu.Unlock()
+	s.wg.Add(1)
+	err := s.p.scheduleOr(task{fn: func() {
+		var cb StreamCallback
+		defer func() { s.complete(slot, cb) }()
+		cb = fn()
+	}}, func(error) { s.complete(slot, nil) })
+	if err != nil {
+		s.complete(slot, nil)
+	}
+	return err
+}
+
+// Wait 等待所有已提交任务及其回调执行完毕
+func (s *Stream) Wait() {
+	s.wg.Wait()
+}
+
+// complete 标记 slot 完成，并由当前 goroutine 依次执行队首所有已完成的回调；
+// 同一时刻只有一个 goroutine 在执行回调，回调之间不需要额外同步
+func (s *Stream) complete(slot *streamSlot, cb StreamCallback) {
+	s.mu.Lock()
+	slot.done, slot.cb = true, cb
+	if s.running || s.pending[0] != slot {
+		s.mu.Unlock() // 由正在执行回调的 goroutine 或前面未完成的任务负责执行本回调
+		return
+	}
+	s.running = true
+	for len(s.pending) > 0 && s.pending[0].done {
+		head := s.pending[0]
+		s.pending[0] = nil
+		s.pending = s.pending[1:]
+		s.mu.Unlock()
+		head.run(s.p)
+		<-s.window
+		s.wg.Done()
+		s.mu.Lock()
+	}
+	s.running = false
+	s.mu.Unlock()
+}
+
+func (slot *streamSlot) run(p *Pool) {
+	defer func() {
+		if err := recover(); err != nil {
+			p.logf("stream: recover callback panic[%s]\n", err)
+		}
+	}()
+	if slot.cb != nil {
+		slot.cb()
+	}
+}
@@ -0,0 +1,50 @@
+package workerpool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStreamCallbacksInOrderAndPanicLogged(t *testing.T) {
+	var mu sync.Mutex
+	var logs []string
+	p := New(4, WithLogger(func(format string, args ...any) {
+		mu.Lock()
+		logs = append(logs, fmt.Sprintf(format, args...))
+		mu.Unlock()
+	}))
+	defer p.Free()
+	s := NewStream(p, 0)
+	var got []int
+	for i := 0; i < 100; i++ {
+		if err := s.Go(func() StreamCallback {
+			return func() {
+				got = append(got, i)
+				if i == 50 {
+					panic("boom")
+				}
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Wait()
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("callback %d ran at position %d", v, i)
+		}
+	}
+	if len(got) != 100 {
+		t.Fatalf("%d callbacks ran", len(got))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, l := range logs {
+		if strings.Contains(l, "stream: recover callback panic[boom]") {
+			return
+		}
+	}
+	t.Fatalf("callback panic not reported through the pool logger: %q", logs)
+}
@@ -0,0 +1,42 @@
+//go:build go1.25
+
+package workerpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// 在 synctest 的 bubble 中使用 pool：计时器与空闲回收按 bubble 的假时钟推进，
+// Free 之后不应有 goroutine 遗留在 bubble 中，否则 synctest.Test 报告死锁
+func TestSynctestBubble(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := New(4, WithIdleTimeout(time.Minute), WithLogger(nil))
+		var ran atomic.Int32
+		start := time.Now()
+		fired := make(chan time.Time, 1)
+		if _, err := p.ScheduleAfter(time.Hour, func() { fired <- time.Now() }); err != nil {
+			t.Fatal(err)
+		}
+		for range 8 {
+			p.Schedule(func() { time.Sleep(time.Second); ran.Add(1) })
+		}
+		p.Wait()
+		if ran.Load() != 8 || time.Since(start) != 2*time.Second {
+			t.Fatalf("ran %d tasks in %s", ran.Load(), time.Since(start))
+		}
+
+		time.Sleep(2 * time.Minute)
+		synctest.Wait()
+		if s := p.Stats(); s.Workers != 0 {
+			t.Fatalf("%d workers alive past the idle timeout", s.Workers)
+		}
+
+		if at := <-fired; at.Sub(start) != time.Hour {
+			t.Fatalf("timer fired after %s", at.Sub(start))
+		}
+		p.Free()
+	})
+}
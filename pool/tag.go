@@ -0,0 +1,178 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTaskCanceled 是 CancelByTag 丢弃排队中的任务的原因，也是执行中的任务 ctx 的取消原因（context.Cause）
+var ErrTaskCanceled = errors.New("task canceled by tag")
+
+func WithTag(tag string) TaskOption { // 为任务打上标签，可以多次使用；同一标签的任务可通过 CancelByTag 一并取消、WaitTag 一并等待
+	return func(t *task) {
+		if t.tag == nil {
+			t.tag = &taskTag{}
+		}
+		t.tag.tags = append(t.tag.tags, tag)
+	}
+}
+
+// taskTag 是带标签的任务在 tagIndex 中的登记，从提交起到执行完或被丢弃为止
+type taskTag struct {
+	tags  []string
+	claim *taskClaim // 用于移除尚未开始的任务
+
+	mu       sync.Mutex
+	cancel   context.CancelCauseFunc // 开始执行后才有
+	canceled bool
+}
+
+// tagIndex 按标签记录尚未结束的任务
+type tagIndex struct {
+	mu sync.Mutex
+	m  map[string]*tagSet
+}
+
+type tagSet struct {
+	entries map[*taskTag]struct{}
+	empty   chan struct{} // WaitTag 等待时创建，最后一个任务结束时关闭
+}
+
+func (x *tagIndex) add(e *taskTag) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.m == nil {
+		x.m = make(map[string]*tagSet)
+	}
+	for _, tag := range e.tags {
+		set := x.m[tag]
+		if set == nil {
+			set = &tagSet{entries: make(map[*taskTag]struct{})}
+			x.m[tag] = set
+		}
+		set.entries[e] = struct{}{}
+	}
+}
+
+func (x *tagIndex) remove(e *taskTag) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, tag := range e.tags {
+		set := x.m[tag]
+		if set == nil {
+			continue
+		}
+		delete(set.entries, e)
+		if len(set.entries) == 0 {
+			if set.empty != nil {
+				close(set.empty)
+			}
+			delete(x.m, tag)
+		}
+	}
+}
+
+func (x *tagIndex) get(tag string) []*taskTag {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	set := x.m[tag]
+	if set == nil {
+		return nil
+	}
+	entries := make([]*taskTag, 0, len(set.entries))
+	for e := range set.entries {
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// wait 返回 tag 的任务全部结束时关闭的 channel
+func (x *tagIndex) wait(tag string) <-chan struct{} {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	set := x.m[tag]
+	if set == nil {
+		return closedChan
+	}
+	if set.empty == nil {
+		set.empty = make(chan struct{})
+	}
+	return set.empty
+}
+
+// tagTask 在提交 t 时登记它的标签，t 被丢弃时撤销；须在 t.claim 确定之后调用
+func (p *Pool) tagTask(t *task) {
+	if t.claim == nil {
+		t.claim = newTaskClaim()
+	}
+	e, c := t.tag, t.claim
+	e.claim = c
+	prev := c.onDiscard
+	c.onDiscard = func(reason error) {
+		p.tags.remove(e)
+		if prev != nil {
+			prev(reason)
+		}
+	}
+	p.tags.add(e)
+}
+
+// start 在任务开始执行时调用，返回可以被 CancelByTag 取消的 ctx
+func (e *taskTag) start(ctx context.Context) context.Context {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ctx, e.cancel = context.WithCancelCause(ctx)
+	if e.canceled {
+		e.cancel(ErrTaskCanceled)
+	}
+	return ctx
+}
+
+// finish 在任务执行完后调用
+func (p *Pool) finishTag(e *taskTag) {
+	p.tags.remove(e)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cancel(nil)
+}
+
+// WaitTag 阻塞直到所有带有 tag 的任务执行完或被丢弃，等待期间新提交的同一标签的任务同样需要等待；
+// ctx 取消时返回 ctx.Err()
+func (p *Pool) WaitTag(ctx context.Context, tag string) error {
+	p = p.applied()
+	select {
+	case <-p.tags.wait(tag):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelByTag 取消所有带有 tag 的任务：尚未开始的任务被移除（以 ErrTaskCanceled 为原因调用 WithOnDiscard 的回调），
+// 执行中的任务 ctx 以 ErrTaskCanceled 取消；返回移除与取消的数量
+func (p *Pool) CancelByTag(tag string) (removed, canceled int) {
+	p = p.applied()
+	for _, e := range p.tags.get(tag) {
+		if p.discard(e.claim, ErrTaskCanceled) {
+			removed++
+			continue
+		}
+		e.mu.Lock()
+		if !e.canceled {
+			e.canceled = true
+			canceled++
+			if e.cancel != nil {
+				e.cancel(ErrTaskCanceled)
+			}
+		}
+		e.mu.Unlock()
+	}
+	return removed, canceled
+}
@@ -0,0 +1,96 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCancelByTag(t *testing.T) {
+	var discarded atomic.Int32
+	p := New(2, WithQueueSize(4), WithLogger(nil), WithOnDiscard(func(reason error) {
+		if errors.Is(reason, ErrTaskCanceled) {
+			discarded.Add(1)
+		}
+	}))
+	defer p.Free()
+	started := make(chan struct{}, 2)
+	causes := make(chan error, 2)
+	running := func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+	}
+	// 两个执行中的任务占满 worker，其后排队的任务中一部分属于 job:42
+	if err := p.ScheduleWith(running, WithTag("job:42")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ScheduleWith(running, WithTag("job:7")); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	<-started
+	var ran atomic.Int32
+	for i := 0; i < 4; i++ {
+		tag := "job:42"
+		if i%2 == 1 {
+			tag = "job:7"
+		}
+		if err := p.ScheduleWith(func(context.Context) { ran.Add(1) }, WithTag(tag), WithTag("all")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removed, canceled := p.CancelByTag("job:42")
+	if removed != 2 || canceled != 1 {
+		t.Fatalf("CancelByTag = (%d, %d), want (2, 1)", removed, canceled)
+	}
+	if err := <-causes; err != ErrTaskCanceled {
+		t.Fatalf("running task canceled with %v, want ErrTaskCanceled", err)
+	}
+	if n := discarded.Load(); n != 2 {
+		t.Fatalf("%d removed tasks reported, want 2", n)
+	}
+	// 空出的 worker 可能已开始执行排队的 job:7 任务，未开始的被移除
+	removed, _ = p.CancelByTag("job:7")
+	<-causes
+	p.Wait()
+	if n := ran.Load() + int32(removed); n != 2 {
+		t.Fatalf("ran %d + removed %d job:7 tasks, want 2", ran.Load(), removed)
+	}
+	if r, c := p.CancelByTag("all"); r != 0 || c != 0 {
+		t.Fatalf("finished tasks still registered: (%d, %d)", r, c)
+	}
+}
+
+func TestWaitTag(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	if err := p.WaitTag(context.Background(), "batch"); err != nil {
+		t.Fatal(err)
+	}
+	gate := make(chan struct{})
+	var done atomic.Int32
+	for i := 0; i < 3; i++ {
+		if err := p.ScheduleWith(func(context.Context) { <-gate; done.Add(1) }, WithTag("batch")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := make(chan struct{})
+	p.ScheduleWith(func(context.Context) { <-other }, WithTag("other"))
+	defer close(other)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.WaitTag(ctx, "batch"); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	close(gate)
+	if err := p.WaitTag(context.Background(), "batch"); err != nil {
+		t.Fatal(err)
+	}
+	if n := done.Load(); n != 3 {
+		t.Fatalf("WaitTag returned after %d of 3 tasks", n)
+	}
+}
@@ -0,0 +1,128 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TaskInfo 是经由 ScheduleWith 提交的任务的元数据：执行时可从任务 ctx 取出（TaskInfoFrom，中间件中同样可用），
+// 出现在该任务的日志中，并随 *TaskError 出现在 ScheduleWith 返回的错误与 WithOnDiscard 收到的原因中
+type TaskInfo struct {
+	ID        uint64            // pool 内唯一的编号，按提交顺序递增
+	Name      string            // WithTaskName
+	Labels    map[string]string // WithTaskLabel
+	Tags      []string          // WithTag
+	Priority  int               // WithPriority
+	Submitted time.Time         // 提交的时刻
+	Deadline  time.Time         // WithDeadline，零值表示没有
+	Attempt   int               // WithAttempt，第几次尝试，从 1 开始
+	Expected  time.Duration     // WithExpectedDuration，预计的执行时长
+	Size      int64             // WithPayloadSize，负载的字节数
+	Cost      int               // WithCost 或 WithCostEstimator 估计的成本
+}
+
+func (info *TaskInfo) String() string {
+	if info.Name == "" {
+		return fmt.Sprintf("task[%d]", info.ID)
+	}
+	return fmt.Sprintf("task[%d %s]", info.ID, info.Name)
+}
+
+// TaskError 为错误附上出错的任务
+type TaskError struct {
+	Info TaskInfo
+	Err  error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("%s: %s", &e.Info, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// TaskOption 是单次提交的选项，见 ScheduleWith
+type TaskOption func(*task)
+
+// ScheduleWith 以 opts 提交 t，其余与 ScheduleFunc 相同；任务带有 TaskInfo，提交失败时返回 *TaskError
+func (p *Pool) ScheduleWith(t TaskFunc, opts ...TaskOption) error {
+	tk := p.taskWith(t, opts)
+	schedule := p.schedule
+	if tk.info.Cost > 1 {
+		schedule = p.scheduleCosted
+	}
+	if err := schedule(tk); err != nil {
+		return &TaskError{Info: *tk.info, Err: err}
+	}
+	return nil
+}
+
+// taskWith 创建以 opts 提交 t 的任务，带有新的 TaskInfo
+func (p *Pool) taskWith(t TaskFunc, opts []TaskOption) task {
+	info := &TaskInfo{ID: p.taskSeq.Add(1), Submitted: p.clock.Now(), Attempt: 1}
+	tk := task{fnc: t, info: info, claim: newTaskClaim()}
+	tk.claim.info = info
+	for _, opt := range opts {
+		opt(&tk)
+	}
+	if tk.tag != nil {
+		info.Tags = tk.tag.tags
+	}
+	p.estimateCost(info)
+	return tk
+}
+
+type taskInfoKey struct{}
+
+// TaskInfoFrom 返回当前任务的 TaskInfo，ctx 不是经由 ScheduleWith 提交的任务收到的 ctx 时 ok 为 false
+func TaskInfoFrom(ctx context.Context) (info TaskInfo, ok bool) {
+	if v, ok := ctx.Value(taskInfoKey{}).(*TaskInfo); ok {
+		return *v, true
+	}
+	return TaskInfo{}, false
+}
+
+func WithTaskName(name string) TaskOption { // 任务的名称，出现在日志与 TaskInfo 中
+	return func(t *task) {
+		t.info.Name = name
+	}
+}
+
+func WithTaskLabel(key, value string) TaskOption { // 为 TaskInfo 添加一个标签，可以多次使用
+	return func(t *task) {
+		if t.info.Labels == nil {
+			t.info.Labels = make(map[string]string)
+		}
+		t.info.Labels[key] = value
+	}
+}
+
+func WithPriority(priority int) TaskOption { // 任务的优先级，开启 WithPriorityScheduling 时生效，见 SchedulePriority
+	return func(t *task) {
+		t.prio = priority
+		t.info.Priority = priority
+	}
+}
+
+func WithDeadline(d time.Time) TaskOption { // 任务 ctx 在 d 到达时取消
+	return func(t *task) {
+		t.info.Deadline = d
+	}
+}
+
+func WithAttempt(n int) TaskOption { // 记录这是第几次尝试，供重试的调用方标注，默认为 1
+	return func(t *task) {
+		t.info.Attempt = n
+	}
+}
+
+// taskContext 为任务 ctx 附上 info，设置了 Deadline 时在其到达时取消
+func taskContext(ctx context.Context, info *TaskInfo) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, taskInfoKey{}, info)
+	if info.Deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, info.Deadline)
+}
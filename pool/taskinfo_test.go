@@ -0,0 +1,53 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskInfo(t *testing.T) {
+	reasons := make(chan error, 1)
+	p, release := newBusyPool(t, WithQueueSize(1), WithBlock(false), WithOnDiscard(func(reason error) { reasons <- reason }))
+	defer p.Free()
+	deadline := time.Now().Add(time.Hour)
+	got := make(chan TaskInfo, 1)
+	err := p.ScheduleWith(func(context.Context) { t.Error("purged task ran") }, WithTaskName("report"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 队列已满，提交失败的错误带有任务信息
+	var te *TaskError
+	err = p.ScheduleWith(func(context.Context) {}, WithTaskName("overflow"))
+	if !errors.As(err, &te) || te.Info.Name != "overflow" || !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want a *TaskError wrapping ErrPoolSaturated", err)
+	}
+
+	p.Purge()
+	reason := <-reasons
+	if !errors.As(reason, &te) || te.Info.Name != "report" || !errors.Is(reason, ErrTaskDiscarded) {
+		t.Fatalf("discard reason = %v, want a *TaskError wrapping ErrTaskDiscarded", reason)
+	}
+
+	release()
+	q := New(1, WithLogger(nil))
+	defer q.Free()
+	if err := q.ScheduleWith(func(ctx context.Context) {
+		info, _ := TaskInfoFrom(ctx)
+		if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+			t.Errorf("task ctx deadline = %v, want %v", d, deadline)
+		}
+		got <- info
+	}, WithTaskName("report"), WithTaskLabel("tenant", "acme"), WithTag("job:1"), WithDeadline(deadline), WithAttempt(2)); err != nil {
+		t.Fatal(err)
+	}
+	info := <-got
+	if info.Name != "report" || info.Labels["tenant"] != "acme" || len(info.Tags) != 1 || info.Attempt != 2 || info.ID == 0 || info.Submitted.IsZero() {
+		t.Fatalf("TaskInfo = %+v", info)
+	}
+	if _, ok := TaskInfoFrom(context.Background()); ok {
+		t.Fatal("TaskInfoFrom outside a task returned ok")
+	}
+}
@@ -0,0 +1,270 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+
+// TenantLimits 是一个租户的配额
+type TenantLimits struct {
+	MaxInflight int // 同时交给 pool 的任务数上限，0 表示不限
+	MaxQueued   int // 等待交给 pool 的任务数上限，超出时 Schedule 返回 ErrTenantQuotaExceeded，0 表示不限
+
+	// 每秒交给 pool 的任务数上限，0 表示不限；超出的任务留在租户的队列中延后分发，不会被拒绝
+	Rate  float64
+	Burst int // Rate 之外允许连续分发的任务数，0 表示 1
+}
+
+// TenantStats 是一个租户的统计
+type TenantStats struct {
+	Inflight  int           // 已交给 pool、尚未结束的任务数
+	Queued    int           // 等待交给 pool 的任务数
+	Completed uint64        // 已执行完的任务数
+	Rejected  uint64        // 超出 MaxQueued 被拒绝的任务数
+	Discarded uint64        // 被接受后未执行即被丢弃（Purge、Free）的任务数
+	Busy      time.Duration // 已执行完的任务累计占用 worker 的时间
+}
+
+// Tenants 按租户对提交到 p 的任务做配额与公平调度：每个租户的任务先在自己的队列中排队，
+// 由一个辅助 goroutine 在有任务可分发的租户之间轮流取出一个交给 pool，已达 MaxInflight 的租户暂不参与，
+// 某个租户大量提交时其它租户的任务仍按轮次得到执行
+// 排队中的任务计入 p.Wait，不受 p.Purge 影响；pool 销毁时经由 WithOnDiscard 报告丢弃
+type Tenants struct {
+	p      *Pool
+	limits TenantLimits // 未单独设置的租户使用的配额
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+	ring    []*tenant // 轮询的顺序，按首次提交的先后
+	next    int       // 下一轮从 ring 中的这个位置开始
+	pumping bool      // 辅助 goroutine 正在运行
+
+	wake chan struct{} // 辅助 goroutine 等待 Rate 的令牌期间有新的任务可分发
+}
+
+type tenant struct {
+	limits TenantLimits
+	queue  []task
+	stats  TenantStats
+
+	tokens float64   // Rate 的令牌桶中剩余的令牌
+	filled time.Time // 上次补充令牌的时刻，零值表示尚未分发过
+}
+
+func NewTenants(p *Pool, limits TenantLimits) *Tenants {
+	return &Tenants{p: p, limits: limits, tenants: make(map[string]*tenant), wake: make(chan struct{}, 1)}
+}
+
+// SetLimits 设置 name 的配额；降低 MaxQueued 不影响已在排队的任务
+func (q *Tenants) SetLimits(name string, limits TenantLimits) {
+	q.mu.Lock()
+	q.get(name).limits = limits
+	q.unlockKick()
+}
+
+// Schedule 以租户 name 的名义提交 t；租户的排队数已达 MaxQueued 时返回 ErrTenantQuotaExceeded，
+// 否则 t 进入租户的队列，立即返回，不因 pool 已满而阻塞
+func (q *Tenants) Schedule(name string, t Task) error {
+	return q.schedule(name, task{fn: t})
+}
+
+// ScheduleFunc 是 TaskFunc 形式的 Schedule
+func (q *Tenants) ScheduleFunc(name string, t TaskFunc) error {
+	return q.schedule(name, task{fnc: t})
+}
+
+// Stats 返回每个租户的统计
+func (q *Tenants) Stats() map[string]TenantStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m := make(map[string]TenantStats, len(q.tenants))
+	for name, tn := range q.tenants {
+		s := tn.stats
+		s.Queued = len(tn.queue)
+		m[name] = s
+	}
+	return m
+}
+
+func (q *Tenants) schedule(name string, t task) error {
+	if !q.p.enterSubmit() {
+		return q.p.freedErr()
+	}
+	defer q.p.submitters.Done()
+	q.mu.Lock()
+	tn := q.get(name)
+	if n := tn.limits.MaxQueued; n > 0 && len(tn.queue) >= n {
+		tn.stats.Rejected++
+		q.mu.Unlock()
+		return ErrTenantQuotaExceeded
+	}
+	q.p.inflight.add(1) // 排队期间代为计入 Wait，交给 pool 后由任务自身计入
+	tn.queue = append(tn.queue, t)
+	q.unlockKick()
+	return nil
+}
+
+// 需持有 q.mu
+func (q *Tenants) get(name string) *tenant {
+	tn := q.tenants[name]
+	if tn == nil {
+		tn = &tenant{limits: q.limits}
+		q.tenants[name] = tn
+		q.ring = append(q.ring, tn)
+	}
+	return tn
+}
+
+// 需持有 q.mu：tn 有任务排队且未达 MaxInflight，不考虑 Rate
+func (tn *tenant) ready() bool {
+	return len(tn.queue) > 0 && (tn.limits.MaxInflight <= 0 || tn.stats.Inflight < tn.limits.MaxInflight)
+}
+
+// 需持有 q.mu：按 Rate 补充令牌后返回还需等待多久才有一个令牌，不限速时为 0
+func (tn *tenant) throttled(now time.Time) time.Duration {
+	rate := tn.limits.Rate
+	if rate <= 0 {
+		return 0
+	}
+	burst := float64(max(tn.limits.Burst, 1))
+	if tn.filled.IsZero() {
+		tn.tokens = burst
+	} else {
+		tn.tokens = min(burst, tn.tokens+now.Sub(tn.filled).Seconds()*rate)
+	}
+	tn.filled = now
+	if tn.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tn.tokens) / rate * float64(time.Second))
+}
+
+// 需持有 q.mu：从下一个轮到的、有任务可分发的租户取出一个任务；没有时返回 nil，
+// 以及因 Rate 而暂不能分发的租户中最早可以分发的时间，没有这样的租户时为 0
+// limited 为 false 时忽略 Rate，用于 pool 销毁时尽快丢弃排队的任务
+func (q *Tenants) pick(limited bool) (*tenant, task, time.Duration) {
+	now := q.p.clock.Now()
+	var wait time.Duration
+	for i := range q.ring {
+		tn := q.ring[(q.next+i)%len(q.ring)]
+		if !tn.ready() {
+			continue
+		}
+		if d := tn.throttled(now); limited && d > 0 {
+			if wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		q.next = (q.next + i + 1) % len(q.ring)
+		t := tn.queue[0]
+		tn.queue[0] = task{}
+		tn.queue = tn.queue[1:]
+		tn.stats.Inflight++
+		tn.tokens--
+		return tn, t, 0
+	}
+	return nil, task{}, wait
+}
+
+// unlockKick 在释放 q.mu 前检查是否有任务可分发，有则启动辅助 goroutine；
+// pool 已开始销毁时改为丢弃所有排队的任务，在释放 q.mu 后报告
+// 调用方可能是任意 goroutine，经由 enterSubmit 确认 Add p.wg 是安全的
+func (q *Tenants) unlockKick() {
+	ready := false
+	for _, tn := range q.ring {
+		ready = ready || tn.ready()
+	}
+	if q.pumping || !ready {
+		if q.pumping && ready {
+			select {
+			case q.wake <- struct{}{}: // 辅助 goroutine 可能在等待 Rate 的令牌
+			default:
+			}
+		}
+		q.mu.Unlock()
+		return
+	}
+	if q.p.enterSubmit() {
+		q.pumping = true
+		q.p.wg.Add(1)
+		go q.pump()
+		q.p.submitters.Done()
+		q.mu.Unlock()
+		return
+	}
+	dropped := 0
+	for _, tn := range q.ring {
+		dropped += len(tn.queue)
+		tn.stats.Discarded += uint64(len(tn.queue))
+		tn.queue = nil
+	}
+	q.mu.Unlock()
+	for ; dropped > 0; dropped-- {
+		q.p.inflight.add(-1)
+		if q.p.onDiscard != nil {
+			q.p.onDiscard(q.p.freedErr())
+		}
+	}
+}
+
+// pump 依次把轮到的任务交给 pool，pool 已满时阻塞等待，只有受 Rate 限制的任务时等待令牌，没有可分发的任务时退出
+func (q *Tenants) pump() {
+	defer q.p.wg.Done()
+	q.p.setLabels(roleHelper)
+	limited := true
+	for {
+		q.mu.Lock()
+		tn, t, wait := q.pick(limited)
+		if tn == nil && wait == 0 {
+			q.pumping = false
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+		if tn == nil {
+			timer := q.p.clock.NewTimer(wait)
+			select {
+			case <-timer.C():
+			case <-q.wake:
+			case <-q.p.closing:
+				limited = false // 之后的提交都会失败，不再等待令牌
+			}
+			timer.Stop()
+			continue
+		}
+		err := q.p.submitOr(nil, q.wrap(tn, t), true, func(error) { q.finish(tn, 0, false) })
+		q.p.inflight.add(-1)
+		if err != nil { // pool 已开始销毁，之后的提交同样失败，排队的任务逐个被丢弃
+			q.finish(tn, 0, false)
+			if q.p.onDiscard != nil {
+				q.p.onDiscard(err)
+			}
+		}
+	}
+}
+
+func (q *Tenants) wrap(tn *tenant, t task) task {
+	return task{fnc: func(ctx context.Context) {
+		start := q.p.clock.Now()
+		defer func() { q.finish(tn, q.p.clock.Since(start), true) }()
+		t.exec(ctx)
+	}}
+}
+
+// finish 在交给 pool 的任务执行完（completed 为 true）或被丢弃后调用，腾出 tn 的 MaxInflight 名额
+func (q *Tenants) finish(tn *tenant, busy time.Duration, completed bool) {
+	q.mu.Lock()
+	tn.stats.Inflight--
+	if completed {
+		tn.stats.Completed++
+		tn.stats.Busy += busy
+	} else {
+		tn.stats.Discarded++
+	}
+	q.unlockKick()
+}
@@ -0,0 +1,145 @@
+package workerpool
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTenantsFairness(t *testing.T) {
+	p, release := newBusyPool(t)
+	defer p.Free()
+	q := NewTenants(p, TenantLimits{MaxQueued: 20})
+	var mu sync.Mutex
+	var order []string
+	run := func(name string) Task {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if err := q.Schedule("noisy", run("noisy")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Schedule("noisy", run("noisy")); err != ErrTenantQuotaExceeded {
+		t.Fatalf("err = %v, want ErrTenantQuotaExceeded", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := q.Schedule("quiet", run("quiet")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	release()
+	p.Wait()
+	// 辅助 goroutine 已取出 noisy 的第一个任务等待 worker，之后两个租户轮流
+	last := 0
+	for i, name := range order {
+		if name == "quiet" {
+			last = i
+		}
+	}
+	if len(order) != 23 || last > 6 {
+		t.Fatalf("quiet tenant's tasks finished at position %d of %v", last, order)
+	}
+	st := q.Stats()
+	if n := st["noisy"]; n.Completed != 20 || n.Rejected != 1 || n.Inflight != 0 || n.Queued != 0 {
+		t.Fatalf("noisy stats = %+v", n)
+	}
+	if n := st["quiet"]; n.Completed != 3 {
+		t.Fatalf("quiet stats = %+v", n)
+	}
+}
+
+func TestTenantsMaxInflight(t *testing.T) {
+	p := New(8, WithLogger(nil))
+	defer p.Free()
+	q := NewTenants(p, TenantLimits{MaxInflight: 2})
+	q.SetLimits("single", TenantLimits{MaxInflight: 1})
+	var running, single peak
+	gate := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		q.Schedule("default", func() {
+			running.inc()
+			defer running.dec()
+			<-gate
+		})
+		q.Schedule("single", func() {
+			single.inc()
+			defer single.dec()
+			<-gate
+		})
+	}
+	close(gate)
+	p.Wait()
+	if m := running.max.Load(); m > 2 {
+		t.Fatalf("%d tasks of a tenant ran at once, limit 2", m)
+	}
+	if m := single.max.Load(); m > 1 {
+		t.Fatalf("%d tasks of a tenant ran at once, limit 1", m)
+	}
+}
+
+func TestTenantsFreeDiscardsQueued(t *testing.T) {
+	var discarded atomic.Int32
+	p, release := newBusyPool(t, WithOnDiscard(func(error) { discarded.Add(1) }))
+	q := NewTenants(p, TenantLimits{})
+	for i := 0; i < 5; i++ {
+		if err := q.Schedule("a", func() { t.Error("task ran after Free") }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Free()
+	release()
+	if n := discarded.Load(); n != 5 {
+		t.Fatalf("%d tasks reported discarded, want 5", n)
+	}
+	if n := q.Stats()["a"]; n.Discarded != 5 || n.Queued != 0 || n.Inflight != 0 {
+		t.Fatalf("stats = %+v", n)
+	}
+	if err := q.Schedule("a", func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("err = %v, want ErrPoolClosed", err)
+	}
+}
+
+// 超出 Rate 的任务留在队列中，令牌补充后再分发，其它租户不受影响
+func TestTenantsRate(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	p := New(4, WithClock(clk), WithLogger(nil))
+	defer p.Free()
+	q := NewTenants(p, TenantLimits{})
+	q.SetLimits("slow", TenantLimits{Rate: 1, Burst: 2})
+	for i := 0; i < 5; i++ {
+		if err := q.Schedule("slow", func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		q.Schedule("fast", func() {})
+	}
+	completed := func(name string, want uint64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for q.Stats()[name].Completed != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s completed %d, want %d", name, q.Stats()[name].Completed, want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	completed("fast", 3)
+	completed("slow", 2)
+	time.Sleep(10 * time.Millisecond)
+	if s := q.Stats()["slow"]; s.Completed != 2 || s.Queued != 3 {
+		t.Fatalf("slow before refill = %+v, want 2 completed and 3 queued", s)
+	}
+	clk.Advance(time.Second)
+	completed("slow", 3)
+	clk.Advance(2 * time.Second)
+	completed("slow", 5)
+	p.Wait()
+}
@@ -0,0 +1,122 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Tier 是 Tiers 中任务的去向
+type Tier int
+
+const (
+	TierSpill Tier = iota // 先交给 warm 层，warm 层已满时交给 cold 层
+	TierWarm              // 只交给 warm 层，已满时按其 WithBlock 等待或失败
+	TierCold              // 只交给 cold 层
+)
+
+func (t Tier) String() string {
+	switch t {
+	case TierSpill:
+		return "spill"
+	case TierWarm:
+		return "warm"
+	case TierCold:
+		return "cold"
+	}
+	return fmt.Sprintf("Tier(%d)", int(t))
+}
+
+// 未设置 TierConfig.ColdIdle 时 cold 层 worker 的空闲时间
+const defaultColdIdle = 30 * time.Second
+
+type TierConfig struct {
+	Warm     int             // warm 层常驻的 worker 数，创建时即启动，供对延迟敏感的任务使用
+	Cold     int             // cold 层 worker 数的上限，按需创建，空闲 ColdIdle 后退出
+	ColdIdle time.Duration   // 默认 30s
+	Routes   map[string]Tier // 任务类别到层的路由
+	Default  Tier            // 未列出的类别的去向，默认 TierSpill
+}
+
+// Tiers 由两个 pool 组成：warm 层的 worker 一直运行，任务无需等待 worker 启动；
+// cold 层在需要时才创建 worker（启动开销见 PoolStats.SpinUp），吸收 warm 层容纳不下的任务，
+// 平时不占用资源。每个任务按类别路由到其中一层
+type Tiers struct {
+	warm, cold *Pool
+	routes     map[string]Tier
+	def        Tier
+}
+
+// TierStats 是两层各自的状态
+type TierStats struct {
+	Warm PoolStats
+	Cold PoolStats
+}
+
+// NewTiers 按 cfg 创建两层 pool，opts 同时应用于两者（WithPreAllocWorkers 与 WithIdleTimeout 由 Tiers 设置）
+func NewTiers(cfg TierConfig, opts ...Option) (*Tiers, error) {
+	if cfg.ColdIdle <= 0 {
+		cfg.ColdIdle = defaultColdIdle
+	}
+	warm, err := NewE(cfg.Warm, append(opts[:len(opts):len(opts)], WithPreAllocWorkers(true))...)
+	if err != nil {
+		return nil, fmt.Errorf("workerpool: warm tier: %w", err)
+	}
+	cold, err := NewE(cfg.Cold, append(opts[:len(opts):len(opts)], WithPreAllocWorkers(false), WithIdleTimeout(cfg.ColdIdle))...)
+	if err != nil {
+		warm.Free()
+		return nil, fmt.Errorf("workerpool: cold tier: %w", err)
+	}
+	return &Tiers{warm: warm, cold: cold, routes: cfg.Routes, def: cfg.Default}, nil
+}
+
+// Warm 返回 warm 层的 pool
+func (t *Tiers) Warm() *Pool { return t.warm }
+
+// Cold 返回 cold 层的 pool
+func (t *Tiers) Cold() *Pool { return t.cold }
+
+// Route 返回类别 class 的去向
+func (t *Tiers) Route(class string) Tier {
+	if tier, ok := t.routes[class]; ok {
+		return tier
+	}
+	return t.def
+}
+
+// Schedule 按类别 class 的路由提交 task
+func (t *Tiers) Schedule(class string, task Task) error {
+	return t.ScheduleFunc(class, func(context.Context) { task() })
+}
+
+// ScheduleFunc 是 TaskFunc 形式的 Schedule
+func (t *Tiers) ScheduleFunc(class string, task TaskFunc) error {
+	switch t.Route(class) {
+	case TierWarm:
+		return t.warm.ScheduleFunc(task)
+	case TierCold:
+		return t.cold.ScheduleFunc(task)
+	}
+	if err := t.warm.TryScheduleFunc(task); !errors.Is(err, ErrPoolSaturated) {
+		return err
+	}
+	return t.cold.ScheduleFunc(task)
+}
+
+// Stats 返回两层各自的状态
+func (t *Tiers) Stats() TierStats {
+	return TierStats{Warm: t.warm.Stats(), Cold: t.cold.Stats()}
+}
+
+// Wait 等待两层已提交的任务都执行完
+func (t *Tiers) Wait() {
+	t.warm.Wait()
+	t.cold.Wait()
+}
+
+// Free 销毁两层 pool
+func (t *Tiers) Free() {
+	t.warm.Free()
+	t.cold.Free()
+}
@@ -0,0 +1,61 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTiersRouting(t *testing.T) {
+	tiers, err := NewTiers(TierConfig{
+		Warm:   1,
+		Cold:   2,
+		Routes: map[string]Tier{"api": TierWarm, "batch": TierCold},
+	}, WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tiers.Free()
+	if s := tiers.Stats(); s.Warm.Workers != 1 || s.Cold.Workers != 0 {
+		t.Fatalf("stats = %+v, want 1 warm worker and no cold worker before any task", s)
+	}
+
+	// batch 只进入 cold 层，并记录 worker 的启动时间
+	if err := tiers.Schedule("batch", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	tiers.Wait()
+	if s := tiers.Stats(); s.Cold.ExecTime.Count != 1 || s.Cold.SpinUp.Count != 1 || s.Warm.ExecTime.Count != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	// 占住 warm 层后，未列出的类别溢出到 cold 层
+	gate, started := make(chan struct{}), make(chan struct{})
+	if err := tiers.Schedule("api", func() { close(started); <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	done := make(chan struct{})
+	if err := tiers.Schedule("misc", func() { close(done) }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done: // warm 层仍被占住，只能在 cold 层执行
+	case <-time.After(5 * time.Second):
+		t.Fatal("spilled task did not run")
+	}
+	close(gate)
+	tiers.Wait()
+	if s := tiers.Stats(); s.Cold.ExecTime.Count != 2 || s.Warm.ExecTime.Count != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestTiersInvalid(t *testing.T) {
+	if _, err := NewTiers(TierConfig{Warm: 1, Cold: 0}); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption", err)
+	}
+	if got := TierCold.String(); got != "cold" {
+		t.Fatalf("TierCold.String() = %q", got)
+	}
+}
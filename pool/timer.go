@@ -0,0 +1,370 @@
+package workerpool
+
+import (
+	"sync"
+	"time"
+)
+
+// TimerHandle 是延迟任务与周期任务的句柄，用于在任务触发前取消它
+type TimerHandle struct {
+	e *timerEntry
+}
+
+type timerEntry struct {
+	p    *Pool
+	t    task
+	id   uint64
+	kind PendingKind
+
+	at time.Time // ScheduleAt 的目标墙上时间，触发时按墙上时间复核
+
+	// 以下仅周期任务使用
+	nextAt func(now time.Time) (time.Time, bool) // 计算下一次触发时间，返回 false 表示不再触发
+	occur  func() (task, bool)                   // 生成本次要投递的任务，返回 false 表示跳过本次
+	reset  func(now time.Time, d time.Duration)  // Reset 时调整周期，仅 ScheduleEvery 使用
+
+	// 在时间轮中的位置，由 timerWheel.mu 保护
+	expire      uint64 // 到期的 tick
+	level, slot int
+	prev, next  *timerEntry
+	linked      bool
+}
+
+// 默认时间轮精度
+const defaultTimerTick = time.Millisecond
+
+// 分层时间轮：共 wheelLevels 层，每层 wheelSlots 个槽，第 L 层每个槽覆盖 wheelSlots^L 个 tick，
+// 1ms 精度下可表示约 34 年的延迟。所有延迟/周期任务共用一个驱动 goroutine 与一个运行时 timer，
+// 添加与取消均为 O(1)，大量待触发任务不会压垮 runtime 的 timer 堆
+const (
+	wheelBits   = 8
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 5
+)
+
+type timerWheel struct {
+	mu      sync.Mutex
+	tick    time.Duration
+	start   time.Time // 第 0 个 tick 对应的时间
+	now     uint64    // 已经处理到的 tick
+	slots   [wheelLevels][wheelSlots]*timerEntry
+	count   int           // 待触发的任务数
+	seq     uint64        // 分配任务 ID
+	wake    chan struct{} // 有新任务加入时唤醒驱动 goroutine 重新计算休眠时间
+	started bool
+	closed  bool
+}
+
+// ScheduleAfter 在 d 之后将 t 提交给 pool 执行，d <= 0 时立即提交；
+// 到期时由 pool 内部异步投递，不会阻塞计时器，也不需要调用方为每个任务启动 goroutine
+func (p *Pool) ScheduleAfter(d time.Duration, t Task) (TimerHandle, error) {
+	p = p.applied()
+	return p.addTimer(&timerEntry{p: p, kind: PendingAfter, t: task{fn: t}}, d)
+}
+
+// ScheduleAt 在墙上时间 when 将 t 提交给 pool 执行，when 已经过去时立即提交
+// 时间轮按单调时钟计时，为了应对系统时间被调整，等待期间会分段按墙上时间复核：
+// 时间被回拨时顺延，被调快时最多延迟剩余等待时间的 1/16（且不少于 1s）后触发
+func (p *Pool) ScheduleAt(when time.Time, t Task) (TimerHandle, error) {
+	p = p.applied()
+	when = when.Round(0) // 去掉单调时钟读数，按墙上时间比较
+	return p.addTimer(&timerEntry{p: p, kind: PendingAt, t: task{fn: t}, at: when}, wallStep(when.Sub(p.clock.Now().Round(0))))
+}
+
+// 距离墙上时间目标还有 r 时，下一次复核前等待的时长
+func wallStep(r time.Duration) time.Duration {
+	if r <= time.Second {
+		return r
+	}
+	if step := r / 16; step > time.Second {
+		return step
+	}
+	return time.Second
+}
+
+func (p *Pool) addTimer(e *timerEntry, d time.Duration) (TimerHandle, error) {
+	w := &p.timers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return TimerHandle{}, p.freedErr()
+	}
+	w.seq++
+	e.id = w.seq
+	if d <= 0 && e.nextAt == nil {
+		// 持锁时 closed 为 false，说明 Free 尚未开始等待 p.wg，此时 dispatchAsync 中的 Add 是安全的
+		p.dispatchAsync(e.t)
+		return TimerHandle{e: e}, nil
+	}
+	w.schedule(e, d)
+	return TimerHandle{e: e}, nil
+}
+
+// ID 返回任务的编号，与 Pending 返回的 PendingTask.ID 对应
+func (h TimerHandle) ID() uint64 {
+	if h.e == nil {
+		return 0
+	}
+	return h.e.id
+}
+
+// Stop 取消尚未触发的延迟任务，返回 true 表示取消成功，
+// 返回 false 表示任务已经触发、已被取消或 pool 已销毁；
+// 对周期任务，Stop 取消之后所有的触发，已投递的执行不受影响
+func (h TimerHandle) Stop() bool {
+	if h.e == nil {
+		return false
+	}
+	w := &h.e.p.timers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !h.e.linked {
+		return false
+	}
+	w.unlink(h.e)
+	return true
+}
+
+// Reset 将任务的下一次触发改为 d 之后，返回任务在调用前是否仍在等待触发
+// 与 time.Timer.Reset 一致，已触发或已 Stop 的任务也会被重新安排；
+// 对 ScheduleAt 的任务，之后按 d 计时而不再对照墙上时间；
+// 对 ScheduleEvery 的任务，周期同时改为 d；对 Cron 的任务，下一次触发后继续按表达式执行
+// pool 已销毁时不做任何事并返回 false
+func (h TimerHandle) Reset(d time.Duration) bool {
+	if h.e == nil {
+		return false
+	}
+	p := h.e.p
+	w := &p.timers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	pending := h.e.linked
+	if pending {
+		w.unlink(h.e)
+	}
+	h.e.at = time.Time{}
+	if h.e.reset != nil {
+		h.e.reset(p.clock.Now(), d)
+	}
+	w.schedule(h.e, d)
+	return pending
+}
+
+// 时间轮驱动 goroutine：休眠到下一个有任务的槽，推进时间轮并投递到期任务
+func (p *Pool) runTimers() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	w := &p.timers
+	for {
+		w.mu.Lock()
+		w.advance(w.tickOf(p.clock.Now()))
+		n, ok := w.nextEvent()
+		w.mu.Unlock()
+
+		var t Timer
+		var c <-chan time.Time
+		if ok {
+			t = p.clock.NewTimer(w.timeOf(n).Sub(p.clock.Now()))
+			c = t.C()
+		}
+		select {
+		case <-c:
+		case <-w.wake:
+		case <-p.quit:
+		}
+		if t != nil {
+			t.Stop()
+		}
+		select {
+		case <-p.quit:
+			return
+		default:
+		}
+	}
+}
+
+// 停止所有尚未触发的延迟任务，之后不再接受新的延迟任务
+func (p *Pool) stopTimers() {
+	w := &p.timers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	for l := range w.slots {
+		for s := range w.slots[l] {
+			for e := w.slots[l][s]; e != nil; e = e.next {
+				e.linked = false
+			}
+			w.slots[l][s] = nil
+		}
+	}
+	w.count = 0
+}
+
+// 以下方法均需持有 w.mu
+
+// 将 e 放入时间轮并唤醒驱动 goroutine，首次使用时启动驱动 goroutine
+// closed 为 false，说明 Free 尚未开始等待 p.wg，此时 Add 是安全的
+func (w *timerWheel) schedule(e *timerEntry, d time.Duration) {
+	p := e.p
+	if !w.started {
+		w.started = true
+		w.start = p.clock.Now()
+		w.wake = make(chan struct{}, 1)
+		p.wg.Add(1)
+		go p.runTimers()
+	}
+	w.insert(e, w.tickAt(p.clock.Now().Add(d)))
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *timerWheel) tickOf(t time.Time) uint64 {
+	if d := t.Sub(w.start); d > 0 {
+		return uint64(d / w.tick)
+	}
+	return 0
+}
+
+// 不早于 t 的第一个 tick
+func (w *timerWheel) tickAt(t time.Time) uint64 {
+	d := t.Sub(w.start)
+	if d <= 0 {
+		return 0
+	}
+	return uint64((d + w.tick - 1) / w.tick)
+}
+
+func (w *timerWheel) timeOf(tick uint64) time.Time {
+	return w.start.Add(time.Duration(tick) * w.tick)
+}
+
+func (w *timerWheel) insert(e *timerEntry, expire uint64) {
+	if expire <= w.now {
+		expire = w.now + 1
+	}
+	// 选择最低的、与当前 tick 处于同一轮次的层；超出最高层范围时放在最高层，级联时重新放置
+	level := 0
+	for level < wheelLevels-1 && (expire^w.now)>>(wheelBits*(level+1)) != 0 {
+		level++
+	}
+	slot := int(expire>>(wheelBits*level)) & wheelMask
+	e.expire, e.level, e.slot = expire, level, slot
+	e.prev, e.next = nil, w.slots[level][slot]
+	if e.next != nil {
+		e.next.prev = e
+	}
+	w.slots[level][slot] = e
+	e.linked = true
+	w.count++
+}
+
+func (w *timerWheel) unlink(e *timerEntry) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		w.slots[e.level][e.slot] = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	}
+	e.prev, e.next, e.linked = nil, nil, false
+	w.count--
+}
+
+// 当前 tick 之后第一个需要处理的 tick：第 0 层有任务的槽，或更高层有任务的槽的级联时刻
+func (w *timerWheel) nextEvent() (uint64, bool) {
+	if w.count == 0 {
+		return 0, false
+	}
+	for level := 0; level < wheelLevels; level++ {
+		shift := uint(wheelBits * level)
+		cur := w.now >> shift
+		// 本层当前轮次中剩余的槽，最高层则扫描一整圈
+		end := cur | wheelMask
+		if level == wheelLevels-1 {
+			end = cur + wheelSlots
+		}
+		for i := cur + 1; i <= end; i++ {
+			if w.slots[level][i&wheelMask] != nil {
+				return i << shift, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// 推进到 target，依次处理期间的级联与到期任务
+func (w *timerWheel) advance(target uint64) {
+	for w.now < target {
+		n, ok := w.nextEvent()
+		if !ok || n > target {
+			w.now = target
+			return
+		}
+		w.now = n
+		// 从高层到低层级联，低层槽为 0 的位置才会发生上一层的级联
+		for level := wheelLevels - 1; level > 0; level-- {
+			shift := uint(wheelBits * level)
+			if n&(1<<shift-1) != 0 {
+				continue
+			}
+			slot := int(n>>shift) & wheelMask
+			e := w.slots[level][slot]
+			w.slots[level][slot] = nil
+			for e != nil {
+				next := e.next
+				e.prev, e.next, e.linked = nil, nil, false
+				w.count--
+				if e.expire <= n {
+					w.fire(e)
+				} else {
+					w.insert(e, e.expire)
+				}
+				e = next
+			}
+		}
+		slot := int(n) & wheelMask
+		e := w.slots[0][slot]
+		w.slots[0][slot] = nil
+		for e != nil {
+			next := e.next
+			e.prev, e.next, e.linked = nil, nil, false
+			w.count--
+			w.fire(e)
+			e = next
+		}
+	}
+}
+
+func (w *timerWheel) fire(e *timerEntry) {
+	if w.closed {
+		return
+	}
+	p := e.p
+	if !e.at.IsZero() {
+		if r := e.at.Sub(p.clock.Now().Round(0)); r > 0 {
+			w.insert(e, w.tickAt(p.clock.Now().Add(wallStep(r))))
+			return
+		}
+	}
+	if e.nextAt != nil {
+		now := p.clock.Now()
+		if at, ok := e.nextAt(now); ok {
+			w.insert(e, w.tickAt(at))
+		}
+	}
+	t, ok := e.t, true
+	if e.occur != nil {
+		t, ok = e.occur()
+	}
+	// 持锁时 closed 为 false，说明 Free 尚未开始等待 p.wg，此时 dispatchAsync 中的 Add 是安全的
+	if ok {
+		p.dispatchAsync(t)
+	}
+}
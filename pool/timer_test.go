@@ -0,0 +1,162 @@
+package workerpool
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// 跨越各层边界的任务都在自己的 tick 触发：级联既不提前也不遗漏
+func TestTimerWheelCascade(t *testing.T) {
+	var expires []uint64
+	for level := 0; level < wheelLevels; level++ {
+		base := uint64(1) << (wheelBits * level)
+		expires = append(expires, base-1, base, base+1, 3*base+7)
+	}
+	expires = append(expires, 1<<(wheelBits*wheelLevels)+5) // 超出最高层范围，需多次级联
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		expires = append(expires, uint64(rng.Int63n(1<<30))+1)
+	}
+
+	for _, steps := range []int{1, 7, 1000} {
+		t.Run(fmt.Sprintf("steps=%d", steps), func(t *testing.T) {
+			w := &timerWheel{tick: time.Millisecond}
+			fired := make(map[*timerEntry]uint64)
+			var last uint64
+			for _, at := range expires {
+				e := &timerEntry{}
+				e.occur = func() (task, bool) {
+					fired[e] = w.now
+					return task{}, false
+				}
+				w.insert(e, at)
+				last = max(last, at)
+			}
+			// 分 steps 次推进到最后一个任务之后，中途的目标落在任意位置
+			for i := 1; i <= steps; i++ {
+				w.advance(last / uint64(steps) * uint64(i))
+			}
+			w.advance(last + 1)
+			if w.count != 0 {
+				t.Fatalf("%d entries left in the wheel", w.count)
+			}
+			if len(fired) != len(expires) {
+				t.Fatalf("fired %d of %d entries", len(fired), len(expires))
+			}
+			for e, at := range fired {
+				if at != e.expire {
+					t.Fatalf("entry due at tick %d fired at tick %d", e.expire, at)
+				}
+			}
+		})
+	}
+}
+
+// 将时钟推进到 ScheduleAfter 的到期时间时任务才被提交
+func TestScheduleAfterFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := New(1, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	ran := make(chan time.Duration, 3)
+	start := clock.Now()
+	for _, d := range []time.Duration{time.Second, time.Minute, time.Hour} {
+		if _, err := p.ScheduleAfter(d, func() { ran <- clock.Since(start) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []time.Duration{time.Second, time.Minute, time.Hour} {
+		clock.BlockUntil(1)
+		clock.Set(start.Add(want))
+		select {
+		case got := <-ran:
+			if got != want {
+				t.Fatalf("task due after %s ran after %s", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("task due after %s did not run", want)
+		}
+	}
+}
+
+// 已有大量待触发任务时，添加与取消一个延迟任务的开销
+func BenchmarkScheduleAfter(b *testing.B) {
+	for _, pending := range []int{100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("pending=%d", pending), func(b *testing.B) {
+			p := New(1, WithLogger(nil))
+			defer p.Free()
+			noop := func() {}
+			for i := 0; i < pending; i++ {
+				if _, err := p.ScheduleAfter(time.Hour+time.Duration(i)*time.Millisecond, noop); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h, err := p.ScheduleAfter(time.Hour+time.Duration(i%pending)*time.Millisecond, noop)
+				if err != nil {
+					b.Fatal(err)
+				}
+				h.Stop()
+			}
+		})
+	}
+}
+
+// 推进时间轮使 b.N 个待触发任务（至少 100k）依次到期时，每个任务的开销，不含任务的执行
+func BenchmarkTimerWheelAdvance(b *testing.B) {
+	n := max(b.N, 100_000)
+	w := &timerWheel{tick: time.Millisecond}
+	skip := func() (task, bool) { return task{}, false }
+	for j := 0; j < n; j++ {
+		w.insert(&timerEntry{occur: skip}, uint64(j)*37+1)
+	}
+	b.ResetTimer()
+	w.advance(uint64(n)*37 + 1)
+	b.StopTimer()
+	if w.count != 0 {
+		b.Fatalf("%d entries left in the wheel", w.count)
+	}
+}
+
+func TestScheduleAt(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1e9, 0))
+	p := New(1, WithClock(clock), WithLogger(nil))
+	defer p.Free()
+	start := clock.Now()
+	ran := make(chan time.Duration, 2)
+	if _, err := p.ScheduleAt(start.Add(time.Hour), func() { ran <- clock.Since(start) }); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(59 * time.Minute) // 分段复核墙上时间，不会提前触发
+	clock.BlockUntil(1)
+	if len(ran) != 0 {
+		t.Fatalf("task due in 1h ran after %s", <-ran)
+	}
+	clock.Advance(time.Minute)
+	if got := <-ran; got != time.Hour {
+		t.Fatalf("task due in 1h ran after %s", got)
+	}
+
+	if _, err := p.ScheduleAt(start, func() { ran <- clock.Since(start) }); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-ran; got != time.Hour { // 已经过去的时刻立即提交
+		t.Fatalf("past task ran after %s", got)
+	}
+}
+
+func TestWallStep(t *testing.T) {
+	for r, want := range map[time.Duration]time.Duration{
+		500 * time.Millisecond: 500 * time.Millisecond,
+		10 * time.Second:       time.Second,
+		time.Hour:              time.Hour / 16,
+	} {
+		if got := wallStep(r); got != want {
+			t.Errorf("wallStep(%s) = %s, want %s", r, got, want)
+		}
+	}
+}
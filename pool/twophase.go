@@ -0,0 +1,19 @@
+package workerpool
+
+import "fmt"
+
+// PreparedTask 是分两阶段执行的任务：Prepare 在提交方的 goroutine 中同步执行（校验参数、复制调用方的状态等），
+// 返回 nil 后 Execute 才会被提交到 worker 上执行
+type PreparedTask interface {
+	Prepare() error
+	Execute()
+}
+
+// SchedulePrepared 先调用 t.Prepare，失败时不提交并返回它的错误，使提交时即可发现的问题直接报告给调用方，
+// 而不是之后在 pool 内部出现；成功后将 t.Execute 以 Schedule 提交
+func (p *Pool) SchedulePrepared(t PreparedTask) error {
+	if err := t.Prepare(); err != nil {
+		return fmt.Errorf("workerpool: prepare: %w", err)
+	}
+	return p.schedule(task{fn: t.Execute})
+}
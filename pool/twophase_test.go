@@ -0,0 +1,29 @@
+package workerpool
+
+import (
+	"errors"
+	"testing"
+)
+
+type preparedTask struct {
+	err      error
+	executed chan struct{}
+}
+
+func (t *preparedTask) Prepare() error { return t.err }
+func (t *preparedTask) Execute()       { close(t.executed) }
+
+func TestSchedulePrepared(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	bad := errors.New("bad input")
+	if err := p.SchedulePrepared(&preparedTask{err: bad}); !errors.Is(err, bad) {
+		t.Fatalf("err = %v, want the Prepare error", err)
+	}
+	p.Wait() // 准备失败的任务没有被提交
+	ok := &preparedTask{executed: make(chan struct{})}
+	if err := p.SchedulePrepared(ok); err != nil {
+		t.Fatal(err)
+	}
+	<-ok.executed
+}
@@ -0,0 +1,122 @@
+package workerpool
+
+import (
+	"context"
+	"runtime"
+)
+
+// 紧急任务：WithReservedWorkers 在 capacity 之外预留若干 worker 的容量，只有 ScheduleUrgent 提交的任务可以使用，
+// pool 被大批任务占满时健康检查、控制面操作等仍能立即执行。紧急任务不进入缓冲、优先级队列与 WithFIFO 的排队：
+// 先交给新的或空闲的普通 worker，没有时在预留的容量上启动一个临时 worker，执行完即退出
+
+// ScheduleUrgent 提交紧急任务 t，未设置 WithReservedWorkers 时等同于 Schedule
+func (p *Pool) ScheduleUrgent(t Task) error {
+	return p.schedule(task{fn: t, urgent: true})
+}
+
+// ScheduleUrgentFunc 是 TaskFunc 形式的 ScheduleUrgent
+func (p *Pool) ScheduleUrgentFunc(t TaskFunc) error {
+	return p.schedule(task{fnc: t, urgent: true})
+}
+
+// submitUrgent 是设置了预留容量时紧急任务的 submit，t 已登记为可丢弃
+func (p *Pool) submitUrgent(ctx context.Context, t task, block bool) error {
+	if p.handOff(t) || p.tryReserved(t) {
+		return nil
+	}
+	if !block {
+		return p.saturatedErr()
+	}
+	if handled, rerr := p.handleReentrant(t); handled {
+		return rerr
+	}
+	unwait, derr := p.enterWait()
+	if derr != nil {
+		return derr
+	}
+	defer unwait()
+	p.blocked.Add(1)
+	defer p.blocked.Add(-1)
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case p.active <- struct{}{}:
+		p.newWorker(&t)
+		return nil
+	case p.handoffChan() <- t:
+		return nil
+	case p.reserved <- struct{}{}:
+		p.runReserved(t)
+		return nil
+	case <-p.closing:
+		return p.freedErr()
+	case <-t.claim.discarded:
+		return ErrTaskDiscarded
+	case <-done:
+		return p.waitErr(ctx)
+	}
+}
+
+// tryOverflow 在 WithOverflow 的容量上启动一个临时 worker 执行 t，对 p.wg 的要求与 tryDispatch 相同
+func (p *Pool) tryOverflow(t task) bool {
+	if p.overflow == nil {
+		return false
+	}
+	select {
+	case p.overflow <- struct{}{}:
+		p.spawnWorker(&t, true)
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Pool) tryReserved(t task) bool {
+	select {
+	case p.reserved <- struct{}{}:
+		p.runReserved(t)
+		return true
+	default:
+		return false
+	}
+}
+
+// runReserved 在已占住的预留容量上启动一个临时 worker 执行 t，对 p.wg 的要求与 tryDispatch 相同
+func (p *Pool) runReserved(t task) {
+	i := int(p.workerSeq.Add(1))
+	p.wg.Add(1)
+	go func() {
+		p.setLabels(roleWorker, LabelWorker, workerLabel(i))
+		if p.lockOSThread {
+			runtime.LockOSThread() // 与普通 worker 相同，退出时不解除绑定
+		}
+		w := &worker{id: i}
+		track := p.reentrant != ReentrantBlock || p.detectDeadlock
+		var gid int64
+		if track {
+			gid = goid()
+			p.workers.add(gid, w)
+		}
+		p.live.Store(i, w)
+		defer func() {
+			if err := recover(); err != nil {
+				p.logf("worker[%03d]: recover panic[%s] in urgent task\n", i, err)
+			}
+			if track {
+				p.workers.remove(gid)
+			}
+			p.live.Delete(i)
+			p.teardownWorker(w)
+			<-p.reserved
+			p.wg.Done()
+		}()
+		if !p.initWorker(w) {
+			p.dispatchAsync(t)
+			return
+		}
+		p.logf("worker[%03d]: run an urgent task\n", i)
+		p.runTask(w, t)
+	}()
+}
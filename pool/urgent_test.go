@@ -0,0 +1,55 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduleUrgentUsesReservedWorkers(t *testing.T) {
+	p, release := newBusyPool(t, WithReservedWorkers(1), WithQueueSize(4))
+	defer p.Free()
+	// 普通任务只能排队，紧急任务在预留的 worker 上立即执行
+	for i := 0; i < 4; i++ {
+		if err := p.Schedule(func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.TryScheduleFunc(func(context.Context) {}); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated", err)
+	}
+	ran := make(chan struct{})
+	if err := p.ScheduleUrgent(func() { close(ran) }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("urgent task did not run on the reserved worker")
+	}
+
+	// 预留的 worker 也被占用时，后来的紧急任务等待其中之一空出
+	gate := make(chan struct{})
+	if err := p.ScheduleUrgent(func() { <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	var second atomic.Bool
+	done := make(chan error)
+	go func() { done <- p.ScheduleUrgent(func() { second.Store(true) }) }()
+	select {
+	case err := <-done:
+		t.Fatalf("urgent submit returned %v while all workers were busy", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(gate)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	release()
+	p.Wait()
+	if !second.Load() {
+		t.Fatal("second urgent task did not run")
+	}
+}
@@ -0,0 +1,185 @@
+package workerpool
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoUtilizationData = errors.New("no utilization data")
+
+// targetUtilization 是 RecommendCapacity 为突发留出余量时的目标利用率
+const targetUtilization = 0.75
+
+// UtilizationSample 是 WithUtilizationRecorder 的一次采样，除 Busy、Queued、Blocked 外均为与上一次采样之间的统计
+type UtilizationSample struct {
+	Time        time.Time
+	Interval    time.Duration // 与上一次采样的间隔
+	Capacity    int
+	Busy        int          // 采样时正在执行任务的 worker 数
+	Utilization float64      // 期间执行完的任务的执行时间之和占 Capacity × Interval 的比例，上限为 1
+	Queued      int          // 采样时排队的任务数
+	Blocked     int          // 采样时阻塞在提交中的提交方数
+	Wait        LatencyStats // 期间开始执行的任务的排队时间，Count 即开始执行的任务数
+	Exec        LatencyStats // 期间执行完的任务的执行时间
+}
+
+// CapacityRecommendation 是 RecommendCapacity 根据采样给出的容量建议
+type CapacityRecommendation struct {
+	Capacity  int           // 建议的容量
+	QueueSize int           // 建议的队列长度（WithQueueSize）
+	Samples   int           // 依据的采样数
+	Window    time.Duration // 采样覆盖的时长
+	Rationale []string      // 得出建议的依据
+}
+
+func (r CapacityRecommendation) String() string {
+	return fmt.Sprintf("capacity %d, queue size %d: %s", r.Capacity, r.QueueSize, strings.Join(r.Rationale, "; "))
+}
+
+type utilizationRecorder struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []UtilizationSample
+	next    int  // 下一个样本写入的位置
+	full    bool // 已写满一轮，next 处是最早的样本
+}
+
+func WithUtilizationRecorder(interval time.Duration, size int) Option { // 每 interval（0 表示 1s）采样一次利用率、排队长度与排队时间，保留最近 size 个样本，供 UtilizationSamples 查看与 RecommendCapacity 给出容量建议
+	return func(p *Pool) {
+		if size <= 0 {
+			p.invalid("WithUtilizationRecorder(%s, %d): size must be positive", interval, size)
+			return
+		}
+		if interval <= 0 {
+			interval = time.Second
+		}
+		p.utilization = &utilizationRecorder{interval: interval, samples: make([]UtilizationSample, size)}
+	}
+}
+
+func (p *Pool) runUtilization() {
+	defer p.wg.Done()
+	p.setLabels(roleHelper)
+	r := p.utilization
+	ticker := p.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+	last := p.clock.Now()
+	waitSnap, execSnap := p.queueWait.snapshot(), p.execTime.snapshot()
+	for {
+		select {
+		case <-ticker.C():
+		case <-p.quit:
+			return
+		}
+		now := p.clock.Now()
+		s := p.stats()
+		sample := UtilizationSample{Time: now, Interval: now.Sub(last), Capacity: s.Capacity, Busy: s.Busy, Queued: s.Queued, Blocked: s.Blocked}
+		sample.Wait, waitSnap = p.queueWait.interval(waitSnap)
+		sample.Exec, execSnap = p.execTime.interval(execSnap)
+		if sample.Capacity > 0 && sample.Interval > 0 {
+			sample.Utilization = min(float64(sample.Exec.Total)/(float64(sample.Capacity)*float64(sample.Interval)), 1)
+		}
+		last = now
+		r.add(sample)
+	}
+}
+
+func (r *utilizationRecorder) add(s UtilizationSample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[r.next] = s
+	r.next++
+	if r.next == len(r.samples) {
+		r.next, r.full = 0, true
+	}
+}
+
+// UtilizationSamples 按时间顺序返回保留的采样；未设置 WithUtilizationRecorder 时返回 nil
+func (p *Pool) UtilizationSamples() []UtilizationSample {
+	p = p.applied()
+	r := p.utilization
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var samples []UtilizationSample
+	if r.full {
+		samples = append(samples, r.samples[r.next:]...)
+	}
+	return append(samples, r.samples[:r.next]...)
+}
+
+// RecommendCapacity 根据保留的采样给出容量与队列长度的建议：以平均执行时间与每段时间的到达数估计需要的 worker 数，
+// 取其 95 分位按 75% 的目标利用率留出余量；队列长度取观察到的积压峰值，不小于建议的容量
+// 未设置 WithUtilizationRecorder、尚无采样或期间没有执行完的任务时返回 ErrNoUtilizationData
+func (p *Pool) RecommendCapacity() (CapacityRecommendation, error) {
+	p = p.applied()
+	samples := p.UtilizationSamples()
+	if len(samples) == 0 {
+		return CapacityRecommendation{}, ErrNoUtilizationData
+	}
+	return recommendCapacity(samples, p.Cap(), p.queueSize)
+}
+
+func recommendCapacity(samples []UtilizationSample, capacity, queueSize int) (CapacityRecommendation, error) {
+	rec := CapacityRecommendation{Samples: len(samples)}
+	var finished uint64
+	var exec time.Duration
+	for _, s := range samples {
+		rec.Window += s.Interval
+		finished += s.Exec.Count
+		exec += s.Exec.Total
+	}
+	if finished == 0 {
+		return rec, fmt.Errorf("%w: no task finished in %s", ErrNoUtilizationData, rec.Window)
+	}
+	mean := exec / time.Duration(finished)
+	demand := make([]float64, 0, len(samples)) // 每段时间需要的 worker 数
+	var saturated, backlogPeak int
+	var worstWait time.Duration
+	for i, s := range samples {
+		backlog := s.Queued + s.Blocked
+		arrivals := float64(s.Wait.Count) // 开始执行的任务加上新增的积压
+		if i > 0 {
+			arrivals += float64(max(backlog-(samples[i-1].Queued+samples[i-1].Blocked), 0))
+		}
+		if s.Interval > 0 {
+			demand = append(demand, arrivals*float64(mean)/float64(s.Interval))
+		}
+		if s.Busy >= s.Capacity && backlog > 0 {
+			saturated++
+		}
+		backlogPeak = max(backlogPeak, backlog)
+		worstWait = max(worstWait, s.Wait.P95)
+	}
+	slices.Sort(demand)
+	peak := 0.0
+	if len(demand) > 0 {
+		peak = demand[int(0.95*float64(len(demand)-1))]
+	}
+	rec.Capacity = max(1, int(math.Ceil(peak/targetUtilization)))
+	rec.QueueSize = max(backlogPeak, rec.Capacity)
+
+	add := func(format string, args ...any) { rec.Rationale = append(rec.Rationale, fmt.Sprintf(format, args...)) }
+	add("%d tasks finished in %s, taking %s on average", finished, rec.Window.Round(time.Millisecond), mean.Round(time.Microsecond))
+	add("p95 demand was %.1f busy workers, %d workers keep utilization near %.0f%% (currently %d)", peak, rec.Capacity, targetUtilization*100, capacity)
+	if saturated > 0 {
+		add("all workers were busy with a backlog in %d of %d samples", saturated, len(samples))
+	}
+	if worstWait > 0 {
+		add("worst p95 queue wait was %s", worstWait.Round(time.Microsecond))
+	}
+	if backlogPeak > 0 {
+		add("backlog peaked at %d, queue size %d absorbs it (currently %d)", backlogPeak, rec.QueueSize, queueSize)
+	} else {
+		add("no backlog was observed, queue size %d leaves headroom for bursts (currently %d)", rec.QueueSize, queueSize)
+	}
+	return rec, nil
+}
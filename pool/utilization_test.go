@@ -0,0 +1,68 @@
+package workerpool
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUtilizationRecorder(t *testing.T) {
+	p := New(2, WithLogger(nil), WithUtilizationRecorder(10*time.Millisecond, 100))
+	defer p.Free()
+	if _, err := p.RecommendCapacity(); !errors.Is(err, ErrNoUtilizationData) {
+		t.Fatalf("before sampling: err = %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := p.Schedule(func() { time.Sleep(2 * time.Millisecond) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+	deadline := time.Now().Add(2 * time.Second)
+	var rec CapacityRecommendation
+	for {
+		var err error
+		if rec, err = p.RecommendCapacity(); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no recommendation: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	samples := p.UtilizationSamples()
+	if len(samples) == 0 || samples[0].Capacity != 2 || rec.Capacity < 1 || rec.Samples != len(samples) || len(rec.Rationale) == 0 {
+		t.Fatalf("rec = %+v, %d samples", rec, len(samples))
+	}
+	plain := New(1, WithLogger(nil))
+	defer plain.Free()
+	if plain.UtilizationSamples() != nil {
+		t.Fatal("samples without WithUtilizationRecorder")
+	}
+}
+
+func TestRecommendCapacity(t *testing.T) {
+	samples := make([]UtilizationSample, 10)
+	for i := range samples {
+		samples[i] = UtilizationSample{
+			Interval: time.Second, Capacity: 2, Busy: 2, Queued: 5,
+			Wait: LatencyStats{Count: 10, P95: 200 * time.Millisecond},
+			Exec: LatencyStats{Count: 10, Total: 3 * time.Second},
+		}
+	}
+	rec, err := recommendCapacity(samples, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Capacity != 4 || rec.QueueSize != 5 || rec.Window != 10*time.Second {
+		t.Fatalf("rec = %+v", rec)
+	}
+	if s := rec.String(); !strings.Contains(s, "capacity 4") || !strings.Contains(s, "busy with a backlog in 10 of 10") {
+		t.Fatalf("String() = %q", s)
+	}
+	idle := []UtilizationSample{{Interval: time.Second, Capacity: 2}}
+	if _, err := recommendCapacity(idle, 2, 0); !errors.Is(err, ErrNoUtilizationData) {
+		t.Fatalf("idle: err = %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ackQueue 在 MemoryQueue 之上记录每次 Ack 的结果
+type ackQueue struct {
+	*MemoryQueue
+	mu   sync.Mutex
+	acks []error
+}
+
+func (q *ackQueue) Ack(ctx context.Context, d Delivery, err error) error {
+	q.mu.Lock()
+	q.acks = append(q.acks, err)
+	q.mu.Unlock()
+	return q.MemoryQueue.Ack(ctx, d, err)
+}
+
+func (q *ackQueue) results() []error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]error(nil), q.acks...)
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	q := &ackQueue{MemoryQueue: NewMemoryQueue()}
+	p := New(2, WithLogger(nil), WithQueue(q), WithVisibilityTimeout(50*time.Millisecond))
+	defer p.Free()
+	release := make(chan struct{})
+	attempts := make(chan int, 2)
+	p.Register("stuck", func(ctx context.Context, payload []byte) error {
+		n := JobAttempt(ctx)
+		attempts <- n
+		if n == 1 {
+			<-release // 第一次执行卡住，超时后交给另一个 worker
+		}
+		return nil
+	})
+	if err := p.Enqueue(Job{Type: "stuck"}); err != nil {
+		t.Fatal(err)
+	}
+	for want := 1; want <= 2; want++ {
+		select {
+		case n := <-attempts:
+			if n != want {
+				t.Fatalf("delivery %d has attempt %d", want, n)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("delivery %d not started", want)
+		}
+	}
+	eventually(t, "the redelivery to be acked", func() bool { return len(q.results()) == 2 })
+	close(release)
+	time.Sleep(20 * time.Millisecond) // 超时的执行结束后结果作废，不再 Ack
+	acks := q.results()
+	if len(acks) != 2 || !errors.Is(acks[0], ErrAckTimeout) || acks[1] != nil {
+		t.Fatalf("acks = %v, want [%v <nil>]", acks, ErrAckTimeout)
+	}
+}
+
+func TestVisibilityTimeoutInTime(t *testing.T) {
+	q := &ackQueue{MemoryQueue: NewMemoryQueue()}
+	p := New(1, WithLogger(nil), WithQueue(q), WithVisibilityTimeout(time.Minute))
+	defer p.Free()
+	boom := errors.New("boom")
+	p.Register("ok", func(context.Context, []byte) error { return nil })
+	p.Register("fail", func(context.Context, []byte) error { return boom })
+	p.Enqueue(Job{Type: "ok"})
+	p.Enqueue(Job{Type: "fail"})
+	eventually(t, "both jobs to be acked", func() bool { return len(q.results()) == 2 })
+	if acks := q.results(); acks[0] != nil || !errors.Is(acks[1], boom) {
+		t.Fatalf("acks = %v", acks)
+	}
+	if n, _ := q.Len(context.Background()); n != 0 {
+		t.Fatalf("%d jobs redelivered", n)
+	}
+}
+
+func TestVisibilityTimeoutNegative(t *testing.T) {
+	if _, err := NewE(1, WithVisibilityTimeout(-time.Second)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption", err)
+	}
+}
@@ -0,0 +1,102 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNoFutures = errors.New("no futures")
+
+// WaitAll 等待 fs 全部结束，按顺序返回结果；失败的 Future 对应零值，
+// 错误以 "item i: ..." 的形式合并返回。ctx 取消时返回 ctx 的错误，任务本身不受影响
+func WaitAll[R any](ctx context.Context, fs ...*Future[R]) ([]R, error) {
+	out := make([]R, len(fs))
+	errs := make([]error, len(fs))
+	for i, f := range fs {
+		v, err := f.WaitContext(ctx)
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		if err != nil {
+			errs[i] = err
+		} else {
+			out[i] = v
+		}
+	}
+	return out, joinItemErrors(errs)
+}
+
+// CollectAll 等待 fs 全部结束，按顺序返回各自的结果与错误：vals[i] 与 errs[i] 对应 fs[i]
+func CollectAll[R any](fs ...*Future[R]) (vals []R, errs []error) {
+	return CollectAllContext(context.Background(), fs...)
+}
+
+// CollectAllContext 与 CollectAll 相同，但 ctx 取消后不再等待：已结束的 Future 照常给出结果，
+// 其余的错误为 ctx.Err()，任务本身不受影响
+func CollectAllContext[R any](ctx context.Context, fs ...*Future[R]) (vals []R, errs []error) {
+	vals, errs = make([]R, len(fs)), make([]error, len(fs))
+	for i, f := range fs {
+		select {
+		case <-f.done: // ctx 已取消时也优先取已有的结果
+			vals[i], errs[i] = f.val, f.err
+		default:
+			vals[i], errs[i] = f.WaitContext(ctx)
+		}
+	}
+	return vals, errs
+}
+
+// WaitAny 返回最先成功的结果并取消其余的 Future，适用于对冲请求；
+// 全部失败时返回合并后的错误，ctx 取消时取消全部 Future 并返回 ctx 的错误
+func WaitAny[R any](ctx context.Context, fs ...*Future[R]) (R, error) {
+	vs, err := WaitN(ctx, 1, fs...)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+	return vs[0], nil
+}
+
+// WaitN 等待最先成功的 n 个结果（按成功的先后顺序）并取消其余的 Future，适用于 quorum 读；
+// 失败的数量多到不可能凑够 n 个时提前返回合并后的错误，ctx 取消时取消全部 Future 并返回 ctx 的错误
+func WaitN[R any](ctx context.Context, n int, fs ...*Future[R]) ([]R, error) {
+	if len(fs) == 0 {
+		return nil, ErrNoFutures
+	}
+	if n <= 0 || n > len(fs) {
+		return nil, fmt.Errorf("workerpool: WaitN needs 1 <= n <= %d, got %d", len(fs), n)
+	}
+	done := make(chan int, len(fs))
+	for i, f := range fs {
+		f.onDone(func() { done <- i })
+	}
+	cancelAll := func() {
+		for _, f := range fs {
+			f.Cancel()
+		}
+	}
+	out := make([]R, 0, n)
+	errs := make([]error, len(fs))
+	failed := 0
+	for len(out) < n {
+		select {
+		case i := <-done:
+			v, err := fs[i].Wait()
+			if err == nil {
+				out = append(out, v)
+				continue
+			}
+			errs[i] = err
+			if failed++; len(fs)-failed < n {
+				cancelAll()
+				return nil, joinItemErrors(errs)
+			}
+		case <-ctx.Done():
+			cancelAll()
+			return nil, ctx.Err()
+		}
+	}
+	cancelAll()
+	return out, nil
+}
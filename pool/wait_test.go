@@ -0,0 +1,123 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectAll(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	failed := errors.New("failed")
+	var fs []*Future[int]
+	for i := 0; i < 4; i++ {
+		f, err := Submit(p, func(context.Context) (int, error) {
+			if i == 2 {
+				return 0, failed
+			}
+			return i + 1, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, f)
+	}
+	vals, errs := CollectAll(fs...)
+	for i := range fs {
+		switch {
+		case i == 2 && !errors.Is(errs[i], failed):
+			t.Fatalf("errs[2] = %v", errs[i])
+		case i != 2 && (errs[i] != nil || vals[i] != i+1):
+			t.Fatalf("vals[%d] = %d, %v", i, vals[i], errs[i])
+		}
+	}
+}
+
+func TestCollectAllContext(t *testing.T) {
+	done, pending := newFuture[int](), newFuture[int]()
+	done.resolve(7, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	vals, errs := CollectAllContext(ctx, done, pending)
+	if vals[0] != 7 || errs[0] != nil || !errors.Is(errs[1], context.Canceled) {
+		t.Fatalf("vals %v errs %v", vals, errs)
+	}
+}
+
+// 以 v、err 解析的 Future
+func resolved[R any](v R, err error) *Future[R] {
+	f := newFuture[R]()
+	f.resolve(v, err)
+	return f
+}
+
+func TestWaitAll(t *testing.T) {
+	boom := errors.New("boom")
+	vals, err := WaitAll(context.Background(), resolved(1, nil), resolved(2, boom), resolved(3, nil))
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "item 1") || !slices.Equal(vals, []int{1, 0, 3}) {
+		t.Fatalf("WaitAll = %v, %v", vals, err)
+	}
+	if vals, err := WaitAll(context.Background(), resolved(1, nil), resolved(2, nil)); err != nil || !slices.Equal(vals, []int{1, 2}) {
+		t.Fatalf("WaitAll = %v, %v", vals, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WaitAll(ctx, newFuture[int]()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitAll after cancel = %v", err)
+	}
+}
+
+func TestWaitAny(t *testing.T) {
+	p := New(3, WithLogger(nil))
+	defer p.Free()
+	boom := errors.New("boom")
+	fast, _ := Submit(p, func(ctx context.Context) (int, error) { return 1, nil })
+	failing, _ := Submit(p, func(ctx context.Context) (int, error) { return 0, boom })
+	slow, _ := Submit(p, func(ctx context.Context) (int, error) {
+		<-ctx.Done() // 只有被取消才结束
+		return 0, ctx.Err()
+	})
+	if v, err := WaitAny(context.Background(), failing, slow, fast); v != 1 || err != nil {
+		t.Fatalf("WaitAny = %d, %v", v, err)
+	}
+	select { // 未开始时被移除，已开始时 ctx 被取消
+	case <-slow.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slower future was not canceled")
+	}
+
+	_, err := WaitAny(context.Background(), resolved(0, boom), resolved(0, boom))
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "item 1") {
+		t.Fatalf("WaitAny with all failed = %v", err)
+	}
+	if _, err := WaitAny[int](context.Background()); !errors.Is(err, ErrNoFutures) {
+		t.Fatalf("WaitAny of nothing = %v", err)
+	}
+}
+
+func TestWaitN(t *testing.T) {
+	boom := errors.New("boom")
+	pending := newFuture[int]()
+	vals, err := WaitN(context.Background(), 2, resolved(1, nil), resolved(0, boom), resolved(3, nil), pending)
+	if err != nil || len(vals) != 2 || !slices.Contains(vals, 1) || !slices.Contains(vals, 3) {
+		t.Fatalf("WaitN = %v, %v", vals, err)
+	}
+
+	// 失败的数量多到凑不够 n 个时不必等待未结束的 Future
+	if _, err := WaitN(context.Background(), 2, resolved(0, boom), resolved(0, boom), newFuture[int]()); !errors.Is(err, boom) {
+		t.Fatalf("WaitN without a quorum = %v", err)
+	}
+	if _, err := WaitN(context.Background(), 3, resolved(1, nil)); err == nil {
+		t.Fatal("WaitN with n > len(fs) succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WaitN(ctx, 1, newFuture[int]()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitN after cancel = %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package workerpool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// WAL 文件由一条条记录组成：[4 字节长度][4 字节 crc32][body]，body 的第一个字节为记录类型
+//
+//	walAdd:  [1][8 字节 id][2 字节类型名长度][类型名][payload]
+//	walDone: [2][8 字节 id]
+//	walAddKey: [3][8 字节 id][2 字节类型名长度][类型名][2 字节幂等键长度][幂等键][payload]，带 Key 的 Job
+//
+// 末尾不完整或校验失败的记录视为崩溃时未写完，打开时截断
+const (
+	walAdd    byte = 1
+	walDone   byte = 2
+	walAddKey byte = 3
+
+	walCompactMin = 1024 // 至少有这么多条已完成记录才考虑压缩
+)
+
+var ErrWALClosed = errors.New("wal closed")
+
+type wal struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	w       *bufio.Writer
+	nextID  uint64
+	pending map[uint64]Job // 已写入但尚未完成的 Job
+	done    int            // 上次压缩以来写入的 walDone 记录数
+	replay  []uint64       // 打开时尚未完成的 Job，按 id 排序，等待 Recover 取走
+	err     error          // 打开或写入失败后的错误，之后的 Enqueue 都返回它
+
+	logf func(format string, args ...any) // 所属 pool 的日志输出，见 WithLogger
+}
+
+// 打开 path 处的 WAL，不存在时创建，并读出其中尚未完成的 Job
+func openWAL(path string) (*wal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	l := &wal{path: path, f: f, pending: make(map[uint64]Job)}
+	size := l.load()
+	for id := range l.pending {
+		l.replay = append(l.replay, id)
+	}
+	slices.Sort(l.replay)
+	err = f.Truncate(size)
+	if err == nil {
+		_, err = f.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("wal %s: %w", path, err)
+	}
+	l.w = bufio.NewWriter(f)
+	return l, nil
+}
+
+// load 重放所有记录，返回最后一条完整记录的结束位置
+func (l *wal) load() int64 {
+	r := bufio.NewReader(l.f)
+	var off int64
+	for {
+		body, n, err := readWALRecord(r)
+		if err != nil {
+			return off // 文件结束，或崩溃时未写完的尾部
+		}
+		off += n
+		id := binary.BigEndian.Uint64(body[1:9])
+		l.nextID = max(l.nextID, id)
+		switch body[0] {
+		case walAdd, walAddKey:
+			l.pending[id] = walAddJob(body)
+		case walDone:
+			delete(l.pending, id)
+			l.done++
+		}
+	}
+}
+
+func readWALRecord(r io.Reader) (body []byte, n int64, err error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, 0, err
+	}
+	size := binary.BigEndian.Uint32(hdr[:4])
+	if size < 9 || size > 1<<30 {
+		return nil, 0, errors.New("bad record size")
+	}
+	body = make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(hdr[4:]) {
+		return nil, 0, errors.New("bad record checksum")
+	}
+	if !validAddRecord(body) {
+		return nil, 0, errors.New("bad add record")
+	}
+	return body, int64(len(hdr)) + int64(size), nil
+}
+
+// validAddRecord 检查 walAdd 与 walAddKey 记录的长度字段不超出 body，其它类型的记录不做检查
+func validAddRecord(body []byte) bool {
+	switch body[0] {
+	case walAdd:
+		return len(body) >= 11 && int(binary.BigEndian.Uint16(body[9:11])) <= len(body)-11
+	case walAddKey:
+		if len(body) < 13 {
+			return false
+		}
+		tl := int(binary.BigEndian.Uint16(body[9:11]))
+		if tl > len(body)-13 {
+			return false
+		}
+		return int(binary.BigEndian.Uint16(body[11+tl:13+tl])) <= len(body)-13-tl
+	}
+	return true
+}
+
+// checkAddJob 检查 j 的类型名与幂等键能否以 walAddBody 的 2 字节长度编码
+func checkAddJob(j Job) error {
+	if len(j.Type) > 1<<16-1 {
+		return fmt.Errorf("job type too long: %d bytes", len(j.Type))
+	}
+	if len(j.Key) > 1<<16-1 {
+		return fmt.Errorf("job key too long: %d bytes", len(j.Key))
+	}
+	return nil
+}
+
+func writeWALRecord(w io.Writer, body []byte) error {
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(len(body)))
+	binary.BigEndian.PutUint32(hdr[4:], crc32.ChecksumIEEE(body))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+func walAddBody(id uint64, j Job) []byte {
+	body := make([]byte, 11, 13+len(j.Type)+len(j.Key)+len(j.Payload))
+	body[0] = walAdd
+	binary.BigEndian.PutUint64(body[1:9], id)
+	binary.BigEndian.PutUint16(body[9:11], uint16(len(j.Type)))
+	body = append(body, j.Type...)
+	if j.Key != "" {
+		body[0] = walAddKey
+		body = binary.BigEndian.AppendUint16(body, uint16(len(j.Key)))
+		body = append(body, j.Key...)
+	}
+	return append(body, j.Payload...)
+}
+
+// walAddJob 解析 walAddBody 编码的记录
+func walAddJob(body []byte) Job {
+	tl := int(binary.BigEndian.Uint16(body[9:11]))
+	if body[0] == walAdd {
+		return Job{Type: string(body[11 : 11+tl]), Payload: body[11+tl:]}
+	}
+	kl := int(binary.BigEndian.Uint16(body[11+tl : 13+tl]))
+	return Job{Type: string(body[11 : 11+tl]), Key: string(body[13+tl : 13+tl+kl]), Payload: body[13+tl+kl:]}
+}
+
+// append 写入一条 walAdd 记录并落盘，返回分配给该 Job 的 id
+func (l *wal) append(j Job) (uint64, error) {
+	if err := checkAddJob(j); err != nil {
+		return 0, fmt.Errorf("wal: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return 0, l.err
+	}
+	l.nextID++
+	id := l.nextID
+	err := writeWALRecord(l.w, walAddBody(id, j))
+	if err == nil {
+		err = l.w.Flush()
+	}
+	if err == nil {
+		err = l.f.Sync()
+	}
+	if err != nil {
+		l.err = fmt.Errorf("wal %s: %w", l.path, err)
+		return 0, l.err
+	}
+	l.pending[id] = j
+	return id, nil
+}
+
+// complete 写入一条 walDone 记录，不立即落盘：崩溃时丢失的完成记录只会导致该 Job 被重复执行
+func (l *wal) complete(id uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return
+	}
+	delete(l.pending, id)
+	var body [9]byte
+	body[0] = walDone
+	binary.BigEndian.PutUint64(body[1:], id)
+	if err := writeWALRecord(l.w, body[:]); err != nil {
+		l.err = fmt.Errorf("wal %s: %w", l.path, err)
+		return
+	}
+	if err := l.w.Flush(); err != nil {
+		l.err = fmt.Errorf("wal %s: %w", l.path, err)
+		return
+	}
+	if l.done++; l.done >= walCompactMin && l.done > 2*len(l.pending) {
+		if err := l.compact(); err != nil {
+			l.logf("wal %s: compact failed: %s\n", l.path, err)
+		}
+	}
+}
+
+// compact 将尚未完成的 Job 写入新文件后原子地替换旧文件，需持有 l.mu
+func (l *wal) compact() error {
+	tmp := l.path + ".compact"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for id, j := range l.pending {
+		if err = writeWALRecord(w, walAddBody(id, j)); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, l.path)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	l.f.Close()
+	l.f, l.w, l.done = f, bufio.NewWriter(f), 0
+	return nil
+}
+
+// takeReplay 取走打开时尚未完成的 Job，只有第一次调用返回非空
+func (l *wal) takeReplay() ([]uint64, []Job) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ids := l.replay
+	l.replay = nil
+	jobs := make([]Job, 0, len(ids))
+	for _, id := range ids {
+		jobs = append(jobs, l.pending[id])
+	}
+	return ids, jobs
+}
+
+func (l *wal) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil { // 打开失败
+		return nil
+	}
+	if l.err == nil {
+		l.err = ErrWALClosed
+		if err := l.w.Flush(); err != nil {
+			l.f.Close()
+			return err
+		}
+		if err := l.f.Sync(); err != nil {
+			l.f.Close()
+			return err
+		}
+	}
+	return l.f.Close()
+}
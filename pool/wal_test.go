@@ -0,0 +1,108 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWALKeyTooLong(t *testing.T) {
+	l, err := openWAL(filepath.Join(t.TempDir(), "jobs.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+	if _, err := l.append(Job{Type: "t", Key: strings.Repeat("k", 70000), Payload: []byte("p")}); err == nil || !strings.Contains(err.Error(), "key too long") {
+		t.Fatalf("70000-byte key: err = %v", err)
+	}
+	if _, err := l.append(Job{Type: strings.Repeat("t", 70000)}); err == nil {
+		t.Fatal("70000-byte type accepted")
+	}
+}
+
+func TestWALReplaysKeyedJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.wal")
+	l, err := openWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Job{
+		{Type: "a", Payload: []byte("1")},
+		{Type: "b", Key: strings.Repeat("k", 1<<16-1), Payload: []byte("2")},
+		{Type: "c", Key: "done"},
+	}
+	var ids []uint64
+	for _, j := range want {
+		id, err := l.append(j)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	l.complete(ids[2])
+	if err := l.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = openWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+	_, jobs := l.takeReplay()
+	if len(jobs) != 2 {
+		t.Fatalf("replayed %d jobs", len(jobs))
+	}
+	for i, j := range jobs {
+		if j.Type != want[i].Type || j.Key != want[i].Key || !bytes.Equal(j.Payload, want[i].Payload) {
+			t.Fatalf("job %d = %s/%d-byte key/%q", i, j.Type, len(j.Key), j.Payload)
+		}
+	}
+}
+
+func TestReadWALRecordRejectsBadKeyLength(t *testing.T) {
+	body := walAddBody(1, Job{Type: "t", Key: "key", Payload: []byte("p")})
+	binary.BigEndian.PutUint16(body[12:14], 100) // 幂等键长度超出记录
+	var buf bytes.Buffer
+	if err := writeWALRecord(&buf, body); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readWALRecord(&buf); err == nil {
+		t.Fatal("walAddKey record with an out-of-range key length accepted")
+	}
+}
+
+func TestRecoverRunsPendingJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.wal")
+	l, err := openWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range []string{"x", "y"} {
+		if _, err := l.append(Job{Type: "echo", Payload: []byte(payload)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := l.append(Job{Type: "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+	l.close()
+
+	p := New(1, WithLogger(nil), WithWAL(path))
+	defer p.Free()
+	got := make(chan string, 2)
+	p.Register("echo", func(_ context.Context, payload []byte) error {
+		got <- string(payload)
+		return nil
+	})
+	n, err := p.Recover(func(j Job) bool { return false })
+	if n != 2 || err == nil {
+		t.Fatalf("Recover = %d, %v", n, err)
+	}
+	if a, b := <-got, <-got; a != "x" || b != "y" {
+		t.Fatalf("recovered in order %s, %s", a, b)
+	}
+}
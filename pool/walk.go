@@ -0,0 +1,183 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+)
+
+// WalkDirFunc 是 WalkDir 对每个文件与目录调用的函数，参数与 fs.WalkDirFunc 相同：
+// 读取目录失败时以同一目录和该错误再调用一次；返回 fs.SkipDir 跳过该目录，返回 fs.SkipAll 结束遍历
+type WalkDirFunc func(ctx context.Context, path string, d fs.DirEntry, err error) error
+
+// errWalkStopped 是 fn 返回 fs.SkipAll 时取消遍历所用的原因，不作为错误返回
+var errWalkStopped = errors.New("walk stopped")
+
+// WalkDir 在 p 上并发遍历以 root 为根的目录树，对每个文件与目录（含 root）调用 fn：
+// 每个条目是一个任务，同时执行的数量受 p 的容量约束，同一目录的条目会并发处理，fn 须可并发调用，
+// 只保证对目录的调用先于其中的条目；遍历在调用方的 goroutine 上提交任务，不会因任务内提交子目录而死锁
+// fn 返回的错误（panic 转换为 *PanicError）不会中止遍历，全部结束后合并返回；需要遇错即停时返回 fs.SkipAll 或取消 ctx
+// ctx 取消、提交失败（如 pool 已销毁）或条目被丢弃时不再提交新的条目，等待已提交的结束后返回该原因与已收集的错误
+func WalkDir(ctx context.Context, p *Pool, root string, fn WalkDirFunc) error {
+	stat := func(name string) (fs.DirEntry, error) {
+		info, err := os.Lstat(name)
+		if err != nil {
+			return nil, err
+		}
+		return fs.FileInfoToDirEntry(info), nil
+	}
+	return walkDir(ctx, p, root, stat, os.ReadDir, filepath.Join, fn)
+}
+
+// WalkDirFS 与 WalkDir 相同，遍历的是 fsys 中以 root 为根的目录树，路径以 / 分隔
+func WalkDirFS(ctx context.Context, p *Pool, fsys fs.FS, root string, fn WalkDirFunc) error {
+	stat := func(name string) (fs.DirEntry, error) {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		return fs.FileInfoToDirEntry(info), nil
+	}
+	readDir := func(name string) ([]fs.DirEntry, error) { return fs.ReadDir(fsys, name) }
+	return walkDir(ctx, p, root, stat, readDir, path.Join, fn)
+}
+
+type walkEntry struct {
+	path string
+	d    fs.DirEntry
+}
+
+type walker struct {
+	ctx     context.Context
+	readDir func(string) ([]fs.DirEntry, error)
+	join    func(elem ...string) string
+	fn      WalkDirFunc
+	stop    context.CancelCauseFunc
+
+	mu      sync.Mutex
+	pending []walkEntry // 已发现尚未提交的条目，后进先出以限制其数量
+	running int         // 已提交尚未结束的条目数
+	errs    []error
+	wake    chan struct{} // 有新的条目或有条目结束
+}
+
+func walkDir(ctx context.Context, p *Pool, root string, stat func(string) (fs.DirEntry, error), readDir func(string) ([]fs.DirEntry, error), join func(...string) string, fn WalkDirFunc) error {
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	w := &walker{ctx: ctx, readDir: readDir, join: join, fn: fn, stop: stop, wake: make(chan struct{}, 1)}
+	d, err := stat(root)
+	if err != nil {
+		w.visit(walkEntry{path: root}, err)
+		return w.result()
+	}
+	w.pending = append(w.pending, walkEntry{path: root, d: d})
+	for {
+		w.mu.Lock()
+		if ctx.Err() != nil {
+			w.pending = nil
+		}
+		if len(w.pending) == 0 {
+			done := w.running == 0
+			w.mu.Unlock()
+			if done {
+				break
+			}
+			<-w.wake
+			continue
+		}
+		e := w.pending[len(w.pending)-1]
+		w.pending = w.pending[:len(w.pending)-1]
+		w.running++
+		w.mu.Unlock()
+		err := p.submitOr(ctx, task{fn: func() {
+			defer w.finish()
+			if ctx.Err() == nil {
+				w.visit(e, nil)
+			}
+		}}, true, func(reason error) {
+			stop(reason)
+			w.finish()
+		})
+		if err != nil {
+			stop(err)
+			w.finish()
+		}
+	}
+	return w.result()
+}
+
+// visit 对 e 调用 fn，e 是目录时读取其中的条目加入 pending
+func (w *walker) visit(e walkEntry, statErr error) {
+	if w.call(e.path, e.d, statErr) || statErr != nil || !e.d.IsDir() {
+		return
+	}
+	entries, err := w.readDir(e.path)
+	if err != nil && w.call(e.path, e.d, err) {
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+	w.mu.Lock()
+	for i := len(entries) - 1; i >= 0; i-- { // 倒序加入，出栈时按名称顺序提交
+		w.pending = append(w.pending, walkEntry{path: w.join(e.path, entries[i].Name()), d: entries[i]})
+	}
+	w.mu.Unlock()
+	w.notify()
+}
+
+// call 调用 fn 并处理其结果，返回 true 表示不再深入该条目
+func (w *walker) call(name string, d fs.DirEntry, err error) (skip bool) {
+	defer func() {
+		if x := recover(); x != nil {
+			w.record(&PanicError{Value: x, Stack: debug.Stack()})
+			skip = true
+		}
+	}()
+	switch err := w.fn(w.ctx, name, d, err); {
+	case err == nil:
+		return false
+	case errors.Is(err, fs.SkipDir):
+		return true
+	case errors.Is(err, fs.SkipAll):
+		w.stop(errWalkStopped)
+		return true
+	default:
+		w.record(err)
+		return true
+	}
+}
+
+func (w *walker) record(err error) {
+	w.mu.Lock()
+	w.errs = append(w.errs, err)
+	w.mu.Unlock()
+}
+
+// finish 在一个已提交的条目结束、被丢弃或提交失败时调用，每个条目只调用一次
+func (w *walker) finish() {
+	w.mu.Lock()
+	w.running--
+	w.mu.Unlock()
+	w.notify()
+}
+
+func (w *walker) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *walker) result() error {
+	errs := w.errs
+	if cause := context.Cause(w.ctx); cause != nil && cause != errWalkStopped {
+		errs = append([]error{cause}, errs...)
+	}
+	return errors.Join(errs...)
+}
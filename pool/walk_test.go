@@ -0,0 +1,109 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWalkDirFS(t *testing.T) {
+	p := New(3, WithLogger(nil))
+	defer p.Free()
+	fsys := fstest.MapFS{
+		"a/1.txt":       {},
+		"a/2.txt":       {},
+		"a/b/3.txt":     {},
+		"skip/4.txt":    {},
+		"bad/5.txt":     {},
+		"top.txt":       {},
+		"a/b/c/6.txt":   {},
+		"a/b/c/7.txt":   {},
+		"panic/8.txt":   {},
+		"panic/9.txt":   {},
+		"a/b/c/d/.keep": {},
+	}
+	errBad := errors.New("bad file")
+	var mu sync.Mutex
+	var seen []string
+	err := WalkDirFS(context.Background(), p, fsys, ".", func(_ context.Context, path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		seen = append(seen, path)
+		mu.Unlock()
+		switch path {
+		case "skip":
+			return fs.SkipDir
+		case "bad/5.txt":
+			return errBad
+		case "panic/8.txt":
+			panic("boom")
+		}
+		return nil
+	})
+	var pe *PanicError
+	if !errors.Is(err, errBad) || !errors.As(err, &pe) {
+		t.Fatalf("err = %v", err)
+	}
+	slices.Sort(seen)
+	want := []string{".", "a", "a/1.txt", "a/2.txt", "a/b", "a/b/3.txt", "a/b/c", "a/b/c/6.txt", "a/b/c/7.txt", "a/b/c/d", "a/b/c/d/.keep",
+		"bad", "bad/5.txt", "panic", "panic/8.txt", "panic/9.txt", "skip", "top.txt"}
+	if !slices.Equal(seen, want) {
+		t.Fatalf("visited %v", seen)
+	}
+}
+
+func TestWalkDirStop(t *testing.T) {
+	p := New(2, WithLogger(nil))
+	defer p.Free()
+	root := t.TempDir()
+	for _, name := range []string{"x/1", "x/2", "y/3"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := WalkDir(context.Background(), p, root, func(_ context.Context, path string, d fs.DirEntry, err error) error {
+		if path == root {
+			return fs.SkipAll
+		}
+		t.Errorf("visited %s after SkipAll", path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SkipAll: err = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var files int
+	var mu sync.Mutex
+	err = WalkDir(ctx, p, root, func(_ context.Context, path string, d fs.DirEntry, err error) error {
+		if !d.IsDir() {
+			mu.Lock()
+			files++
+			mu.Unlock()
+		}
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || files != 0 {
+		t.Fatalf("canceled walk: err = %v, files = %d", err, files)
+	}
+
+	err = WalkDir(context.Background(), p, filepath.Join(root, "missing"), func(_ context.Context, path string, d fs.DirEntry, err error) error {
+		return err
+	})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("missing root: err = %v", err)
+	}
+}
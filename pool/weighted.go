@@ -0,0 +1,47 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ScheduleWeighted 提交占用 weight 个 worker 的任务 t：先以 Semaphore 占住另外 weight-1 个 worker，t 执行完后一并释放，
+// 使一个占满 8 个核的任务与 8 个小任务计入同样的并发预算；weight 不超过 1 时等同于 Schedule，超过容量时返回错误
+// 与 Schedule 一样遵循 WithBlock：不阻塞时得不到全部 worker 即返回 ErrPoolSaturated，不持有任何 worker
+func (p *Pool) ScheduleWeighted(t Task, weight int) error {
+	p = p.applied()
+	if weight <= 1 {
+		return p.Schedule(t)
+	}
+	if weight > p.capacity {
+		return fmt.Errorf("%w: weight %d exceeds capacity %d", ErrInvalidOption, weight, p.capacity)
+	}
+	release, err := p.acquireWeight(weight)
+	if err != nil {
+		return err
+	}
+	err = p.scheduleOr(task{fn: func() {
+		defer release()
+		t()
+	}}, func(error) { release() }) // 被 Purge 或 Free 丢弃时同样释放
+	if err != nil {
+		release()
+	}
+	return err
+}
+
+// acquireWeight 为占用 weight 个 worker 的任务占住另外 weight-1 个，返回释放它们的函数，重复调用只释放一次；
+// 与 Schedule 一样遵循 WithBlock
+func (p *Pool) acquireWeight(weight int) (release func(), err error) {
+	p.heavyOnce.Do(func() { p.heavy = p.Semaphore() })
+	extra := int64(weight - 1)
+	if p.block {
+		if err := p.heavy.Acquire(context.Background(), extra); err != nil {
+			return nil, err
+		}
+	} else if !p.heavy.TryAcquire(extra) {
+		return nil, p.saturatedErr()
+	}
+	return sync.OnceFunc(func() { p.heavy.Release(extra) }), nil
+}
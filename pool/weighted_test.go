@@ -0,0 +1,63 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleWeighted(t *testing.T) {
+	p := New(4, WithLogger(nil))
+	defer p.Free()
+	gate, started := make(chan struct{}), make(chan struct{})
+	if err := p.ScheduleWeighted(func() { close(started); <-gate }, 3); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// 重任务占住 3 个 worker，只剩一个给其它任务
+	if err := p.TryScheduleFunc(func(context.Context) { <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.TryScheduleFunc(func(context.Context) {}); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated while the weighted task holds 3 workers", err)
+	}
+	close(gate)
+	p.Wait()
+	// 占位的 worker 在重任务结束后释放
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Busy != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats after Wait = %+v", p.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduleWeightedLimits(t *testing.T) {
+	p := New(2, WithLogger(nil), WithBlock(false))
+	defer p.Free()
+	if err := p.ScheduleWeighted(func() {}, 3); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("err = %v, want ErrInvalidOption for weight above capacity", err)
+	}
+	gate, started := make(chan struct{}), make(chan struct{})
+	if err := p.Schedule(func() { close(started); <-gate }); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := p.ScheduleWeighted(func() {}, 2); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("err = %v, want ErrPoolSaturated", err)
+	}
+	close(gate)
+	p.Wait()
+	// 失败的提交没有留下占住的 worker
+	done := make(chan struct{})
+	deadline := time.Now().Add(5 * time.Second)
+	for p.ScheduleWeighted(func() { close(done) }, 2) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("weighted task never fit after the pool became idle")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	<-done
+}
@@ -0,0 +1,313 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TaskFunc 是可以感知所在 worker 的任务形式，ctx 中携带 worker 信息，
+// 可通过 WorkerValue 等函数取出
+type TaskFunc func(ctx context.Context)
+
+// 在 pool 内部流转的任务，Task、TaskFunc 与 ScheduleArg 的函数三选一，按值传递避免额外分配
+type task struct {
+	fn  Task
+	fnc TaskFunc
+	ctx context.Context // ScheduleCtx 的提交方 ctx，其取消会传递给执行中的任务
+
+	// ScheduleArg 的函数与参数，由 argRun 还原类型后调用，不必为每次提交创建闭包
+	argFn, arg any
+	argRun     interface{ run(fn, arg any) }
+
+	detached bool // ScheduleDetached 提交，不计入 Wait
+	counted  bool // 已计入 p.inflight，执行结束时减去
+	urgent   bool // ScheduleUrgent 提交，可以使用预留的 worker
+
+	claim *taskClaim // 等待期间登记，用于 Purge 丢弃，直接交给空闲 worker 的任务为 nil
+	turn  *fifoTurn  // WithFIFO 下的开始顺序，其它情况为 nil
+	seq   uint64     // 经由 p.tasks 交出时的编号，见 queueSeq
+	prio  int        // SchedulePriority 的优先级
+	tag   *taskTag   // WithTag 的标签，没有时为 nil
+	info  *TaskInfo  // ScheduleWith 提交的任务的元数据，其它情况为 nil
+
+	budget    time.Duration // WithBudget
+	hasBudget bool          // 设置了 WithBudget，否则使用 WithTaskBudget
+
+	id       uint64    // 生命周期事件中的编号，有事件接收方时在提交时分配，见 Event
+	enqueued time.Time // 经由 submit 提交的时刻，用于统计排队等待的时间，见 PoolStats.QueueWait
+
+	affinity uint64 // WithStickyKey 的键的哈希
+	keyed    bool   // 设置了 WithStickyKey
+
+	caller string // WithCaller
+}
+
+func (t task) exec(ctx context.Context) {
+	if parent := t.ctx; parent != nil { // 闭包只捕获 parent，t 不必逃逸到堆上
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		stop := context.AfterFunc(parent, func() { cancel(context.Cause(parent)) })
+		defer stop()
+	}
+	switch {
+	case t.fnc != nil:
+		t.fnc(ctx)
+	case t.argRun != nil:
+		t.argRun.run(t.argFn, t.arg)
+	default:
+		t.fn()
+	}
+}
+
+// worker 初始化失败后，占住容量一段时间再退出，避免反复创建失败的 worker；
+// 连续失败时等待时间从 workerInitRetryDelay 起加倍，直到 workerInitMaxDelay，见 WithInitBackoff
+const (
+	workerInitRetryDelay = 100 * time.Millisecond
+	workerInitMaxDelay   = 10 * time.Second
+)
+
+type workerKey struct{}
+
+type worker struct {
+	id    int
+	value any         // WithWorkerInit 返回的值
+	local map[any]any // worker 本地存储，仅由该 worker 上串行执行的任务访问
+	ready bool
+	ctx   context.Context
+	task  atomic.Pointer[TaskInfo] // 正在执行的任务的 TaskInfo，用于 panic 日志与 DumpStacks
+	// 正在执行的任务开始的时刻（UnixNano），空闲时为 0，见 Healthcheck
+	busySince atomic.Int64
+	beats     atomic.Uint64 // 当前任务调用 Heartbeat 的次数，任务开始时清零
+	stalled   atomic.Bool   // 当前任务的心跳已停止，见 WithHeartbeat
+
+	replaceable bool // 普通的 worker：任务超出预算时可以放弃它，由新的 worker 接替容量
+	abandoned   bool // 任务超出预算被放弃，返回后直接退出，容量已转交
+	yielded     bool // 为降低容量而退出，容量已转交给 runCapacitySchedule
+	lent        bool // 任务阻塞在 Barrier 上时容量已转交给新的 worker，任务返回后直接退出
+	budgeted    bool // 正以预算执行任务，超出时由 abandon 转交容量，期间不在 Barrier 上转交
+	failed      bool // 当前任务已由 MarkFailed 计为失败
+
+	inbox  chan task // 空闲栈中的 worker 经由它得到任务，见 idleStacks
+	parked bool      // 在空闲栈中，由栈的锁保护
+
+	slot atomic.Int32 // WithStickyRouting 下占据的位置加一，0 表示没有占据
+}
+
+// WorkerID 返回执行当前任务的 worker 编号（与日志及 LabelWorker 标签中的编号一致），
+// ctx 不是 TaskFunc 收到的 ctx 时 ok 为 false
+func WorkerID(ctx context.Context) (id int, ok bool) {
+	if w, ok := ctx.Value(workerKey{}).(*worker); ok {
+		return w.id, true
+	}
+	return 0, false
+}
+
+// WorkerValue 返回当前 worker 由 WithWorkerInit 创建的值，ctx 不是 TaskFunc 收到的 ctx 时返回 nil
+func WorkerValue(ctx context.Context) any {
+	if w, ok := ctx.Value(workerKey{}).(*worker); ok {
+		return w.value
+	}
+	return nil
+}
+
+// WorkerStorage 返回当前 worker 的本地存储，同一 worker 上的任务串行执行，读写无需加锁；
+// 存储随 worker 退出而释放，ctx 不是 TaskFunc 收到的 ctx 时返回 nil
+func WorkerStorage(ctx context.Context) map[any]any {
+	w, ok := ctx.Value(workerKey{}).(*worker)
+	if !ok {
+		return nil
+	}
+	if w.local == nil {
+		w.local = make(map[any]any)
+	}
+	return w.local
+}
+
+// WorkerLocal 从 worker 本地存储中取出 key 对应的值，不存在时调用 init 创建并保存，
+// 用于在同一 worker 的多个任务之间复用缓冲区、客户端等；不在 worker 中时每次都调用 init
+func WorkerLocal[T any](ctx context.Context, key any, init func() T) T {
+	m := WorkerStorage(ctx)
+	if m == nil {
+		return init()
+	}
+	if v, ok := m[key].(T); ok {
+		return v
+	}
+	v := init()
+	m[key] = v
+	return v
+}
+
+// 执行 worker 初始化钩子，失败时返回 false，worker 应直接退出
+func (p *Pool) initWorker(w *worker) bool {
+	w.ctx = context.WithValue(p.ctx, workerKey{}, w)
+	if p.workerInit != nil {
+		v, err := p.workerInit(w.id)
+		if err != nil {
+			n := int(p.initFailures.Add(1))
+			delay := p.initBackoff(n)
+			p.logf("worker[%03d]: init failed[%s], %d in a row, retry in %s\n", w.id, err, n, delay)
+			if p.onInitFailure != nil {
+				p.onInitFailure(w.id, err, n)
+			}
+			t := p.clock.NewTimer(delay)
+			defer t.Stop()
+			select {
+			case <-t.C():
+			case <-p.quit:
+			}
+			return false
+		}
+		w.value = v
+	}
+	p.initFailures.Store(0)
+	w.ready = true
+	return true
+}
+
+// initBackoff 返回连续第 n 次初始化失败后的等待时间
+func (p *Pool) initBackoff(n int) time.Duration {
+	base, limit := p.initDelay, p.initMaxDelay
+	if base <= 0 {
+		base = workerInitRetryDelay
+	}
+	if limit <= 0 {
+		limit = workerInitMaxDelay
+	}
+	d := base
+	for i := 1; i < n && d < limit; i++ {
+		d *= 2
+	}
+	return min(d, limit)
+}
+
+// 执行 worker 退出钩子，仅对初始化成功的 worker 调用
+func (p *Pool) teardownWorker(w *worker) {
+	if !w.ready || p.workerTeardown == nil {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			p.logf("worker[%03d]: recover teardown panic[%s]\n", w.id, err)
+		}
+	}()
+	p.workerTeardown(w.id, w.value)
+}
+
+// 在 worker w 上执行任务 t
+func (p *Pool) runTask(w *worker, t task) {
+	if t.counted {
+		defer func() {
+			if !w.abandoned { // 被放弃时已不计入 Wait
+				p.inflight.add(-1)
+			}
+		}()
+	}
+	p.dequeue(t)
+	if t.turn != nil {
+		t.turn.await()
+	}
+	p.pause.wait(p)
+	p.waitBlackout(w, t)
+	p.rate.wait(p) // 等待期间被 Purge 或提交方放弃的任务随后被跳过
+	if p.skipCanceled(t) || p.skipExpired(t) || !p.start(t) {
+		return // 已被 Purge 丢弃，或提交方已放弃
+	}
+	if !t.enqueued.IsZero() {
+		wait := p.clock.Since(t.enqueued)
+		p.queueWait.record(wait)
+		if p.metrics != nil {
+			p.metrics.Observe(MetricQueueWait, wait.Seconds())
+		}
+	}
+	p.injectBeforeTask()
+	if p.blockingMode {
+		p.blocking.Add(1)
+		defer p.blocking.Add(-1)
+	}
+	ctx := w.ctx
+	w.task.Store(t.info)
+	start := p.clock.Now()
+	w.beats.Store(0)
+	w.busySince.Store(start.UnixNano())
+	if t.info != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = taskContext(ctx, t.info)
+		defer cancel()
+	}
+	if t.tag != nil {
+		ctx = t.tag.start(ctx)
+		defer p.finishTag(t.tag)
+	}
+	if t.ctx != nil && p.propagate != nil {
+		ctx = p.propagate(t.ctx, ctx)
+	}
+	if p.guard != nil {
+		defer func() {
+			r := recover()
+			p.guard.record(p, r != nil || w.failed)
+			w.failed = false
+			if r != nil {
+				panic(r) // 交给 worker 照常处理
+			}
+		}()
+	}
+	if p.report != nil {
+		defer p.recordFailed(w) // 先于 guard 的 defer 执行，此时 w.failed 尚未清除
+	}
+	exec := TaskFunc(t.exec)
+	if p.faults != nil {
+		exec = p.faultExec(w.id, t)
+	}
+	run := exec
+	if p.middleware != nil {
+		run = p.middleware(exec)
+	}
+	if p.allocs != nil && p.allocs.sample() {
+		defer p.allocs.record(t.info, readAllocs())
+	}
+	switch budget := p.budgetOf(t); {
+	case p.observed():
+		p.runObserved(w, t, func() {
+			if budget > 0 && w.replaceable {
+				p.runBudget(w, t, ctx, run, budget)
+			} else {
+				run(ctx)
+			}
+		})
+	case budget > 0 && w.replaceable:
+		p.runBudget(w, t, ctx, run, budget)
+	default:
+		run(ctx)
+	}
+	p.execTime.record(p.clock.Since(start))
+	w.task.Store(nil) // panic 时保留，供 worker 的 panic 日志使用
+	w.busySince.Store(0)
+	w.stalled.Store(false)
+	p.throughput.done(p.clock.Now(), p.backlogged())
+}
+
+// resetIdle 在每个任务结束后重新计算空闲时间，丢弃任务执行期间到期的信号
+func (p *Pool) resetIdle(t Timer, d time.Duration) {
+	t.Stop()
+	select {
+	case <-t.C():
+	default:
+	}
+	t.Reset(d)
+}
+
+// 每个任务执行完毕后判断 worker 是否应当退役：已执行 n 个任务，或存活时间已到
+func (p *Pool) shouldRetire(n int, expired <-chan time.Time) bool {
+	if p.maxWorkerTasks > 0 && n >= p.maxWorkerTasks {
+		return true
+	}
+	select {
+	case <-expired:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,55 @@
+package workerpool
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWorkerLocal(t *testing.T) {
+	p := New(1, WithLogger(nil))
+	defer p.Free()
+	type bufKey struct{}
+	var inits atomic.Int32
+	newBuf := func() *bytes.Buffer { inits.Add(1); return new(bytes.Buffer) }
+	for i := range 5 {
+		p.ScheduleFunc(func(ctx context.Context) {
+			buf := WorkerLocal(ctx, bufKey{}, newBuf)
+			buf.WriteByte(byte('0' + i))
+			WorkerStorage(ctx)["last"] = i
+		})
+	}
+	var got string
+	var last any
+	p.ScheduleFunc(func(ctx context.Context) {
+		got = WorkerLocal(ctx, bufKey{}, newBuf).String()
+		last = WorkerStorage(ctx)["last"]
+	})
+	p.Wait()
+	if inits.Load() != 1 || got != "01234" || last != 4 {
+		t.Fatalf("inits %d, buffer %q, last %v", inits.Load(), got, last)
+	}
+
+	if v := WorkerLocal(context.Background(), bufKey{}, newBuf); v == nil || inits.Load() != 2 {
+		t.Fatal("WorkerLocal outside a worker should call init every time")
+	}
+	if WorkerStorage(context.Background()) != nil {
+		t.Fatal("WorkerStorage outside a worker is not nil")
+	}
+}
+
+func TestWorkerLocalFreedWithWorker(t *testing.T) {
+	p := New(1, WithMaxTasksPerWorker(1), WithLogger(nil))
+	defer p.Free()
+	var inits atomic.Int32
+	for range 3 {
+		p.ScheduleFunc(func(ctx context.Context) {
+			WorkerLocal(ctx, "k", func() int { inits.Add(1); return 0 })
+		})
+	}
+	p.Wait()
+	if inits.Load() != 3 { // 每个任务之后 worker 被回收，本地存储随之释放
+		t.Fatalf("inits = %d, want 3", inits.Load())
+	}
+}
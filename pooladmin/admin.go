@@ -0,0 +1,144 @@
+// Package pooladmin 暴露 Manager 中具名 pool 的状态与运维操作（暂停、恢复、调整容量、排空、清空排队、调用栈）：
+// Handler 提供 HTTP 接口，供 cmd/workerpoolctl 等工具使用；Service 实现 admin.proto 中的 gRPC 服务，供中心化的控制器使用
+//
+//	m := workerpool.NewManager(time.Minute)
+//	mux.Handle("/debug/workerpool/", http.StripPrefix("/debug/workerpool", pooladmin.Handler(m)))
+//
+// 接口：
+//
+//	GET  /pools                           所有 pool 的状态
+//	GET  /pools/{name}                    一个 pool 的状态
+//	POST /pools/{name}/pause              暂停分发
+//	POST /pools/{name}/resume             恢复分发
+//	POST /pools/{name}/resize?capacity=N  调整容量
+//	POST /pools/{name}/drain?timeout=D    排空后销毁，D 为等待的上限（默认 30s）
+//	POST /pools/{name}/purge              丢弃尚未开始执行的任务
+//	GET  /pools/{name}/stacks             worker 的调用栈
+//	GET  /pools/{name}/jobs               各 Job 类型的统计
+//	GET  /pools/{name}/sizing             容量建议，需要 WithUtilizationRecorder
+package pooladmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// DefaultDrainTimeout 是 drain 未指定 timeout 时等待的上限
+const DefaultDrainTimeout = 30 * time.Second
+
+// Status 是一个 pool 的状态，即 GET /pools 与 GET /pools/{name} 返回的 JSON
+type Status struct {
+	Name     string               `json:"name"`
+	Stats    workerpool.PoolStats `json:"stats"`
+	Paused   bool                 `json:"paused"`
+	Restarts int                  `json:"restarts"`
+	Health   string               `json:"health,omitempty"` // 最近一次 Healthcheck 的错误，健康时为空
+}
+
+// Handler 返回操作 m 中 pool 的 HTTP handler，出错时返回 {"error": "..."}
+func Handler(m *workerpool.Manager) http.Handler {
+	s := NewService(m)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pools", func(w http.ResponseWriter, r *http.Request) {
+		list, err := s.ListPools(r.Context())
+		reply(w, list, err)
+	})
+	mux.HandleFunc("GET /pools/{name}", func(w http.ResponseWriter, r *http.Request) {
+		st, err := s.GetPool(r.Context(), r.PathValue("name"))
+		reply(w, st, err)
+	})
+	mux.HandleFunc("POST /pools/{name}/pause", func(w http.ResponseWriter, r *http.Request) {
+		st, err := s.Pause(r.Context(), r.PathValue("name"))
+		reply(w, st, err)
+	})
+	mux.HandleFunc("POST /pools/{name}/resume", func(w http.ResponseWriter, r *http.Request) {
+		st, err := s.Resume(r.Context(), r.PathValue("name"))
+		reply(w, st, err)
+	})
+	mux.HandleFunc("POST /pools/{name}/resize", func(w http.ResponseWriter, r *http.Request) {
+		n, err := strconv.Atoi(r.URL.Query().Get("capacity"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("capacity: %w", err))
+			return
+		}
+		st, err := s.Resize(r.Context(), r.PathValue("name"), n)
+		reply(w, st, err)
+	})
+	mux.HandleFunc("POST /pools/{name}/drain", func(w http.ResponseWriter, r *http.Request) {
+		var timeout time.Duration
+		if v := r.URL.Query().Get("timeout"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("timeout: %w", err))
+				return
+			}
+			timeout = d
+		}
+		st, err := s.Drain(r.Context(), r.PathValue("name"), timeout)
+		reply(w, st, err)
+	})
+	mux.HandleFunc("POST /pools/{name}/purge", func(w http.ResponseWriter, r *http.Request) {
+		n, err := s.Purge(r.Context(), r.PathValue("name"))
+		reply(w, map[string]int{"purged": n}, err)
+	})
+	mux.HandleFunc("GET /pools/{name}/stacks", func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.pool(r.PathValue("name"))
+		if err != nil {
+			reply(w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = p.DumpStacks(w)
+	})
+	mux.HandleFunc("GET /pools/{name}/jobs", func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.pool(r.PathValue("name"))
+		if err != nil {
+			reply(w, nil, err)
+			return
+		}
+		reply(w, p.JobTypes(), nil)
+	})
+	mux.HandleFunc("GET /pools/{name}/sizing", func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.pool(r.PathValue("name"))
+		if err != nil {
+			reply(w, nil, err)
+			return
+		}
+		rec, err := p.RecommendCapacity()
+		reply(w, rec, err)
+	})
+	return mux
+}
+
+// reply 写出 v，或按 err 的类型选择状态码写出错误
+func reply(w http.ResponseWriter, v any, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, v)
+	case errors.Is(err, ErrUnknownPool), errors.Is(err, workerpool.ErrNoUtilizationData):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, workerpool.ErrInvalidOption):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
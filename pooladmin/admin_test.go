@@ -0,0 +1,90 @@
+package pooladmin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	workerpool "workerpool/pool"
+)
+
+func newServer(t *testing.T) (*httptest.Server, *workerpool.Pool) {
+	t.Helper()
+	m := workerpool.NewManager(0)
+	p := workerpool.New(2, workerpool.WithLogger(nil))
+	if err := m.Add(workerpool.ManagedPool{Name: "images", Pool: p}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(Handler(m))
+	t.Cleanup(func() {
+		srv.Close()
+		p.Free()
+	})
+	return srv, p
+}
+
+func do(t *testing.T, method, url string, v any) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestHandler(t *testing.T) {
+	srv, p := newServer(t)
+	var list []Status
+	if code := do(t, "GET", srv.URL+"/pools", &list); code != 200 || len(list) != 1 || list[0].Name != "images" {
+		t.Fatalf("GET /pools = %d %+v", code, list)
+	}
+	var st Status
+	if code := do(t, "POST", srv.URL+"/pools/images/pause", &st); code != 200 || !st.Paused || !p.Paused() {
+		t.Fatalf("pause = %d %+v", code, st)
+	}
+	if code := do(t, "POST", srv.URL+"/pools/images/resume", &st); code != 200 || st.Paused {
+		t.Fatalf("resume = %d %+v", code, st)
+	}
+	if code := do(t, "POST", srv.URL+"/pools/images/resize?capacity=5", &st); code != 200 || st.Stats.Capacity != 5 || p.Cap() != 5 {
+		t.Fatalf("resize = %d %+v", code, st)
+	}
+	var e map[string]string
+	if code := do(t, "POST", srv.URL+"/pools/images/resize?capacity=-1", &e); code != 400 || e["error"] == "" {
+		t.Fatalf("invalid resize = %d %v", code, e)
+	}
+	if code := do(t, "GET", srv.URL+"/pools/missing", &e); code != 404 {
+		t.Fatalf("unknown pool = %d %v", code, e)
+	}
+	resp, err := http.Get(srv.URL + "/pools/images/stacks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("stacks = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	p.Register("resize", func(context.Context, []byte) error { return nil })
+	var jobs []workerpool.JobTypeStats
+	if code := do(t, "GET", srv.URL+"/pools/images/jobs", &jobs); code != 200 || len(jobs) != 1 || jobs[0].Type != "resize" {
+		t.Fatalf("jobs = %d %+v", code, jobs)
+	}
+	if code := do(t, "GET", srv.URL+"/pools/images/sizing", nil); code != 404 {
+		t.Fatalf("sizing without a recorder = %d", code)
+	}
+	if code := do(t, "POST", srv.URL+"/pools/images/drain?timeout=5s", &st); code != 200 {
+		t.Fatalf("drain = %d", code)
+	}
+	if err := p.Schedule(func() {}); err == nil {
+		t.Fatal("drained pool still accepts tasks")
+	}
+}
@@ -0,0 +1,164 @@
+package pooladmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// ErrUnknownPool 表示 Manager 中没有该名称的 pool，gRPC 中对应 codes.NotFound
+var ErrUnknownPool = errors.New("unknown pool")
+
+// Service 实现 admin.proto 中的 PoolAdmin，不依赖 google.golang.org/grpc：
+// 生成的服务端代码只需把请求转换为参数后调用对应的方法，错误按 ErrUnknownPool → NotFound、
+// workerpool.ErrInvalidOption → InvalidArgument、context 错误 → DeadlineExceeded/Canceled 转换
+//
+//	func (s *server) Resize(ctx context.Context, req *adminpb.ResizeRequest) (*adminpb.PoolStatus, error) {
+//		st, err := s.svc.Resize(ctx, req.Name, int(req.Capacity))
+//		return toProto(st), toStatus(err)
+//	}
+//	func (s *server) WatchStats(req *adminpb.WatchStatsRequest, ss adminpb.PoolAdmin_WatchStatsServer) error {
+//		return s.svc.WatchStats(ss.Context(), req.Name, req.Interval.AsDuration(), func(st pooladmin.Status) error {
+//			return ss.Send(toProto(st))
+//		})
+//	}
+//
+// HTTP 的 Handler 同样基于 Service
+type Service struct {
+	m *workerpool.Manager
+}
+
+func NewService(m *workerpool.Manager) *Service {
+	return &Service{m: m}
+}
+
+// ListPools 按名称顺序返回所有 pool 的状态
+func (s *Service) ListPools(ctx context.Context) ([]Status, error) {
+	all := s.m.Stats()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]Status, 0, len(names))
+	for _, name := range names {
+		list = append(list, s.status(name, all[name]))
+	}
+	return list, nil
+}
+
+// GetPool 返回名为 name 的 pool 的状态
+func (s *Service) GetPool(ctx context.Context, name string) (Status, error) {
+	if _, err := s.pool(name); err != nil {
+		return Status{}, err
+	}
+	return s.current(name), nil
+}
+
+// WatchStats 立即并此后每隔 interval（0 表示 1s）以 send 推送一次状态，直到 ctx 取消、send 出错或 pool 被移除
+func (s *Service) WatchStats(ctx context.Context, name string, interval time.Duration, send func(Status) error) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		st, err := s.GetPool(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := send(st); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Pause 暂停 pool 的分发
+func (s *Service) Pause(ctx context.Context, name string) (Status, error) {
+	p, err := s.pool(name)
+	if err != nil {
+		return Status{}, err
+	}
+	p.Pause()
+	return s.current(name), nil
+}
+
+// Resume 恢复 pool 的分发
+func (s *Service) Resume(ctx context.Context, name string) (Status, error) {
+	p, err := s.pool(name)
+	if err != nil {
+		return Status{}, err
+	}
+	p.Resume()
+	return s.current(name), nil
+}
+
+// Resize 把 pool 的容量改为 capacity，见 workerpool.Pool.Resize
+func (s *Service) Resize(ctx context.Context, name string, capacity int) (Status, error) {
+	p, err := s.pool(name)
+	if err != nil {
+		return Status{}, err
+	}
+	if err := p.Resize(capacity); err != nil {
+		return Status{}, err
+	}
+	return s.current(name), nil
+}
+
+// Drain 排空并销毁 pool，最多等待 timeout（0 表示 DefaultDrainTimeout），到期后排空在后台继续
+func (s *Service) Drain(ctx context.Context, name string, timeout time.Duration) (Status, error) {
+	p, err := s.pool(name)
+	if err != nil {
+		return Status{}, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := p.Drain(ctx); err != nil {
+		return Status{}, fmt.Errorf("drain still running: %w", err)
+	}
+	return s.current(name), nil
+}
+
+// Purge 丢弃 pool 中尚未开始执行的任务，返回丢弃的数量
+func (s *Service) Purge(ctx context.Context, name string) (int, error) {
+	p, err := s.pool(name)
+	if err != nil {
+		return 0, err
+	}
+	return p.Purge(), nil
+}
+
+func (s *Service) pool(name string) (*workerpool.Pool, error) {
+	p := s.m.Get(name)
+	if p == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownPool, name)
+	}
+	return p, nil
+}
+
+func (s *Service) current(name string) Status {
+	return s.status(name, s.m.Stats()[name])
+}
+
+func (s *Service) status(name string, ms workerpool.ManagedStats) Status {
+	st := Status{Name: name, Stats: ms.PoolStats, Restarts: ms.Restarts}
+	if p := s.m.Get(name); p != nil {
+		st.Paused = p.Paused()
+	}
+	if ms.Health != nil {
+		st.Health = ms.Health.Error()
+	}
+	return st
+}
@@ -0,0 +1,50 @@
+package pooladmin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+func TestServiceWatchStats(t *testing.T) {
+	m := workerpool.NewManager(0)
+	p := workerpool.New(2, workerpool.WithLogger(nil))
+	defer p.Free()
+	m.Add(workerpool.ManagedPool{Name: "images", Pool: p})
+	s := NewService(m)
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []Status
+	err := s.WatchStats(ctx, "images", time.Millisecond, func(st Status) error {
+		got = append(got, st)
+		if len(got) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || len(got) != 3 || got[0].Stats.Capacity != 2 {
+		t.Fatalf("WatchStats = %v after %d updates %+v", err, len(got), got)
+	}
+	if err := s.WatchStats(context.Background(), "missing", 0, func(Status) error { return nil }); !errors.Is(err, ErrUnknownPool) {
+		t.Fatalf("unknown pool: err = %v, want ErrUnknownPool", err)
+	}
+}
+
+func TestServicePurge(t *testing.T) {
+	m := workerpool.NewManager(0)
+	p := workerpool.New(1, workerpool.WithLogger(nil), workerpool.WithQueueSize(2))
+	defer p.Free()
+	m.Add(workerpool.ManagedPool{Name: "images", Pool: p})
+	gate, started := make(chan struct{}), make(chan struct{})
+	p.Schedule(func() { close(started); <-gate })
+	<-started
+	p.Schedule(func() {})
+	p.Schedule(func() {})
+	n, err := NewService(m).Purge(context.Background(), "images")
+	close(gate)
+	if err != nil || n != 2 {
+		t.Fatalf("Purge = %d, %v, want 2", n, err)
+	}
+}
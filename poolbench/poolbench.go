@@ -0,0 +1,217 @@
+// Package poolbench 是 workerpool 的负载生成器：按给定的任务耗时分布、到达速率与突发模式向 pool 提交任务，
+// 报告吞吐、延迟分位数与每个任务的内存分配，用于在自己的硬件上比较不同的 pool 配置与排队策略
+//
+//	reports, err := poolbench.Compare(os.Stdout, 16, poolbench.Config{
+//		Tasks:    10000,
+//		Duration: poolbench.Exponential(2 * time.Millisecond),
+//		Rate:     5000,
+//	}, map[string][]workerpool.Option{
+//		"default":  nil,
+//		"queue128": {workerpool.WithQueueSize(128)},
+//	})
+package poolbench
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// Distribution 按 r 生成一个任务的耗时
+type Distribution func(r *rand.Rand) time.Duration
+
+// Fixed 返回总是 d 的分布
+func Fixed(d time.Duration) Distribution {
+	return func(*rand.Rand) time.Duration { return d }
+}
+
+// Uniform 返回 [lo, hi) 上的均匀分布
+func Uniform(lo, hi time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		if hi <= lo {
+			return lo
+		}
+		return lo + time.Duration(r.Int63n(int64(hi-lo)))
+	}
+}
+
+// Exponential 返回均值为 mean 的指数分布，少数任务远慢于平均值，接近真实服务的长尾
+func Exponential(mean time.Duration) Distribution {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(r.ExpFloat64() * float64(mean))
+	}
+}
+
+// Burst 是在平稳到达之外周期性的突发：每隔 Every 一次性提交 Size 个任务
+type Burst struct {
+	Size  int
+	Every time.Duration
+}
+
+type Config struct {
+	Tasks    int          // 提交的任务总数（含突发），默认 1000
+	Duration Distribution // 任务耗时的分布，默认 Fixed(0)
+	Rate     float64      // 平均每秒到达的任务数（泊松到达），0 表示不加间隔地依次提交
+	Burst    Burst        // 突发模式，Size 或 Every 为 0 表示没有突发
+	Spin     bool         // 任务以忙等占用 CPU，默认以 sleep 模拟 I/O 等待
+	Seed     int64        // 随机数种子，相同的种子生成相同的负载
+	// Submit 提交一个任务，默认 p.Schedule；可替换为 TryScheduleFunc、SchedulePriority 等以比较不同的提交方式
+	Submit func(p *workerpool.Pool, t workerpool.Task) error
+}
+
+// Report 是一次运行的结果
+type Report struct {
+	Submitted int           // 提交成功的任务数
+	Rejected  int           // 提交失败的任务数
+	Elapsed   time.Duration // 从第一个任务提交到最后一个任务执行完
+	// Throughput 是每秒执行完的任务数
+	Throughput float64
+	// Latency 是任务从提交到执行完的耗时，QueueWait 与 ExecTime 取自 pool 的 Stats，见 workerpool.PoolStats
+	Latency       workerpool.LatencyStats
+	QueueWait     workerpool.LatencyStats
+	ExecTime      workerpool.LatencyStats
+	AllocsPerTask float64 // 运行期间整个进程的堆分配次数除以任务数，含负载生成器自身的开销
+	BytesPerTask  float64
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%d tasks (%d rejected) in %s: %.0f tasks/s, latency p50 %s p95 %s p99 %s max %s, queue wait p99 %s, %.1f allocs/task %.0f B/task",
+		r.Submitted, r.Rejected, r.Elapsed.Round(time.Microsecond), r.Throughput,
+		r.Latency.P50, r.Latency.P95, r.Latency.P99, r.Latency.Max, r.QueueWait.P99, r.AllocsPerTask, r.BytesPerTask)
+}
+
+// Run 按 cfg 向 p 提交任务并等待它们执行完；p 的 QueueWait 与 ExecTime 是累计值，比较时应使用新建的 pool
+// 运行期间 p 不应被 Purge 或 Free，否则被丢弃的任务会使 Run 一直等待
+func Run(p *workerpool.Pool, cfg Config) Report {
+	if cfg.Tasks <= 0 {
+		cfg.Tasks = 1000
+	}
+	if cfg.Duration == nil {
+		cfg.Duration = Fixed(0)
+	}
+	if cfg.Submit == nil {
+		cfg.Submit = (*workerpool.Pool).Schedule
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	latencies := make([]time.Duration, cfg.Tasks)
+	var wg sync.WaitGroup
+	var rep Report
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	submit := func(i int) {
+		d := cfg.Duration(rng)
+		submitted := time.Now()
+		wg.Add(1)
+		err := cfg.Submit(p, func() {
+			defer wg.Done()
+			work(d, cfg.Spin)
+			latencies[i] = time.Since(submitted)
+		})
+		if err != nil {
+			wg.Done()
+			latencies[i] = -1
+			rep.Rejected++
+			return
+		}
+		rep.Submitted++
+	}
+	nextArrival, nextBurst := start, start.Add(cfg.Burst.Every)
+	bursty := cfg.Burst.Size > 0 && cfg.Burst.Every > 0
+	for i := 0; i < cfg.Tasks; {
+		if bursty && !nextBurst.After(nextArrival) {
+			time.Sleep(time.Until(nextBurst))
+			for n := 0; n < cfg.Burst.Size && i < cfg.Tasks; n++ {
+				submit(i)
+				i++
+			}
+			nextBurst = nextBurst.Add(cfg.Burst.Every)
+			continue
+		}
+		time.Sleep(time.Until(nextArrival))
+		submit(i)
+		i++
+		if cfg.Rate > 0 {
+			nextArrival = nextArrival.Add(time.Duration(rng.ExpFloat64() / cfg.Rate * float64(time.Second)))
+		} else {
+			nextArrival = time.Now()
+		}
+	}
+	wg.Wait()
+	rep.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if rep.Elapsed > 0 {
+		rep.Throughput = float64(rep.Submitted) / rep.Elapsed.Seconds()
+	}
+	rep.Latency = latencyStats(latencies)
+	stats := p.Stats()
+	rep.QueueWait, rep.ExecTime = stats.QueueWait, stats.ExecTime
+	rep.AllocsPerTask = float64(after.Mallocs-before.Mallocs) / float64(cfg.Tasks)
+	rep.BytesPerTask = float64(after.TotalAlloc-before.TotalAlloc) / float64(cfg.Tasks)
+	return rep
+}
+
+// Compare 对 configs 中的每组选项新建容量为 capacity 的 pool，依次以 cfg 运行并销毁，
+// 按名称顺序把每个报告写入 w（可以为 nil）；选项无效时返回错误
+func Compare(w io.Writer, capacity int, cfg Config, configs map[string][]workerpool.Option) (map[string]Report, error) {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	reports := make(map[string]Report, len(configs))
+	for _, name := range names {
+		opts := append([]workerpool.Option{workerpool.WithLogger(nil)}, configs[name]...)
+		p, err := workerpool.NewE(capacity, opts...)
+		if err != nil {
+			return reports, fmt.Errorf("poolbench: %s: %w", name, err)
+		}
+		reports[name] = Run(p, cfg)
+		p.Free()
+		if w != nil {
+			fmt.Fprintf(w, "%s: %s\n", name, reports[name])
+		}
+	}
+	return reports, nil
+}
+
+func work(d time.Duration, spin bool) {
+	if !spin {
+		time.Sleep(d)
+		return
+	}
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
+
+// latencyStats 由全部记录精确计算分位数，rejected 的任务记为负数并跳过
+func latencyStats(all []time.Duration) workerpool.LatencyStats {
+	ds := make([]time.Duration, 0, len(all))
+	for _, d := range all {
+		if d >= 0 {
+			ds = append(ds, d)
+		}
+	}
+	var s workerpool.LatencyStats
+	if len(ds) == 0 {
+		return s
+	}
+	slices.Sort(ds)
+	for _, d := range ds {
+		s.Total += d
+	}
+	s.Count = uint64(len(ds))
+	s.Max = ds[len(ds)-1]
+	at := func(q float64) time.Duration { return ds[int(q*float64(len(ds)-1))] }
+	s.P50, s.P95, s.P99 = at(0.50), at(0.95), at(0.99)
+	return s
+}
@@ -0,0 +1,73 @@
+package poolbench
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+func TestRun(t *testing.T) {
+	p := workerpool.New(4, workerpool.WithLogger(nil))
+	defer p.Free()
+	rep := Run(p, Config{Tasks: 40, Duration: Fixed(2 * time.Millisecond)})
+	if rep.Submitted != 40 || rep.Rejected != 0 || rep.Latency.Count != 40 {
+		t.Fatalf("report = %+v", rep)
+	}
+	// 4 个 worker 执行 40 个 2ms 的任务至少需要 20ms
+	if rep.Elapsed < 20*time.Millisecond || rep.Throughput <= 0 {
+		t.Fatalf("elapsed %s, throughput %.0f", rep.Elapsed, rep.Throughput)
+	}
+	if rep.Latency.P50 < 2*time.Millisecond || rep.ExecTime.Count != 40 || rep.QueueWait.Count != 40 {
+		t.Fatalf("latency = %+v, exec = %+v", rep.Latency, rep.ExecTime)
+	}
+}
+
+func TestRunRateAndBurst(t *testing.T) {
+	p := workerpool.New(8, workerpool.WithLogger(nil))
+	defer p.Free()
+	// 平均每 1ms 到达一个，另每 10ms 突发 5 个
+	rep := Run(p, Config{Tasks: 30, Rate: 1000, Burst: Burst{Size: 5, Every: 10 * time.Millisecond}, Seed: 1})
+	if rep.Submitted != 30 || rep.Elapsed < 10*time.Millisecond {
+		t.Fatalf("report = %+v", rep)
+	}
+}
+
+func TestRunCountsRejected(t *testing.T) {
+	p := workerpool.New(1, workerpool.WithLogger(nil))
+	defer p.Free()
+	rep := Run(p, Config{
+		Tasks:    10,
+		Duration: Fixed(20 * time.Millisecond),
+		Submit: func(p *workerpool.Pool, t workerpool.Task) error {
+			return p.TryScheduleFunc(func(context.Context) { t() })
+		},
+	})
+	if rep.Rejected == 0 || rep.Submitted+rep.Rejected != 10 || rep.Latency.Count != uint64(rep.Submitted) {
+		t.Fatalf("report = %+v", rep)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var buf bytes.Buffer
+	reports, err := Compare(&buf, 2, Config{Tasks: 20, Duration: Uniform(0, time.Millisecond)}, map[string][]workerpool.Option{
+		"fifo":  {workerpool.WithFIFO()},
+		"queue": {workerpool.WithQueueSize(8)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports["fifo"].Submitted != 20 || reports["queue"].Submitted != 20 {
+		t.Fatalf("reports = %+v", reports)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "fifo: 20 tasks") || !strings.HasPrefix(lines[1], "queue: ") {
+		t.Fatalf("output =\n%s", buf.String())
+	}
+	if _, err := Compare(nil, 2, Config{}, map[string][]workerpool.Option{"bad": {workerpool.WithQueueSize(-1)}}); err == nil {
+		t.Fatal("Compare accepted an invalid option")
+	}
+}
@@ -0,0 +1,173 @@
+// Package procpool 让已注册类型的 Job 在独立的子进程中执行：闭包无法跨进程传递，
+// 因此与 Job 一样以类型名 + 序列化参数描述任务，参数经由 stdin 交给子进程。
+// 任务超时或被取消时子进程被直接杀死，失控或大量占用内存的任务得以真正终止，子进程崩溃也不会拖垮宿主进程
+//
+// 子进程默认是宿主程序自身：处理函数以 procpool.Register 注册（通常在 init 中），main 开头调用 procpool.Main，
+// 以子进程身份启动时执行任务后退出
+//
+//	func init() { procpool.Register("resize", resize) }
+//
+//	func main() {
+//		procpool.Main()
+//		p := workerpool.New(8)
+//		procpool.Install(p, procpool.Config{Timeout: time.Minute}, "resize")
+//		p.Enqueue(workerpool.Job{Type: "resize", Payload: img})
+//	}
+package procpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// envJobType 告诉子进程要执行的 Job 类型，也是 Main 判断自身是否为子进程的依据
+const envJobType = "WORKERPOOL_PROCPOOL_JOB"
+
+var (
+	// ErrKilled 是子进程因 ctx 取消或 Config.Timeout 到期被杀死时返回的错误，同时包装 ctx 的取消原因
+	ErrKilled = errors.New("procpool: subprocess killed")
+	// ErrCrashed 是子进程未报告结果即退出（panic、os.Exit、被信号终止等）时返回的错误
+	ErrCrashed = errors.New("procpool: subprocess crashed")
+)
+
+var (
+	mu       sync.RWMutex
+	handlers = make(map[string]workerpool.JobHandler)
+)
+
+// Register 注册在子进程中执行的 jobType 类型的处理函数；宿主与子进程都必须注册，
+// 因此应在 init 或 Main 之前调用，重复注册时覆盖
+func Register(jobType string, h workerpool.JobHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[jobType] = h
+}
+
+// Main 在以子进程身份启动时从 stdin 读取参数、执行对应的处理函数并退出，不会返回；
+// 否则立即返回，应在 main（测试中为 TestMain）的开头调用
+func Main() {
+	jobType, ok := os.LookupEnv(envJobType)
+	if !ok {
+		return
+	}
+	os.Exit(serve(jobType, os.Stdin, os.Stdout))
+}
+
+// result 是子进程写回 stdout 的执行结果
+type result struct {
+	Error string `json:"error,omitempty"`
+}
+
+func serve(jobType string, in io.Reader, out io.Writer) int {
+	mu.RLock()
+	h, ok := handlers[jobType]
+	mu.RUnlock()
+	if !ok {
+		fmt.Fprintf(os.Stderr, "procpool: %s: %s\n", workerpool.ErrUnknownJobType, jobType)
+		return 2
+	}
+	payload, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "procpool: read payload: %s\n", err)
+		return 2
+	}
+	var r result
+	if err := h(context.Background(), payload); err != nil {
+		r.Error = err.Error()
+	}
+	if err := json.NewEncoder(out).Encode(r); err != nil {
+		return 2
+	}
+	return 0
+}
+
+type Config struct {
+	Path    string        // 子进程的可执行文件，默认为 os.Executable()，须同样调用 Main
+	Args    []string      // 子进程的命令行参数，不含程序名
+	Env     []string      // 追加到宿主环境变量之后的环境变量
+	Timeout time.Duration // 单个任务的执行时间上限，到期杀死子进程，0 表示只受 ctx 约束
+	// Stderr 接收子进程的标准错误输出，默认保留最后 4KB 附在 ErrCrashed 的错误信息中
+	Stderr io.Writer
+}
+
+// stderrTail 是 ErrCrashed 错误信息中保留的子进程 stderr 长度
+const stderrTail = 4 << 10
+
+// Handler 返回在子进程中执行 jobType 的 JobHandler：每次调用启动一个子进程，
+// 任务结束（包括被杀死）后子进程即退出；处理函数返回的错误以其文本原样返回
+func Handler(jobType string, cfg Config) workerpool.JobHandler {
+	return func(ctx context.Context, payload []byte) error {
+		if cfg.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+		}
+		return run(ctx, jobType, cfg, payload)
+	}
+}
+
+// Install 将 jobTypes 以 Handler 注册到 p，之后经由 p.Enqueue 等提交的这些类型的 Job 在子进程中执行
+func Install(p *workerpool.Pool, cfg Config, jobTypes ...string) {
+	for _, jobType := range jobTypes {
+		p.Register(jobType, Handler(jobType, cfg))
+	}
+}
+
+func run(ctx context.Context, jobType string, cfg Config, payload []byte) error {
+	path := cfg.Path
+	if path == "" {
+		var err error
+		if path, err = os.Executable(); err != nil {
+			return fmt.Errorf("procpool: %w", err)
+		}
+	}
+	cmd := exec.CommandContext(ctx, path, cfg.Args...)
+	cmd.Env = append(append(os.Environ(), cfg.Env...), envJobType+"="+jobType)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	tail := &tailBuffer{max: stderrTail}
+	cmd.Stderr = tail
+	if cfg.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cfg.Stderr, tail)
+	}
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %s: %w", ErrKilled, jobType, context.Cause(ctx))
+	}
+	var r result
+	if err != nil || json.Unmarshal(stdout.Bytes(), &r) != nil {
+		if err == nil {
+			err = errors.New("no result reported")
+		}
+		return fmt.Errorf("%w: %s: %w: %s", ErrCrashed, jobType, err, bytes.TrimSpace(tail.buf))
+	}
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+	return nil
+}
+
+// tailBuffer 只保留最后写入的 max 字节
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
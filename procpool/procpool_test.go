@@ -0,0 +1,76 @@
+package procpool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+func init() {
+	Register("echo", func(_ context.Context, payload []byte) error {
+		if len(payload) > 0 {
+			return errors.New(string(payload))
+		}
+		return nil
+	})
+	Register("hang", func(context.Context, []byte) error {
+		time.Sleep(time.Hour) // 不能用 select {}：子进程中没有其它 goroutine，runtime 会判定死锁并退出
+		return nil
+	})
+	Register("crash", func(context.Context, []byte) error {
+		os.Stderr.WriteString("going down\n")
+		os.Exit(3)
+		return nil
+	})
+}
+
+func TestMain(m *testing.M) {
+	Main()
+	os.Exit(m.Run())
+}
+
+func TestHandlerResult(t *testing.T) {
+	h := Handler("echo", Config{})
+	if err := h(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := h(context.Background(), []byte("bad input")); err == nil || err.Error() != "bad input" {
+		t.Fatalf("err = %v, want bad input", err)
+	}
+}
+
+func TestHandlerKillsOnTimeout(t *testing.T) {
+	start := time.Now()
+	err := Handler("hang", Config{Timeout: 100 * time.Millisecond})(context.Background(), nil)
+	if !errors.Is(err, ErrKilled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrKilled wrapping DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("took %s to kill", d)
+	}
+}
+
+func TestHandlerCrash(t *testing.T) {
+	err := Handler("crash", Config{})(context.Background(), nil)
+	if !errors.Is(err, ErrCrashed) || !strings.Contains(err.Error(), "going down") {
+		t.Fatalf("err = %v, want ErrCrashed with stderr", err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	p := workerpool.New(2, workerpool.WithLogger(nil))
+	defer p.Free()
+	Install(p, Config{}, "echo")
+	errs := make(chan error, 1)
+	if err := p.Dispatch(workerpool.Job{Type: "echo", Payload: []byte("from child")}, func(err error) { errs <- err }); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err == nil || err.Error() != "from child" {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package redisqueue 将 workerpool 的 Job 存放在 Redis list 中，多个进程可以共享同一个逻辑队列，
+// 进程重启后未处理的 Job 不会丢失，积压情况可以直接用 LLEN/LRANGE 等 Redis 命令查看
+//
+// 队列采用可靠队列模式：消费者用 BLMOVE 将 Job 从 Key 原子地移动到自己的 processing list，
+// 执行成功后从 processing list 删除；执行失败的 Job 移入 Key + ":failed"，超过可见性超时（workerpool.ErrAckTimeout）的放回队列重新投递；
+// 消费者崩溃时留在 processing list 中的 Job 在下次启动时由 Requeue 放回队列
+//
+// Queue 实现了 workerpool.Queue，通过 WithQueue 交给 pool 后，Enqueue 写入 Redis，
+// pool 从 Redis 取出 Job 执行：
+//
+//	q := redisqueue.New(client, redisqueue.Config{Key: "jobs", Consumer: hostname})
+//	q.Requeue(ctx)
+//	p := workerpool.New(16, workerpool.WithQueue(q))
+//	p.Register("email.send", sendEmail)
+//	p.StartQueue()
+//
+// 包本身不依赖具体的 Redis 客户端，使用 go-redis 时可以这样适配 Client：
+//
+//	type goRedis struct{ c *redis.Client }
+//
+//	func (r goRedis) LPush(ctx context.Context, key string, v []byte) error {
+//		return r.c.LPush(ctx, key, v).Err()
+//	}
+//	func (r goRedis) BLMove(ctx context.Context, src, dst string, timeout time.Duration) ([]byte, error) {
+//		b, err := r.c.BLMove(ctx, src, dst, "RIGHT", "LEFT", timeout).Bytes()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return b, err
+//	}
+//	...
+package redisqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// Client 是 Queue 用到的 Redis 命令
+type Client interface {
+	LPush(ctx context.Context, key string, value []byte) error
+	// BLMove 对应 BLMOVE src dst RIGHT LEFT timeout，超时返回 nil, nil
+	BLMove(ctx context.Context, src, dst string, timeout time.Duration) ([]byte, error)
+	// RPopLPush 对应 RPOPLPUSH src dst，src 为空时返回 nil, nil
+	RPopLPush(ctx context.Context, src, dst string) ([]byte, error)
+	LRem(ctx context.Context, key string, count int64, value []byte) error
+	LLen(ctx context.Context, key string) (int64, error)
+}
+
+// Config 描述一个队列及其消费者
+type Config struct {
+	Key      string                           // 队列的 list key
+	Consumer string                           // 消费者名，processing list 为 Key + ":processing:" + Consumer，各进程应不同
+	Poll     time.Duration                    // BLMOVE 的超时时间，即检查 ctx 的间隔，默认 1s
+	Logf     func(format string, args ...any) // 日志输出，默认 fmt.Printf
+}
+
+type Queue struct {
+	c    Client
+	cfg  Config
+	poll time.Duration
+}
+
+var _ workerpool.Queue = (*Queue)(nil)
+
+// message 是 Job 在 Redis 中的表示，使用 JSON 以便直接用 Redis 工具查看
+type message struct {
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	Key     string `json:"key,omitempty"`
+	Attempt int    `json:"attempt,omitempty"` // 已投递的次数
+}
+
+func New(c Client, cfg Config) *Queue {
+	poll := cfg.Poll
+	if poll <= 0 {
+		poll = time.Second
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(format string, args ...any) { fmt.Printf(format, args...) }
+	}
+	return &Queue{c: c, cfg: cfg, poll: poll}
+}
+
+func (q *Queue) processingKey() string {
+	return q.cfg.Key + ":processing:" + q.cfg.Consumer
+}
+
+func (q *Queue) failedKey() string {
+	return q.cfg.Key + ":failed"
+}
+
+// Push 将 j 放入队列
+func (q *Queue) Push(ctx context.Context, j workerpool.Job) error {
+	b, err := json.Marshal(message{Type: j.Type, Payload: j.Payload, Key: j.Key})
+	if err != nil {
+		return err
+	}
+	return q.c.LPush(ctx, q.cfg.Key, b)
+}
+
+// Len 返回队列中等待处理的 Job 数
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	n, err := q.c.LLen(ctx, q.cfg.Key)
+	return int(n), err
+}
+
+// Pop 将一个 Job 移入本消费者的 processing list 并返回，队列为空时阻塞直到 ctx 取消
+// 无法解析的消息直接移入失败队列
+func (q *Queue) Pop(ctx context.Context) (workerpool.Delivery, error) {
+	for {
+		raw, err := q.c.BLMove(ctx, q.cfg.Key, q.processingKey(), q.poll)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return workerpool.Delivery{}, err
+		}
+		if raw == nil {
+			if ctx.Err() != nil {
+				return workerpool.Delivery{}, ctx.Err()
+			}
+			continue
+		}
+		var m message
+		if err := json.Unmarshal(raw, &m); err != nil {
+			q.cfg.Logf("redisqueue: bad message: %s\n", err)
+			if err := q.fail(context.WithoutCancel(ctx), raw); err != nil {
+				q.cfg.Logf("redisqueue: move to %s: %s\n", q.failedKey(), err)
+			}
+			continue
+		}
+		return workerpool.Delivery{Job: workerpool.Job{Type: m.Type, Payload: m.Payload, Key: m.Key, Attempt: m.Attempt + 1}, Token: raw}, nil
+	}
+}
+
+// Ack 在执行成功时将 d 从 processing list 删除，超过可见性超时时放回队列，其它失败移入失败队列
+func (q *Queue) Ack(ctx context.Context, d workerpool.Delivery, err error) error {
+	raw, ok := d.Token.([]byte)
+	if !ok {
+		return errors.New("redisqueue: delivery not from this queue")
+	}
+	switch {
+	case errors.Is(err, workerpool.ErrAckTimeout):
+		return q.redeliver(ctx, d.Job, raw)
+	case err != nil:
+		return q.fail(ctx, raw)
+	}
+	return q.c.LRem(ctx, q.processingKey(), 1, raw)
+}
+
+// redeliver 将 j 以新的投递次数放回队列，再从 processing list 删除；两步之间崩溃时 j 会由 Requeue 再投递一次
+func (q *Queue) redeliver(ctx context.Context, j workerpool.Job, raw []byte) error {
+	b, err := json.Marshal(message{Type: j.Type, Payload: j.Payload, Key: j.Key, Attempt: j.Attempt})
+	if err != nil {
+		return err
+	}
+	if err := q.c.LPush(ctx, q.cfg.Key, b); err != nil {
+		return err
+	}
+	return q.c.LRem(ctx, q.processingKey(), 1, raw)
+}
+
+// Requeue 将本消费者 processing list 中残留的 Job（上次崩溃时正在处理的）放回队列，返回数量；
+// 这些 Job 可能已经执行过，处理函数应当是幂等的
+func (q *Queue) Requeue(ctx context.Context) (int, error) {
+	n := 0
+	for {
+		b, err := q.c.RPopLPush(ctx, q.processingKey(), q.cfg.Key)
+		if err != nil || b == nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// fail 将执行失败的 Job 从 processing list 移入失败队列
+func (q *Queue) fail(ctx context.Context, raw []byte) error {
+	if err := q.c.LPush(ctx, q.failedKey(), raw); err != nil {
+		return err
+	}
+	return q.c.LRem(ctx, q.processingKey(), 1, raw)
+}
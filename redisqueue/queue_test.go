@@ -0,0 +1,184 @@
+package redisqueue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// fakeRedis 是内存中的 Redis list，list 的左端为下标 0
+type fakeRedis struct {
+	mu    sync.Mutex
+	lists map[string][][]byte
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{lists: make(map[string][][]byte)}
+}
+
+func (r *fakeRedis) LPush(_ context.Context, key string, v []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lists[key] = append([][]byte{bytes.Clone(v)}, r.lists[key]...)
+	return nil
+}
+
+func (r *fakeRedis) rpoplpush(src, dst string) []byte {
+	l := r.lists[src]
+	if len(l) == 0 {
+		return nil
+	}
+	v := l[len(l)-1]
+	r.lists[src] = l[:len(l)-1]
+	r.lists[dst] = append([][]byte{v}, r.lists[dst]...)
+	return v
+}
+
+func (r *fakeRedis) BLMove(ctx context.Context, src, dst string, timeout time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		r.mu.Lock()
+		v := r.rpoplpush(src, dst)
+		r.mu.Unlock()
+		if v != nil || time.Now().After(deadline) {
+			return v, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func (r *fakeRedis) RPopLPush(_ context.Context, src, dst string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rpoplpush(src, dst), nil
+}
+
+func (r *fakeRedis) LRem(_ context.Context, key string, count int64, v []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := r.lists[key]
+	for i := range l {
+		if bytes.Equal(l[i], v) {
+			r.lists[key] = append(l[:i:i], l[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeRedis) LLen(_ context.Context, key string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.lists[key])), nil
+}
+
+func (r *fakeRedis) len(key string) int {
+	n, _ := r.LLen(context.Background(), key)
+	return int(n)
+}
+
+func pop(t *testing.T, q *Queue) workerpool.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestQueueAck(t *testing.T) {
+	r := newFakeRedis()
+	q := New(r, Config{Key: "jobs", Consumer: "a", Poll: 10 * time.Millisecond})
+	ctx := context.Background()
+	for _, p := range []string{"1", "2"} {
+		if err := q.Push(ctx, workerpool.Job{Type: "t", Payload: []byte(p), Key: "k" + p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := q.Len(ctx); n != 2 {
+		t.Fatalf("Len = %d", n)
+	}
+	d := pop(t, q)
+	if string(d.Job.Payload) != "1" || d.Job.Key != "k1" || d.Job.Attempt != 1 || r.len(q.processingKey()) != 1 {
+		t.Fatalf("first Pop = %+v", d.Job)
+	}
+	if err := q.Ack(ctx, d, nil); err != nil {
+		t.Fatal(err)
+	}
+	d = pop(t, q)
+	if err := q.Ack(ctx, d, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	if r.len(q.processingKey()) != 0 || r.len(q.failedKey()) != 1 || r.len("jobs") != 0 {
+		t.Fatalf("lists after Ack: %v", r.lists)
+	}
+}
+
+func TestQueueAckTimeoutRedelivers(t *testing.T) {
+	r := newFakeRedis()
+	q := New(r, Config{Key: "jobs", Consumer: "a", Poll: 10 * time.Millisecond})
+	ctx := context.Background()
+	if err := q.Push(ctx, workerpool.Job{Type: "t", Payload: []byte("x")}); err != nil {
+		t.Fatal(err)
+	}
+	d := pop(t, q)
+	if err := q.Ack(ctx, d, workerpool.ErrAckTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if r.len(q.failedKey()) != 0 || r.len(q.processingKey()) != 0 {
+		t.Fatalf("timed-out job failed or left in processing: %v", r.lists)
+	}
+	d = pop(t, q)
+	if string(d.Job.Payload) != "x" || d.Job.Attempt != 2 {
+		t.Fatalf("redelivered = %+v", d.Job)
+	}
+}
+
+func TestQueueRequeue(t *testing.T) {
+	r := newFakeRedis()
+	q := New(r, Config{Key: "jobs", Consumer: "a", Poll: 10 * time.Millisecond})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t"})
+	pop(t, q) // 消费者在 Ack 之前崩溃
+	if n, err := New(r, Config{Key: "jobs", Consumer: "a"}).Requeue(ctx); n != 1 || err != nil {
+		t.Fatalf("Requeue = %d, %v", n, err)
+	}
+	if r.len("jobs") != 1 || r.len(q.processingKey()) != 0 {
+		t.Fatalf("lists after Requeue: %v", r.lists)
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := New(newFakeRedis(), Config{Key: "empty"}).Pop(cctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Pop on canceled ctx: err = %v", err)
+	}
+}
+
+func TestQueueBadMessage(t *testing.T) {
+	r := newFakeRedis()
+	var logged []string
+	q := New(r, Config{Key: "jobs", Consumer: "a", Poll: 10 * time.Millisecond, Logf: func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}})
+	ctx := context.Background()
+	r.LPush(ctx, "jobs", []byte("not json"))
+	q.Push(ctx, workerpool.Job{Type: "good"})
+	if d := pop(t, q); d.Job.Type != "good" {
+		t.Fatalf("Pop after a bad message = %+v", d.Job)
+	}
+	if r.len(q.failedKey()) != 1 || len(logged) != 1 || !strings.HasPrefix(logged[0], "redisqueue: bad message") {
+		t.Fatalf("failed %d, logged %q", r.len(q.failedKey()), logged)
+	}
+}
@@ -0,0 +1,208 @@
+// Package sqlqueue 以数据库中的 jobs 表作为 workerpool 的 Job 队列（transactional outbox）：
+// 业务代码在同一个事务中修改数据并用 Insert 写入 Job，提交后由 pool 轮询认领执行，
+// 使 pool 成为嵌入进程的轻量任务执行器，多个进程可以共同消费同一张表
+//
+// 认领使用 SELECT ... FOR UPDATE SKIP LOCKED，SQL 为 PostgreSQL 语法，表结构如下：
+//
+//	CREATE TABLE jobs (
+//		id           BIGSERIAL PRIMARY KEY,
+//		type         TEXT NOT NULL,
+//		payload      BYTEA NOT NULL,
+//		key          TEXT NOT NULL DEFAULT '',
+//		status       TEXT NOT NULL DEFAULT 'pending', -- pending、running、done、failed
+//		attempts     INT NOT NULL DEFAULT 0,
+//		locked_until TIMESTAMPTZ,
+//		last_error   TEXT
+//	);
+//	CREATE INDEX ON jobs (id) WHERE status IN ('pending', 'running');
+//
+// 认领后超过 Lease 仍未结束的行（如进程崩溃）会被重新认领，Lease 应大于 Job 的最长执行时间；
+// 已执行 MaxAttempts 次仍过期的行标记为 failed，不再认领，避免使进程崩溃的 Job 无限重试
+package sqlqueue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// Execer 由 *sql.DB 与 *sql.Tx 实现
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type Config struct {
+	Table       string        // 表名，默认 "jobs"，直接拼入 SQL，不应来自外部输入
+	Batch       int           // 每次认领的最大行数，默认 10
+	Poll        time.Duration // 没有待执行的行时的轮询间隔，默认 1s
+	Lease       time.Duration // 认领的有效期，默认 5m
+	MaxAttempts int           // 每个 Job 最多执行的次数，之后标记为 failed，默认 3
+}
+
+type Queue struct {
+	db  *sql.DB
+	cfg Config
+
+	mu       sync.Mutex
+	buffered []workerpool.Delivery // 已认领但尚未交给 pool 的行
+}
+
+var _ workerpool.Queue = (*Queue)(nil)
+
+// row 是 Delivery 的 Token
+type row struct {
+	id       int64
+	attempts int
+}
+
+func New(db *sql.DB, cfg Config) *Queue {
+	if cfg.Table == "" {
+		cfg.Table = "jobs"
+	}
+	if cfg.Batch <= 0 {
+		cfg.Batch = 10
+	}
+	if cfg.Poll <= 0 {
+		cfg.Poll = time.Second
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	return &Queue{db: db, cfg: cfg}
+}
+
+// Insert 在 tx 中写入一个 Job，tx 提交后才对消费者可见
+func (q *Queue) Insert(ctx context.Context, tx Execer, j workerpool.Job) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (type, payload, key) VALUES ($1, $2, $3)`, q.cfg.Table),
+		j.Type, j.Payload, j.Key)
+	return err
+}
+
+func (q *Queue) Push(ctx context.Context, j workerpool.Job) error {
+	return q.Insert(ctx, q.db, j)
+}
+
+// Len 返回待执行（不含执行中）的行数
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	var n int
+	err := q.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s WHERE status = 'pending'`, q.cfg.Table)).Scan(&n)
+	return n, err
+}
+
+// Pop 返回一个已认领的 Job，本地没有时向数据库认领一批，没有可认领的行时每隔 Poll 重试
+func (q *Queue) Pop(ctx context.Context) (workerpool.Delivery, error) {
+	for {
+		q.mu.Lock()
+		if len(q.buffered) > 0 {
+			d := q.buffered[0]
+			q.buffered = q.buffered[1:]
+			q.mu.Unlock()
+			return d, nil
+		}
+		q.mu.Unlock()
+		ds, err := q.claim(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return workerpool.Delivery{}, err
+		}
+		if len(ds) > 0 {
+			q.mu.Lock()
+			q.buffered = append(q.buffered, ds...)
+			q.mu.Unlock()
+			continue
+		}
+		t := time.NewTimer(q.cfg.Poll)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return workerpool.Delivery{}, ctx.Err()
+		}
+	}
+}
+
+// claim 认领最多 Batch 个待执行或认领已过期的行，先将已用完 MaxAttempts 的过期行标记为 failed
+func (q *Queue) claim(ctx context.Context) ([]workerpool.Delivery, error) {
+	_, err := q.db.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s SET status = 'failed', locked_until = NULL, last_error = 'lease expired after ' || attempts || ' attempts'
+		WHERE status = 'running' AND locked_until < now() AND attempts >= $1`, q.cfg.Table),
+		q.cfg.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.db.QueryContext(ctx, fmt.Sprintf(`
+		UPDATE %[1]s SET status = 'running', attempts = attempts + 1, locked_until = now() + $2 * interval '1 second'
+		WHERE id IN (
+			SELECT id FROM %[1]s
+			WHERE status = 'pending' OR (status = 'running' AND locked_until < now() AND attempts < $3)
+			ORDER BY id LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, payload, key, attempts`, q.cfg.Table),
+		q.cfg.Batch, q.cfg.Lease.Seconds(), q.cfg.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ds []workerpool.Delivery
+	for rows.Next() {
+		var r row
+		var j workerpool.Job
+		if err := rows.Scan(&r.id, &j.Type, &j.Payload, &j.Key, &r.attempts); err != nil {
+			return nil, err
+		}
+		j.Attempt = r.attempts
+		ds = append(ds, workerpool.Delivery{Job: j, Token: r})
+	}
+	return ds, rows.Err()
+}
+
+// Ack 在执行成功时将行标记为 done；失败时未达到 MaxAttempts 的放回 pending，否则标记为 failed
+// 认领已过期并被其它消费者重新认领的行不会被修改
+func (q *Queue) Ack(ctx context.Context, d workerpool.Delivery, err error) error {
+	r, ok := d.Token.(row)
+	if !ok {
+		return errors.New("sqlqueue: delivery not from this queue")
+	}
+	const where = `WHERE id = $1 AND status = 'running' AND attempts = $2`
+	if err == nil {
+		_, err := q.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = 'done', locked_until = NULL `+where, q.cfg.Table),
+			r.id, r.attempts)
+		return err
+	}
+	status := "pending"
+	if r.attempts >= q.cfg.MaxAttempts {
+		status = "failed"
+	}
+	_, xerr := q.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = $3, locked_until = NULL, last_error = $4 `+where, q.cfg.Table),
+		r.id, r.attempts, status, err.Error())
+	return xerr
+}
+
+// Close 将已认领但尚未交给 pool 的行放回 pending，应在 pool 的 Free 返回后调用
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	ds := q.buffered
+	q.buffered = nil
+	q.mu.Unlock()
+	var errs []error
+	for _, d := range ds {
+		r := d.Token.(row)
+		_, err := q.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = 'pending', attempts = attempts - 1, locked_until = NULL
+			WHERE id = $1 AND status = 'running' AND attempts = $2`, q.cfg.Table), r.id, r.attempts)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,237 @@
+package sqlqueue
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// fakeTable 以内存中的行模拟 jobs 表，按语句中的关键字解释 Queue 发出的几种 SQL
+type fakeTable struct {
+	mu   sync.Mutex
+	now  time.Time
+	rows []*fakeRow
+}
+
+type fakeRow struct {
+	id          int64
+	typ         string
+	payload     []byte
+	key         string
+	status      string
+	attempts    int64
+	lockedUntil time.Time
+	lastError   string
+}
+
+func (tb *fakeTable) advance(d time.Duration) {
+	tb.mu.Lock()
+	tb.now = tb.now.Add(d)
+	tb.mu.Unlock()
+}
+
+func (tb *fakeTable) row(id int64) fakeRow {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return *tb.rows[id-1]
+}
+
+func (tb *fakeTable) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	arg := func(i int) driver.Value { return args[i-1].Value }
+	expired := func(r *fakeRow) bool { return r.status == "running" && r.lockedUntil.Before(tb.now) }
+	n := int64(0)
+	switch {
+	case strings.Contains(query, "INSERT INTO"):
+		tb.rows = append(tb.rows, &fakeRow{id: int64(len(tb.rows) + 1), typ: arg(1).(string), payload: arg(2).([]byte), key: arg(3).(string), status: "pending"})
+		n = 1
+	case strings.Contains(query, "lease expired"):
+		for _, r := range tb.rows {
+			if expired(r) && r.attempts >= arg(1).(int64) {
+				r.status, r.lastError = "failed", "lease expired"
+				n++
+			}
+		}
+	default:
+		id, attempts := arg(1).(int64), arg(2).(int64)
+		for _, r := range tb.rows {
+			if r.id != id || r.status != "running" || r.attempts != attempts {
+				continue
+			}
+			n++
+			switch {
+			case strings.Contains(query, "status = 'done'"):
+				r.status = "done"
+			case strings.Contains(query, "status = $3"):
+				r.status, r.lastError = arg(3).(string), arg(4).(string)
+			case strings.Contains(query, "attempts = attempts - 1"):
+				r.status, r.attempts = "pending", r.attempts-1
+			default:
+				return nil, errors.New("unexpected exec: " + query)
+			}
+		}
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (tb *fakeTable) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	switch {
+	case strings.Contains(query, "count(*)"):
+		n := int64(0)
+		for _, r := range tb.rows {
+			if r.status == "pending" {
+				n++
+			}
+		}
+		return &fakeRows{cols: []string{"count"}, vals: [][]driver.Value{{n}}}, nil
+	case strings.Contains(query, "SET status = 'running'"):
+		limit, lease, maxAttempts := args[0].Value.(int64), args[1].Value.(float64), args[2].Value.(int64)
+		out := &fakeRows{cols: []string{"id", "type", "payload", "key", "attempts"}}
+		for _, r := range tb.rows {
+			if int64(len(out.vals)) == limit {
+				break
+			}
+			if r.status == "pending" || r.status == "running" && r.lockedUntil.Before(tb.now) && r.attempts < maxAttempts {
+				r.status, r.attempts = "running", r.attempts+1
+				r.lockedUntil = tb.now.Add(time.Duration(lease * float64(time.Second)))
+				out.vals = append(out.vals, []driver.Value{r.id, r.typ, r.payload, r.key, r.attempts})
+			}
+		}
+		return out, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type fakeRows struct {
+	cols []string
+	vals [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.vals) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.vals[0])
+	r.vals = r.vals[1:]
+	return nil
+}
+
+type fakeConn struct{ tb *fakeTable }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (c fakeConn) ExecContext(_ context.Context, q string, args []driver.NamedValue) (driver.Result, error) {
+	return c.tb.exec(q, args)
+}
+func (c fakeConn) QueryContext(_ context.Context, q string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.tb.query(q, args)
+}
+
+type fakeConnector struct{ tb *fakeTable }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+func newFakeQueue(t *testing.T, cfg Config) (*Queue, *fakeTable) {
+	tb := &fakeTable{now: time.Unix(1000, 0)}
+	db := sql.OpenDB(fakeConnector{tb})
+	t.Cleanup(func() { db.Close() })
+	return New(db, cfg), tb
+}
+
+func pop(t *testing.T, q *Queue) workerpool.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestQueueAck(t *testing.T) {
+	q, tb := newFakeQueue(t, Config{Poll: time.Millisecond, MaxAttempts: 2})
+	ctx := context.Background()
+	for _, p := range []string{"a", "b"} {
+		if err := q.Push(ctx, workerpool.Job{Type: "t", Payload: []byte(p), Key: p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := q.Len(ctx); n != 2 || err != nil {
+		t.Fatalf("Len = %d, %v", n, err)
+	}
+	d := pop(t, q)
+	if string(d.Job.Payload) != "a" || d.Job.Key != "a" || d.Job.Attempt != 1 {
+		t.Fatalf("Pop = %+v", d.Job)
+	}
+	if err := q.Ack(ctx, d, nil); err != nil || tb.row(1).status != "done" {
+		t.Fatalf("Ack: %v, status %s", err, tb.row(1).status)
+	}
+
+	d = pop(t, q)
+	if err := q.Ack(ctx, d, errors.New("boom")); err != nil || tb.row(2).status != "pending" {
+		t.Fatalf("first failure: %v, status %s", err, tb.row(2).status)
+	}
+	d = pop(t, q)
+	if d.Job.Attempt != 2 {
+		t.Fatalf("retry attempt = %d", d.Job.Attempt)
+	}
+	if err := q.Ack(ctx, d, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	if r := tb.row(2); r.status != "failed" || r.lastError != "boom" {
+		t.Fatalf("after MaxAttempts: %+v", r)
+	}
+}
+
+func TestQueueExpiredLeaseBounded(t *testing.T) {
+	q, tb := newFakeQueue(t, Config{Poll: time.Millisecond, Lease: time.Minute, MaxAttempts: 2, Batch: 1})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "crash"})
+	for attempt := 1; attempt <= 2; attempt++ {
+		if d := pop(t, q); d.Job.Attempt != attempt {
+			t.Fatalf("attempt = %d, want %d", d.Job.Attempt, attempt)
+		}
+		tb.advance(2 * time.Minute) // 进程在 Ack 之前崩溃，认领过期
+	}
+	pctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if d, err := q.Pop(pctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("row re-claimed past MaxAttempts: %+v, %v", d.Job, err)
+	}
+	if r := tb.row(1); r.status != "failed" || r.attempts != 2 {
+		t.Fatalf("row = %+v", r)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	q, tb := newFakeQueue(t, Config{Poll: time.Millisecond, Batch: 2})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t"})
+	q.Push(ctx, workerpool.Job{Type: "t"})
+	pop(t, q) // 第二行已认领，留在本地缓冲中
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r := tb.row(2); r.status != "pending" || r.attempts != 0 {
+		t.Fatalf("buffered row after Close = %+v", r)
+	}
+	if r := tb.row(1); r.status != "running" {
+		t.Fatalf("dispatched row after Close = %+v", r)
+	}
+}
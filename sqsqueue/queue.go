@@ -0,0 +1,222 @@
+// Package sqsqueue 以 Amazon SQS 作为 workerpool 的 Job 队列：
+// pool 长轮询拉取消息并按容量执行，执行成功后才删除消息；执行期间定期延长消息的可见性超时，
+// 避免长任务尚未结束时消息被投递给其它消费者；执行失败的消息立即恢复可见，
+// 由 SQS 的重投与死信队列（redrive policy）处理
+//
+// 包本身不依赖 AWS SDK，使用 aws-sdk-go-v2 时以 *sqs.Client 实现 Client 即可：
+//
+//	func (c sdkClient) DeleteMessage(ctx context.Context, queueURL, receipt string) error {
+//		_, err := c.c.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: &queueURL, ReceiptHandle: &receipt})
+//		return err
+//	}
+//	...
+package sqsqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// Message 是 ReceiveMessages 返回的一条消息
+type Message struct {
+	ID            string
+	ReceiptHandle string
+	Body          string
+	ReceiveCount  int // ApproximateReceiveCount 属性，包括本次接收，作为 Job.Attempt；0 视为 1
+}
+
+// Client 是 Queue 用到的 SQS API
+type Client interface {
+	SendMessage(ctx context.Context, queueURL, body string) error
+	// ReceiveMessages 对应 ReceiveMessage，wait 为长轮询时间，visibility 为取出消息的可见性超时；
+	// 须请求 ApproximateReceiveCount 属性并填入 Message.ReceiveCount
+	ReceiveMessages(ctx context.Context, queueURL string, max int, wait, visibility time.Duration) ([]Message, error)
+	DeleteMessage(ctx context.Context, queueURL, receiptHandle string) error
+	ChangeMessageVisibility(ctx context.Context, queueURL, receiptHandle string, timeout time.Duration) error
+	// ApproximateLen 对应 GetQueueAttributes 中的 ApproximateNumberOfMessages
+	ApproximateLen(ctx context.Context, queueURL string) (int, error)
+}
+
+type Config struct {
+	QueueURL    string
+	MaxMessages int                              // 每次拉取的最大消息数，默认 10（SQS 的上限）
+	WaitTime    time.Duration                    // 长轮询时间，默认 20s
+	Visibility  time.Duration                    // 可见性超时，执行期间每隔 Visibility/2 续期一次，默认 30s
+	Logf        func(format string, args ...any) // 日志输出，默认 fmt.Printf
+}
+
+type Queue struct {
+	c   Client
+	cfg Config
+
+	mu       sync.Mutex
+	buffered []*inflight            // 已拉取但尚未交给 pool 的消息
+	pending  map[*inflight]struct{} // 已拉取但尚未 Ack 的消息，包括 buffered
+}
+
+var _ workerpool.Queue = (*Queue)(nil)
+
+// 一条已拉取的消息，从拉取到 Ack 期间持续续期
+type inflight struct {
+	msg   Message
+	mu    sync.Mutex
+	timer *time.Timer
+	done  bool
+}
+
+type message struct {
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	Key     string `json:"key,omitempty"`
+}
+
+func New(c Client, cfg Config) *Queue {
+	if cfg.MaxMessages <= 0 || cfg.MaxMessages > 10 {
+		cfg.MaxMessages = 10
+	}
+	if cfg.WaitTime <= 0 {
+		cfg.WaitTime = 20 * time.Second
+	}
+	if cfg.Visibility <= 0 {
+		cfg.Visibility = 30 * time.Second
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(format string, args ...any) { fmt.Printf(format, args...) }
+	}
+	return &Queue{c: c, cfg: cfg, pending: make(map[*inflight]struct{})}
+}
+
+func (q *Queue) Push(ctx context.Context, j workerpool.Job) error {
+	b, err := json.Marshal(message{Type: j.Type, Payload: j.Payload, Key: j.Key})
+	if err != nil {
+		return err
+	}
+	return q.c.SendMessage(ctx, q.cfg.QueueURL, string(b))
+}
+
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	return q.c.ApproximateLen(ctx, q.cfg.QueueURL)
+}
+
+// Pop 返回一条消息，本地没有已拉取的消息时长轮询 SQS，直到拉到消息或 ctx 取消；
+// Job.Attempt 取自消息的接收次数，失败后重投的消息随之递增。无法解析的消息直接删除
+func (q *Queue) Pop(ctx context.Context) (workerpool.Delivery, error) {
+	for {
+		m, err := q.next(ctx)
+		if err != nil {
+			return workerpool.Delivery{}, err
+		}
+		var msg message
+		if err := json.Unmarshal([]byte(m.msg.Body), &msg); err != nil {
+			q.cfg.Logf("sqsqueue: bad message %s: %s\n", m.msg.ID, err)
+			q.stop(m)
+			if err := q.c.DeleteMessage(context.WithoutCancel(ctx), q.cfg.QueueURL, m.msg.ReceiptHandle); err != nil {
+				q.cfg.Logf("sqsqueue: delete message %s: %s\n", m.msg.ID, err)
+			}
+			continue
+		}
+		j := workerpool.Job{Type: msg.Type, Payload: msg.Payload, Key: msg.Key, Attempt: max(m.msg.ReceiveCount, 1)}
+		return workerpool.Delivery{Job: j, Token: m}, nil
+	}
+}
+
+func (q *Queue) next(ctx context.Context) (*inflight, error) {
+	for {
+		q.mu.Lock()
+		if len(q.buffered) > 0 {
+			m := q.buffered[0]
+			q.buffered = q.buffered[1:]
+			q.mu.Unlock()
+			return m, nil
+		}
+		q.mu.Unlock()
+		msgs, err := q.c.ReceiveMessages(ctx, q.cfg.QueueURL, q.cfg.MaxMessages, q.cfg.WaitTime, q.cfg.Visibility)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return nil, err
+		}
+		q.mu.Lock()
+		for _, msg := range msgs {
+			m := &inflight{msg: msg}
+			m.mu.Lock() // extend 可能在赋值 timer 之前触发
+			m.timer = time.AfterFunc(q.cfg.Visibility/2, func() { q.extend(m) })
+			m.mu.Unlock()
+			q.buffered = append(q.buffered, m)
+			q.pending[m] = struct{}{}
+		}
+		q.mu.Unlock()
+		if len(msgs) == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// extend 续期 m 的可见性超时，并在 Visibility/2 后再次续期
+func (q *Queue) extend(m *inflight) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return
+	}
+	if err := q.c.ChangeMessageVisibility(context.Background(), q.cfg.QueueURL, m.msg.ReceiptHandle, q.cfg.Visibility); err != nil {
+		q.cfg.Logf("sqsqueue: extend visibility of %s: %s\n", m.msg.ID, err)
+	}
+	m.timer.Reset(q.cfg.Visibility / 2)
+}
+
+// stop 停止续期，返回是否是第一次调用
+func (q *Queue) stop(m *inflight) bool {
+	q.mu.Lock()
+	delete(q.pending, m)
+	q.mu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	first := !m.done
+	m.done = true
+	m.timer.Stop()
+	return first
+}
+
+// Ack 在执行成功时删除消息；失败时将可见性超时置 0，使其立即可以被重新投递
+func (q *Queue) Ack(ctx context.Context, d workerpool.Delivery, err error) error {
+	m, ok := d.Token.(*inflight)
+	if !ok {
+		return errors.New("sqsqueue: delivery not from this queue")
+	}
+	if !q.stop(m) {
+		return nil // 已被 Close 释放
+	}
+	if err != nil {
+		return q.c.ChangeMessageVisibility(ctx, q.cfg.QueueURL, m.msg.ReceiptHandle, 0)
+	}
+	return q.c.DeleteMessage(ctx, q.cfg.QueueURL, m.msg.ReceiptHandle)
+}
+
+// Close 停止续期所有已拉取但尚未 Ack 的消息并使其立即可见，交给其它消费者处理；
+// 应在 pool 的 Free 返回后调用，此后执行中的任务都已结束
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	ms := make([]*inflight, 0, len(q.pending))
+	for m := range q.pending {
+		ms = append(ms, m)
+	}
+	q.buffered = nil
+	q.mu.Unlock()
+	var errs []error
+	for _, m := range ms {
+		if q.stop(m) {
+			if err := q.c.ChangeMessageVisibility(ctx, q.cfg.QueueURL, m.msg.ReceiptHandle, 0); err != nil {
+				errs = append(errs, fmt.Errorf("release %s: %w", m.msg.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,216 @@
+package sqsqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// fakeSQS 模拟一个 SQS 队列：接收的消息在可见性超时内不可见，接收次数随每次接收递增
+type fakeSQS struct {
+	mu       sync.Mutex
+	seq      int
+	msgs     map[string]*fakeMessage // 以 receipt handle 为键
+	extended int                     // 以非 0 超时调用 ChangeMessageVisibility 的次数
+}
+
+type fakeMessage struct {
+	id        string
+	body      string
+	received  int
+	invisible time.Time
+}
+
+func newFakeSQS() *fakeSQS {
+	return &fakeSQS{msgs: make(map[string]*fakeMessage)}
+}
+
+func (c *fakeSQS) SendMessage(_ context.Context, _, body string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	id := strconv.Itoa(c.seq)
+	c.msgs[id] = &fakeMessage{id: id, body: body}
+	return nil
+}
+
+func (c *fakeSQS) ReceiveMessages(ctx context.Context, _ string, max int, _, visibility time.Duration) ([]Message, error) {
+	for {
+		c.mu.Lock()
+		var out []Message
+		now := time.Now()
+		for receipt, m := range c.msgs {
+			if len(out) == max || now.Before(m.invisible) {
+				continue
+			}
+			m.received++
+			m.invisible = now.Add(visibility)
+			out = append(out, Message{ID: m.id, ReceiptHandle: receipt, Body: m.body, ReceiveCount: m.received})
+		}
+		c.mu.Unlock()
+		if len(out) > 0 {
+			return out, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func (c *fakeSQS) DeleteMessage(_ context.Context, _, receipt string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.msgs[receipt]; !ok {
+		return errors.New("receipt handle is invalid")
+	}
+	delete(c.msgs, receipt)
+	return nil
+}
+
+func (c *fakeSQS) ChangeMessageVisibility(_ context.Context, _, receipt string, timeout time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.msgs[receipt]
+	if !ok {
+		return errors.New("receipt handle is invalid")
+	}
+	m.invisible = time.Now().Add(timeout)
+	if timeout > 0 {
+		c.extended++
+	}
+	return nil
+}
+
+func (c *fakeSQS) ApproximateLen(context.Context, string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.msgs), nil
+}
+
+func (c *fakeSQS) extensions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.extended
+}
+
+func pop(t *testing.T, q *Queue) workerpool.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestQueueAck(t *testing.T) {
+	c := newFakeSQS()
+	q := New(c, Config{QueueURL: "q"})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t", Payload: []byte("a"), Key: "k"})
+	d := pop(t, q)
+	if d.Job.Type != "t" || string(d.Job.Payload) != "a" || d.Job.Key != "k" || d.Job.Attempt != 1 {
+		t.Fatalf("Pop = %+v", d.Job)
+	}
+	if err := q.Ack(ctx, d, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("Len after Ack = %d", n)
+	}
+}
+
+func TestQueueRedeliverCountsAttempts(t *testing.T) {
+	c := newFakeSQS()
+	q := New(c, Config{QueueURL: "q"})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "t"})
+	for attempt := 1; attempt <= 3; attempt++ {
+		d := pop(t, q)
+		if d.Job.Attempt != attempt {
+			t.Fatalf("Attempt = %d, want %d", d.Job.Attempt, attempt)
+		}
+		if err := q.Ack(ctx, d, errors.New("boom")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQueueExtendsVisibility(t *testing.T) {
+	c := newFakeSQS()
+	q := New(c, Config{QueueURL: "q", Visibility: 20 * time.Millisecond})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "slow"})
+	d := pop(t, q)
+	time.Sleep(60 * time.Millisecond) // 超过可见性超时，续期使消息不被重投
+	pctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if d, err := q.Pop(pctx); err == nil {
+		t.Fatalf("message redelivered while running: %+v", d.Job)
+	}
+	if c.extensions() == 0 {
+		t.Fatal("visibility never extended")
+	}
+	if err := q.Ack(ctx, d, nil); err != nil {
+		t.Fatal(err)
+	}
+	n := c.extensions()
+	time.Sleep(30 * time.Millisecond)
+	if c.extensions() != n {
+		t.Fatal("visibility extended after Ack")
+	}
+}
+
+func TestQueueBadMessageDeleted(t *testing.T) {
+	c := newFakeSQS()
+	var logged []string
+	q := New(c, Config{QueueURL: "q", MaxMessages: 1, Logf: func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}})
+	ctx := context.Background()
+	c.SendMessage(ctx, "q", "not json")
+	pctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(pctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Pop = %v", err)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("bad message not deleted, Len = %d", n)
+	}
+	if len(logged) != 1 || !strings.HasPrefix(logged[0], "sqsqueue: bad message 1") {
+		t.Fatalf("logged %q", logged)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	c := newFakeSQS()
+	q := New(c, Config{QueueURL: "q", Visibility: time.Minute})
+	ctx := context.Background()
+	q.Push(ctx, workerpool.Job{Type: "a"})
+	q.Push(ctx, workerpool.Job{Type: "b"})
+	d := pop(t, q) // 两条一起拉取，另一条留在本地缓冲中
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Ack(ctx, d, nil); err != nil {
+		t.Fatal(err)
+	}
+	other := New(c, Config{QueueURL: "q"})
+	for range 2 { // Close 释放的消息立即可见，Close 之后的 Ack 不再删除
+		d := pop(t, other)
+		if d.Job.Attempt != 2 {
+			t.Fatalf("released message %+v", d.Job)
+		}
+		other.Ack(ctx, d, nil)
+	}
+}
@@ -0,0 +1,98 @@
+// Package workerpool 是 workerpool 的 v2 接口：所有可能阻塞的操作都接受 ctx，任务总是收到 ctx，
+// New 对不合理的参数返回错误而不是纠正，默认不打印日志。
+// v2 建立在 v1（workerpool/pool）之上，v1 的接口保持不变；v2 暂未覆盖的功能可以通过 Pool.V1 使用
+//
+//	p, err := workerpool.New(8, workerpool.WithQueueSize(64))
+//	if err != nil {
+//		return err
+//	}
+//	defer p.Close(context.Background())
+//	err = p.Submit(ctx, func(ctx context.Context) { ... })
+package workerpool
+
+import (
+	"context"
+
+	v1 "workerpool/pool"
+)
+
+// Task 是提交到 pool 的任务，ctx 在提交方的 ctx 取消或 pool 销毁时取消，其中携带所在 worker 的信息，见 v1.WorkerID
+type Task func(ctx context.Context)
+
+// Option 与 v1 的选项相同，v1 中所有的 WithXxx 都可以直接使用
+type Option = v1.Option
+
+// Stats 是 Pool.Stats 返回的运行状态
+type Stats = v1.PoolStats
+
+// 常用的选项，其余见 v1
+var (
+	WithName       = v1.WithName
+	WithQueueSize  = v1.WithQueueSize
+	WithLogger     = v1.WithLogger
+	WithSlog       = v1.WithSlog
+	WithContext    = v1.WithContext
+	WithMiddleware = v1.WithMiddleware
+)
+
+// 与 v1 相同的错误，可以用 errors.Is 判断
+var (
+	ErrPoolClosed    = v1.ErrPoolClosed
+	ErrPoolSaturated = v1.ErrPoolSaturated
+	ErrInvalidOption = v1.ErrInvalidOption
+)
+
+type Pool struct {
+	p *v1.Pool
+}
+
+// New 创建最多 capacity 个 worker 的 pool；capacity 或选项不合理时返回包装 ErrInvalidOption 的错误
+// 默认不打印日志（需要时使用 WithLogger 或 WithSlog），提交总是阻塞到得到 worker 或 ctx 取消
+func New(capacity int, opts ...Option) (*Pool, error) {
+	p, err := v1.NewE(capacity, append([]Option{v1.WithLogger(nil), v1.WithBlock(true)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{p: p}, nil
+}
+
+// Submit 提交 t，阻塞直到有 worker 接受或 ctx 取消（返回 ctx.Err()）；t 的 ctx 在提交方的 ctx 取消时随之取消
+func (p *Pool) Submit(ctx context.Context, t Task) error {
+	return p.p.ScheduleCtx(ctx, v1.TaskFunc(t))
+}
+
+// TrySubmit 与 Submit 相同但不阻塞，没有空闲 worker 时立即返回 ErrPoolSaturated
+func (p *Pool) TrySubmit(t Task) error {
+	return p.p.TryScheduleFunc(v1.TaskFunc(t))
+}
+
+// Wait 阻塞直到所有已提交的任务执行完毕、pool 已关闭或 ctx 取消，ctx 取消时返回 ctx.Err()
+func (p *Pool) Wait(ctx context.Context) error {
+	return p.p.WaitCtx(ctx)
+}
+
+// Close 停止接受新的任务，执行完所有已接受的任务后释放 worker；
+// ctx 取消时返回 ctx.Err()，关闭在后台继续，需要放弃未执行的任务时调用 Abort
+func (p *Pool) Close(ctx context.Context) error {
+	return p.p.Drain(ctx)
+}
+
+// Abort 立即关闭 pool：未开始的任务被丢弃，执行中任务的 ctx 以 cause 取消，返回时 worker 均已退出
+func (p *Pool) Abort(cause error) {
+	p.p.FreeWithCause(cause)
+}
+
+// Stats 返回当前的运行状态
+func (p *Pool) Stats() Stats {
+	return p.p.Stats()
+}
+
+// Cap 返回 worker 数量上限
+func (p *Pool) Cap() int {
+	return p.p.Cap()
+}
+
+// V1 返回底层的 v1 pool，用于 v2 尚未覆盖的功能
+func (p *Pool) V1() *v1.Pool {
+	return p.p
+}
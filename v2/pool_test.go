@@ -0,0 +1,56 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	if _, err := New(0); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("New(0): %v", err)
+	}
+	p, err := New(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if err := p.Submit(context.Background(), func(ctx context.Context) {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := p.TrySubmit(func(context.Context) {}); !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("TrySubmit on a full pool: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func(context.Context) {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit past the deadline: %v", err)
+	}
+	if err := p.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait past the deadline: %v", err)
+	}
+	close(release)
+
+	var ran atomic.Int32
+	for i := 0; i < 10; i++ {
+		if err := p.Submit(context.Background(), func(context.Context) { ran.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := ran.Load(); n != 10 {
+		t.Fatalf("%d of 10 tasks ran before Close returned", n)
+	}
+	if err := p.Submit(context.Background(), func(context.Context) {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Submit after Close: %v", err)
+	}
+}
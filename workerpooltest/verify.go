@@ -0,0 +1,56 @@
+// Package workerpooltest 提供测试 workerpool 使用方代码时的辅助函数
+package workerpooltest
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// Free 返回后 goroutine 可能还在执行最后几条指令，留出一点退出时间
+var leakGracePeriod = time.Second
+
+// VerifyFreed 检查 p 派生的 worker 及内部 goroutine 在 Free 之后是否都已退出，
+// 若仍有存活的 goroutine，则以带标签的栈信息使测试失败
+// 应在 p.Free() 返回后调用
+func VerifyFreed(t testing.TB, p *workerpool.Pool) {
+	t.Helper()
+	deadline := time.Now().Add(leakGracePeriod)
+	for {
+		leaks := Leaks(p)
+		if len(leaks) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("workerpool %d: %d goroutine group(s) still alive after Free:\n\n%s",
+				p.ID(), len(leaks), strings.Join(leaks, "\n\n"))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Leaks 返回当前仍存活的、属于 p 的 goroutine 栈（按相同栈与标签分组）
+func Leaks(p *workerpool.Pool) []string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		panic(err)
+	}
+	// debug=1 格式中每组 goroutine 以空行分隔，带标签的组包含一行 "# labels: {...}"
+	want := fmt.Sprintf("%q:%q", workerpool.LabelPool, fmt.Sprint(p.ID()))
+	var leaks []string
+	for _, group := range strings.Split(buf.String(), "\n\n") {
+		for _, line := range strings.Split(group, "\n") {
+			if strings.HasPrefix(line, "# labels: ") && strings.Contains(line, want) {
+				leaks = append(leaks, strings.TrimSpace(group))
+				break
+			}
+		}
+	}
+	return leaks
+}
@@ -0,0 +1,56 @@
+package workerpooltest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	workerpool "workerpool/pool"
+)
+
+// recorder 记录 VerifyFreed 报告的失败
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestVerifyFreed(t *testing.T) {
+	p := workerpool.New(2, workerpool.WithLogger(nil))
+	for range 4 {
+		p.Schedule(func() { time.Sleep(time.Millisecond) })
+	}
+	p.Free()
+	VerifyFreed(t, p)
+}
+
+func TestLeaksReportsLiveWorkers(t *testing.T) {
+	grace := leakGracePeriod
+	leakGracePeriod = 20 * time.Millisecond
+	defer func() { leakGracePeriod = grace }()
+
+	p := workerpool.New(1, workerpool.WithLogger(nil))
+	other := workerpool.New(1, workerpool.WithLogger(nil))
+	defer other.Free()
+	started, release := make(chan struct{}), make(chan struct{})
+	p.Schedule(func() { close(started); <-release })
+	other.Schedule(func() {})
+	<-started
+
+	r := &recorder{TB: t}
+	VerifyFreed(r, p) // 尚未 Free，worker 仍在执行
+	if len(r.errs) != 1 || !strings.Contains(r.errs[0], fmt.Sprintf("workerpool %d:", p.ID())) {
+		t.Fatalf("VerifyFreed on a live pool reported %q", r.errs)
+	}
+	if !strings.Contains(r.errs[0], workerpool.LabelPool) {
+		t.Fatalf("report lacks goroutine labels:\n%s", r.errs[0])
+	}
+	close(release)
+	p.Free()
+	VerifyFreed(t, p) // 另一个 pool 的 goroutine 不计入
+}